@@ -24,7 +24,6 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -186,7 +185,7 @@ func verify(ctx *cli.Context) error {
 		return errors.New("missing accumulators file")
 	}
 
-	roots, err := readHashes(ctx.Args().First())
+	roots, err := era.ReadRoots(ctx.Args().First())
 	if err != nil {
 		return fmt.Errorf("unable to read expected roots file: %w", err)
 	}
@@ -304,22 +303,3 @@ func checkAccumulator(e *era.Era) error {
 	}
 	return nil
 }
-
-// readHashes reads a file of newline-delimited hashes.
-func readHashes(f string) ([]common.Hash, error) {
-	b, err := os.ReadFile(f)
-	if err != nil {
-		return nil, errors.New("unable to open accumulators file")
-	}
-	s := strings.Split(string(b), "\n")
-	// Remove empty last element, if present.
-	if s[len(s)-1] == "" {
-		s = s[:len(s)-1]
-	}
-	// Convert to hashes.
-	r := make([]common.Hash, len(s))
-	for i := range s {
-		r[i] = common.HexToHash(s[i])
-	}
-	return r, nil
-}
@@ -0,0 +1,395 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	GasBenchForkFlag = &cli.StringFlag{
+		Name:     "fork",
+		Usage:    "Fork (optionally with extra EIPs, e.g. Cancun+3855) to benchmark",
+		Value:    "Cancun",
+		Category: flags.VMCategory,
+	}
+	GasBenchGasFlag = &cli.Uint64Flag{
+		Name:     "gas",
+		Usage:    "Gas limit of a single benchmark program",
+		Value:    10_000_000,
+		Category: flags.VMCategory,
+	}
+	GasBenchRunsFlag = &cli.IntFlag{
+		Name:     "runs",
+		Usage:    "Number of times each program is executed, the fastest run is reported",
+		Value:    5,
+		Category: flags.VMCategory,
+	}
+	GasBenchOutlierFlag = &cli.Float64Flag{
+		Name:     "outlier",
+		Usage:    "Factor from the median ns/gas above (or below) which an entry is flagged as mispriced",
+		Value:    2,
+		Category: flags.VMCategory,
+	}
+	GasBenchOutFlag = &cli.StringFlag{
+		Name:     "out",
+		Usage:    "File to write the JSON report to",
+		Category: flags.VMCategory,
+	}
+	GasBenchToleranceFlag = &cli.Float64Flag{
+		Name:     "tolerance",
+		Usage:    "Change of ns/gas in percent above which an entry is reported as changed",
+		Value:    10,
+		Category: flags.VMCategory,
+	}
+)
+
+var gasBenchCommand = &cli.Command{
+	Name:  "gasbench",
+	Usage: "Measures the execution time per unit of gas of opcodes and precompiles",
+	Subcommands: []*cli.Command{
+		{
+			Action: gasBenchRunCmd,
+			Name:   "run",
+			Usage:  "Executes a generated corpus of worst case programs and reports their ns/gas",
+			Flags: []cli.Flag{
+				GasBenchForkFlag,
+				GasBenchGasFlag,
+				GasBenchRunsFlag,
+				GasBenchOutlierFlag,
+				GasBenchOutFlag,
+			},
+			Description: `
+The run command generates a program for every opcode and precompile active in the
+given fork. Each program executes its operation in a loop with worst case operands
+until it runs out of gas. The time spent per unit of gas is reported, and entries
+deviating from the median by more than the outlier factor are flagged, as their
+gas cost does not reflect their execution time.`,
+		},
+		{
+			Action:    gasBenchCompareCmd,
+			Name:      "compare",
+			Usage:     "Compares two JSON reports produced by the run command",
+			ArgsUsage: "<old.json> <new.json>",
+			Flags: []cli.Flag{
+				GasBenchToleranceFlag,
+			},
+		},
+	},
+}
+
+// gasBenchReport is the JSON report of a gas benchmark run.
+type gasBenchReport struct {
+	Fork           string            `json:"fork"`
+	GasLimit       uint64            `json:"gasLimit"`
+	MedianNsPerGas float64           `json:"medianNsPerGas"`
+	Results        []*gasBenchResult `json:"results"`
+}
+
+// gasBenchResult is the measurement of a single benchmark program.
+type gasBenchResult struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	StaticGas uint64  `json:"staticGas"`
+	GasUsed   uint64  `json:"gasUsed"`
+	Ns        int64   `json:"ns"`
+	NsPerGas  float64 `json:"nsPerGas"`
+	Ratio     float64 `json:"ratio"` // NsPerGas relative to the median of the report
+	Error     string  `json:"error,omitempty"`
+}
+
+func gasBenchRunCmd(ctx *cli.Context) error {
+	fork := ctx.String(GasBenchForkFlag.Name)
+	config, eips, err := tests.GetChainConfig(fork)
+	if err != nil {
+		return err
+	}
+	programs, err := gasBenchPrograms(config, eips)
+	if err != nil {
+		return err
+	}
+	var (
+		gasLimit = ctx.Uint64(GasBenchGasFlag.Name)
+		runs     = ctx.Int(GasBenchRunsFlag.Name)
+	)
+	if runs < 1 {
+		return errors.New("at least one run is required")
+	}
+	report := &gasBenchReport{Fork: fork, GasLimit: gasLimit}
+	for _, p := range programs {
+		report.Results = append(report.Results, runGasBenchProgram(config, eips, p, gasLimit, runs))
+	}
+	report.computeRatios()
+	report.print(os.Stdout, ctx.Float64(GasBenchOutlierFlag.Name))
+
+	if out := ctx.String(GasBenchOutFlag.Name); out != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(out, data, 0644)
+	}
+	return nil
+}
+
+// gasBenchRules returns the rules of the given chain config at genesis.
+func gasBenchRules(config *params.ChainConfig) params.Rules {
+	return config.Rules(common.Big0, config.TerminalTotalDifficulty != nil, 0)
+}
+
+// gasBenchPrograms generates the benchmark programs for all opcodes and
+// precompiles active on the given chain.
+func gasBenchPrograms(config *params.ChainConfig, eips []int) ([]*benchProgram, error) {
+	rules := gasBenchRules(config)
+
+	// Forks which are not finalized yet return the closest instruction set along
+	// with an error, benchmark that one.
+	table, _ := vm.LookupInstructionSet(rules)
+	for _, eip := range eips {
+		if err := vm.EnableEIP(eip, &table); err != nil {
+			return nil, err
+		}
+	}
+	precompiles, err := precompilePrograms(vm.ActivePrecompiledContracts(rules))
+	if err != nil {
+		return nil, err
+	}
+	return append(opcodePrograms(table), precompiles...), nil
+}
+
+// runGasBenchProgram executes the program the given number of times, and measures
+// the fastest run. Programs are expected to run out of gas, any other error is
+// recorded in the result.
+func runGasBenchProgram(config *params.ChainConfig, eips []int, p *benchProgram, gasLimit uint64, runs int) *gasBenchResult {
+	var (
+		result = &gasBenchResult{Name: p.name, Kind: p.kind, StaticGas: p.staticGas}
+		addr   = common.BytesToAddress([]byte("gasbench"))
+	)
+	// Calls only forward 63/64 of the available gas, make sure the operation can
+	// be executed at least once, otherwise only the loop overhead is measured.
+	if p.staticGas > gasLimit-gasLimit/64 {
+		result.Error = fmt.Sprintf("gas limit %d too low for a single execution", gasLimit)
+		return result
+	}
+	for i := 0; i < runs; i++ {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(addr, p.code)
+
+		cfg := &runtime.Config{
+			ChainConfig: config,
+			State:       statedb,
+			GasLimit:    gasLimit,
+			BlockNumber: big.NewInt(0),
+			EVMConfig:   vm.Config{ExtraEips: eips},
+		}
+		if config.TerminalTotalDifficulty != nil {
+			cfg.Random = new(common.Hash)
+		}
+		start := time.Now()
+		_, leftOver, err := runtime.Call(addr, p.input, cfg)
+		elapsed := time.Since(start)
+
+		if err != nil && !errors.Is(err, vm.ErrOutOfGas) {
+			result.Error = err.Error()
+			return result
+		}
+		if i == 0 || elapsed.Nanoseconds() < result.Ns {
+			result.Ns = elapsed.Nanoseconds()
+			result.GasUsed = gasLimit - leftOver
+		}
+	}
+	if result.GasUsed > 0 {
+		result.NsPerGas = float64(result.Ns) / float64(result.GasUsed)
+	}
+	return result
+}
+
+// computeRatios sets the median ns/gas of the report and the ratio of every
+// successful result to it.
+func (r *gasBenchReport) computeRatios() {
+	var values []float64
+	for _, res := range r.Results {
+		if res.Error == "" && res.NsPerGas > 0 {
+			values = append(values, res.NsPerGas)
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	sort.Float64s(values)
+	if n := len(values); n%2 == 1 {
+		r.MedianNsPerGas = values[n/2]
+	} else {
+		r.MedianNsPerGas = (values[n/2-1] + values[n/2]) / 2
+	}
+	for _, res := range r.Results {
+		if res.Error == "" && res.NsPerGas > 0 {
+			res.Ratio = res.NsPerGas / r.MedianNsPerGas
+		}
+	}
+}
+
+// outliers returns the results whose ns/gas deviates from the median by more than
+// the given factor, the most underpriced first.
+func (r *gasBenchReport) outliers(factor float64) []*gasBenchResult {
+	var outliers []*gasBenchResult
+	for _, res := range r.Results {
+		if res.Ratio > 0 && (res.Ratio > factor || res.Ratio < 1/factor) {
+			outliers = append(outliers, res)
+		}
+	}
+	sort.SliceStable(outliers, func(i, j int) bool { return outliers[i].Ratio > outliers[j].Ratio })
+	return outliers
+}
+
+func (r *gasBenchReport) print(w io.Writer, factor float64) {
+	fmt.Fprintf(w, "%-16s %-10s %10s %12s %12s %10s %8s\n", "name", "kind", "static gas", "gas used", "time", "ns/gas", "ratio")
+	for _, res := range r.Results {
+		if res.Error != "" {
+			fmt.Fprintf(w, "%-16s %-10s %10d error: %v\n", res.Name, res.Kind, res.StaticGas, res.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%-16s %-10s %10d %12d %12v %10.3f %8.2f\n", res.Name, res.Kind, res.StaticGas, res.GasUsed, time.Duration(res.Ns), res.NsPerGas, res.Ratio)
+	}
+	fmt.Fprintf(w, "\nmedian: %.3f ns/gas\n", r.MedianNsPerGas)
+
+	outliers := r.outliers(factor)
+	if len(outliers) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\noutliers (more than %.2fx off the median):\n", factor)
+	for _, res := range outliers {
+		verdict := "underpriced"
+		if res.Ratio < 1 {
+			verdict = "overpriced"
+		}
+		fmt.Fprintf(w, "  %-16s %-10s %8.2fx  %s\n", res.Name, res.Kind, res.Ratio, verdict)
+	}
+}
+
+func readGasBenchReport(path string) (*gasBenchReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	report := new(gasBenchReport)
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("invalid report %s: %v", path, err)
+	}
+	return report, nil
+}
+
+// gasBenchChange is the difference of a single entry between two reports.
+type gasBenchChange struct {
+	Name    string
+	Kind    string
+	Old     float64 // ns/gas in the old report
+	New     float64 // ns/gas in the new report
+	Percent float64 // Relative change from the old to the new report
+}
+
+// compareGasBenchReports returns the entries present in both reports, whose
+// ns/gas changed by more than the given tolerance in percent. Entries only
+// present in one of the reports are returned separately.
+func compareGasBenchReports(old, new *gasBenchReport, tolerance float64) (changes []gasBenchChange, missing []string) {
+	key := func(res *gasBenchResult) string { return res.Kind + "/" + res.Name }
+
+	olds := make(map[string]*gasBenchResult)
+	for _, res := range old.Results {
+		olds[key(res)] = res
+	}
+	news := make(map[string]*gasBenchResult)
+	for _, res := range new.Results {
+		news[key(res)] = res
+		prev, ok := olds[key(res)]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s only in new report", key(res)))
+			continue
+		}
+		if prev.NsPerGas == 0 || res.NsPerGas == 0 {
+			continue
+		}
+		percent := (res.NsPerGas - prev.NsPerGas) / prev.NsPerGas * 100
+		if percent > tolerance || percent < -tolerance {
+			changes = append(changes, gasBenchChange{
+				Name:    res.Name,
+				Kind:    res.Kind,
+				Old:     prev.NsPerGas,
+				New:     res.NsPerGas,
+				Percent: percent,
+			})
+		}
+	}
+	for _, res := range old.Results {
+		if _, ok := news[key(res)]; !ok {
+			missing = append(missing, fmt.Sprintf("%s only in old report", key(res)))
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Percent > changes[j].Percent })
+	return changes, missing
+}
+
+func gasBenchCompareCmd(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return errors.New("two reports are required: <old.json> <new.json>")
+	}
+	old, err := readGasBenchReport(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	new, err := readGasBenchReport(ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	if old.Fork != new.Fork || old.GasLimit != new.GasLimit {
+		fmt.Printf("warning: comparing reports of different setups (%s/%d vs %s/%d)\n", old.Fork, old.GasLimit, new.Fork, new.GasLimit)
+	}
+	tolerance := ctx.Float64(GasBenchToleranceFlag.Name)
+	changes, missing := compareGasBenchReports(old, new, tolerance)
+
+	fmt.Printf("median: %.3f -> %.3f ns/gas\n", old.MedianNsPerGas, new.MedianNsPerGas)
+	if len(changes) == 0 {
+		fmt.Printf("no entry changed by more than %.1f%%\n", tolerance)
+	} else {
+		fmt.Printf("%-16s %-10s %10s %10s %9s\n", "name", "kind", "old ns/gas", "new ns/gas", "change")
+		for _, c := range changes {
+			fmt.Printf("%-16s %-10s %10.3f %10.3f %+8.1f%%\n", c.Name, c.Kind, c.Old, c.New, c.Percent)
+		}
+	}
+	for _, m := range missing {
+		fmt.Println(m)
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	opcodeRepeats     = 64 // Number of times an opcode is repeated in a loop iteration
+	precompileRepeats = 16 // Number of times a precompile is called in a loop iteration
+)
+
+// benchProgram is a single entry of the gas benchmark corpus: a piece of EVM code
+// looping forever over an operation, until it runs out of gas.
+type benchProgram struct {
+	name      string // Name of the benchmarked opcode or precompile
+	kind      string // Either "opcode" or "precompile"
+	staticGas uint64 // Constant gas of the opcode or gas of a single precompile call
+	code      []byte // Code of the program
+	input     []byte // Call data of the program
+}
+
+// operandKind describes the value placed on the stack for an operand.
+type operandKind int
+
+const (
+	maxWord operandKind = iota // The largest 256 bit word, the worst case for most arithmetic
+	small                      // A small constant, used for offsets and sizes
+	counter                    // A fresh value on every execution, forcing cold accesses
+)
+
+type operand struct {
+	kind  operandKind
+	value uint64 // Value of small operands
+}
+
+var (
+	opMax     = operand{kind: maxWord}
+	opCounter = operand{kind: counter}
+)
+
+func opSmall(v uint64) operand { return operand{kind: small, value: v} }
+
+// opcodeOperands contains the worst case operands, top of the stack first, for
+// opcodes which cannot use the largest word for all of their inputs. Accesses to
+// accounts and storage slots use a counter to be cold on every execution.
+var opcodeOperands = map[vm.OpCode][]operand{
+	vm.KECCAK256:      {opSmall(0), opSmall(1024)},
+	vm.BALANCE:        {opCounter},
+	vm.CALLDATACOPY:   {opSmall(0), opSmall(0), opSmall(1024)},
+	vm.CODECOPY:       {opSmall(0), opSmall(0), opSmall(1024)},
+	vm.EXTCODESIZE:    {opCounter},
+	vm.EXTCODECOPY:    {opCounter, opSmall(0), opSmall(0), opSmall(1024)},
+	vm.RETURNDATACOPY: {opSmall(0), opSmall(0), opSmall(0)},
+	vm.EXTCODEHASH:    {opCounter},
+	vm.BLOCKHASH:      {opSmall(0)},
+	vm.BLOBHASH:       {opSmall(0)},
+	vm.MLOAD:          {opSmall(0)},
+	vm.MSTORE:         {opSmall(0), opMax},
+	vm.MSTORE8:        {opSmall(0), opMax},
+	vm.SLOAD:          {opCounter},
+	vm.SSTORE:         {opCounter, opMax},
+	vm.TLOAD:          {opCounter},
+	vm.TSTORE:         {opCounter, opMax},
+	vm.MCOPY:          {opSmall(0), opSmall(1024), opSmall(1024)},
+	vm.LOG0:           {opSmall(0), opSmall(1024)},
+	vm.LOG1:           {opSmall(0), opSmall(1024), opMax},
+	vm.LOG2:           {opSmall(0), opSmall(1024), opMax, opMax},
+	vm.LOG3:           {opSmall(0), opSmall(1024), opMax, opMax, opMax},
+	vm.LOG4:           {opSmall(0), opSmall(1024), opMax, opMax, opMax, opMax},
+	vm.CREATE:         {opSmall(0), opSmall(0), opSmall(0)},
+	vm.CREATE2:        {opSmall(0), opSmall(0), opSmall(0), opCounter},
+	vm.CALL:           {opSmall(0), opCounter, opSmall(0), opSmall(0), opSmall(0), opSmall(0), opSmall(0)},
+	vm.CALLCODE:       {opSmall(0), opCounter, opSmall(0), opSmall(0), opSmall(0), opSmall(0), opSmall(0)},
+	vm.DELEGATECALL:   {opSmall(0), opCounter, opSmall(0), opSmall(0), opSmall(0), opSmall(0)},
+	vm.STATICCALL:     {opSmall(0), opCounter, opSmall(0), opSmall(0), opSmall(0), opSmall(0)},
+}
+
+// skippedOpcodes are the opcodes which halt execution or alter the control flow,
+// and hence cannot be repeated in a straight line.
+var skippedOpcodes = map[vm.OpCode]bool{
+	vm.STOP:         true,
+	vm.JUMP:         true,
+	vm.JUMPI:        true,
+	vm.RETURN:       true,
+	vm.REVERT:       true,
+	vm.INVALID:      true,
+	vm.SELFDESTRUCT: true,
+}
+
+// programBuilder assembles EVM code.
+type programBuilder struct {
+	code []byte
+}
+
+func (b *programBuilder) op(ops ...vm.OpCode) {
+	for _, op := range ops {
+		b.code = append(b.code, byte(op))
+	}
+}
+
+// push emits the shortest push of the given value.
+func (b *programBuilder) push(v *big.Int) {
+	data := v.Bytes()
+	if len(data) == 0 {
+		data = []byte{0}
+	}
+	b.code = append(b.code, byte(vm.PUSH1)+byte(len(data)-1))
+	b.code = append(b.code, data...)
+}
+
+// loop wraps the body emitted by the given function into an endless loop.
+func (b *programBuilder) loop(body func()) {
+	start := len(b.code)
+	b.op(vm.JUMPDEST)
+	body()
+	b.push(big.NewInt(int64(start)))
+	b.op(vm.JUMP)
+}
+
+// opcodePrograms generates a benchmark program for every opcode defined in the
+// given instruction set which can be executed in a loop.
+func opcodePrograms(table vm.JumpTable) []*benchProgram {
+	var programs []*benchProgram
+	for i, operation := range table {
+		op := vm.OpCode(i)
+		if operation.Undefined() || skippedOpcodes[op] {
+			continue
+		}
+		minStack, maxStack := operation.Stack()
+		var (
+			pops     = minStack
+			pushes   = int(params.StackLimit) + minStack - maxStack
+			operands = opcodeOperands[op]
+		)
+		if operands == nil {
+			for j := 0; j < pops; j++ {
+				operands = append(operands, opMax)
+			}
+		}
+		var useCounter bool
+		for _, o := range operands {
+			useCounter = useCounter || o.kind == counter
+		}
+		b := new(programBuilder)
+		if useCounter {
+			// The counter lives at the bottom of the stack. Start it high enough
+			// to not collide with the addresses of precompiles.
+			b.push(new(big.Int).Lsh(common.Big1, 32))
+		}
+		b.loop(func() {
+			for r := 0; r < opcodeRepeats; r++ {
+				if useCounter {
+					b.push(big.NewInt(1))
+					b.op(vm.ADD)
+				}
+				// Push the operands in reverse order, so the first one ends on top
+				for j := len(operands) - 1; j >= 0; j-- {
+					switch operands[j].kind {
+					case maxWord:
+						b.push(new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 256), common.Big1))
+					case small:
+						b.push(new(big.Int).SetUint64(operands[j].value))
+					case counter:
+						b.op(vm.DUP1 + vm.OpCode(len(operands)-1-j))
+					}
+				}
+				b.op(op)
+				if op.IsPush() {
+					for j := 0; j < int(op-vm.PUSH0); j++ {
+						b.code = append(b.code, 0xff)
+					}
+				}
+				for j := 0; j < pushes; j++ {
+					b.op(vm.POP)
+				}
+			}
+		})
+		programs = append(programs, &benchProgram{
+			name:      op.String(),
+			kind:      "opcode",
+			staticGas: operation.ConstantGas(),
+			code:      b.code,
+		})
+	}
+	return programs
+}
+
+// precompilePrograms generates a benchmark program for every precompile active in
+// the given set, for which a worst case input is known.
+func precompilePrograms(precompiles vm.PrecompiledContracts) ([]*benchProgram, error) {
+	inputs, err := precompileInputs()
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, 0, len(precompiles))
+	for addr := range precompiles {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Cmp(addrs[j]) < 0 })
+
+	var programs []*benchProgram
+	for _, addr := range addrs {
+		in, ok := inputs[addr]
+		if !ok {
+			continue
+		}
+		p := precompiles[addr]
+		if _, err := p.Run(in.data); err != nil {
+			return nil, fmt.Errorf("invalid benchmark input for precompile %s: %v", in.name, err)
+		}
+		b := new(programBuilder)
+		// Copy the call data into memory once, then call the precompile in a loop
+		b.op(vm.CALLDATASIZE)
+		b.push(big.NewInt(0))
+		b.push(big.NewInt(0))
+		b.op(vm.CALLDATACOPY)
+		b.loop(func() {
+			for r := 0; r < precompileRepeats; r++ {
+				b.push(big.NewInt(0))
+				b.push(big.NewInt(0))
+				b.op(vm.CALLDATASIZE)
+				b.push(big.NewInt(0))
+				b.push(new(big.Int).SetBytes(addr.Bytes()))
+				b.op(vm.GAS, vm.STATICCALL, vm.POP)
+			}
+		})
+		programs = append(programs, &benchProgram{
+			name:      in.name,
+			kind:      "precompile",
+			staticGas: p.RequiredGas(in.data),
+			code:      b.code,
+			input:     in.data,
+		})
+	}
+	return programs, nil
+}
+
+type precompileInput struct {
+	name string
+	data []byte
+}
+
+// precompileInputs generates valid, expensive inputs for the known precompiles.
+func precompileInputs() (map[common.Address]precompileInput, error) {
+	inputs := make(map[common.Address]precompileInput)
+	add := func(addr []byte, name string, data []byte) {
+		inputs[common.BytesToAddress(addr)] = precompileInput{name: name, data: data}
+	}
+	// Hashing and copying precompiles charge per word, use a sizeable input
+	blob := make([]byte, 1024)
+	for i := range blob {
+		blob[i] = byte(i)
+	}
+	// ecrecover with a valid signature
+	key, _ := crypto.ToECDSA(crypto.Keccak256([]byte("gasbench")))
+	hash := crypto.Keccak256([]byte("message"))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	ecrec := make([]byte, 128)
+	copy(ecrec, hash)
+	ecrec[63] = sig[64] + 27
+	copy(ecrec[64:], sig[:64])
+	add([]byte{0x01}, "ecrecover", ecrec)
+	add([]byte{0x02}, "sha256", blob)
+	add([]byte{0x03}, "ripemd160", blob)
+	add([]byte{0x04}, "identity", blob)
+
+	// modexp with large operands and an odd modulus
+	modexp := make([]byte, 96+3*256)
+	for i := 0; i < 3; i++ {
+		new(big.Int).SetUint64(256).FillBytes(modexp[i*32 : (i+1)*32])
+	}
+	for i := 96; i < len(modexp); i++ {
+		modexp[i] = 0xff
+	}
+	add([]byte{0x05}, "modexp", modexp)
+
+	// alt_bn128 operations on the generators
+	g1 := new(bn256.G1).ScalarBaseMult(common.Big1).Marshal()
+	g2 := new(bn256.G2).ScalarBaseMult(common.Big1).Marshal()
+	negG1 := new(bn256.G1).Neg(new(bn256.G1).ScalarBaseMult(common.Big1)).Marshal()
+	add([]byte{0x06}, "bn256Add", append(append([]byte{}, g1...), g1...))
+	add([]byte{0x07}, "bn256ScalarMul", append(append([]byte{}, g1...), common.MaxHash.Bytes()...))
+	add([]byte{0x08}, "bn256Pairing", concat(g1, g2, negG1, g2))
+
+	// blake2f with a large number of rounds
+	blake := make([]byte, 213)
+	binary.BigEndian.PutUint32(blake, 1024)
+	blake[212] = 1
+	add([]byte{0x09}, "blake2F", blake)
+
+	// point evaluation of the empty blob
+	var (
+		kzgBlob  kzg4844.Blob
+		kzgPoint kzg4844.Point
+	)
+	commitment, err := kzg4844.BlobToCommitment(&kzgBlob)
+	if err != nil {
+		return nil, err
+	}
+	proof, claim, err := kzg4844.ComputeProof(&kzgBlob, kzgPoint)
+	if err != nil {
+		return nil, err
+	}
+	vhash := kzg4844.CalcBlobHashV1(sha256.New(), &commitment)
+	add([]byte{0x0a}, "pointEvaluation", concat(vhash[:], kzgPoint[:], claim[:], commitment[:], proof[:]))
+
+	// BLS12-381 operations on the generators
+	_, _, blsG1, blsG2 := bls12381.Generators()
+	var (
+		p1     = encodeBLSPointG1(&blsG1)
+		p2     = encodeBLSPointG2(&blsG2)
+		scalar = common.MaxHash.Bytes()
+	)
+	var msmG1, msmG2, pairing []byte
+	for i := 0; i < 64; i++ {
+		msmG1 = concat(msmG1, p1, scalar)
+		msmG2 = concat(msmG2, p2, scalar)
+	}
+	var negBlsG1 bls12381.G1Affine
+	negBlsG1.Neg(&blsG1)
+	pairing = concat(p1, p2, encodeBLSPointG1(&negBlsG1), p2)
+
+	fieldElem := make([]byte, 64)
+	fieldElem[63] = 1
+	add([]byte{0x0b}, "blsG1Add", concat(p1, p1))
+	add([]byte{0x0c}, "blsG1Mul", concat(p1, scalar))
+	add([]byte{0x0d}, "blsG1MultiExp", msmG1)
+	add([]byte{0x0e}, "blsG2Add", concat(p2, p2))
+	add([]byte{0x0f}, "blsG2Mul", concat(p2, scalar))
+	add([]byte{0x10}, "blsG2MultiExp", msmG2)
+	add([]byte{0x11}, "blsPairing", pairing)
+	add([]byte{0x12}, "blsMapG1", fieldElem)
+	add([]byte{0x13}, "blsMapG2", concat(fieldElem, fieldElem))
+
+	// secp256r1 verification with a valid signature
+	p256key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, p256key, hash)
+	if err != nil {
+		return nil, err
+	}
+	p256 := make([]byte, 160)
+	copy(p256, hash)
+	r.FillBytes(p256[32:64])
+	s.FillBytes(p256[64:96])
+	p256key.X.FillBytes(p256[96:128])
+	p256key.Y.FillBytes(p256[128:160])
+	add([]byte{0x01, 0x00}, "p256Verify", p256)
+
+	return inputs, nil
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// encodeBLSPointG1 encodes a G1 point as specified by EIP-2537.
+func encodeBLSPointG1(p *bls12381.G1Affine) []byte {
+	out := make([]byte, 128)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[16:]), p.X)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[64+16:]), p.Y)
+	return out
+}
+
+// encodeBLSPointG2 encodes a G2 point as specified by EIP-2537.
+func encodeBLSPointG2(p *bls12381.G2Affine) []byte {
+	out := make([]byte, 256)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[16:16+48]), p.X.A0)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[80:80+48]), p.X.A1)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[144:144+48]), p.Y.A0)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[208:208+48]), p.Y.A1)
+	return out
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// TestGasBenchPrograms checks that every generated program executes its operation
+// until running out of gas, without hitting any other error.
+func TestGasBenchPrograms(t *testing.T) {
+	const gasLimit = 1_000_000
+
+	for _, fork := range []string{"Berlin", "Cancun", "Prague"} {
+		config, eips, err := tests.GetChainConfig(fork)
+		if err != nil {
+			t.Fatal(err)
+		}
+		programs, err := gasBenchPrograms(config, eips)
+		if err != nil {
+			t.Fatalf("%s: failed to generate programs: %v", fork, err)
+		}
+		for _, p := range programs {
+			if p.staticGas > gasLimit/2 {
+				continue
+			}
+			res := runGasBenchProgram(config, eips, p, gasLimit, 1)
+			if res.Error != "" {
+				t.Errorf("%s: program %s failed: %v", fork, p.name, res.Error)
+			}
+			if res.GasUsed != gasLimit {
+				t.Errorf("%s: program %s used %d gas, want %d", fork, p.name, res.GasUsed, gasLimit)
+			}
+		}
+	}
+}
+
+func TestGasBenchReport(t *testing.T) {
+	report := &gasBenchReport{
+		Results: []*gasBenchResult{
+			{Name: "ADD", Kind: "opcode", NsPerGas: 1},
+			{Name: "MUL", Kind: "opcode", NsPerGas: 2},
+			{Name: "SLOAD", Kind: "opcode", NsPerGas: 0.5},
+			{Name: "ecrecover", Kind: "precompile", NsPerGas: 10},
+			{Name: "sha256", Kind: "precompile", Error: "boom"},
+		},
+	}
+	report.computeRatios()
+	if report.MedianNsPerGas != 1.5 {
+		t.Fatalf("median mismatch: have %v, want 1.5", report.MedianNsPerGas)
+	}
+	var names []string
+	for _, res := range report.outliers(2) {
+		names = append(names, res.Name)
+	}
+	if want := []string{"ecrecover", "SLOAD"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("outliers mismatch: have %v, want %v", names, want)
+	}
+}
+
+func TestGasBenchCompare(t *testing.T) {
+	old := &gasBenchReport{
+		Results: []*gasBenchResult{
+			{Name: "ADD", Kind: "opcode", NsPerGas: 1},
+			{Name: "MUL", Kind: "opcode", NsPerGas: 2},
+			{Name: "SLOAD", Kind: "opcode", NsPerGas: 4},
+			{Name: "ecrecover", Kind: "precompile", NsPerGas: 10},
+		},
+	}
+	new := &gasBenchReport{
+		Results: []*gasBenchResult{
+			{Name: "ADD", Kind: "opcode", NsPerGas: 1.05},
+			{Name: "MUL", Kind: "opcode", NsPerGas: 3},
+			{Name: "SLOAD", Kind: "opcode", NsPerGas: 2},
+			{Name: "p256Verify", Kind: "precompile", NsPerGas: 10},
+		},
+	}
+	changes, missing := compareGasBenchReports(old, new, 10)
+	if len(changes) != 2 {
+		t.Fatalf("change count mismatch: have %d, want 2", len(changes))
+	}
+	if changes[0].Name != "MUL" || changes[0].Percent != 50 {
+		t.Errorf("first change mismatch: have %+v", changes[0])
+	}
+	if changes[1].Name != "SLOAD" || changes[1].Percent != -50 {
+		t.Errorf("second change mismatch: have %+v", changes[1])
+	}
+	want := []string{"precompile/p256Verify only in new report", "precompile/ecrecover only in old report"}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing entries mismatch: have %v, want %v", missing, want)
+	}
+}
@@ -151,6 +151,22 @@ from Era archives.
 		Description: `
 The export-history command will export blocks and their corresponding receipts
 into Era archives. Eras are typically packaged in steps of 8192 blocks.
+`,
+	}
+	pruneHistoryCommand = &cli.Command{
+		Action:    pruneHistory,
+		Name:      "prune-history",
+		Usage:     "Prune the block bodies and receipts below a block",
+		ArgsUsage: "[<block>]",
+		Flags:     flags.Merge(utils.DatabaseFlags, utils.NetworkFlags),
+		Description: `
+The prune-history command removes the block bodies and receipts below the given
+block from the ancient store, along with their transaction indexes. The headers
+are retained. Without a block, the history is pruned up to the merge block of
+the network.
+
+The pruned history can be served from a directory of era1 archives configured
+with --history.era.
 `,
 	}
 	importPreimagesCommand = &cli.Command{
@@ -222,7 +238,7 @@ func initGenesis(ctx *cli.Context) error {
 		overrides.OverrideVerkle = &v
 	}
 	for _, name := range []string{"chaindata", "lightchaindata"} {
-		chaindb, err := stack.OpenDatabaseWithFreezer(name, 0, 0, ctx.String(utils.AncientFlag.Name), "", false)
+		chaindb, err := stack.OpenDatabaseWithFreezer(name, 0, 0, ctx.String(utils.AncientFlag.Name), utils.MakeEraRoots(ctx), "", false)
 		if err != nil {
 			utils.Fatalf("Failed to open database: %v", err)
 		}
@@ -492,6 +508,41 @@ func exportHistory(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneHistory removes the chain history below the specified block, or below the
+// merge block of the network by default.
+func pruneHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	var cutoff uint64
+	if ctx.Args().Len() == 1 {
+		number, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid block number: %v", err)
+		}
+		cutoff = number
+	} else {
+		genesis := rawdb.ReadCanonicalHash(db, 0)
+		number, ok := core.HistoryPrunePoints[genesis]
+		if !ok {
+			return errors.New("no default pruning block for the network, specify the block")
+		}
+		cutoff = number
+	}
+	start := time.Now()
+	if err := core.PruneHistory(db, cutoff); err != nil {
+		return err
+	}
+	fmt.Printf("Pruning done in %v\n", time.Since(start))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 // it is deprecated, and the export function has been removed, but
 // the import function is kept around for the time being so that
@@ -516,36 +567,12 @@ func importPreimages(ctx *cli.Context) error {
 }
 
 func parseDumpConfig(ctx *cli.Context, db ethdb.Database) (*state.DumpConfig, common.Hash, error) {
-	var header *types.Header
 	if ctx.NArg() > 1 {
 		return nil, common.Hash{}, fmt.Errorf("expected 1 argument (number or hash), got %d", ctx.NArg())
 	}
-	if ctx.NArg() == 1 {
-		arg := ctx.Args().First()
-		if hashish(arg) {
-			hash := common.HexToHash(arg)
-			if number := rawdb.ReadHeaderNumber(db, hash); number != nil {
-				header = rawdb.ReadHeader(db, hash, *number)
-			} else {
-				return nil, common.Hash{}, fmt.Errorf("block %x not found", hash)
-			}
-		} else {
-			number, err := strconv.ParseUint(arg, 10, 64)
-			if err != nil {
-				return nil, common.Hash{}, err
-			}
-			if hash := rawdb.ReadCanonicalHash(db, number); hash != (common.Hash{}) {
-				header = rawdb.ReadHeader(db, hash, number)
-			} else {
-				return nil, common.Hash{}, fmt.Errorf("header for block %d not found", number)
-			}
-		}
-	} else {
-		// Use latest
-		header = rawdb.ReadHeadHeader(db)
-	}
-	if header == nil {
-		return nil, common.Hash{}, errors.New("no head block found")
+	header, err := parseHeaderArg(db, ctx.Args().First())
+	if err != nil {
+		return nil, common.Hash{}, err
 	}
 	startArg := common.FromHex(ctx.String(utils.StartKeyFlag.Name))
 	var start common.Hash
@@ -572,6 +599,39 @@ func parseDumpConfig(ctx *cli.Context, db ethdb.Database) (*state.DumpConfig, co
 	return conf, header.Root, nil
 }
 
+// parseHeaderArg resolves the header of the block identified by the given number
+// or hash, or the head header if no block is given.
+func parseHeaderArg(db ethdb.Database, arg string) (*types.Header, error) {
+	var header *types.Header
+	if arg != "" {
+		if hashish(arg) {
+			hash := common.HexToHash(arg)
+			if number := rawdb.ReadHeaderNumber(db, hash); number != nil {
+				header = rawdb.ReadHeader(db, hash, *number)
+			} else {
+				return nil, fmt.Errorf("block %x not found", hash)
+			}
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if hash := rawdb.ReadCanonicalHash(db, number); hash != (common.Hash{}) {
+				header = rawdb.ReadHeader(db, hash, number)
+			} else {
+				return nil, fmt.Errorf("header for block %d not found", number)
+			}
+		}
+	} else {
+		// Use latest
+		header = rawdb.ReadHeadHeader(db)
+	}
+	if header == nil {
+		return nil, errors.New("no head block found")
+	}
+	return header, nil
+}
+
 func dump(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
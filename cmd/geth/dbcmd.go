@@ -18,6 +18,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -41,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
 )
@@ -78,11 +81,14 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbRecompressFreezerCmd,
 			dbImportCmd,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbBackupCmd,
+			dbVerifyPathdbCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -91,9 +97,17 @@ Remove blockchain and state databases`,
 		ArgsUsage: "<prefix> <start>",
 		Flags: flags.Merge([]cli.Flag{
 			utils.SyncModeFlag,
+			inspectFullFlag,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
-		Usage:       "Inspect the storage size for each type of data in the database",
-		Description: `This commands iterates the entire database. If the optional 'prefix' and 'start' arguments are provided, then the iteration is limited to the given subset of data.`,
+		Usage: "Inspect the storage size for each type of data in the database",
+		Description: `This commands reports the approximate sizes tracked by the database if available,
+otherwise it iterates the entire database, which also seeds the tracked sizes. If the
+optional 'prefix' and 'start' arguments are provided, then the iteration is limited to
+the given subset of data.`,
+	}
+	inspectFullFlag = &cli.BoolFlag{
+		Name:  "full",
+		Usage: "Iterate the entire database instead of reporting the tracked sizes",
 	}
 	dbCheckStateContentCmd = &cli.Command{
 		Action:    checkStateContent,
@@ -178,6 +192,20 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command displays information about the freezer index.",
 	}
+	dbRecompressFreezerCmd = &cli.Command{
+		Action:    freezerRecompress,
+		Name:      "freezer-recompress",
+		Usage:     "Rewrite a specific freezer table with another compression codec",
+		ArgsUsage: "<freezer-type> <table-type> <codec (snappy|zstd)>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command rewrites the data files of a freezer table in place with the
+given compression codec. The node must be stopped. The codec is recorded in the
+table metadata, so tables with mixed codecs remain readable, and is also used for
+the data files appended afterwards. New tables always start out with snappy. An
+interrupted run is finished or cleaned up the next time the table is opened.`,
+	}
 	dbImportCmd = &cli.Command{
 		Action:    importLDBdata,
 		Name:      "import",
@@ -207,6 +235,39 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Shows metadata about the chain status.",
 	}
+	dbBackupCmd = &cli.Command{
+		Action:    dbBackup,
+		Name:      "backup",
+		Usage:     "Create a consistent copy of the chain database",
+		ArgsUsage: "<dir>",
+		Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command writes a consistent copy of the chain database, including the
+ancient store and the state histories, into the given directory. The database is
+placed at the same location in the directory as in the datadir, so the directory
+can be used as a datadir afterwards. The directory must not exist yet.
+
+The admin_backup RPC method creates the same backup from a running node.`,
+	}
+	dbVerifyPathdbCmd = &cli.Command{
+		Action: dbVerifyPathdb,
+		Name:   "verify-pathdb",
+		Usage:  "Verify the consistency of the path-based state database",
+		Flags: flags.Merge([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "repair",
+				Usage: "Delete unreachable trie nodes and truncate corrupted state histories",
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command walks the persistent state trie of a path-based database from its
+root, verifying every trie node against the hash referenced by its parent. Missing
+and corrupted trie nodes are reported, as well as the stored trie nodes which are
+not reachable from the root. The state histories are checked for continuity and
+against the persistent state, and the state id lookups against the histories.
+
+With --repair, unreachable trie nodes are deleted, corrupted state histories are
+truncated as long as the persistent state stays covered, and inconsistent state
+id lookups are rewritten. Missing or corrupted trie nodes can't be repaired.`,
+	}
 	dbInspectHistoryCmd = &cli.Command{
 		Action:    inspectHistory,
 		Name:      "inspect-history",
@@ -348,7 +409,20 @@ func inspect(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
 
-	db := utils.MakeChainDatabase(ctx, stack, true)
+	// Report the tracked sizes if they're available, otherwise iterate the
+	// database. A full iteration seeds the tracked sizes, so the database is
+	// opened in write mode for it.
+	full := prefix == nil && start == nil
+	if full && !ctx.Bool(inspectFullFlag.Name) {
+		db := utils.MakeChainDatabase(ctx, stack, true)
+		ok, err := rawdb.InspectDatabaseSizes(db)
+		db.Close()
+		if ok || err != nil {
+			return err
+		}
+		log.Info("Database sizes are not tracked yet, iterating the database")
+	}
+	db := utils.MakeChainDatabase(ctx, stack, !full)
 	defer db.Close()
 
 	return rawdb.InspectDatabase(db, prefix, start)
@@ -448,6 +522,83 @@ func dbCompact(ctx *cli.Context) error {
 	return nil
 }
 
+// dbBackup writes a consistent copy of the chain database into a new datadir.
+func dbBackup(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	path, err := filepath.Rel(stack.DataDir(), stack.ResolvePath("chaindata"))
+	if err != nil {
+		return err
+	}
+	// Pebble can't checkpoint a database opened in read-only mode
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, db, false, true, false)
+	defer triedb.Close()
+
+	return core.BackupDatabase(db, triedb, filepath.Join(ctx.Args().Get(0), path))
+}
+
+// dbVerifyPathdb verifies the consistency of the path-based state database,
+// repairing it if requested.
+func dbVerifyPathdb(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	repair := ctx.Bool("repair")
+	db := utils.MakeChainDatabase(ctx, stack, !repair)
+	defer db.Close()
+
+	if scheme := rawdb.ReadStateScheme(db); scheme != rawdb.PathScheme {
+		return fmt.Errorf("state scheme %q is not supported", scheme)
+	}
+	var freezer ethdb.AncientStore
+	if ancient, err := db.AncientDatadir(); err == nil {
+		store, err := rawdb.NewStateFreezer(ancient, false, !repair)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		freezer = store
+	}
+	report, err := pathdb.Verify(db, freezer, repair)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Persistent state: root %x, id %d\n", report.Root, report.StateID)
+	fmt.Printf("Verified %d trie nodes, %d accounts, %d storage slots\n", report.Nodes, report.Accounts, report.Slots)
+	for _, ref := range report.Missing {
+		fmt.Printf("Missing node: %v\n", ref)
+	}
+	for _, ref := range report.Corrupted {
+		fmt.Printf("Corrupted node: %v\n", ref)
+	}
+	for _, ref := range report.Orphans {
+		fmt.Printf("Unreachable node: %v\n", ref)
+	}
+	if freezer != nil {
+		fmt.Printf("State histories: (%d, %d]\n", report.HistoryTail, report.HistoryHead)
+	}
+	for _, msg := range report.HistoryErrors {
+		fmt.Printf("History error: %s\n", msg)
+	}
+	if repair {
+		fmt.Printf("Repaired: %d nodes deleted, %d histories truncated from head, %d from tail, %d lookups rewritten\n",
+			report.Deleted, report.TruncatedHead, report.TruncatedTail, report.FixedLookups)
+		return nil
+	}
+	if !report.Healthy() {
+		return errors.New("state database is inconsistent")
+	}
+	fmt.Println("State database is consistent")
+	return nil
+}
+
 // dbGet shows the value of a given database key
 func dbGet(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
@@ -627,6 +778,21 @@ func freezerInspect(ctx *cli.Context) error {
 	return rawdb.InspectFreezerTable(ancient, freezer, table, start, end)
 }
 
+func freezerRecompress(ctx *cli.Context) error {
+	if ctx.NArg() < 3 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	var (
+		freezer = ctx.Args().Get(0)
+		table   = ctx.Args().Get(1)
+		codec   = ctx.Args().Get(2)
+	)
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+	return rawdb.RecompressFreezerTable(ancient, freezer, table, codec)
+}
+
 func importLDBdata(ctx *cli.Context) error {
 	start := 0
 	switch ctx.NArg() {
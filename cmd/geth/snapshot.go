@@ -159,6 +159,34 @@ block is used.
 				Description: `
 The export-preimages command exports hash preimages to a flat file, in exactly
 the expected order for the overlay tree migration.
+`,
+			},
+			{
+				Action:    snapshotExport,
+				Name:      "export",
+				Usage:     "Export the state of a block into a binary snapshot file",
+				ArgsUsage: "<file> [<blockHash> | <blockNum>]",
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot export <file> [<blockHash> | <blockNum>]
+will export the flat state (accounts, storage slots and contract codes) of the
+given block from the state snapshot into a chunked, checksummed binary file. The
+file is anchored to the state root of the block. If no block is provided, the
+head block is used.
+`,
+			},
+			{
+				Action:    snapshotImport,
+				Name:      "import",
+				Usage:     "Import the state from a binary snapshot file",
+				ArgsUsage: "<file>",
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot import <file>
+will import a state exported by 'geth snapshot export' into the database. The
+state trie is rebuilt in the scheme configured by --state.scheme along with the
+flat snapshot, and its root is verified against the one recorded in the file.
+Only the state is imported, the chain itself needs to be imported separately.
 `,
 			},
 		},
@@ -661,6 +689,61 @@ func snapshotExportPreimages(ctx *cli.Context) error {
 	return utils.ExportSnapshotPreimages(chaindb, snaptree, ctx.Args().First(), root)
 }
 
+// snapshotExport exports the state of a block into a binary snapshot file.
+func snapshotExport(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return errors.New("need <file> [<blockHash> | <blockNum>] args")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	header, err := parseHeaderArg(chaindb, ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, false, true, false)
+	defer triedb.Close()
+
+	snapConfig := snapshot.Config{
+		CacheSize:  256,
+		Recovery:   false,
+		NoBuild:    true,
+		AsyncBuild: false,
+	}
+	snaptree, err := snapshot.New(snapConfig, chaindb, triedb, header.Root)
+	if err != nil {
+		return err
+	}
+	return utils.ExportSnapshot(chaindb, snaptree, header, ctx.Args().First())
+}
+
+// snapshotImport imports the state from a binary snapshot file.
+func snapshotImport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need <file> arg")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	scheme, err := rawdb.ParseStateScheme(ctx.String(utils.StateSchemeFlag.Name), chaindb)
+	if err != nil {
+		return err
+	}
+	root, err := utils.ImportSnapshot(chaindb, scheme, ctx.Args().First())
+	if err != nil {
+		log.Error("Failed to import state snapshot", "err", err)
+		return err
+	}
+	log.Info("Imported the state", "root", root, "scheme", scheme)
+	return nil
+}
+
 // checkAccount iterates the snap data layers, and looks up the given account
 // across all layers.
 func checkAccount(ctx *cli.Context) error {
@@ -58,6 +58,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
 	"github.com/ethereum/go-ethereum/ethstats"
 	"github.com/ethereum/go-ethereum/graphql"
+	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
@@ -97,7 +98,7 @@ var (
 	}
 	RemoteDBFlag = &cli.StringFlag{
 		Name:     "remotedb",
-		Usage:    "URL for remote database",
+		Usage:    "URL for remote database, served by the remotedb namespace (--remotedb.serve) or the debug namespace of the owning node",
 		Category: flags.LoggingCategory,
 	}
 	DBEngineFlag = &cli.StringFlag{
@@ -106,9 +107,19 @@ var (
 		Value:    node.DefaultConfig.DBEngine,
 		Category: flags.EthCategory,
 	}
+	DBTrackSizesFlag = &cli.BoolFlag{
+		Name:     "db.sizes",
+		Usage:    "Maintain the approximate size of each data category as it's written, at the cost of slower writes",
+		Category: flags.EthCategory,
+	}
 	AncientFlag = &flags.DirectoryFlag{
 		Name:     "datadir.ancient",
-		Usage:    "Root directory for ancient data (default = inside chaindata)",
+		Usage:    "Root directory for ancient data, or a read-only directory of era1 archives (default = inside chaindata)",
+		Category: flags.EthCategory,
+	}
+	AncientRootsFlag = &cli.StringFlag{
+		Name:     "datadir.ancient.roots",
+		Usage:    "File of trusted accumulator roots, one per line, verifying the era1 archives of --datadir.ancient",
 		Category: flags.EthCategory,
 	}
 	MinFreeDiskSpaceFlag = &flags.DirectoryFlag{
@@ -284,6 +295,11 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	HistoryEraFlag = &flags.DirectoryFlag{
+		Name:     "history.era",
+		Usage:    "Directory of era1 archives to serve the pruned chain history from",
+		Category: flags.StateCategory,
+	}
 	// Beacon client light sync settings
 	BeaconApiFlag = &cli.StringSliceFlag{
 		Name:     "beacon.api",
@@ -355,10 +371,34 @@ var (
 	}
 	TxPoolRejournalFlag = &cli.DurationFlag{
 		Name:     "txpool.rejournal",
-		Usage:    "Time interval to regenerate the local transaction journal",
+		Usage:    "Time interval to regenerate the local transaction journal and remote transaction snapshot",
 		Value:    ethconfig.Defaults.TxPool.Rejournal,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolSnapshotFlag = &cli.StringFlag{
+		Name:     "txpool.snapshot",
+		Usage:    "Disk snapshot of remote transactions to survive node restarts (disabled if empty)",
+		Value:    ethconfig.Defaults.TxPool.Snapshot,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolSnapshotTxsFlag = &cli.Uint64Flag{
+		Name:     "txpool.snapshottxs",
+		Usage:    "Maximum number of remote transactions to snapshot",
+		Value:    ethconfig.Defaults.TxPool.SnapshotTxs,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolSnapshotSizeFlag = &cli.Uint64Flag{
+		Name:     "txpool.snapshotsize",
+		Usage:    "Maximum byte size of the remote transactions to snapshot",
+		Value:    ethconfig.Defaults.TxPool.SnapshotSize,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolSnapshotAgeFlag = &cli.DurationFlag{
+		Name:     "txpool.snapshotage",
+		Usage:    "Maximum age of the snapshotted remote transactions to restore on startup",
+		Value:    ethconfig.Defaults.TxPool.SnapshotAge,
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolPriceLimitFlag = &cli.Uint64Flag{
 		Name:     "txpool.pricelimit",
 		Usage:    "Minimum gas price tip to enforce for acceptance into the pool",
@@ -401,6 +441,12 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPrivateLifetimeFlag = &cli.Uint64Flag{
+		Name:     "txpool.privatelifetime",
+		Usage:    "Number of blocks a private transaction is kept for local inclusion before being dropped",
+		Value:    ethconfig.Defaults.TxPool.PrivateLifetime,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -451,6 +497,12 @@ var (
 		Value:    10,
 		Category: flags.PerfCategory,
 	}
+	CacheJournalFlag = &cli.IntFlag{
+		Name:     "cache.journal",
+		Usage:    "Megabytes of hot trie nodes (path scheme only) and snapshot entries per cache to persist across restarts (0 = disabled)",
+		Value:    ethconfig.Defaults.CacheJournalSize,
+		Category: flags.PerfCategory,
+	}
 	CacheNoPrefetchFlag = &cli.BoolFlag{
 		Name:     "cache.noprefetch",
 		Usage:    "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
@@ -503,6 +555,22 @@ var (
 		Value:    ethconfig.Defaults.Miner.Recommit,
 		Category: flags.MinerCategory,
 	}
+	MinerOrderingFlag = &cli.StringFlag{
+		Name:     "miner.ordering",
+		Usage:    "Transaction ordering strategy for block building (price, fcfs)",
+		Value:    "price",
+		Category: flags.MinerCategory,
+	}
+	MinerSenderCapFlag = &cli.IntFlag{
+		Name:     "miner.sendercap",
+		Usage:    "Maximum number of transactions per sender in a built block (0 = unlimited)",
+		Category: flags.MinerCategory,
+	}
+	MinerReportsFlag = &cli.BoolFlag{
+		Name:     "miner.reports",
+		Usage:    "Keep the build reports of the recent payloads, including the skipped transactions",
+		Category: flags.MinerCategory,
+	}
 	MinerPendingFeeRecipientFlag = &cli.StringFlag{
 		Name:     "miner.pending.feeRecipient",
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
@@ -551,6 +619,11 @@ var (
 		Category: flags.VMCategory,
 	}
 	// API options.
+	RemoteDBServeFlag = &cli.BoolFlag{
+		Name:     "remotedb.serve",
+		Usage:    "Serve the chain database over the remotedb RPC namespace, writes are only accepted over IPC and the authenticated endpoint",
+		Category: flags.APICategory,
+	}
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
 		Name:     "rpc.gascap",
 		Usage:    "Sets a cap on gas that can be used in eth_call/estimateGas (0=infinite)",
@@ -976,8 +1049,10 @@ var (
 	DatabaseFlags = []cli.Flag{
 		DataDirFlag,
 		AncientFlag,
+		AncientRootsFlag,
 		RemoteDBFlag,
 		DBEngineFlag,
+		DBTrackSizesFlag,
 		StateSchemeFlag,
 		HttpHeaderFlag,
 	}
@@ -1449,6 +1524,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
 		cfg.DBEngine = dbEngine
 	}
+	if ctx.IsSet(DBTrackSizesFlag.Name) {
+		cfg.DBTrackSizes = ctx.Bool(DBTrackSizesFlag.Name)
+	}
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("log.backtrace flag is deprecated")
@@ -1528,6 +1606,18 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.Duration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.IsSet(TxPoolSnapshotFlag.Name) {
+		cfg.Snapshot = ctx.String(TxPoolSnapshotFlag.Name)
+	}
+	if ctx.IsSet(TxPoolSnapshotTxsFlag.Name) {
+		cfg.SnapshotTxs = ctx.Uint64(TxPoolSnapshotTxsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolSnapshotSizeFlag.Name) {
+		cfg.SnapshotSize = ctx.Uint64(TxPoolSnapshotSizeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolSnapshotAgeFlag.Name) {
+		cfg.SnapshotAge = ctx.Duration(TxPoolSnapshotAgeFlag.Name)
+	}
 	if ctx.IsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.Uint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1549,6 +1639,9 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPrivateLifetimeFlag.Name) {
+		cfg.PrivateLifetime = ctx.Uint64(TxPoolPrivateLifetimeFlag.Name)
+	}
 }
 
 func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
@@ -1583,6 +1676,18 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		log.Warn("The flag --miner.newpayload-timeout is deprecated and will be removed, please use --miner.recommit")
 		cfg.Recommit = ctx.Duration(MinerNewPayloadTimeoutFlag.Name)
 	}
+	if ctx.IsSet(MinerOrderingFlag.Name) {
+		cfg.Ordering = ctx.String(MinerOrderingFlag.Name)
+	}
+	if ctx.IsSet(MinerSenderCapFlag.Name) {
+		cfg.SenderCap = ctx.Int(MinerSenderCapFlag.Name)
+	}
+	if ctx.IsSet(MinerReportsFlag.Name) {
+		cfg.Reports = ctx.Bool(MinerReportsFlag.Name)
+	}
+	if _, err := miner.MakeOrdering(cfg.Ordering, cfg.SenderCap); err != nil {
+		Fatalf("Invalid miner ordering: %v", err)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1704,6 +1809,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(AncientFlag.Name) {
 		cfg.DatabaseFreezer = ctx.String(AncientFlag.Name)
 	}
+	if ctx.IsSet(AncientRootsFlag.Name) {
+		cfg.DatabaseEraRoots = ctx.String(AncientRootsFlag.Name)
+	}
 
 	if gcmode := ctx.String(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1738,6 +1846,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		log.Warn("The flag --txlookuplimit is deprecated and will be removed, please use --history.transactions")
 		cfg.TransactionHistory = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(HistoryEraFlag.Name) {
+		cfg.HistoryEra = ctx.String(HistoryEraFlag.Name)
+	}
 	if ctx.String(GCModeFlag.Name) == "archive" && cfg.TransactionHistory != 0 {
 		cfg.TransactionHistory = 0
 		log.Warn("Disabled transaction unindexing for archive node")
@@ -1754,6 +1865,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheSnapshotFlag.Name) {
 		cfg.SnapshotCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheSnapshotFlag.Name) / 100
 	}
+	if ctx.IsSet(CacheJournalFlag.Name) {
+		cfg.CacheJournalSize = ctx.Int(CacheJournalFlag.Name)
+	}
 	if ctx.IsSet(CacheLogSizeFlag.Name) {
 		cfg.FilterLogCacheSize = ctx.Int(CacheLogSizeFlag.Name)
 	}
@@ -1783,6 +1897,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		cfg.EnableWitnessCollection = ctx.Bool(CollectWitnessFlag.Name)
 	}
 
+	if ctx.IsSet(RemoteDBServeFlag.Name) {
+		cfg.RemoteDB = ctx.Bool(RemoteDBServeFlag.Name)
+	}
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
 	}
@@ -2091,7 +2208,7 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node, readonly bool) ethdb.
 	case ctx.String(SyncModeFlag.Name) == "light":
 		chainDb, err = stack.OpenDatabase("lightchaindata", cache, handles, "", readonly)
 	default:
-		chainDb, err = stack.OpenDatabaseWithFreezer("chaindata", cache, handles, ctx.String(AncientFlag.Name), "", readonly)
+		chainDb, err = stack.OpenDatabaseWithFreezer("chaindata", cache, handles, ctx.String(AncientFlag.Name), MakeEraRoots(ctx), "", readonly)
 	}
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
@@ -2099,6 +2216,19 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node, readonly bool) ethdb.
 	return chainDb
 }
 
+// MakeEraRoots reads the trusted accumulator roots of the era1 archives serving
+// as the ancient store, if configured.
+func MakeEraRoots(ctx *cli.Context) []common.Hash {
+	if !ctx.IsSet(AncientRootsFlag.Name) {
+		return nil
+	}
+	roots, err := era.ReadRoots(ctx.String(AncientRootsFlag.Name))
+	if err != nil {
+		Fatalf("Could not read era1 accumulator roots: %v", err)
+	}
+	return roots
+}
+
 // tryMakeReadOnlyDatabase try to open the chain database in read-only mode,
 // or fallback to write mode if the database is not initialized.
 func tryMakeReadOnlyDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
@@ -0,0 +1,524 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// The state snapshot file format is a magic string followed by a sequence of
+// chunks. Every chunk is laid out as
+//
+//	kind (1 byte) || length (4 bytes) || payload || crc32c(kind || length || payload)
+//
+// The first chunk is the header anchoring the file to a block, followed by entry
+// chunks carrying the flat state in snapshot iteration order: every account is
+// followed by its code (if not yet exported) and its storage slots. The last
+// chunk is the footer with the number of exported items, used to detect
+// truncated files.
+const (
+	snapshotFileMagic   = "gethsnap"
+	snapshotFileVersion = 0
+
+	snapshotChunkHeader  = 0 // Payload is an RLP encoded snapshotFileHeader
+	snapshotChunkEntries = 1 // Payload is an RLP encoded list of snapshotEntry
+	snapshotChunkFooter  = 2 // Payload is an RLP encoded snapshotFileFooter
+
+	snapshotChunkSize    = 1024 * 1024 // Payload size after which an entry chunk is flushed
+	snapshotChunkMaxSize = 64 * 1024 * 1024
+)
+
+// Entry kinds within an entry chunk.
+const (
+	snapshotEntryAccount = 0 // Key is the account hash, value the slim RLP account
+	snapshotEntryCode    = 1 // Key is the code hash, value the code
+	snapshotEntrySlot    = 2 // Key is the slot hash, value the RLP slot of the last account
+)
+
+var snapshotCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// snapshotFileHeader is the header of a state snapshot file.
+type snapshotFileHeader struct {
+	Version  uint64
+	Root     common.Hash // State root the snapshot was exported at
+	Number   uint64      // Number of the block with the state root
+	Hash     common.Hash // Hash of the block with the state root
+	UnixTime uint64
+}
+
+// snapshotFileFooter is the footer of a state snapshot file.
+type snapshotFileFooter struct {
+	Accounts uint64
+	Slots    uint64
+	Codes    uint64
+}
+
+// snapshotEntry is a single state item in a state snapshot file.
+type snapshotEntry struct {
+	Kind  uint8
+	Key   common.Hash
+	Value []byte
+}
+
+// snapshotFileWriter writes checksummed chunks into a state snapshot file.
+type snapshotFileWriter struct {
+	w       io.Writer
+	entries []snapshotEntry
+	size    int
+}
+
+func (w *snapshotFileWriter) writeChunk(kind byte, val interface{}) error {
+	payload, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 5, 5+len(payload)+4)
+	buf[0] = kind
+	binary.BigEndian.PutUint32(buf[1:], uint32(len(payload)))
+	buf = append(buf, payload...)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.Checksum(buf, snapshotCRCTable))
+	_, err = w.w.Write(buf)
+	return err
+}
+
+// add appends an entry to the pending chunk, flushing it if it grew too large.
+func (w *snapshotFileWriter) add(kind uint8, key common.Hash, value []byte) error {
+	// Iterators may reuse their buffers, copy the value until the chunk is flushed
+	w.entries = append(w.entries, snapshotEntry{Kind: kind, Key: key, Value: common.CopyBytes(value)})
+	w.size += common.HashLength + len(value)
+	if w.size < snapshotChunkSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *snapshotFileWriter) flush() error {
+	if len(w.entries) == 0 {
+		return nil
+	}
+	if err := w.writeChunk(snapshotChunkEntries, w.entries); err != nil {
+		return err
+	}
+	w.entries, w.size = w.entries[:0], 0
+	return nil
+}
+
+// snapshotFileReader reads and verifies the chunks of a state snapshot file.
+type snapshotFileReader struct {
+	r io.Reader
+}
+
+func (r *snapshotFileReader) readChunk() (byte, []byte, error) {
+	head := make([]byte, 5)
+	if _, err := io.ReadFull(r.r, head); err != nil {
+		if err == io.EOF {
+			return 0, nil, io.ErrUnexpectedEOF // The footer is always expected
+		}
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(head[1:])
+	if size > snapshotChunkMaxSize {
+		return 0, nil, fmt.Errorf("chunk too large: %d bytes", size)
+	}
+	body := make([]byte, size+4)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	checksum := crc32.Update(crc32.Checksum(head, snapshotCRCTable), snapshotCRCTable, body[:size])
+	if have := binary.BigEndian.Uint32(body[size:]); have != checksum {
+		return 0, nil, fmt.Errorf("chunk checksum mismatch: have %08x, want %08x", have, checksum)
+	}
+	return head[0], body[:size], nil
+}
+
+// ExportSnapshot exports the state of the given block from the snapshot into a
+// chunked, checksummed binary file, truncating any data already present.
+func ExportSnapshot(chaindb ethdb.KeyValueReader, snaptree *snapshot.Tree, header *types.Header, fn string) error {
+	log.Info("Exporting state snapshot", "file", fn, "number", header.Number, "root", header.Root)
+
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	buf := bufio.NewWriter(fh)
+	if err := exportSnapshot(buf, chaindb, snaptree, header); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	return fh.Sync()
+}
+
+func exportSnapshot(w io.Writer, chaindb ethdb.KeyValueReader, snaptree *snapshot.Tree, header *types.Header) error {
+	root := header.Root
+	accIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	if _, err := io.WriteString(w, snapshotFileMagic); err != nil {
+		return err
+	}
+	writer := &snapshotFileWriter{w: w}
+	if err := writer.writeChunk(snapshotChunkHeader, &snapshotFileHeader{
+		Version:  snapshotFileVersion,
+		Root:     root,
+		Number:   header.Number.Uint64(),
+		Hash:     header.Hash(),
+		UnixTime: uint64(time.Now().Unix()),
+	}); err != nil {
+		return err
+	}
+	var (
+		footer snapshotFileFooter
+		codes  = make(map[common.Hash]struct{})
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for accIt.Next() {
+		account, err := types.FullAccount(accIt.Account())
+		if err != nil {
+			return err
+		}
+		if err := writer.add(snapshotEntryAccount, accIt.Hash(), accIt.Account()); err != nil {
+			return err
+		}
+		footer.Accounts++
+
+		codeHash := common.BytesToHash(account.CodeHash)
+		if _, ok := codes[codeHash]; !ok && codeHash != types.EmptyCodeHash {
+			code := rawdb.ReadCode(chaindb, codeHash)
+			if len(code) == 0 {
+				return fmt.Errorf("missing code %x of account %x", codeHash, accIt.Hash())
+			}
+			if err := writer.add(snapshotEntryCode, codeHash, code); err != nil {
+				return err
+			}
+			codes[codeHash] = struct{}{}
+			footer.Codes++
+		}
+		if account.Root != types.EmptyRootHash {
+			stIt, err := snaptree.StorageIterator(root, accIt.Hash(), common.Hash{})
+			if err != nil {
+				return err
+			}
+			for stIt.Next() {
+				if err := writer.add(snapshotEntrySlot, stIt.Hash(), stIt.Slot()); err != nil {
+					stIt.Release()
+					return err
+				}
+				footer.Slots++
+
+				if time.Since(logged) > 8*time.Second {
+					log.Info("Exporting state snapshot", "at", accIt.Hash(), "accounts", footer.Accounts, "slots", footer.Slots, "codes", footer.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+					logged = time.Now()
+				}
+			}
+			stIt.Release()
+			if err := stIt.Error(); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state snapshot", "at", accIt.Hash(), "accounts", footer.Accounts, "slots", footer.Slots, "codes", footer.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	if err := writer.flush(); err != nil {
+		return err
+	}
+	if err := writer.writeChunk(snapshotChunkFooter, &footer); err != nil {
+		return err
+	}
+	log.Info("Exported state snapshot", "root", root, "accounts", footer.Accounts, "slots", footer.Slots, "codes", footer.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// ImportSnapshot imports a state snapshot file into the database, rebuilding the
+// state trie in the given scheme along with the flat snapshot. The root of the
+// rebuilt trie is verified against the one recorded in the file, and returned
+// on success.
+//
+// The target database must not contain any state yet: the import neither
+// deletes stale trie nodes nor snapshot entries, which would otherwise end up
+// mixed into the imported state and marked as a complete snapshot.
+func ImportSnapshot(db ethdb.Database, scheme string, fn string) (common.Hash, error) {
+	log.Info("Importing state snapshot", "file", fn, "scheme", scheme)
+
+	if err := checkSnapshotImportTarget(db); err != nil {
+		return common.Hash{}, err
+	}
+	fh, err := os.Open(fn)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer fh.Close()
+
+	header, err := importSnapshot(bufio.NewReader(fh), db, scheme)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+// checkSnapshotImportTarget returns an error if the database already contains
+// any state trie or flat snapshot data.
+func checkSnapshotImportTarget(db ethdb.Database) error {
+	if scheme := rawdb.ReadStateScheme(db); scheme != "" {
+		return fmt.Errorf("target database already contains %s state", scheme)
+	}
+	if root := rawdb.ReadSnapshotRoot(db); root != (common.Hash{}) {
+		return fmt.Errorf("target database already contains snapshot %x", root)
+	}
+	// Legacy trie nodes are keyed by their bare hash, so only keys of exactly
+	// the snapshot entry length are treated as leftovers.
+	for prefix, size := range map[string]int{
+		string(rawdb.SnapshotAccountPrefix): len(rawdb.SnapshotAccountPrefix) + common.HashLength,
+		string(rawdb.SnapshotStoragePrefix): len(rawdb.SnapshotStoragePrefix) + 2*common.HashLength,
+	} {
+		it := db.NewIterator([]byte(prefix), nil)
+		for it.Next() {
+			if len(it.Key()) == size {
+				it.Release()
+				return errors.New("target database already contains snapshot data")
+			}
+		}
+		it.Release()
+	}
+	return nil
+}
+
+// snapshotImporter rebuilds the state tries out of the stream of flat state
+// entries.
+type snapshotImporter struct {
+	batch  ethdb.Batch
+	scheme string
+
+	accTrie     *trie.StackTrie
+	lastAccount *common.Hash         // Hash of the last imported account
+	account     *types.StateAccount  // Last imported account
+	storageTrie *trie.StackTrie      // Storage trie of the last imported account
+	lastSlot    *common.Hash         // Hash of the last imported slot of the account
+	codes       map[common.Hash]bool // Referenced codes, flagged whether already imported
+
+	imported snapshotFileFooter
+}
+
+func newSnapshotImporter(db ethdb.Database, scheme string) *snapshotImporter {
+	imp := &snapshotImporter{
+		batch:  db.NewBatch(),
+		scheme: scheme,
+		codes:  make(map[common.Hash]bool),
+	}
+	imp.accTrie = trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+		rawdb.WriteTrieNode(imp.batch, common.Hash{}, path, hash, blob, scheme)
+	})
+	return imp
+}
+
+func (imp *snapshotImporter) process(entry *snapshotEntry) error {
+	switch entry.Kind {
+	case snapshotEntryAccount:
+		if imp.lastAccount != nil && bytes.Compare(entry.Key[:], imp.lastAccount[:]) <= 0 {
+			return fmt.Errorf("account %x out of order", entry.Key)
+		}
+		if err := imp.finishAccount(); err != nil {
+			return err
+		}
+		account, err := types.FullAccount(entry.Value)
+		if err != nil {
+			return fmt.Errorf("invalid account %x: %v", entry.Key, err)
+		}
+		full, err := rlp.EncodeToBytes(account)
+		if err != nil {
+			return err
+		}
+		if err := imp.accTrie.Update(entry.Key[:], full); err != nil {
+			return err
+		}
+		rawdb.WriteAccountSnapshot(imp.batch, entry.Key, entry.Value)
+
+		key := entry.Key
+		imp.lastAccount, imp.account, imp.lastSlot = &key, account, nil
+		if account.Root != types.EmptyRootHash {
+			imp.storageTrie = trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+				rawdb.WriteTrieNode(imp.batch, key, path, hash, blob, imp.scheme)
+			})
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if _, ok := imp.codes[codeHash]; !ok {
+				imp.codes[codeHash] = false
+			}
+		}
+		imp.imported.Accounts++
+
+	case snapshotEntryCode:
+		if crypto.Keccak256Hash(entry.Value) != entry.Key {
+			return fmt.Errorf("code hash mismatch for %x", entry.Key)
+		}
+		rawdb.WriteCode(imp.batch, entry.Key, entry.Value)
+		imp.codes[entry.Key] = true
+		imp.imported.Codes++
+
+	case snapshotEntrySlot:
+		if imp.storageTrie == nil {
+			return fmt.Errorf("slot %x without account storage", entry.Key)
+		}
+		if imp.lastSlot != nil && bytes.Compare(entry.Key[:], imp.lastSlot[:]) <= 0 {
+			return fmt.Errorf("slot %x of account %x out of order", entry.Key, *imp.lastAccount)
+		}
+		if err := imp.storageTrie.Update(entry.Key[:], entry.Value); err != nil {
+			return err
+		}
+		rawdb.WriteStorageSnapshot(imp.batch, *imp.lastAccount, entry.Key, entry.Value)
+
+		key := entry.Key
+		imp.lastSlot = &key
+		imp.imported.Slots++
+
+	default:
+		return fmt.Errorf("unknown entry kind %d", entry.Kind)
+	}
+	return nil
+}
+
+// finishAccount verifies the storage root of the last imported account.
+func (imp *snapshotImporter) finishAccount() error {
+	if imp.storageTrie == nil {
+		return nil
+	}
+	if root := imp.storageTrie.Hash(); root != imp.account.Root {
+		return fmt.Errorf("storage root mismatch for account %x: have %x, want %x", *imp.lastAccount, root, imp.account.Root)
+	}
+	imp.storageTrie = nil
+	return nil
+}
+
+func importSnapshot(r io.Reader, db ethdb.Database, scheme string) (*snapshotFileHeader, error) {
+	magic := make([]byte, len(snapshotFileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != snapshotFileMagic {
+		return nil, errors.New("incompatible data, wrong magic")
+	}
+	reader := &snapshotFileReader{r: r}
+	kind, payload, err := reader.readChunk()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %v", err)
+	}
+	if kind != snapshotChunkHeader {
+		return nil, fmt.Errorf("unexpected chunk kind %d, want header", kind)
+	}
+	header := new(snapshotFileHeader)
+	if err := rlp.DecodeBytes(payload, header); err != nil {
+		return nil, fmt.Errorf("could not decode header: %v", err)
+	}
+	if header.Version != snapshotFileVersion {
+		return nil, fmt.Errorf("incompatible version %d, (support only %d)", header.Version, snapshotFileVersion)
+	}
+	log.Info("Importing state snapshot", "number", header.Number, "hash", header.Hash, "root", header.Root,
+		"age", common.PrettyDuration(time.Since(time.Unix(int64(header.UnixTime), 0))))
+
+	var (
+		imp    = newSnapshotImporter(db, scheme)
+		start  = time.Now()
+		logged = time.Now()
+		footer *snapshotFileFooter
+	)
+	for footer == nil {
+		kind, payload, err := reader.readChunk()
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case snapshotChunkEntries:
+			var entries []snapshotEntry
+			if err := rlp.DecodeBytes(payload, &entries); err != nil {
+				return nil, fmt.Errorf("could not decode entries: %v", err)
+			}
+			for i := range entries {
+				if err := imp.process(&entries[i]); err != nil {
+					return nil, err
+				}
+			}
+		case snapshotChunkFooter:
+			footer = new(snapshotFileFooter)
+			if err := rlp.DecodeBytes(payload, footer); err != nil {
+				return nil, fmt.Errorf("could not decode footer: %v", err)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected chunk kind %d", kind)
+		}
+		if imp.batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := imp.batch.Write(); err != nil {
+				return nil, err
+			}
+			imp.batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing state snapshot", "accounts", imp.imported.Accounts, "slots", imp.imported.Slots, "codes", imp.imported.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := imp.finishAccount(); err != nil {
+		return nil, err
+	}
+	if imp.imported != *footer {
+		return nil, fmt.Errorf("item count mismatch: have %+v, want %+v", imp.imported, *footer)
+	}
+	for hash, imported := range imp.codes {
+		if !imported {
+			return nil, fmt.Errorf("missing code %x", hash)
+		}
+	}
+	if root := imp.accTrie.Hash(); root != header.Root {
+		return nil, fmt.Errorf("state root mismatch: have %x, want %x", root, header.Root)
+	}
+	// The state is complete, mark the flat snapshot as matching it
+	snapshot.MarkComplete(imp.batch, header.Root)
+	if err := imp.batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Imported state snapshot", "root", header.Root, "accounts", imp.imported.Accounts, "slots", imp.imported.Slots, "codes", imp.imported.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return header, nil
+}
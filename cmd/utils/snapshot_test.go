@@ -0,0 +1,227 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func newSnapshotTestTrieDB(db ethdb.Database, scheme string) *triedb.Database {
+	if scheme == rawdb.HashScheme {
+		return triedb.NewDatabase(db, &triedb.Config{HashDB: hashdb.Defaults})
+	}
+	return triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.Defaults})
+}
+
+// makeSnapshotTestState creates a state with a few thousand accounts, some
+// of them sharing code and having storage, along with its snapshot.
+func makeSnapshotTestState(t *testing.T) (ethdb.Database, *snapshot.Tree, common.Hash) {
+	db := rawdb.NewMemoryDatabase()
+	tdb := newSnapshotTestTrieDB(db, rawdb.HashScheme)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseWithNodeDB(db, tdb), nil)
+	for i := 0; i < 3000; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i)))
+		statedb.SetBalance(addr, uint256.NewInt(uint64(i)), tracing.BalanceChangeUnspecified)
+		statedb.SetNonce(addr, uint64(i%7))
+		if i%10 == 0 {
+			statedb.SetCode(addr, []byte{byte(i % 3), 0x60, 0x00})
+		}
+		if i%100 == 0 {
+			for j := 0; j < 1000; j++ {
+				statedb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(int64(i+j+1))))
+			}
+		}
+	}
+	root, err := statedb.Commit(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tdb.Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := snapshot.New(snapshot.Config{CacheSize: 16}, db, tdb, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, snaps, root
+}
+
+func TestSnapshotExportImport(t *testing.T) {
+	srcdb, snaps, root := makeSnapshotTestState(t)
+	defer snaps.Release()
+
+	fn := filepath.Join(t.TempDir(), "state.snap")
+	header := &types.Header{Number: big.NewInt(42), Root: root}
+	if err := ExportSnapshot(srcdb, snaps, header, fn); err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	src, _ := state.New(root, state.NewDatabase(srcdb), nil)
+
+	for _, scheme := range []string{rawdb.HashScheme, rawdb.PathScheme} {
+		t.Run(scheme, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			imported, err := ImportSnapshot(db, scheme, fn)
+			if err != nil {
+				t.Fatalf("failed to import snapshot: %v", err)
+			}
+			if imported != root {
+				t.Fatalf("root mismatch: have %x, want %x", imported, root)
+			}
+			if stored := rawdb.ReadSnapshotRoot(db); stored != root {
+				t.Fatalf("snapshot root mismatch: have %x, want %x", stored, root)
+			}
+			tdb := newSnapshotTestTrieDB(db, scheme)
+			defer tdb.Close()
+
+			dst, err := state.New(root, state.NewDatabaseWithNodeDB(db, tdb), nil)
+			if err != nil {
+				t.Fatalf("failed to open imported state: %v", err)
+			}
+			for i := 0; i < 3000; i += 7 {
+				addr := common.BigToAddress(big.NewInt(int64(i)))
+				if dst.GetBalance(addr).Cmp(src.GetBalance(addr)) != 0 || dst.GetNonce(addr) != src.GetNonce(addr) {
+					t.Fatalf("account %d mismatch", i)
+				}
+				if !bytes.Equal(dst.GetCode(addr), src.GetCode(addr)) {
+					t.Fatalf("code %d mismatch", i)
+				}
+			}
+			for i := 0; i < 3000; i += 100 {
+				addr := common.BigToAddress(big.NewInt(int64(i)))
+				for j := 0; j < 1000; j += 33 {
+					key := common.BigToHash(big.NewInt(int64(j)))
+					if have, want := dst.GetState(addr, key), src.GetState(addr, key); have != want {
+						t.Fatalf("slot %d/%d mismatch: have %x, want %x", i, j, have, want)
+					}
+				}
+			}
+			// The imported flat snapshot must be usable without regeneration
+			dstSnaps, err := snapshot.New(snapshot.Config{CacheSize: 16, NoBuild: true}, db, tdb, root)
+			if err != nil {
+				t.Fatalf("failed to open imported snapshot: %v", err)
+			}
+			defer dstSnaps.Release()
+
+			srcIt, _ := snaps.AccountIterator(root, common.Hash{})
+			defer srcIt.Release()
+			dstIt, _ := dstSnaps.AccountIterator(root, common.Hash{})
+			defer dstIt.Release()
+			for srcIt.Next() {
+				if !dstIt.Next() {
+					t.Fatalf("account %x missing from imported snapshot", srcIt.Hash())
+				}
+				if srcIt.Hash() != dstIt.Hash() || !bytes.Equal(srcIt.Account(), dstIt.Account()) {
+					t.Fatalf("account mismatch: have %x, want %x", dstIt.Hash(), srcIt.Hash())
+				}
+			}
+			if dstIt.Next() {
+				t.Fatalf("unexpected account %x in imported snapshot", dstIt.Hash())
+			}
+		})
+	}
+}
+
+func TestSnapshotImportCorrupted(t *testing.T) {
+	srcdb, snaps, root := makeSnapshotTestState(t)
+	defer snaps.Release()
+
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "state.snap")
+	if err := ExportSnapshot(srcdb, snaps, &types.Header{Number: big.NewInt(1), Root: root}, fn); err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	blob, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := func(name string, mutate func([]byte) []byte) {
+		data := mutate(common.CopyBytes(blob))
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		db := rawdb.NewMemoryDatabase()
+		if _, err := ImportSnapshot(db, rawdb.HashScheme, file); err == nil {
+			t.Errorf("%s: corrupted file imported", name)
+		}
+		if stored := rawdb.ReadSnapshotRoot(db); stored != (common.Hash{}) {
+			t.Errorf("%s: snapshot root written for corrupted file", name)
+		}
+	}
+	corrupt("magic", func(b []byte) []byte { b[0] ^= 0xff; return b })
+	for _, pos := range []int{len(blob) / 3, len(blob) / 2, len(blob) - 3} {
+		pos := pos
+		corrupt(fmt.Sprintf("flip-%d", pos), func(b []byte) []byte { b[pos] ^= 0x01; return b })
+	}
+	corrupt("truncated", func(b []byte) []byte { return b[:len(b)-20] })
+	corrupt("no-footer", func(b []byte) []byte { return b[:len(b)/2] })
+}
+
+func TestSnapshotImportPopulated(t *testing.T) {
+	srcdb, snaps, root := makeSnapshotTestState(t)
+	defer snaps.Release()
+
+	fn := filepath.Join(t.TempDir(), "state.snap")
+	if err := ExportSnapshot(srcdb, snaps, &types.Header{Number: big.NewInt(1), Root: root}, fn); err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	// Importing into a database holding the state in path scheme must fail
+	// without touching the existing snapshot.
+	populated := rawdb.NewMemoryDatabase()
+	if _, err := ImportSnapshot(populated, rawdb.PathScheme, fn); err != nil {
+		t.Fatalf("failed to import snapshot: %v", err)
+	}
+	if _, err := ImportSnapshot(populated, rawdb.PathScheme, fn); err == nil {
+		t.Fatal("snapshot imported into populated path database")
+	}
+	// Importing into a database with leftover snapshot entries must fail too
+	stale := rawdb.NewMemoryDatabase()
+	rawdb.WriteAccountSnapshot(stale, common.Hash{0x01}, []byte{0x01})
+	if _, err := ImportSnapshot(stale, rawdb.HashScheme, fn); err == nil {
+		t.Fatal("snapshot imported into database with stale snapshot entries")
+	}
+	if stored := rawdb.ReadSnapshotRoot(stale); stored != (common.Hash{}) {
+		t.Fatalf("snapshot root written into populated database: %x", stored)
+	}
+	// Importing into a database with hash scheme state must fail as well
+	legacy := rawdb.NewMemoryDatabase()
+	rawdb.WriteLegacyTrieNode(legacy, common.Hash{0x02}, []byte{0x02})
+	rawdb.WriteSnapshotRoot(legacy, common.Hash{0x02})
+	if _, err := ImportSnapshot(legacy, rawdb.HashScheme, fn); err == nil {
+		t.Fatal("snapshot imported into database with existing snapshot")
+	}
+	if stored := rawdb.ReadSnapshotRoot(legacy); stored != (common.Hash{0x02}) {
+		t.Fatalf("existing snapshot root overwritten: %x", stored)
+	}
+}
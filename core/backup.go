@@ -0,0 +1,69 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// BackupDatabase writes a consistent copy of the chain database, along with its
+// ancient store and the state histories of the trie database, into the given
+// directory, which must not exist yet. The databases remain fully operational
+// in the meantime. The copy can be opened as the chain database of a datadir.
+//
+// Only the persistent state is part of the copy, the state layers held in memory
+// by the trie database are lost, just like after a crash. The chain is rewound
+// to the latest persisted state once the copy is opened.
+func BackupDatabase(db ethdb.Database, tdb *triedb.Database, dir string) error {
+	checkpointer, ok := db.(ethdb.Checkpointer)
+	if !ok {
+		return errors.New("database checkpoint not supported")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("backup location %s already exists", dir)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := checkpointer.Checkpoint(dir); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	// The state histories must be copied after the key-value store, so that they
+	// are not behind the persistent state in the copy.
+	if tdb != nil && tdb.Scheme() == rawdb.PathScheme {
+		if err := tdb.CheckpointHistory(filepath.Join(dir, "ancient")); err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+	}
+	log.Info("Backed up chain database", "path", dir, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBackupDatabase(t *testing.T) {
+	testBackupDatabase(t, rawdb.HashScheme)
+	testBackupDatabase(t, rawdb.PathScheme)
+}
+
+func testBackupDatabase(t *testing.T, scheme string) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}}}
+		signer  = types.LatestSigner(gspec.Config)
+		dir     = t.TempDir()
+		backup  = filepath.Join(dir, "backup", "chaindata")
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 32, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0x01}, big.NewInt(1), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:              "pebble",
+		Directory:         filepath.Join(dir, "chaindata"),
+		AncientsDirectory: filepath.Join(dir, "chaindata", "ancient"),
+		Ephemeral:         true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	chain, err := NewBlockChain(db, DefaultCacheConfigWithScheme(scheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks[:16]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	head := blocks[15]
+	if err := chain.TrieDB().Commit(head.Root(), false); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := BackupDatabase(db, chain.TrieDB(), backup); err != nil {
+		t.Fatalf("failed to back up database: %v", err)
+	}
+	if err := BackupDatabase(db, chain.TrieDB(), backup); err == nil {
+		t.Fatal("backup into existing directory succeeded")
+	}
+	// Keep importing into the original chain, it must not affect the backup
+	if _, err := chain.InsertChain(blocks[16:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	cpydb, err := rawdb.Open(rawdb.OpenOptions{
+		Type:              "pebble",
+		Directory:         backup,
+		AncientsDirectory: filepath.Join(backup, "ancient"),
+		Ephemeral:         true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer cpydb.Close()
+
+	cpy, err := NewBlockChain(cpydb, DefaultCacheConfigWithScheme(scheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain from backup: %v", err)
+	}
+	defer cpy.Stop()
+
+	if have := cpy.CurrentBlock().Hash(); have != head.Hash() {
+		t.Fatalf("head block mismatch: have %x, want %x", have, head.Hash())
+	}
+	statedb, err := cpy.StateAt(head.Root())
+	if err != nil {
+		t.Fatalf("head state missing from backup: %v", err)
+	}
+	if nonce := statedb.GetNonce(address); nonce != 16 {
+		t.Fatalf("nonce mismatch: have %d, want 16", nonce)
+	}
+	if scheme == rawdb.PathScheme {
+		if ok, _ := cpy.TrieDB().Recoverable(blocks[7].Root()); !ok {
+			t.Fatal("state histories missing from backup")
+		}
+	}
+	if _, err := cpy.InsertChain(blocks[16:]); err != nil {
+		t.Fatalf("failed to continue chain from backup: %v", err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -137,9 +138,12 @@ type CacheConfig struct {
 	TrieDirtyDisabled   bool          // Whether to disable trie write caching and GC altogether (archive node)
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
+	CacheJournal        string        // Directory to persist the hot clean cache entries across restarts (empty = disabled)
+	CacheJournalSize    int           // Memory allowance (MB) of hot entries to persist per cache
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
+	HistoryEra          string        // Directory of era1 archives serving the pruned chain history (empty = disabled)
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
@@ -162,6 +166,10 @@ func (c *CacheConfig) triedbConfig(isVerkle bool) *triedb.Config {
 			CleanCacheSize: c.TrieCleanLimit * 1024 * 1024,
 			DirtyCacheSize: c.TrieDirtyLimit * 1024 * 1024,
 		}
+		if c.CacheJournal != "" {
+			config.PathDB.CleanCacheJournal = filepath.Join(c.CacheJournal, "triecache")
+			config.PathDB.CleanCacheJournalSize = c.CacheJournalSize * 1024 * 1024
+		}
 	}
 	return config
 }
@@ -219,6 +227,7 @@ type BlockChain struct {
 	triedb        *triedb.Database                 // The database handler for maintaining trie nodes.
 	stateCache    state.Database                   // State database to reuse between imports (contains state cache)
 	txIndexer     *txIndexer                       // Transaction indexer, might be nil if not enabled
+	eraHistory    *eraHistory                      // Era1 archives serving the pruned chain history, might be nil if not configured
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
@@ -317,7 +326,22 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 
 	bc.genesisBlock = bc.GetBlockByNumber(0)
 	if bc.genesisBlock == nil {
-		return nil, ErrNoGenesis
+		// The genesis body might have been pruned along with the chain history
+		genesisHeader := bc.GetHeaderByNumber(0)
+		if genesisHeader == nil {
+			return nil, ErrNoGenesis
+		}
+		bc.genesisBlock = types.NewBlockWithHeader(genesisHeader)
+	}
+	if cacheConfig.HistoryEra != "" {
+		network, ok := eraNetworks[genesisHash]
+		if !ok {
+			return nil, fmt.Errorf("era1 archives not supported for network with genesis %x", genesisHash)
+		}
+		bc.eraHistory, err = newEraHistory(cacheConfig.HistoryEra, network)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	bc.currentBlock.Store(nil)
@@ -376,8 +400,14 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 			}
 		}
 	}
-	// Ensure that a previous crash in SetHead doesn't leave extra ancients
-	if frozen, err := bc.db.Ancients(); err == nil && frozen > 0 {
+	if frozen, err := bc.db.Ancients(); err == nil && frozen > 0 && rawdb.HasImmutableAncients(bc.db) {
+		// An era1-backed ancient store is read-only and can never be truncated.
+		// Rather than rewinding into it, treat the archive as already synced.
+		if err := bc.fastForwardToArchive(frozen); err != nil {
+			return nil, err
+		}
+	} else if err == nil && frozen > 0 {
+		// Ensure that a previous crash in SetHead doesn't leave extra ancients
 		var (
 			needRewind bool
 			low        uint64
@@ -447,6 +477,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 			NoBuild:    bc.cacheConfig.SnapshotNoBuild,
 			AsyncBuild: !bc.cacheConfig.SnapshotWait,
 		}
+		if bc.cacheConfig.CacheJournal != "" {
+			snapconfig.CacheJournal = filepath.Join(bc.cacheConfig.CacheJournal, "snapcache")
+			snapconfig.CacheJournalSize = bc.cacheConfig.CacheJournalSize
+		}
 		bc.snaps, _ = snapshot.New(snapconfig, bc.db, bc.triedb, head.Root)
 	}
 	// Rewind the chain in case of an incompatible config upgrade.
@@ -481,6 +515,31 @@ func (bc *BlockChain) empty() bool {
 	return true
 }
 
+// fastForwardToArchive moves the head header and head snap block markers up to
+// the last block of a read-only ancient store, so that neither sync nor repair
+// attempts to rewrite or truncate the archived chain.
+func (bc *BlockChain) fastForwardToArchive(frozen uint64) error {
+	head := bc.GetHeaderByNumber(frozen - 1)
+	if head == nil {
+		return fmt.Errorf("missing archived head header #%d", frozen-1)
+	}
+	batch := bc.db.NewBatch()
+	if bc.CurrentHeader().Number.Uint64() < head.Number.Uint64() {
+		rawdb.WriteHeadHeaderHash(batch, head.Hash())
+		bc.hc.SetCurrentHeader(head)
+	}
+	if bc.CurrentSnapBlock().Number.Uint64() < head.Number.Uint64() {
+		rawdb.WriteHeadFastBlockHash(batch, head.Hash())
+		bc.currentSnapBlock.Store(head)
+		headFastBlockGauge.Update(int64(head.Number.Uint64()))
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Using read-only ancient chain", "head", head.Number, "hash", head.Hash())
+	return nil
+}
+
 // loadLastState loads the last known chain state from the database. This method
 // assumes that the chain manager mutex is held.
 func (bc *BlockChain) loadLastState() error {
@@ -493,6 +552,10 @@ func (bc *BlockChain) loadLastState() error {
 	}
 	// Make sure the entire head block is available
 	headBlock := bc.GetBlockByHash(head)
+	if headBlock == nil && head == bc.genesisBlock.Hash() {
+		// The genesis body might have been pruned along with the chain history
+		headBlock = bc.genesisBlock
+	}
 	if headBlock == nil {
 		// Corrupt or empty database, init from scratch
 		log.Warn("Head block missing, resetting chain", "hash", head)
@@ -1160,6 +1223,9 @@ func (bc *BlockChain) Stop() {
 	if err := bc.triedb.Close(); err != nil {
 		log.Error("Failed to close trie database", "err", err)
 	}
+	if bc.eraHistory != nil {
+		bc.eraHistory.close()
+	}
 	log.Info("Blockchain stopped")
 }
 
@@ -1196,6 +1262,18 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		ancientBlocks, liveBlocks     types.Blocks
 		ancientReceipts, liveReceipts []types.Receipts
 	)
+	// A read-only ancient store already holds the chain up to its head and cannot
+	// be appended to, so anything beyond it has to go into the key-value store.
+	immutable := rawdb.HasImmutableAncients(bc.db)
+	if immutable {
+		frozen, err := bc.db.Ancients()
+		if err != nil {
+			return 0, err
+		}
+		if frozen > 0 && ancientLimit >= frozen {
+			ancientLimit = frozen - 1
+		}
+	}
 	// Do a sanity check that the provided chain is actually ordered and linked
 	for i, block := range blockChain {
 		if i != 0 {
@@ -1389,7 +1467,14 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 	}
 
 	// Write downloaded chain data and corresponding receipt chain data
-	if len(ancientBlocks) > 0 {
+	if len(ancientBlocks) > 0 && immutable {
+		// The blocks are already archived, just make sure they are the same ones
+		last := ancientBlocks[len(ancientBlocks)-1]
+		if rawdb.ReadCanonicalHash(bc.db, last.NumberU64()) != last.Hash() {
+			return 0, errSideChainReceipts
+		}
+		stats.ignored += int32(len(ancientBlocks))
+	} else if len(ancientBlocks) > 0 {
 		if n, err := writeAncient(ancientBlocks, ancientReceipts); err != nil {
 			if err == errInsertionInterrupted {
 				return 0, nil
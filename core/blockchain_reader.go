@@ -106,7 +106,9 @@ func (bc *BlockChain) GetBody(hash common.Hash) *types.Body {
 	}
 	body := rawdb.ReadBody(bc.db, hash, *number)
 	if body == nil {
-		return nil
+		if body = bc.readPrunedBody(hash, *number); body == nil {
+			return nil
+		}
 	}
 	// Cache the found body for next time and return
 	bc.bodyCache.Add(hash, body)
@@ -126,7 +128,9 @@ func (bc *BlockChain) GetBodyRLP(hash common.Hash) rlp.RawValue {
 	}
 	body := rawdb.ReadBodyRLP(bc.db, hash, *number)
 	if len(body) == 0 {
-		return nil
+		if body = bc.readPrunedBodyRLP(hash, *number); len(body) == 0 {
+			return nil
+		}
 	}
 	// Cache the found body for next time and return
 	bc.bodyRLPCache.Add(hash, body)
@@ -164,7 +168,15 @@ func (bc *BlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
 	}
 	block := rawdb.ReadBlock(bc.db, hash, number)
 	if block == nil {
-		return nil
+		header := bc.GetHeader(hash, number)
+		if header == nil {
+			return nil
+		}
+		body := bc.readPrunedBody(hash, number)
+		if body == nil {
+			return nil
+		}
+		block = types.NewBlockWithHeader(header).WithBody(*body)
 	}
 	// Cache the found block for next time and return
 	bc.blockCache.Add(block.Hash(), block)
@@ -224,7 +236,9 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	}
 	receipts := rawdb.ReadReceipts(bc.db, hash, *number, header.Time, bc.chainConfig)
 	if receipts == nil {
-		return nil
+		if receipts = bc.readPrunedReceipts(header); receipts == nil {
+			return nil
+		}
 	}
 	bc.receiptsCache.Add(hash, receipts)
 	return receipts
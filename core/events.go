@@ -17,6 +17,8 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -24,6 +26,50 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// TxLifecycle is a state change of a transaction in the transaction pool.
+type TxLifecycle uint8
+
+const (
+	TxAdded    TxLifecycle = iota // Transaction entered the pool
+	TxPromoted                    // Transaction became executable
+	TxDemoted                     // Transaction became non-executable due to a nonce gap
+	TxReplaced                    // Transaction was replaced by one with the same nonce
+	TxEvicted                     // Transaction was dropped from the pool
+	TxIncluded                    // Transaction was included in the chain
+)
+
+// String implements fmt.Stringer.
+func (l TxLifecycle) String() string {
+	switch l {
+	case TxAdded:
+		return "added"
+	case TxPromoted:
+		return "promoted"
+	case TxDemoted:
+		return "demoted"
+	case TxReplaced:
+		return "replaced"
+	case TxEvicted:
+		return "evicted"
+	case TxIncluded:
+		return "included"
+	default:
+		return fmt.Sprintf("lifecycle(%d)", uint8(l))
+	}
+}
+
+// TxLifecycleEvent is posted when a transaction changes state in the transaction
+// pool.
+type TxLifecycleEvent struct {
+	Type        TxLifecycle
+	Hash        common.Hash
+	From        common.Address
+	Nonce       uint64
+	Replacement common.Hash // Hash of the replacing transaction, set if replaced
+	Reason      error       // Reason of the eviction, set if evicted
+	Private     bool        // Whether the transaction was submitted privately, never to be revealed
+}
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -94,11 +94,10 @@ func ReadGenesis(db ethdb.Database) (*Genesis, error) {
 	if genesis.Config == nil {
 		return nil, errors.New("genesis config missing from db")
 	}
-	genesisBlock := rawdb.ReadBlock(db, stored, 0)
-	if genesisBlock == nil {
+	genesisHeader := rawdb.ReadHeader(db, stored, 0)
+	if genesisHeader == nil {
 		return nil, errors.New("genesis block missing from db")
 	}
-	genesisHeader := genesisBlock.Header()
 	genesis.Nonce = genesisHeader.Nonce.Uint64()
 	genesis.Timestamp = genesisHeader.Time
 	genesis.ExtraData = genesisHeader.Extra
@@ -0,0 +1,286 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// HistoryPrunePoints contains the default blocks below which the chain history
+// can be pruned for the known networks, keyed by genesis hash. These are the
+// merge blocks, so that the entire pre-merge history is pruned.
+var HistoryPrunePoints = map[common.Hash]uint64{
+	params.MainnetGenesisHash: 15537394,
+	params.SepoliaGenesisHash: 1450409,
+}
+
+// ErrHistoryPruned is returned when the requested block bodies or receipts have
+// been pruned and are not available from era1 archives either.
+var ErrHistoryPruned = errors.New("pruned history unavailable")
+
+// eraNetworks maps the genesis hashes of the known networks to the network names
+// used in the era1 file names.
+var eraNetworks = map[common.Hash]string{
+	params.MainnetGenesisHash: "mainnet",
+	params.SepoliaGenesisHash: "sepolia",
+	params.GoerliGenesisHash:  "goerli",
+}
+
+// eraHistoryOpenFiles is the maximum number of era1 files kept open.
+const eraHistoryOpenFiles = 8
+
+// PruneHistory removes the block bodies and receipts below the given block from
+// the ancient store. The headers are retained. The transaction indexes of the
+// pruned blocks are removed beforehand, while the bodies are still available.
+//
+// The database must not be in use while being pruned.
+func PruneHistory(db ethdb.Database, cutoff uint64) error {
+	frozen, err := db.Ancients()
+	if err != nil {
+		return err
+	}
+	if cutoff > frozen {
+		return fmt.Errorf("pruning target %d is above the ancient store head %d", cutoff, frozen)
+	}
+	tail, err := db.Tail()
+	if err != nil {
+		return err
+	}
+	if cutoff <= tail {
+		log.Info("Chain history already pruned", "tail", tail, "target", cutoff)
+		return nil
+	}
+	if indexTail := rawdb.ReadTxIndexTail(db); indexTail != nil && *indexTail < cutoff {
+		rawdb.UnindexTransactions(db, *indexTail, cutoff, nil, true)
+	}
+	if _, err := db.TruncateTail(cutoff); err != nil {
+		return err
+	}
+	log.Info("Pruned chain history", "from", tail, "to", cutoff)
+	return nil
+}
+
+// eraHistory serves the pruned block bodies and receipts of the canonical chain
+// from a directory of era1 archives.
+type eraHistory struct {
+	dir   string
+	files []string // Era1 file names, indexed by epoch
+	limit uint64   // Number of blocks covered by the archives
+
+	lock sync.Mutex
+	open lru.BasicLRU[int, *era.Era] // Recently accessed era1 files
+}
+
+// newEraHistory opens the era1 archives of the given network in the directory.
+func newEraHistory(dir string, network string) (*eraHistory, error) {
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return nil, err
+	}
+	h := &eraHistory{
+		dir:   dir,
+		files: files,
+		open:  lru.NewBasicLRU[int, *era.Era](eraHistoryOpenFiles),
+	}
+	if len(files) > 0 {
+		e, err := h.file(len(files) - 1)
+		if err != nil {
+			return nil, err
+		}
+		h.limit = e.Start() + e.Count()
+	}
+	log.Info("Opened era1 history archives", "dir", dir, "files", len(files), "blocks", h.limit)
+	return h, nil
+}
+
+// file returns the opened era1 file of the given epoch. The caller must hold
+// the lock.
+func (h *eraHistory) file(epoch int) (*era.Era, error) {
+	if e, ok := h.open.Get(epoch); ok {
+		return e, nil
+	}
+	e, err := era.Open(filepath.Join(h.dir, h.files[epoch]))
+	if err != nil {
+		return nil, err
+	}
+	if h.open.Len() >= eraHistoryOpenFiles {
+		if _, old, ok := h.open.RemoveOldest(); ok {
+			old.Close()
+		}
+	}
+	h.open.Add(epoch, e)
+	return e, nil
+}
+
+// read retrieves an entry of the given canonical block using the provided
+// accessor, after verifying that the archived block matches the hash.
+func (h *eraHistory) read(hash common.Hash, number uint64, get func(e *era.Era, number uint64) ([]byte, error)) ([]byte, error) {
+	if number >= h.limit {
+		return nil, ErrHistoryPruned
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	e, err := h.file(int(number / uint64(era.MaxEra1Size)))
+	if err != nil {
+		return nil, err
+	}
+	header, err := e.GetRawHeaderByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	if have := crypto.Keccak256Hash(header); have != hash {
+		return nil, fmt.Errorf("archived block %d mismatch: have %x, want %x", number, have, hash)
+	}
+	return get(e, number)
+}
+
+// bodyRLP retrieves the RLP-encoded body of the given canonical block.
+func (h *eraHistory) bodyRLP(hash common.Hash, number uint64) (rlp.RawValue, error) {
+	return h.read(hash, number, (*era.Era).GetRawBodyByNumber)
+}
+
+// receipts retrieves the receipts of the given canonical block, without the
+// derived metadata fields.
+func (h *eraHistory) receipts(hash common.Hash, number uint64) (types.Receipts, error) {
+	blob, err := h.read(hash, number, (*era.Era).GetRawReceiptsByNumber)
+	if err != nil {
+		return nil, err
+	}
+	var receipts types.Receipts
+	if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// close releases all the opened era1 files.
+func (h *eraHistory) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, epoch := range h.open.Keys() {
+		if e, ok := h.open.Peek(epoch); ok {
+			e.Close()
+		}
+	}
+	h.open.Purge()
+}
+
+// HistoryCutoff returns the first block whose body and receipts are retained
+// in the local database.
+func (bc *BlockChain) HistoryCutoff() uint64 {
+	tail, err := bc.db.Tail()
+	if err != nil {
+		return 0
+	}
+	return tail
+}
+
+// HistoryRange returns the range of blocks whose bodies and receipts are
+// available, either from the local database or from the configured era1
+// archives. The bodies and receipts of the blocks below have been pruned.
+func (bc *BlockChain) HistoryRange() (first uint64, last uint64) {
+	first = bc.HistoryCutoff()
+	if bc.eraHistory != nil && bc.eraHistory.limit >= first {
+		first = 0
+	}
+	return first, bc.CurrentSnapBlock().Number.Uint64()
+}
+
+// HistoryPruned reports whether the body and receipts of the given block have
+// been pruned and are unavailable.
+func (bc *BlockChain) HistoryPruned(number uint64) bool {
+	first, _ := bc.HistoryRange()
+	return number < first
+}
+
+// readPrunedBodyRLP retrieves the RLP-encoded body of a canonical block below
+// the history cutoff from the era1 archives, if configured.
+func (bc *BlockChain) readPrunedBodyRLP(hash common.Hash, number uint64) rlp.RawValue {
+	if bc.eraHistory == nil || number >= bc.HistoryCutoff() {
+		return nil
+	}
+	body, err := bc.eraHistory.bodyRLP(hash, number)
+	if err != nil {
+		log.Debug("Failed to read archived block body", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return body
+}
+
+// readPrunedBody retrieves the body of a canonical block below the history
+// cutoff from the era1 archives, if configured.
+func (bc *BlockChain) readPrunedBody(hash common.Hash, number uint64) *types.Body {
+	blob := bc.readPrunedBodyRLP(hash, number)
+	if len(blob) == 0 {
+		return nil
+	}
+	body := new(types.Body)
+	if err := rlp.DecodeBytes(blob, body); err != nil {
+		log.Error("Invalid archived block body RLP", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return body
+}
+
+// readPrunedReceipts retrieves the receipts of a canonical block below the
+// history cutoff from the era1 archives, if configured, including the derived
+// metadata fields.
+func (bc *BlockChain) readPrunedReceipts(header *types.Header) types.Receipts {
+	var (
+		hash   = header.Hash()
+		number = header.Number.Uint64()
+	)
+	if bc.eraHistory == nil || number >= bc.HistoryCutoff() {
+		return nil
+	}
+	body := bc.readPrunedBody(hash, number)
+	if body == nil {
+		return nil
+	}
+	receipts, err := bc.eraHistory.receipts(hash, number)
+	if err != nil {
+		log.Debug("Failed to read archived receipts", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	var blobGasPrice *big.Int
+	if header.ExcessBlobGas != nil {
+		blobGasPrice = eip4844.CalcBlobFee(*header.ExcessBlobGas)
+	}
+	if err := receipts.DeriveFields(bc.chainConfig, hash, number, header.Time, header.BaseFee, blobGasPrice, body.Transactions); err != nil {
+		log.Error("Failed to derive archived receipts fields", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return receipts
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestPruneHistory(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x01}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.AddTx(tx)
+	})
+	// Import the chain into the ancient store
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	chain, err := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := chain.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("Failed to insert header %d: %v", n, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, uint64(len(blocks)+1)); err != nil {
+		t.Fatalf("Failed to insert receipt %d: %v", n, err)
+	}
+	chain.Stop()
+
+	// Archive the chain into an era1 file
+	dir := t.TempDir()
+	writeEra(t, dir, gspec.ToBlock(), blocks, receipts)
+
+	// Prune the history and ensure the remaining range is served
+	const cutoff = 5
+	if err := PruneHistory(db, cutoff); err != nil {
+		t.Fatalf("Failed to prune history: %v", err)
+	}
+	if err := PruneHistory(db, uint64(len(blocks))+2); err == nil {
+		t.Fatal("Pruning above the ancient store succeeded")
+	}
+	chain, err = NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if first, last := chain.HistoryRange(); first != cutoff || last != uint64(len(blocks)) {
+		t.Fatalf("Unexpected history range: [%d, %d]", first, last)
+	}
+	for _, block := range blocks {
+		number := block.NumberU64()
+		if pruned := chain.GetBlockByNumber(number) == nil; pruned != (number < cutoff) {
+			t.Fatalf("Block %d pruned: %v", number, pruned)
+		}
+		if pruned := chain.GetReceiptsByHash(block.Hash()) == nil; pruned != (number < cutoff) {
+			t.Fatalf("Receipts %d pruned: %v", number, pruned)
+		}
+		if pruned := chain.HistoryPruned(number); pruned != (number < cutoff) {
+			t.Fatalf("Block %d reported pruned: %v", number, pruned)
+		}
+	}
+	// Serve the pruned history from the era1 archive
+	chain.eraHistory, err = newEraHistory(dir, "test")
+	if err != nil {
+		t.Fatalf("Failed to open era1 archive: %v", err)
+	}
+	if first, _ := chain.HistoryRange(); first != 0 {
+		t.Fatalf("Unexpected history start with era1 archive: %d", first)
+	}
+	for i, block := range blocks[:cutoff-1] {
+		have := chain.GetBlockByNumber(block.NumberU64())
+		if have == nil || have.Hash() != block.Hash() || have.Transactions()[0].Hash() != block.Transactions()[0].Hash() {
+			t.Fatalf("Block %d not served from era1 archive", block.NumberU64())
+		}
+		if body := chain.GetBodyRLP(block.Hash()); len(body) == 0 {
+			t.Fatalf("Body %d not served from era1 archive", block.NumberU64())
+		}
+		got := chain.GetReceiptsByHash(block.Hash())
+		if len(got) != len(receipts[i]) || got[0].TxHash != receipts[i][0].TxHash || got[0].GasUsed != receipts[i][0].GasUsed {
+			t.Fatalf("Receipts %d not served from era1 archive", block.NumberU64())
+		}
+	}
+}
+
+// writeEra archives the given chain into an era1 file in the directory.
+func writeEra(t *testing.T, dir string, genesis *types.Block, blocks []*types.Block, receipts []types.Receipts) {
+	t.Helper()
+
+	f, err := os.CreateTemp(dir, "era1-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var (
+		builder = era.NewBuilder(f)
+		td      = new(big.Int).Set(genesis.Difficulty())
+	)
+	if err := builder.Add(genesis, types.Receipts{}, new(big.Int).Set(td)); err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range blocks {
+		td.Add(td, block.Difficulty())
+		if err := builder.Add(block, receipts[i], new(big.Int).Set(td)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(f.Name(), filepath.Join(dir, era.Filename("test", 0, root))); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -187,3 +187,17 @@ func WriteTransitionStatus(db ethdb.KeyValueWriter, data []byte) {
 		log.Crit("Failed to store the eth2 transition status", "err", err)
 	}
 }
+
+// ReadEraIndex retrieves the cached index of the era1 archives backing the
+// ancient store.
+func ReadEraIndex(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(eraIndexKey)
+	return data
+}
+
+// WriteEraIndex stores the index of the era1 archives backing the ancient store.
+func WriteEraIndex(db ethdb.KeyValueWriter, data []byte) {
+	if err := db.Put(eraIndexKey, data); err != nil {
+		log.Crit("Failed to store the era1 index", "err", err)
+	}
+}
@@ -54,17 +54,23 @@ type chainFreezer struct {
 //
 //   - if the empty directory is given, initializes the pure in-memory
 //     state freezer (e.g. dev mode).
+//   - if a directory of era1 archives is given, initializes the read-only
+//     era-backed freezer verified against the trusted accumulator roots,
+//     caching its index in the key-value store.
 //   - if non-empty directory is given, initializes the regular file-based
 //     state freezer.
-func newChainFreezer(datadir string, namespace string, readonly bool) (*chainFreezer, error) {
+func newChainFreezer(datadir string, namespace string, readonly bool, db ethdb.KeyValueStore, eraRoots []common.Hash) (*chainFreezer, error) {
 	var (
 		err     error
 		freezer ethdb.AncientStore
 	)
-	if datadir == "" {
-		freezer = NewMemoryFreezer(readonly, chainFreezerNoSnappy)
-	} else {
-		freezer, err = NewFreezer(datadir, namespace, readonly, freezerTableSize, chainFreezerNoSnappy)
+	switch {
+	case datadir == "":
+		freezer = NewMemoryFreezer(readonly, chainFreezerTableConfigs)
+	case isEraDir(datadir):
+		freezer, err = NewEraFreezer(datadir, eraRoots, db, readonly)
+	default:
+		freezer, err = NewFreezer(datadir, namespace, readonly, freezerTableSize, chainFreezerTableConfigs)
 	}
 	if err != nil {
 		return nil, err
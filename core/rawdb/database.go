@@ -41,10 +41,14 @@ type freezerdb struct {
 
 	readOnly    bool
 	ancientRoot string
+	sharedRoot  bool // Whether the ancient root is a shared directory of era1 archives
 }
 
 // AncientDatadir returns the path of root ancient directory.
 func (frdb *freezerdb) AncientDatadir() (string, error) {
+	if frdb.sharedRoot {
+		return "", errNotSupported
+	}
 	return frdb.ancientRoot, nil
 }
 
@@ -64,6 +68,50 @@ func (frdb *freezerdb) Close() error {
 	return nil
 }
 
+// ImmutableAncients reports whether the chain segment in the ancient store is
+// served from read-only era1 archives.
+func (frdb *freezerdb) ImmutableAncients() bool {
+	_, ok := frdb.chainFreezer.AncientStore.(*EraFreezer)
+	return ok
+}
+
+// HasImmutableAncients reports whether the chain segment in the ancient store of
+// the database can't be modified. Chain data past such an ancient store is kept
+// in the key-value store instead of being frozen.
+func HasImmutableAncients(db ethdb.Database) bool {
+	mutability, ok := db.(ethdb.AncientMutability)
+	return ok && mutability.ImmutableAncients()
+}
+
+// Checkpoint writes a consistent copy of the database into the given directory,
+// which must not exist yet. The chain freezer is placed into the default ancient
+// location within the directory, so the copy can be opened as a regular chain
+// database. The in-memory chain freezer is not supported.
+func (frdb *freezerdb) Checkpoint(dir string) error {
+	kvstore, ok := frdb.KeyValueStore.(ethdb.Checkpointer)
+	if !ok {
+		return errNotSupported
+	}
+	freezer, ok := frdb.chainFreezer.AncientStore.(*Freezer)
+	if !ok {
+		return errNotSupported
+	}
+	// Checkpoint the key-value store while the chain freezer is blocked, so that
+	// no items can be moved over from the key-value store in the meantime.
+	return freezer.checkpoint(filepath.Join(dir, "ancient", ChainFreezerName), func() error {
+		return kvstore.Checkpoint(dir)
+	})
+}
+
+// NewSnapshot creates a read-only view of the key-value store at the current
+// point in time. The ancient store is not covered by the snapshot.
+func (frdb *freezerdb) NewSnapshot() (ethdb.Snapshot, error) {
+	if kvstore, ok := frdb.KeyValueStore.(ethdb.Snapshotter); ok {
+		return kvstore.NewSnapshot()
+	}
+	return nil, errNotSupported
+}
+
 // Freeze is a helper method used for external testing to trigger and block until
 // a freeze cycle completes, without having to sleep for a minute to trigger the
 // automatic background run.
@@ -71,6 +119,9 @@ func (frdb *freezerdb) Freeze() error {
 	if frdb.readOnly {
 		return errReadOnly
 	}
+	if _, ok := frdb.AncientStore.(*EraFreezer); ok {
+		return errReadOnly
+	}
 	// Trigger a freeze cycle and block until it's done
 	trigger := make(chan struct{}, 1)
 	frdb.chainFreezer.trigger <- trigger
@@ -160,6 +211,24 @@ func (db *nofreezedb) AncientDatadir() (string, error) {
 	return "", errNotSupported
 }
 
+// Checkpoint writes a consistent copy of the key-value store into the given
+// directory, which must not exist yet.
+func (db *nofreezedb) Checkpoint(dir string) error {
+	if kvstore, ok := db.KeyValueStore.(ethdb.Checkpointer); ok {
+		return kvstore.Checkpoint(dir)
+	}
+	return errNotSupported
+}
+
+// NewSnapshot creates a read-only view of the key-value store at the current
+// point in time.
+func (db *nofreezedb) NewSnapshot() (ethdb.Snapshot, error) {
+	if kvstore, ok := db.KeyValueStore.(ethdb.Snapshotter); ok {
+		return kvstore.NewSnapshot()
+	}
+	return nil, errNotSupported
+}
+
 // NewDatabase creates a high level database on top of a given key-value data
 // store without a freezer moving immutable chain segments into cold storage.
 func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
@@ -175,7 +244,11 @@ func resolveChainFreezerDir(ancient string) string {
 	// - chain freezer exists in legacy location (root ancient folder)
 	freezer := filepath.Join(ancient, ChainFreezerName)
 	if !common.FileExist(freezer) {
-		if !common.FileExist(ancient) {
+		if isEraDir(ancient) {
+			// Ancient root is a directory of era1 archives, serving the chain
+			// segment directly.
+			freezer = ancient
+		} else if !common.FileExist(ancient) {
 			// The entire ancient store is not initialized, still use the sub
 			// folder for initialization.
 		} else {
@@ -194,6 +267,13 @@ func resolveChainFreezerDir(ancient string) string {
 // storage. The passed ancient indicates the path of root ancient directory
 // where the chain freezer can be opened.
 func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly bool) (ethdb.Database, error) {
+	return newDatabaseWithFreezer(db, ancient, nil, namespace, readonly)
+}
+
+// newDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer, verifying an ancient directory of era1
+// archives against the given trusted accumulator roots.
+func newDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, eraRoots []common.Hash, namespace string, readonly bool) (ethdb.Database, error) {
 	// Create the idle freezer instance. If the given ancient directory is empty,
 	// in-memory chain freezer is used (e.g. dev mode); otherwise the regular
 	// file-based freezer is created.
@@ -201,7 +281,7 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 	if chainFreezerDir != "" {
 		chainFreezerDir = resolveChainFreezerDir(chainFreezerDir)
 	}
-	frdb, err := newChainFreezer(chainFreezerDir, namespace, readonly)
+	frdb, err := newChainFreezer(chainFreezerDir, namespace, readonly, db, eraRoots)
 	if err != nil {
 		printChainMetadata(db)
 		return nil, err
@@ -284,8 +364,16 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 			// freezer.
 		}
 	}
+	// The era1 archives are immutable, the recent chain segment is retained in
+	// the key-value store instead of being frozen. If the archives are located
+	// in the ancient root directory itself, the other ancient stores (e.g. the
+	// state histories) can't be placed alongside the shared archives.
+	_, eraBacked := frdb.AncientStore.(*EraFreezer)
+	if eraBacked && chainFreezerDir == ancient {
+		log.Warn("Ancient root is an era1 directory, state histories are disabled", "dir", ancient)
+	}
 	// Freezer is consistent with the key-value database, permit combining the two
-	if !readonly {
+	if !readonly && !eraBacked {
 		frdb.wg.Add(1)
 		go func() {
 			frdb.freeze(db)
@@ -294,6 +382,7 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 	}
 	return &freezerdb{
 		ancientRoot:   ancient,
+		sharedRoot:    eraBacked && chainFreezerDir == ancient,
 		KeyValueStore: db,
 		chainFreezer:  frdb,
 	}, nil
@@ -364,6 +453,8 @@ type OpenOptions struct {
 	Cache             int    // the capacity(in megabytes) of the data caching
 	Handles           int    // number of files to be open simultaneously
 	ReadOnly          bool
+	EraRoots          []common.Hash // trusted accumulator roots if the ancients-dir holds era1 archives
+	TrackSizes        bool          // maintain the approximate size of each data category
 	// Ephemeral means that filesystem sync operations should be avoided: data integrity in the face of
 	// a crash is not important. This option should typically be used in tests.
 	Ephemeral bool
@@ -409,10 +500,20 @@ func Open(o OpenOptions) (ethdb.Database, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Track the size of the data categories as the data is written if requested,
+	// otherwise drop any previously tracked sizes, which would become stale.
+	if !o.ReadOnly {
+		if o.TrackSizes {
+			kvdb = NewDatabase(newSizeTracker(kvdb, o.Namespace))
+		} else if err := kvdb.Delete(databaseSizesKey); err != nil {
+			kvdb.Close()
+			return nil, err
+		}
+	}
 	if len(o.AncientsDirectory) == 0 {
 		return kvdb, nil
 	}
-	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly)
+	frdb, err := newDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.EraRoots, o.Namespace, o.ReadOnly)
 	if err != nil {
 		kvdb.Close()
 		return nil, err
@@ -451,7 +552,8 @@ func (s *stat) Count() string {
 }
 
 // InspectDatabase traverses the entire database and checks the size
-// of all different categories of data.
+// of all different categories of data. If the entire database is traversed,
+// the result is stored as the baseline of the tracked database sizes.
 func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 	it := db.NewIterator(keyPrefix, keyStart)
 	defer it.Release()
@@ -462,36 +564,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		logged = time.Now()
 
 		// Key-value store statistics
-		headers         stat
-		bodies          stat
-		receipts        stat
-		tds             stat
-		numHashPairings stat
-		hashNumPairings stat
-		legacyTries     stat
-		stateLookups    stat
-		accountTries    stat
-		storageTries    stat
-		codes           stat
-		txLookups       stat
-		accountSnaps    stat
-		storageSnaps    stat
-		preimages       stat
-		bloomBits       stat
-		beaconHeaders   stat
-		cliqueSnaps     stat
-
-		// Verkle statistics
-		verkleTries        stat
-		verkleStateLookups stat
-
-		// Les statistic
-		chtTrieNodes   stat
-		bloomTrieNodes stat
-
-		// Meta- and unaccounted data
-		metadata    stat
-		unaccounted stat
+		stats [sizeCategoryCount]stat
 
 		// Totals
 		total common.StorageSize
@@ -503,125 +576,62 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			size = common.StorageSize(len(key) + len(it.Value()))
 		)
 		total += size
-		switch {
-		case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
-			headers.Add(size)
-		case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
-			bodies.Add(size)
-		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
-			receipts.Add(size)
-		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix):
-			tds.Add(size)
-		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
-			numHashPairings.Add(size)
-		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
-			hashNumPairings.Add(size)
-		case IsLegacyTrieNode(key, it.Value()):
-			legacyTries.Add(size)
-		case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
-			stateLookups.Add(size)
-		case IsAccountTrieNode(key):
-			accountTries.Add(size)
-		case IsStorageTrieNode(key):
-			storageTries.Add(size)
-		case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
-			codes.Add(size)
-		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
-			txLookups.Add(size)
-		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
-			accountSnaps.Add(size)
-		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
-			storageSnaps.Add(size)
-		case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
-			preimages.Add(size)
-		case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
-			metadata.Add(size)
-		case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
-			metadata.Add(size)
-		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
-			bloomBits.Add(size)
-		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
-			bloomBits.Add(size)
-		case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
-			beaconHeaders.Add(size)
-		case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
-			cliqueSnaps.Add(size)
-		case bytes.HasPrefix(key, ChtTablePrefix) ||
-			bytes.HasPrefix(key, ChtIndexTablePrefix) ||
-			bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
-			chtTrieNodes.Add(size)
-		case bytes.HasPrefix(key, BloomTrieTablePrefix) ||
-			bytes.HasPrefix(key, BloomTrieIndexPrefix) ||
-			bytes.HasPrefix(key, BloomTriePrefix): // Bloomtrie sub
-			bloomTrieNodes.Add(size)
-
-		// Verkle trie data is detected, determine the sub-category
-		case bytes.HasPrefix(key, VerklePrefix):
-			remain := key[len(VerklePrefix):]
-			switch {
-			case IsAccountTrieNode(remain):
-				verkleTries.Add(size)
-			case bytes.HasPrefix(remain, stateIDPrefix) && len(remain) == len(stateIDPrefix)+common.HashLength:
-				verkleStateLookups.Add(size)
-			case bytes.Equal(remain, persistentStateIDKey):
-				metadata.Add(size)
-			case bytes.Equal(remain, trieJournalKey):
-				metadata.Add(size)
-			case bytes.Equal(remain, snapSyncStatusFlagKey):
-				metadata.Add(size)
-			default:
-				unaccounted.Add(size)
-			}
-		default:
-			var accounted bool
-			for _, meta := range [][]byte{
-				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
-				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
-				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
-				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
-				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
-			} {
-				if bytes.Equal(key, meta) {
-					metadata.Add(size)
-					accounted = true
-					break
-				}
-			}
-			if !accounted {
-				unaccounted.Add(size)
-			}
-		}
+		stats[classifyKey(key, it.Value())].Add(size)
+
 		count++
 		if count%1000 == 0 && time.Since(logged) > 8*time.Second {
 			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
 			logged = time.Now()
 		}
 	}
-	// Display the database statistic of key-value store.
-	stats := [][]string{
-		{"Key-Value store", "Headers", headers.Size(), headers.Count()},
-		{"Key-Value store", "Bodies", bodies.Size(), bodies.Count()},
-		{"Key-Value store", "Receipt lists", receipts.Size(), receipts.Count()},
-		{"Key-Value store", "Difficulties", tds.Size(), tds.Count()},
-		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
-		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
-		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
-		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
-		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
-		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
-		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
-		{"Key-Value store", "Path trie account nodes", accountTries.Size(), accountTries.Count()},
-		{"Key-Value store", "Path trie storage nodes", storageTries.Size(), storageTries.Count()},
-		{"Key-Value store", "Verkle trie nodes", verkleTries.Size(), verkleTries.Count()},
-		{"Key-Value store", "Verkle trie state lookups", verkleStateLookups.Size(), verkleStateLookups.Count()},
-		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
-		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
-		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
-		{"Key-Value store", "Beacon sync headers", beaconHeaders.Size(), beaconHeaders.Count()},
-		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
-		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
-		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
-		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
+	if err := it.Error(); err != nil {
+		return err
+	}
+	sizes := &DatabaseSizes{Complete: true}
+	for i, info := range sizeCategoryInfos {
+		sizes.Categories = append(sizes.Categories, CategorySize{
+			Database: info.database,
+			Name:     info.name,
+			Size:     uint64(stats[i].size),
+			Count:    uint64(stats[i].count),
+		})
+	}
+	if keyPrefix == nil && keyStart == nil {
+		if err := writeDatabaseSizes(db, sizes); err != nil {
+			log.Debug("Failed to store database sizes", "err", err)
+		}
+	}
+	return printDatabaseSizes(db, sizes, total)
+}
+
+// InspectDatabaseSizes prints the size of all different categories of data as
+// tracked by the database, without traversing it. It returns false if the sizes
+// are not tracked, or only cover parts of the database.
+func InspectDatabaseSizes(db ethdb.Database) (bool, error) {
+	sizes := ReadDatabaseSizes(db)
+	if sizes == nil || !sizes.Complete {
+		return false, nil
+	}
+	var total common.StorageSize
+	for _, category := range sizes.Categories {
+		total += common.StorageSize(category.Size)
+	}
+	return true, printDatabaseSizes(db, sizes, total)
+}
+
+// printDatabaseSizes prints the sizes of the key-value store categories along
+// with the sizes of the append-only file stores.
+func printDatabaseSizes(db ethdb.Database, sizes *DatabaseSizes, total common.StorageSize) error {
+	var (
+		stats       [][]string
+		unaccounted CategorySize
+	)
+	for _, category := range sizes.Categories {
+		if category.Name == sizeCategoryInfos[sizeUnaccounted].name {
+			unaccounted = category
+			continue
+		}
+		stats = append(stats, []string{category.Database, category.Name, common.StorageSize(category.Size).String(), fmt.Sprintf("%d", category.Count)})
 	}
 	// Inspect all registered append-only file store then.
 	ancients, err := inspectFreezers(db)
@@ -645,8 +655,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 	table.AppendBulk(stats)
 	table.Render()
 
-	if unaccounted.size > 0 {
-		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
+	if unaccounted.Size > 0 {
+		log.Error("Database contains unaccounted data", "size", common.StorageSize(unaccounted.Size), "count", unaccounted.Count)
 	}
 	return nil
 }
@@ -0,0 +1,500 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// sizeCategory is a category of the data stored in the key-value store.
+type sizeCategory int
+
+const (
+	sizeHeaders sizeCategory = iota
+	sizeBodies
+	sizeReceipts
+	sizeDifficulties
+	sizeNumHashPairings
+	sizeHashNumPairings
+	sizeTxLookups
+	sizeBloomBits
+	sizeCodes
+	sizeLegacyTries
+	sizeStateLookups
+	sizeAccountTries
+	sizeStorageTries
+	sizeVerkleTries
+	sizeVerkleStateLookups
+	sizePreimages
+	sizeAccountSnaps
+	sizeStorageSnaps
+	sizeBeaconHeaders
+	sizeCliqueSnaps
+	sizeMetadata
+	sizeChtTrieNodes
+	sizeBloomTrieNodes
+	sizeUnaccounted
+	sizeCategoryCount
+)
+
+// sizeCategoryInfos describes the categories. Mutable categories contain keys
+// which are frequently overwritten, so the size of the replaced value has to be
+// looked up to track them accurately.
+var sizeCategoryInfos = [sizeCategoryCount]struct {
+	database string
+	name     string
+	mutable  bool
+}{
+	sizeHeaders:            {"Key-Value store", "Headers", false},
+	sizeBodies:             {"Key-Value store", "Bodies", false},
+	sizeReceipts:           {"Key-Value store", "Receipt lists", false},
+	sizeDifficulties:       {"Key-Value store", "Difficulties", false},
+	sizeNumHashPairings:    {"Key-Value store", "Block number->hash", false},
+	sizeHashNumPairings:    {"Key-Value store", "Block hash->number", false},
+	sizeTxLookups:          {"Key-Value store", "Transaction index", false},
+	sizeBloomBits:          {"Key-Value store", "Bloombit index", false},
+	sizeCodes:              {"Key-Value store", "Contract codes", false},
+	sizeLegacyTries:        {"Key-Value store", "Hash trie nodes", false},
+	sizeStateLookups:       {"Key-Value store", "Path trie state lookups", false},
+	sizeAccountTries:       {"Key-Value store", "Path trie account nodes", true},
+	sizeStorageTries:       {"Key-Value store", "Path trie storage nodes", true},
+	sizeVerkleTries:        {"Key-Value store", "Verkle trie nodes", true},
+	sizeVerkleStateLookups: {"Key-Value store", "Verkle trie state lookups", false},
+	sizePreimages:          {"Key-Value store", "Trie preimages", false},
+	sizeAccountSnaps:       {"Key-Value store", "Account snapshot", true},
+	sizeStorageSnaps:       {"Key-Value store", "Storage snapshot", true},
+	sizeBeaconHeaders:      {"Key-Value store", "Beacon sync headers", true},
+	sizeCliqueSnaps:        {"Key-Value store", "Clique snapshots", false},
+	sizeMetadata:           {"Key-Value store", "Singleton metadata", true},
+	sizeChtTrieNodes:       {"Light client", "CHT trie nodes", false},
+	sizeBloomTrieNodes:     {"Light client", "Bloom trie nodes", false},
+	sizeUnaccounted:        {"Key-Value store", "Unaccounted", false},
+}
+
+// metadataKeys are the singleton keys of the key-value store.
+var metadataKeys = [][]byte{
+	databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
+	lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
+	snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
+	uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
+	persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey, eraIndexKey,
+	databaseSizesKey,
+}
+
+// classifyKey determines the category of a database entry from its key. If the
+// value is not available, keys of hash trie nodes are not verified against the
+// hash of the value.
+func classifyKey(key []byte, value []byte) sizeCategory {
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
+		return sizeHeaders
+	case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
+		return sizeBodies
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
+		return sizeReceipts
+	case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix):
+		return sizeDifficulties
+	case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
+		return sizeNumHashPairings
+	case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
+		return sizeHashNumPairings
+	case len(key) == common.HashLength && (value == nil || IsLegacyTrieNode(key, value)):
+		return sizeLegacyTries
+	case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
+		return sizeStateLookups
+	case IsAccountTrieNode(key):
+		return sizeAccountTries
+	case IsStorageTrieNode(key):
+		return sizeStorageTries
+	case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
+		return sizeCodes
+	case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
+		return sizeTxLookups
+	case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
+		return sizeAccountSnaps
+	case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
+		return sizeStorageSnaps
+	case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
+		return sizePreimages
+	case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
+		return sizeMetadata
+	case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
+		return sizeMetadata
+	case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
+		return sizeBloomBits
+	case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+		return sizeBloomBits
+	case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
+		return sizeBeaconHeaders
+	case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
+		return sizeCliqueSnaps
+	case bytes.HasPrefix(key, ChtTablePrefix) ||
+		bytes.HasPrefix(key, ChtIndexTablePrefix) ||
+		bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
+		return sizeChtTrieNodes
+	case bytes.HasPrefix(key, BloomTrieTablePrefix) ||
+		bytes.HasPrefix(key, BloomTrieIndexPrefix) ||
+		bytes.HasPrefix(key, BloomTriePrefix): // Bloomtrie sub
+		return sizeBloomTrieNodes
+
+	// Verkle trie data is detected, determine the sub-category
+	case bytes.HasPrefix(key, VerklePrefix):
+		remain := key[len(VerklePrefix):]
+		switch {
+		case IsAccountTrieNode(remain):
+			return sizeVerkleTries
+		case bytes.HasPrefix(remain, stateIDPrefix) && len(remain) == len(stateIDPrefix)+common.HashLength:
+			return sizeVerkleStateLookups
+		case bytes.Equal(remain, persistentStateIDKey):
+			return sizeMetadata
+		case bytes.Equal(remain, trieJournalKey):
+			return sizeMetadata
+		case bytes.Equal(remain, snapSyncStatusFlagKey):
+			return sizeMetadata
+		default:
+			return sizeUnaccounted
+		}
+	default:
+		for _, meta := range metadataKeys {
+			if bytes.Equal(key, meta) {
+				return sizeMetadata
+			}
+		}
+		return sizeUnaccounted
+	}
+}
+
+// CategorySize is the approximate size and item count of a data category.
+type CategorySize struct {
+	Database string `json:"database"`
+	Name     string `json:"name"`
+	Size     uint64 `json:"size"`
+	Count    uint64 `json:"count"`
+}
+
+// DatabaseSizes is the approximate size of each data category of the key-value
+// store, maintained incrementally by the database.
+type DatabaseSizes struct {
+	// Complete reports whether the sizes cover the entire database. Otherwise
+	// they only reflect the changes since the tracking started, until a full
+	// inspection of the database seeds them.
+	Complete   bool           `json:"complete"`
+	Categories []CategorySize `json:"categories"`
+}
+
+// ReadDatabaseSizes retrieves the persisted approximate size of each category
+// of the key-value store, or nil if the sizes are not tracked. The sizes are
+// persisted periodically, so they might lag behind for a while.
+func ReadDatabaseSizes(db ethdb.KeyValueReader) *DatabaseSizes {
+	blob, err := db.Get(databaseSizesKey)
+	if err != nil || len(blob) == 0 {
+		return nil
+	}
+	var sizes DatabaseSizes
+	if err := rlp.DecodeBytes(blob, &sizes); err != nil {
+		log.Error("Invalid database sizes", "err", err)
+		return nil
+	}
+	return &sizes
+}
+
+// writeDatabaseSizes stores the approximate size of each category. If the
+// database tracks the sizes itself, it adopts them as the new baseline.
+func writeDatabaseSizes(db ethdb.KeyValueWriter, sizes *DatabaseSizes) error {
+	blob, err := rlp.EncodeToBytes(sizes)
+	if err != nil {
+		return err
+	}
+	return db.Put(databaseSizesKey, blob)
+}
+
+// sizeCounter is the running size and item count of a category.
+type sizeCounter struct {
+	size  int64
+	count int64
+}
+
+// sizeFlushInterval is the interval at which the tracked sizes are persisted.
+const sizeFlushInterval = time.Minute
+
+// sizeTracker is a key-value store wrapper, which maintains the approximate size
+// of each data category as the data is written. Inserted entries are accounted
+// precisely. The size of overwritten and deleted entries is only looked up for
+// the mutable categories; otherwise keys are assumed to be written once, and the
+// average size of the category is discounted for deletions.
+type sizeTracker struct {
+	ethdb.KeyValueStore
+
+	counters [sizeCategoryCount]sizeCounter
+	complete bool
+	flushed  time.Time
+	lock     sync.Mutex
+
+	sizeGauges  [sizeCategoryCount]metrics.Gauge
+	countGauges [sizeCategoryCount]metrics.Gauge
+}
+
+// newSizeTracker wraps the key-value store with size tracking. The sizes are
+// resumed from the persisted ones, or start out complete for a new database.
+func newSizeTracker(db ethdb.KeyValueStore, namespace string) *sizeTracker {
+	t := &sizeTracker{KeyValueStore: db, flushed: time.Now()}
+	for i, info := range sizeCategoryInfos {
+		slug := strings.ReplaceAll(strings.ToLower(info.name), " ", "-")
+		slug = strings.ReplaceAll(slug, "->", "-")
+		t.sizeGauges[i] = metrics.GetOrRegisterGauge(namespace+"size/"+slug, nil)
+		t.countGauges[i] = metrics.GetOrRegisterGauge(namespace+"count/"+slug, nil)
+	}
+	if sizes := ReadDatabaseSizes(db); sizes != nil {
+		t.adopt(sizes)
+	} else {
+		it := db.NewIterator(nil, nil)
+		t.complete = !it.Next()
+		it.Release()
+	}
+	return t
+}
+
+// adopt replaces the tracked sizes with the given ones. The caller must hold
+// the lock, unless the tracker is being created.
+func (t *sizeTracker) adopt(sizes *DatabaseSizes) {
+	t.counters = [sizeCategoryCount]sizeCounter{}
+	t.complete = sizes.Complete
+	for _, category := range sizes.Categories {
+		for i, info := range sizeCategoryInfos {
+			if info.database == category.Database && info.name == category.Name {
+				t.counters[i] = sizeCounter{size: int64(category.Size), count: int64(category.Count)}
+			}
+		}
+	}
+	t.updateGauges()
+}
+
+// updateGauges reports the tracked sizes to the metrics system.
+func (t *sizeTracker) updateGauges() {
+	for i, counter := range t.counters {
+		t.sizeGauges[i].Update(counter.size)
+		t.countGauges[i].Update(counter.count)
+	}
+}
+
+// sizes returns a copy of the tracked sizes. The caller must hold the lock.
+func (t *sizeTracker) sizes() *DatabaseSizes {
+	sizes := &DatabaseSizes{Complete: t.complete}
+	for i, info := range sizeCategoryInfos {
+		sizes.Categories = append(sizes.Categories, CategorySize{
+			Database: info.database,
+			Name:     info.name,
+			Size:     uint64(t.counters[i].size),
+			Count:    uint64(t.counters[i].count),
+		})
+	}
+	return sizes
+}
+
+// flush persists the tracked sizes. The caller must hold the lock.
+func (t *sizeTracker) flush() error {
+	t.flushed = time.Now()
+	return writeDatabaseSizes(t.KeyValueStore, t.sizes())
+}
+
+// sizeChange is a change of a category.
+type sizeChange struct {
+	category sizeCategory
+	size     int64 // Size of the inserted entry, or -1 for deletions
+	previous int64 // Size of the replaced entry, or -1 if not looked up
+}
+
+// change creates the change for writing or deleting an entry. The size of the
+// replaced entry is looked up if it belongs to a mutable category, so it must
+// be called before the change is applied. Entries already written by the same
+// batch are taken from the written sizes instead, which are updated in turn.
+func (t *sizeTracker) change(key []byte, value []byte, del bool, written map[string]int64) sizeChange {
+	change := sizeChange{category: classifyKey(key, nil), size: int64(len(key) + len(value)), previous: -1}
+	if del {
+		change.size = -1
+	}
+	if sizeCategoryInfos[change.category].mutable {
+		if size, ok := written[string(key)]; ok {
+			change.previous = size
+		} else {
+			change.previous = 0 // not present
+			if value, err := t.KeyValueStore.Get(key); err == nil {
+				change.previous = int64(len(key) + len(value))
+			}
+		}
+		if written != nil {
+			written[string(key)] = max(change.size, 0)
+		}
+	}
+	return change
+}
+
+// apply accounts the applied changes.
+func (t *sizeTracker) apply(changes ...sizeChange) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, change := range changes {
+		counter := &t.counters[change.category]
+		switch {
+		case change.previous > 0:
+			// The replaced entry is known, discount it
+			counter.size -= change.previous
+			counter.count--
+		case change.previous < 0 && change.size < 0:
+			// The deleted entry is unknown, discount an average one
+			if counter.count > 0 {
+				counter.size -= counter.size / counter.count
+				counter.count--
+			}
+		}
+		if change.size >= 0 {
+			counter.size += change.size
+			counter.count++
+		}
+		counter.size, counter.count = max(counter.size, 0), max(counter.count, 0)
+	}
+	t.updateGauges()
+
+	if time.Since(t.flushed) > sizeFlushInterval {
+		if err := t.flush(); err != nil {
+			log.Warn("Failed to persist database sizes", "err", err)
+		}
+	}
+}
+
+// Put inserts the given value into the key-value store. Storing the database
+// sizes replaces the tracked ones.
+func (t *sizeTracker) Put(key []byte, value []byte) error {
+	if bytes.Equal(key, databaseSizesKey) {
+		var sizes DatabaseSizes
+		if err := rlp.DecodeBytes(value, &sizes); err != nil {
+			return err
+		}
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		t.adopt(&sizes)
+		return t.flush()
+	}
+	change := t.change(key, value, false, nil)
+	if err := t.KeyValueStore.Put(key, value); err != nil {
+		return err
+	}
+	t.apply(change)
+	return nil
+}
+
+// Delete removes the key from the key-value store.
+func (t *sizeTracker) Delete(key []byte) error {
+	change := t.change(key, nil, true, nil)
+	if err := t.KeyValueStore.Delete(key); err != nil {
+		return err
+	}
+	t.apply(change)
+	return nil
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (t *sizeTracker) NewBatch() ethdb.Batch {
+	return &sizeTrackerBatch{Batch: t.KeyValueStore.NewBatch(), tracker: t}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (t *sizeTracker) NewBatchWithSize(size int) ethdb.Batch {
+	return &sizeTrackerBatch{Batch: t.KeyValueStore.NewBatchWithSize(size), tracker: t}
+}
+
+// Checkpoint forwards the checkpoint request to the wrapped store.
+func (t *sizeTracker) Checkpoint(dir string) error {
+	if checkpointer, ok := t.KeyValueStore.(ethdb.Checkpointer); ok {
+		return checkpointer.Checkpoint(dir)
+	}
+	return errNotSupported
+}
+
+// NewSnapshot forwards the snapshot request to the wrapped store.
+func (t *sizeTracker) NewSnapshot() (ethdb.Snapshot, error) {
+	if snapshotter, ok := t.KeyValueStore.(ethdb.Snapshotter); ok {
+		return snapshotter.NewSnapshot()
+	}
+	return nil, errNotSupported
+}
+
+// Close persists the tracked sizes and closes the wrapped store.
+func (t *sizeTracker) Close() error {
+	t.lock.Lock()
+	err := t.flush()
+	t.lock.Unlock()
+
+	if err != nil {
+		log.Warn("Failed to persist database sizes", "err", err)
+	}
+	return t.KeyValueStore.Close()
+}
+
+// sizeTrackerBatch is a batch which accounts its changes once written. The
+// changes are derived by replaying the batch, so keys are only retained while
+// the batch is being written.
+type sizeTrackerBatch struct {
+	ethdb.Batch
+	tracker *sizeTracker
+}
+
+// Write flushes any accumulated data to disk, accounting the changes.
+func (b *sizeTrackerBatch) Write() error {
+	changes := &sizeChangeCollector{tracker: b.tracker, written: make(map[string]int64)}
+	if err := b.Batch.Replay(changes); err != nil {
+		return err
+	}
+	if err := b.Batch.Write(); err != nil {
+		return err
+	}
+	b.tracker.apply(changes.changes...)
+	return nil
+}
+
+// sizeChangeCollector is a key-value writer collecting the changes of a batch
+// replayed into it. Mutable entries written repeatedly by the batch are only
+// looked up once, and accounted against their previously batched value.
+type sizeChangeCollector struct {
+	tracker *sizeTracker
+	changes []sizeChange
+	written map[string]int64 // Sizes of the mutable entries batched so far, 0 if deleted
+}
+
+// Put collects the change of inserting the given value.
+func (c *sizeChangeCollector) Put(key []byte, value []byte) error {
+	c.changes = append(c.changes, c.tracker.change(key, value, false, c.written))
+	return nil
+}
+
+// Delete collects the change of removing the given key.
+func (c *sizeChangeCollector) Delete(key []byte) error {
+	c.changes = append(c.changes, c.tracker.change(key, nil, true, c.written))
+	return nil
+}
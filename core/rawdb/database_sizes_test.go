@@ -0,0 +1,164 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+func checkSizeCounter(t *testing.T, tracker *sizeTracker, category sizeCategory, size, count int64) {
+	t.Helper()
+	if have := tracker.counters[category]; have.size != size || have.count != count {
+		t.Fatalf("%s: counter mismatch: have %d bytes in %d items, want %d bytes in %d items",
+			sizeCategoryInfos[category].name, have.size, have.count, size, count)
+	}
+}
+
+// Tests that writes, overwrites and deletions are accounted to the categories.
+func TestSizeTracker(t *testing.T) {
+	tracker := newSizeTracker(memorydb.New(), "")
+	if !tracker.complete {
+		t.Fatal("Tracker of an empty database is not complete")
+	}
+	var (
+		header  = headerKey(1, common.Hash{1})
+		account = accountSnapshotKey(common.Hash{2})
+	)
+	tracker.Put(header, make([]byte, 100))
+	tracker.Put(account, make([]byte, 10))
+	checkSizeCounter(t, tracker, sizeHeaders, int64(len(header)+100), 1)
+	checkSizeCounter(t, tracker, sizeAccountSnaps, int64(len(account)+10), 1)
+
+	// Overwrites of mutable categories replace the previous size
+	tracker.Put(account, make([]byte, 20))
+	checkSizeCounter(t, tracker, sizeAccountSnaps, int64(len(account)+20), 1)
+
+	// Batched changes are only accounted once written
+	batch := tracker.NewBatch()
+	batch.Delete(account)
+	batch.Put(headerKey(2, common.Hash{2}), make([]byte, 100))
+	checkSizeCounter(t, tracker, sizeAccountSnaps, int64(len(account)+20), 1)
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	checkSizeCounter(t, tracker, sizeAccountSnaps, 0, 0)
+	checkSizeCounter(t, tracker, sizeHeaders, int64(2*(len(header)+100)), 2)
+
+	// Deletions of other categories discount an average entry
+	tracker.Delete(header)
+	checkSizeCounter(t, tracker, sizeHeaders, int64(len(header)+100), 1)
+
+	// Repeated writes within a batch are accounted against the batched value
+	batch = tracker.NewBatch()
+	batch.Put(account, make([]byte, 10))
+	batch.Put(account, make([]byte, 30))
+	batch.Delete(account)
+	batch.Put(account, make([]byte, 40))
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	checkSizeCounter(t, tracker, sizeAccountSnaps, int64(len(account)+40), 1)
+}
+
+// Tests that the tracked sizes are persisted on close and resumed afterwards.
+func TestSizeTrackerPersistence(t *testing.T) {
+	var (
+		kvdb    = memorydb.New()
+		tracker = newSizeTracker(kvdb, "")
+		code    = codeKey(common.Hash{1})
+	)
+	tracker.Put(code, make([]byte, 50))
+	tracker.flush()
+
+	sizes := ReadDatabaseSizes(kvdb)
+	if sizes == nil || !sizes.Complete {
+		t.Fatalf("Database sizes not persisted: %v", sizes)
+	}
+	tracker = newSizeTracker(kvdb, "")
+	checkSizeCounter(t, tracker, sizeCodes, int64(len(code)+50), 1)
+}
+
+// Tests that a database populated before the tracking started is reported as
+// incomplete, until a full inspection seeds the sizes.
+func TestSizeTrackerSeeding(t *testing.T) {
+	var (
+		kvdb   = memorydb.New()
+		header = headerKey(1, common.Hash{1})
+		code   = codeKey(common.Hash{1})
+	)
+	kvdb.Put(header, make([]byte, 100))
+
+	tracker := newSizeTracker(kvdb, "")
+	if tracker.complete {
+		t.Fatal("Tracker of a populated database is complete")
+	}
+	db, err := NewDatabaseWithFreezer(tracker, "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if ok, err := InspectDatabaseSizes(db); ok || err != nil {
+		t.Fatalf("Incomplete sizes reported: %t (err %v)", ok, err)
+	}
+	if err := InspectDatabase(db, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !tracker.complete {
+		t.Fatal("Tracker not seeded by the inspection")
+	}
+	checkSizeCounter(t, tracker, sizeHeaders, int64(len(header)+100), 1)
+
+	// Changes after the seeding are accounted on top
+	db.Put(code, make([]byte, 50))
+	checkSizeCounter(t, tracker, sizeCodes, int64(len(code)+50), 1)
+	if ok, err := InspectDatabaseSizes(db); !ok || err != nil {
+		t.Fatalf("Complete sizes not reported: %t (err %v)", ok, err)
+	}
+}
+
+// Tests that the sizes are only tracked if requested, and that the ones tracked
+// previously are dropped once the tracking is disabled, as they become stale.
+func TestSizeTrackerOptIn(t *testing.T) {
+	dir := t.TempDir()
+	open := func(track bool) ethdb.Database {
+		db, err := Open(OpenOptions{Type: dbPebble, Directory: dir, TrackSizes: track, Ephemeral: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	db := open(true)
+	db.Put(codeKey(common.Hash{1}), make([]byte, 50))
+	db.Close()
+
+	db = open(true)
+	if sizes := ReadDatabaseSizes(db); sizes == nil {
+		t.Fatal("Tracked sizes not persisted")
+	}
+	db.Close()
+
+	db = open(false)
+	defer db.Close()
+	if sizes := ReadDatabaseSizes(db); sizes != nil {
+		t.Fatalf("Stale sizes retained: %v", sizes)
+	}
+}
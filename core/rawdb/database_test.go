@@ -15,3 +15,75 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package rawdb
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Tests that the checkpoint of a chain database contains both the key-value
+// store and the ancient store, and can be opened as a regular chain database.
+func TestDatabaseCheckpoint(t *testing.T) {
+	for _, kind := range []string{dbLeveldb, dbPebble} {
+		t.Run(kind, func(t *testing.T) {
+			testDatabaseCheckpoint(t, kind)
+		})
+	}
+}
+
+func testDatabaseCheckpoint(t *testing.T, kind string) {
+	var (
+		dir    = t.TempDir()
+		path   = filepath.Join(dir, "checkpoint")
+		blocks []*types.Block
+	)
+	for i := 0; i < 10; i++ {
+		blocks = append(blocks, types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))}))
+	}
+	db, err := Open(OpenOptions{
+		Type:              kind,
+		Directory:         filepath.Join(dir, "chaindata"),
+		AncientsDirectory: filepath.Join(dir, "chaindata", "ancient"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := WriteAncientBlocks(db, blocks[:5], make([]types.Receipts, 5), big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks[5:] {
+		WriteHeader(db, block.Header())
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	if err := db.(ethdb.Checkpointer).Checkpoint(path); err != nil {
+		t.Fatalf("failed to create checkpoint: %v", err)
+	}
+	cpy, err := Open(OpenOptions{
+		Type:              kind,
+		Directory:         path,
+		AncientsDirectory: filepath.Join(path, "ancient"),
+		ReadOnly:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open checkpoint: %v", err)
+	}
+	defer cpy.Close()
+
+	if frozen, _ := cpy.Ancients(); frozen != 5 {
+		t.Fatalf("frozen items mismatch: have %d, want 5", frozen)
+	}
+	for _, block := range blocks {
+		if hash := ReadCanonicalHash(cpy, block.NumberU64()); hash != block.Hash() {
+			t.Fatalf("canonical hash %d mismatch: have %x, want %x", block.NumberU64(), hash, block.Hash())
+		}
+		if header := ReadHeader(cpy, block.Hash(), block.NumberU64()); header == nil {
+			t.Fatalf("header %d missing", block.NumberU64())
+		}
+	}
+}
@@ -21,7 +21,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/rlp"
-	"github.com/golang/snappy"
 )
 
 // This is the maximum amount of data that will be buffered in memory
@@ -84,7 +83,7 @@ func (batch *freezerBatch) commit() (item uint64, writeSize int64, err error) {
 type freezerTableBatch struct {
 	t *freezerTable
 
-	sb          *snappyBuffer
+	cb          *compressBuffer
 	encBuffer   writeBuffer
 	dataBuffer  []byte
 	indexBuffer []byte
@@ -95,8 +94,8 @@ type freezerTableBatch struct {
 // newBatch creates a new batch for the freezer table.
 func (t *freezerTable) newBatch() *freezerTableBatch {
 	batch := &freezerTableBatch{t: t}
-	if !t.noCompression {
-		batch.sb = new(snappyBuffer)
+	if !t.config.noSnappy {
+		batch.cb = new(compressBuffer)
 	}
 	batch.reset()
 	return batch
@@ -123,11 +122,7 @@ func (batch *freezerTableBatch) Append(item uint64, data interface{}) error {
 	if err := rlp.Encode(&batch.encBuffer, data); err != nil {
 		return err
 	}
-	encItem := batch.encBuffer.data
-	if batch.sb != nil {
-		encItem = batch.sb.compress(encItem)
-	}
-	return batch.appendItem(encItem)
+	return batch.appendItem(batch.encBuffer.data)
 }
 
 // AppendRaw injects a binary blob at the end of the freezer table. The item number is a
@@ -137,15 +132,23 @@ func (batch *freezerTableBatch) AppendRaw(item uint64, blob []byte) error {
 	if item != batch.curItem {
 		return fmt.Errorf("%w: have %d want %d", errOutOrderInsertion, item, batch.curItem)
 	}
+	return batch.appendItem(blob)
+}
 
-	encItem := blob
-	if batch.sb != nil {
-		encItem = batch.sb.compress(blob)
+// compress compresses the item with the codec of the current head file.
+func (batch *freezerTableBatch) compress(item []byte) ([]byte, error) {
+	if batch.cb == nil {
+		return item, nil
 	}
-	return batch.appendItem(encItem)
+	batch.cb.codec = codecOf(batch.t.codecs, batch.t.headId)
+	return batch.cb.compress(item)
 }
 
-func (batch *freezerTableBatch) appendItem(data []byte) error {
+func (batch *freezerTableBatch) appendItem(item []byte) error {
+	data, err := batch.compress(item)
+	if err != nil {
+		return err
+	}
 	// Check if item fits into current data file.
 	itemSize := int64(len(data))
 	itemOffset := batch.t.headBytes + int64(len(batch.dataBuffer))
@@ -158,6 +161,12 @@ func (batch *freezerTableBatch) appendItem(data []byte) error {
 			return err
 		}
 		itemOffset = 0
+
+		// The next data file might use a different codec.
+		if data, err = batch.compress(item); err != nil {
+			return err
+		}
+		itemSize = int64(len(data))
 	}
 
 	// Put data to buffer.
@@ -216,30 +225,6 @@ func (batch *freezerTableBatch) commit() error {
 	return nil
 }
 
-// snappyBuffer writes snappy in block format, and can be reused. It is
-// reset when WriteTo is called.
-type snappyBuffer struct {
-	dst []byte
-}
-
-// compress snappy-compresses the data.
-func (s *snappyBuffer) compress(data []byte) []byte {
-	// The snappy library does not care what the capacity of the buffer is,
-	// but only checks the length. If the length is too small, it will
-	// allocate a brand new buffer.
-	// To avoid that, we check the required size here, and grow the size of the
-	// buffer to utilize the full capacity.
-	if n := snappy.MaxEncodedLen(len(data)); len(s.dst) < n {
-		if cap(s.dst) < n {
-			s.dst = make([]byte, n)
-		}
-		s.dst = s.dst[:n]
-	}
-
-	s.dst = snappy.Encode(s.dst, data)
-	return s.dst
-}
-
 // writeBuffer implements io.Writer for a byte slice.
 type writeBuffer struct {
 	data []byte
@@ -0,0 +1,197 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+// freezerCodec is the compression algorithm of the items in a compressed
+// freezer table data file.
+type freezerCodec uint8
+
+const (
+	codecSnappy freezerCodec = iota // Snappy block format, the legacy default
+	codecZstd                       // Zstandard, slower but much denser
+)
+
+// freezerCodecNames maps the supported codecs to their user facing names.
+var freezerCodecNames = map[freezerCodec]string{
+	codecSnappy: "snappy",
+	codecZstd:   "zstd",
+}
+
+// String implements fmt.Stringer.
+func (c freezerCodec) String() string {
+	if name, ok := freezerCodecNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(c))
+}
+
+// parseFreezerCodec resolves a codec from its user facing name.
+func parseFreezerCodec(name string) (freezerCodec, error) {
+	for codec, n := range freezerCodecNames {
+		if n == name {
+			return codec, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown freezer codec %q, supported ones: snappy, zstd", name)
+}
+
+// freezerCodecRange marks the codec of the data files starting from the given
+// file number, up until the next range.
+type freezerCodecRange struct {
+	File  uint32
+	Codec freezerCodec
+}
+
+// codecOf returns the codec of the given data file. Data files which are not
+// covered by any range are snappy compressed, as tables created before codecs
+// were selectable don't record any ranges.
+func codecOf(ranges []freezerCodecRange, file uint32) freezerCodec {
+	codec := codecSnappy
+	for _, r := range ranges {
+		if r.File > file {
+			break
+		}
+		codec = r.Codec
+	}
+	return codec
+}
+
+// setCodec returns the codec ranges with the given data file and all the ones
+// after it switched to the new codec.
+func setCodec(ranges []freezerCodecRange, file uint32, codec freezerCodec) []freezerCodecRange {
+	var updated []freezerCodecRange
+	for _, r := range ranges {
+		if r.File < file {
+			updated = append(updated, r)
+		}
+	}
+	if codecOf(updated, file) != codec {
+		updated = append(updated, freezerCodecRange{File: file, Codec: codec})
+	}
+	return updated
+}
+
+// compressBuffer compresses items with a codec, and can be reused.
+type compressBuffer struct {
+	codec freezerCodec
+	dst   []byte
+}
+
+// compress compresses the data. The returned slice is only valid until the
+// next invocation.
+func (b *compressBuffer) compress(data []byte) ([]byte, error) {
+	switch b.codec {
+	case codecSnappy:
+		// The snappy library does not care what the capacity of the buffer is,
+		// but only checks the length. If the length is too small, it will
+		// allocate a brand new buffer.
+		// To avoid that, we check the required size here, and grow the size of the
+		// buffer to utilize the full capacity.
+		if n := snappy.MaxEncodedLen(len(data)); len(b.dst) < n {
+			if cap(b.dst) < n {
+				b.dst = make([]byte, n)
+			}
+			b.dst = b.dst[:n]
+		}
+		b.dst = snappy.Encode(b.dst, data)
+		return b.dst, nil
+
+	case codecZstd:
+		dst, err := zstdCompress(b.dst, data)
+		if err != nil {
+			return nil, err
+		}
+		b.dst = dst
+		return dst, nil
+	}
+	return nil, fmt.Errorf("unsupported freezer codec %v", b.codec)
+}
+
+// decompress decompresses an item with the given codec.
+func decompress(codec freezerCodec, data []byte) ([]byte, error) {
+	switch codec {
+	case codecSnappy:
+		return snappy.Decode(nil, data)
+	case codecZstd:
+		return zstdDecompress(data)
+	}
+	return nil, fmt.Errorf("unsupported freezer codec %v", codec)
+}
+
+// decodedLen returns the length of an item once decompressed with the given
+// codec, without actually decompressing it.
+func decodedLen(codec freezerCodec, data []byte) (int, error) {
+	switch codec {
+	case codecSnappy:
+		return snappy.DecodedLen(data)
+	case codecZstd:
+		return zstdDecodedLen(data)
+	}
+	return 0, fmt.Errorf("unsupported freezer codec %v", codec)
+}
+
+// zstdMagic is the magic number starting every zstd frame.
+const zstdMagic = 0xFD2FB528
+
+// zstdDecodedLen parses the content size from the header of a zstd frame, as
+// specified in RFC 8878. The frames written by the freezer always carry it.
+func zstdDecodedLen(data []byte) (int, error) {
+	if len(data) < 5 || binary.LittleEndian.Uint32(data) != zstdMagic {
+		return 0, errors.New("invalid zstd frame header")
+	}
+	var (
+		desc   = data[4]
+		fcs    = []int{0, 2, 4, 8}[desc>>6]
+		single = desc&0x20 != 0
+		offset = 5 + []int{0, 1, 2, 4}[desc&0x03]
+	)
+	if !single {
+		offset++ // window descriptor
+	}
+	if fcs == 0 {
+		if !single {
+			return 0, errors.New("zstd frame content size unknown")
+		}
+		fcs = 1
+	}
+	if len(data) < offset+fcs {
+		return 0, errors.New("truncated zstd frame header")
+	}
+	var size uint64
+	switch fcs {
+	case 1:
+		size = uint64(data[offset])
+	case 2:
+		size = uint64(binary.LittleEndian.Uint16(data[offset:])) + 256
+	case 4:
+		size = uint64(binary.LittleEndian.Uint32(data[offset:]))
+	case 8:
+		size = binary.LittleEndian.Uint64(data[offset:])
+	}
+	if size > uint64(^uint(0)>>1) {
+		return 0, errors.New("zstd frame content size too large")
+	}
+	return int(size), nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func openCodecTable(t *testing.T, dir string, readonly bool) (*freezerTable, error) {
+	return newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, 100, freezerTableConfig{}, readonly)
+}
+
+func checkChunks(t *testing.T, f *freezerTable, from, to int) {
+	t.Helper()
+	items := make(map[uint64][]byte)
+	for i := from; i < to; i++ {
+		items[uint64(i)] = getChunk(40, i)
+	}
+	checkRetrieve(t, f, items)
+}
+
+func TestSetCodec(t *testing.T) {
+	var ranges []freezerCodecRange
+	ranges = setCodec(ranges, 5, codecZstd)
+	ranges = setCodec(ranges, 3, codecZstd)
+	if want := []freezerCodecRange{{3, codecZstd}}; !reflect.DeepEqual(ranges, want) {
+		t.Fatalf("Unexpected codec ranges: have %v, want %v", ranges, want)
+	}
+	ranges = setCodec(ranges, 7, codecSnappy)
+	if want := []freezerCodecRange{{3, codecZstd}, {7, codecSnappy}}; !reflect.DeepEqual(ranges, want) {
+		t.Fatalf("Unexpected codec ranges: have %v, want %v", ranges, want)
+	}
+	for file, want := range []freezerCodec{codecSnappy, codecSnappy, codecSnappy, codecZstd, codecZstd, codecZstd, codecZstd, codecSnappy, codecSnappy} {
+		if have := codecOf(ranges, uint32(file)); have != want {
+			t.Fatalf("File %d: codec mismatch: have %v, want %v", file, have, want)
+		}
+	}
+}
+
+// Tests that the decompressed size of items is derived from their headers.
+func TestDecodedLen(t *testing.T) {
+	for _, size := range []int{0, 1, 255, 256, 257, 65791, 65792, 1 << 20} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i % 7)
+		}
+		for codec := range freezerCodecNames {
+			buf := compressBuffer{codec: codec}
+			blob, err := buf.compress(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			have, err := decodedLen(codec, blob)
+			if err != nil {
+				t.Fatalf("%v, size %d: %v", codec, size, err)
+			}
+			if have != size {
+				t.Fatalf("%v: decoded length mismatch: have %d, want %d", codec, have, size)
+			}
+		}
+	}
+	if _, err := decodedLen(codecZstd, []byte{1, 2, 3, 4, 5, 6}); err == nil {
+		t.Fatal("Invalid zstd frame accepted")
+	}
+}
+
+// Tests recompressing a table with hidden and deleted items, and that files
+// appended afterwards use the new codec too.
+func TestFreezerRecompress(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := openCodecTable(t, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(t, f, 30, 40)
+	if err := f.truncateTail(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.recompress(codecZstd); err != nil {
+		t.Fatal(err)
+	}
+	if want := []freezerCodecRange{{f.tailId, codecZstd}}; !reflect.DeepEqual(f.codecs, want) {
+		t.Fatalf("Unexpected codec ranges: have %v, want %v", f.codecs, want)
+	}
+	checkChunks(t, f, 5, 30)
+
+	// Append more items spilling into new data files
+	batch := f.newBatch()
+	for i := 30; i < 40; i++ {
+		if err := batch.AppendRaw(uint64(i), getChunk(40, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := batch.commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, f, 5, 40)
+	f.Close()
+
+	// Reopen and recompress back to snappy
+	f, err = openCodecTable(t, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	checkChunks(t, f, 5, 40)
+	if err := f.recompress(codecSnappy); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.codecs) != 0 {
+		t.Fatalf("Unexpected codec ranges: %v", f.codecs)
+	}
+	checkChunks(t, f, 5, 40)
+}
+
+// Tests that an interrupted recompression is finished when the table is opened,
+// and that unfinished rewrites are discarded.
+func TestFreezerRecompressRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := openCodecTable(t, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(t, f, 30, 40)
+
+	// Leave a rewritten file without a journal behind
+	stale := f.recompressPath(f.tailId)
+	if err := os.WriteFile(stale, []byte{1, 2, 3}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Crash after the rewrite of the head file became durable
+	spans, err := f.dataFileItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := f.headId
+	if _, err := f.rewriteFile(head, spans[head][0], spans[head][1], codecZstd); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// The interrupted recompression can't be finished in read-only mode
+	if _, err := openCodecTable(t, dir, true); err == nil {
+		t.Fatal("Opened table with pending recompression in read-only mode")
+	}
+	f, err = openCodecTable(t, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("Unfinished rewrite not discarded: %v", err)
+	}
+	if _, err := os.Stat(f.journalPath()); !os.IsNotExist(err) {
+		t.Fatalf("Recompression journal not removed: %v", err)
+	}
+	// The table holds mixed codecs now
+	if want := []freezerCodecRange{{head, codecZstd}}; !reflect.DeepEqual(f.codecs, want) {
+		t.Fatalf("Unexpected codec ranges: have %v, want %v", f.codecs, want)
+	}
+	checkChunks(t, f, 0, 30)
+}
@@ -0,0 +1,484 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// eraFreezerOpenFiles is the maximum number of era1 files kept open by the
+// era-backed freezer.
+const eraFreezerOpenFiles = 16
+
+// eraIndexEntry is the cached metadata of an era1 archive whose accumulator
+// has already been verified.
+type eraIndexEntry struct {
+	Name    string
+	Size    uint64
+	ModTime uint64
+	Start   uint64
+	Count   uint64
+	Root    common.Hash
+}
+
+// EraFreezer is a read-only ancient store serving the chain segment directly
+// from a directory of era1 archives. The archives must be contiguous, starting
+// from the genesis block.
+//
+// The accumulator of every archive is verified against a trusted root when the
+// freezer is opened. The verified archives are indexed in the key-value store,
+// along with the hash to number mappings of their blocks, so that they are not
+// verified again on subsequent runs unless they are modified.
+type EraFreezer struct {
+	datadir string
+	roots   []common.Hash   // Trusted accumulator roots, indexed by epoch
+	names   []string        // Era1 file names, indexed by epoch
+	files   []eraIndexEntry // Verified era1 archives, indexed by epoch
+	frozen  uint64          // Number of blocks covered by the archives
+	size    uint64          // Total size of the archives
+
+	lock sync.Mutex
+	open lru.BasicLRU[int, *era.Era] // Recently accessed era1 archives
+}
+
+// NewEraFreezer opens the era1 archives in the given directory as a read-only
+// ancient store, verifying them against the given trusted accumulator roots.
+// The index of verified archives is cached in the given key-value store, unless
+// it's opened in read-only mode.
+func NewEraFreezer(datadir string, roots []common.Hash, db ethdb.KeyValueStore, readonly bool) (*EraFreezer, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("no trusted accumulator roots for the era1 archives")
+	}
+	network, err := eraNetwork(datadir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := era.ReadDir(datadir, network)
+	if err != nil {
+		return nil, err
+	}
+	// Load the previously verified archives, it's fine to discard the index if
+	// it's corrupted as it will be regenerated.
+	cached := make(map[string]eraIndexEntry)
+	if blob := ReadEraIndex(db); len(blob) > 0 {
+		var entries []eraIndexEntry
+		if err := rlp.DecodeBytes(blob, &entries); err != nil {
+			log.Warn("Discarding corrupted era1 index", "err", err)
+		}
+		for _, entry := range entries {
+			cached[entry.Name] = entry
+		}
+	}
+	f := &EraFreezer{
+		datadir: datadir,
+		roots:   roots,
+		names:   names,
+		open:    lru.NewBasicLRU[int, *era.Era](eraFreezerOpenFiles),
+	}
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		verified int
+		batch    ethdb.Batch
+	)
+	if !readonly {
+		batch = db.NewBatch()
+	}
+	for epoch, name := range names {
+		entry, err := f.verifyFile(epoch, name, cached[name], batch)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if batch != nil && batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				f.Close()
+				return nil, err
+			}
+			batch.Reset()
+		}
+		if epoch < len(names)-1 && entry.Count != uint64(era.MaxEra1Size) {
+			f.Close()
+			return nil, fmt.Errorf("era1 archive %s is incomplete: %d blocks", name, entry.Count)
+		}
+		if entry != cached[name] {
+			verified++
+		}
+		f.files = append(f.files, entry)
+		f.frozen += entry.Count
+		f.size += entry.Size
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying era1 archives", "network", network, "verified", epoch+1, "total", len(names), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if verified > 0 && !readonly {
+		if err := batch.Write(); err != nil {
+			f.Close()
+			return nil, err
+		}
+		blob, err := rlp.EncodeToBytes(f.files)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		WriteEraIndex(db, blob)
+	}
+	log.Info("Opened era1 ancient store", "dir", datadir, "network", network, "files", len(f.files), "verified", verified, "blocks", f.frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+	return f, nil
+}
+
+// verifyFile checks the era1 archive of the given epoch against the cached
+// index entry. If the archive is not indexed yet or has been modified since,
+// its accumulator is recomputed and checked against the trusted root, and the
+// hash to number mappings of its blocks are written into the given batch.
+func (f *EraFreezer) verifyFile(epoch int, name string, cached eraIndexEntry, batch ethdb.Batch) (eraIndexEntry, error) {
+	if epoch >= len(f.roots) {
+		return eraIndexEntry{}, fmt.Errorf("no trusted accumulator root for era1 archive %s", name)
+	}
+	trusted := f.roots[epoch]
+
+	stat, err := os.Stat(filepath.Join(f.datadir, name))
+	if err != nil {
+		return eraIndexEntry{}, err
+	}
+	entry := eraIndexEntry{
+		Name:    name,
+		Size:    uint64(stat.Size()),
+		ModTime: uint64(stat.ModTime().UnixNano()),
+	}
+	if cached.Name == name && cached.Size == entry.Size && cached.ModTime == entry.ModTime && cached.Start == f.frozen && cached.Root == trusted {
+		return cached, nil
+	}
+	e, err := f.file(epoch)
+	if err != nil {
+		return eraIndexEntry{}, err
+	}
+	if e.Start() != f.frozen {
+		return eraIndexEntry{}, fmt.Errorf("era1 archive %s starts at block %d, want %d", name, e.Start(), f.frozen)
+	}
+	entry.Start, entry.Count = e.Start(), e.Count()
+
+	want, err := e.Accumulator()
+	if err != nil {
+		return eraIndexEntry{}, err
+	}
+	if want != trusted {
+		return eraIndexEntry{}, fmt.Errorf("era1 archive %s accumulator mismatches trusted root: have %x, want %x", name, want, trusted)
+	}
+
+	// Recompute the accumulator from the archived blocks
+	it, err := era.NewRawIterator(e)
+	if err != nil {
+		return eraIndexEntry{}, err
+	}
+	var (
+		hashes = make([]common.Hash, 0, entry.Count)
+		tds    = make([]*big.Int, 0, entry.Count)
+	)
+	for it.Next() {
+		if err := it.Error(); err != nil {
+			return eraIndexEntry{}, fmt.Errorf("failed to read era1 archive %s: %w", name, err)
+		}
+		header, err := io.ReadAll(it.Header)
+		if err != nil {
+			return eraIndexEntry{}, fmt.Errorf("failed to read header %d from %s: %w", it.Number(), name, err)
+		}
+		td, err := e.GetTotalDifficultyByNumber(it.Number())
+		if err != nil {
+			return eraIndexEntry{}, fmt.Errorf("failed to read td %d from %s: %w", it.Number(), name, err)
+		}
+		hashes = append(hashes, crypto.Keccak256Hash(header))
+		tds = append(tds, td)
+	}
+	if err := it.Error(); err != nil {
+		return eraIndexEntry{}, fmt.Errorf("failed to read era1 archive %s: %w", name, err)
+	}
+	root, err := era.ComputeAccumulator(hashes, tds)
+	if err != nil {
+		return eraIndexEntry{}, err
+	}
+	if root != want {
+		return eraIndexEntry{}, fmt.Errorf("era1 archive %s accumulator mismatch: have %x, want %x", name, root, want)
+	}
+	if !strings.HasSuffix(name, "-"+root.Hex()[2:10]+".era1") {
+		return eraIndexEntry{}, fmt.Errorf("era1 archive %s name mismatches accumulator %x", name, root)
+	}
+	if batch != nil {
+		for i, hash := range hashes {
+			WriteHeaderNumber(batch, hash, entry.Start+uint64(i))
+		}
+	}
+	entry.Root = root
+	return entry, nil
+}
+
+// file returns the opened era1 archive of the given epoch. The caller must hold
+// the lock or have exclusive access to the freezer.
+func (f *EraFreezer) file(epoch int) (*era.Era, error) {
+	if e, ok := f.open.Get(epoch); ok {
+		return e, nil
+	}
+	e, err := era.Open(filepath.Join(f.datadir, f.names[epoch]))
+	if err != nil {
+		return nil, err
+	}
+	if f.open.Len() >= eraFreezerOpenFiles {
+		if _, old, ok := f.open.RemoveOldest(); ok {
+			old.Close()
+		}
+	}
+	f.open.Add(epoch, e)
+	return e, nil
+}
+
+// read retrieves the given kind of data of a block from the era1 archives.
+func (f *EraFreezer) read(kind string, number uint64) ([]byte, error) {
+	if number >= f.frozen {
+		return nil, errOutOfBounds
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	e, err := f.file(int(number / uint64(era.MaxEra1Size)))
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case ChainFreezerHeaderTable:
+		return e.GetRawHeaderByNumber(number)
+
+	case ChainFreezerHashTable:
+		header, err := e.GetRawHeaderByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(header), nil
+
+	case ChainFreezerBodiesTable:
+		return e.GetRawBodyByNumber(number)
+
+	case ChainFreezerReceiptTable:
+		// The archives contain the consensus encoding of the receipts, convert
+		// them into the storage encoding used by the freezer.
+		blob, err := e.GetRawReceiptsByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		var receipts types.Receipts
+		if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+			return nil, err
+		}
+		storage := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			storage[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		return rlp.EncodeToBytes(storage)
+
+	case ChainFreezerDifficultyTable:
+		td, err := e.GetTotalDifficultyByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		return rlp.EncodeToBytes(td)
+	}
+	return nil, errUnknownTable
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the era1 archives.
+func (f *EraFreezer) HasAncient(kind string, number uint64) (bool, error) {
+	if _, ok := chainFreezerTableConfigs[kind]; !ok {
+		return false, nil
+	}
+	return number < f.frozen, nil
+}
+
+// Ancient retrieves an ancient binary blob from the era1 archives.
+func (f *EraFreezer) Ancient(kind string, number uint64) ([]byte, error) {
+	return f.read(kind, number)
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index 'start'.
+// It will return
+//   - at most 'count' items,
+//   - if maxBytes is specified: at least 1 item (even if exceeding the maxByteSize),
+//     but will otherwise return as many items as fit into maxByteSize.
+//   - if maxBytes is not specified, 'count' items will be returned if they are present.
+func (f *EraFreezer) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	if _, ok := chainFreezerTableConfigs[kind]; !ok {
+		return nil, errUnknownTable
+	}
+	if start >= f.frozen || count == 0 {
+		return nil, errOutOfBounds
+	}
+	if start+count > f.frozen {
+		count = f.frozen - start
+	}
+	var (
+		size  uint64
+		batch [][]byte
+	)
+	for number := start; number < start+count; number++ {
+		blob, err := f.read(kind, number)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != 0 && maxBytes != 0 && size+uint64(len(blob)) > maxBytes {
+			break
+		}
+		batch = append(batch, blob)
+		size += uint64(len(blob))
+	}
+	return batch, nil
+}
+
+// Ancients returns the number of blocks covered by the era1 archives.
+func (f *EraFreezer) Ancients() (uint64, error) {
+	return f.frozen, nil
+}
+
+// Tail returns the number of first stored item in the freezer, which is always
+// the genesis block.
+func (f *EraFreezer) Tail() (uint64, error) {
+	return 0, nil
+}
+
+// AncientSize returns the ancient size of the specified category. The tables
+// are interleaved within the archives, so the total size of the archives is
+// attributed to the block bodies.
+func (f *EraFreezer) AncientSize(kind string) (uint64, error) {
+	if _, ok := chainFreezerTableConfigs[kind]; !ok {
+		return 0, errUnknownTable
+	}
+	if kind == ChainFreezerBodiesTable {
+		return f.size, nil
+	}
+	return 0, nil
+}
+
+// ReadAncients runs the given read operation. The era1 archives are immutable,
+// so no synchronization is required.
+func (f *EraFreezer) ReadAncients(fn func(ethdb.AncientReaderOp) error) (err error) {
+	return fn(f)
+}
+
+// ModifyAncients is not supported by the read-only era1 archives.
+func (f *EraFreezer) ModifyAncients(func(ethdb.AncientWriteOp) error) (int64, error) {
+	return 0, errReadOnly
+}
+
+// TruncateHead is not supported by the read-only era1 archives, unless it is
+// a noop.
+func (f *EraFreezer) TruncateHead(items uint64) (uint64, error) {
+	if items >= f.frozen {
+		return f.frozen, nil
+	}
+	return 0, errReadOnly
+}
+
+// TruncateTail is not supported by the read-only era1 archives, unless it is
+// a noop.
+func (f *EraFreezer) TruncateTail(tail uint64) (uint64, error) {
+	if tail == 0 {
+		return 0, nil
+	}
+	return 0, errReadOnly
+}
+
+// Sync is a noop for the read-only era1 archives.
+func (f *EraFreezer) Sync() error {
+	return nil
+}
+
+// MigrateTable is not supported by the read-only era1 archives.
+func (f *EraFreezer) MigrateTable(string, func([]byte) ([]byte, error)) error {
+	return errReadOnly
+}
+
+// Close releases all the opened era1 archives.
+func (f *EraFreezer) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, epoch := range f.open.Keys() {
+		if e, ok := f.open.Peek(epoch); ok {
+			e.Close()
+		}
+	}
+	f.open.Purge()
+	return nil
+}
+
+// eraNetwork determines the network of the era1 archives in the directory,
+// ensuring that archives of a single network are present.
+func eraNetwork(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var network string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".era1" {
+			continue
+		}
+		parts := strings.Split(entry.Name(), "-")
+		if len(parts) != 3 {
+			continue
+		}
+		if network != "" && network != parts[0] {
+			return "", fmt.Errorf("era1 archives of multiple networks in %s: %s, %s", dir, network, parts[0])
+		}
+		network = parts[0]
+	}
+	if network == "" {
+		return "", fmt.Errorf("no era1 archives in %s", dir)
+	}
+	return network, nil
+}
+
+// isEraDir reports whether the given directory contains era1 archives.
+func isEraDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".era1" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+)
+
+// makeEraTestChain creates a small chain with receipts and archives it into an
+// era1 file in the given directory, returning the accumulator root too.
+func makeEraTestChain(t *testing.T, dir string, n int) ([]*types.Block, []types.Receipts, common.Hash) {
+	t.Helper()
+
+	var (
+		blocks   = makeTestBlocks(n, 2)
+		receipts = make([]types.Receipts, n)
+		parent   common.Hash
+	)
+	for i := range blocks {
+		header := blocks[i].Header()
+		header.ParentHash = parent
+		header.Difficulty = big.NewInt(int64(i + 1))
+		blocks[i] = types.NewBlockWithHeader(header).WithBody(*blocks[i].Body())
+		parent = blocks[i].Hash()
+
+		for j, tx := range blocks[i].Transactions() {
+			receipts[i] = append(receipts[i], &types.Receipt{
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: uint64(21000 * (j + 1)),
+				Logs:              []*types.Log{{Address: common.Address{byte(i)}, Topics: []common.Hash{tx.Hash()}, Data: []byte{byte(j)}}},
+				TxHash:            tx.Hash(),
+			})
+			receipts[i][j].Bloom = types.CreateBloom(types.Receipts{receipts[i][j]})
+		}
+	}
+	f, err := os.CreateTemp(dir, "era1-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var (
+		builder = era.NewBuilder(f)
+		td      = new(big.Int)
+	)
+	for i, block := range blocks {
+		td.Add(td, block.Difficulty())
+		if err := builder.Add(block, receipts[i], new(big.Int).Set(td)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(f.Name(), filepath.Join(dir, era.Filename("test", 0, root))); err != nil {
+		t.Fatal(err)
+	}
+	return blocks, receipts, root
+}
+
+func TestEraFreezer(t *testing.T) {
+	var (
+		dir                  = t.TempDir()
+		blocks, recpts, root = makeEraTestChain(t, dir, 64)
+		db                   = NewMemoryDatabase()
+	)
+	// Freeze the same chain into a regular freezer as the reference
+	ref := NewMemoryFreezer(false, chainFreezerTableConfigs)
+	if _, err := WriteAncientBlocks(ref, blocks, recpts, blocks[0].Difficulty()); err != nil {
+		t.Fatalf("Failed to write reference ancients: %v", err)
+	}
+	f, err := NewEraFreezer(dir, []common.Hash{root}, db, false)
+	if err != nil {
+		t.Fatalf("Failed to open era freezer: %v", err)
+	}
+	defer f.Close()
+
+	for i, block := range blocks {
+		if number := ReadHeaderNumber(db, block.Hash()); number == nil || *number != uint64(i) {
+			t.Fatalf("Missing hash to number mapping of block %d", i)
+		}
+	}
+	if frozen, _ := f.Ancients(); frozen != uint64(len(blocks)) {
+		t.Fatalf("Unexpected number of ancients: have %d, want %d", frozen, len(blocks))
+	}
+	if len(ReadEraIndex(db)) == 0 {
+		t.Fatal("Era1 index not cached")
+	}
+	for kind := range chainFreezerTableConfigs {
+		for i := range blocks {
+			want, _ := ref.Ancient(kind, uint64(i))
+			have, err := f.Ancient(kind, uint64(i))
+			if err != nil {
+				t.Fatalf("Failed to read %s %d: %v", kind, i, err)
+			}
+			if !bytes.Equal(have, want) {
+				t.Fatalf("Mismatched %s %d: have %x, want %x", kind, i, have, want)
+			}
+		}
+		want, _ := ref.AncientRange(kind, 10, 20, 0)
+		have, err := f.AncientRange(kind, 10, 20, 0)
+		if err != nil || len(have) != len(want) {
+			t.Fatalf("Mismatched %s range: have %d items, want %d (err %v)", kind, len(have), len(want), err)
+		}
+		if have, _ := f.AncientRange(kind, 10, 20, 1); len(have) != 1 {
+			t.Fatalf("Unexpected %s range with byte limit: have %d items", kind, len(have))
+		}
+	}
+	if _, err := f.Ancient(ChainFreezerHeaderTable, uint64(len(blocks))); err == nil {
+		t.Fatal("Retrieved ancient above the era1 archives")
+	}
+	if _, err := f.ModifyAncients(func(ethdb.AncientWriteOp) error { return nil }); err == nil {
+		t.Fatal("Modified the read-only era1 archives")
+	}
+	if _, err := f.TruncateHead(1); err == nil {
+		t.Fatal("Truncated the read-only era1 archives")
+	}
+}
+
+func TestEraFreezerVerification(t *testing.T) {
+	dir := t.TempDir()
+	_, _, root := makeEraTestChain(t, dir, 16)
+
+	// Archives without trusted roots, or with mismatching ones are rejected
+	if _, err := NewEraFreezer(dir, nil, NewMemoryDatabase(), false); err == nil {
+		t.Fatal("Opened era1 archive without trusted roots")
+	}
+	if _, err := NewEraFreezer(dir, []common.Hash{{0x01}}, NewMemoryDatabase(), false); err == nil {
+		t.Fatal("Opened era1 archive with untrusted accumulator")
+	}
+	// Previously indexed archives must be checked against the trusted roots too
+	db := NewMemoryDatabase()
+	f, err := NewEraFreezer(dir, []common.Hash{root}, db, false)
+	if err != nil {
+		t.Fatalf("Failed to open era freezer: %v", err)
+	}
+	f.Close()
+	if _, err := NewEraFreezer(dir, []common.Hash{{0x01}}, db, false); err == nil {
+		t.Fatal("Opened indexed era1 archive with untrusted accumulator")
+	}
+
+	names, err := era.ReadDir(dir, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rename the archive, making its name mismatch the accumulator
+	path := filepath.Join(dir, names[0])
+	if err := os.Rename(path, filepath.Join(dir, "test-00000-00000000.era1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEraFreezer(dir, []common.Hash{root}, NewMemoryDatabase(), false); err == nil {
+		t.Fatal("Opened era1 archive with mismatched accumulator")
+	}
+}
+
+func TestEraDatabase(t *testing.T) {
+	var (
+		dir             = t.TempDir()
+		blocks, _, root = makeEraTestChain(t, dir, 16)
+	)
+	if _, err := NewDatabaseWithFreezer(NewMemoryDatabase(), dir, "", false); err == nil {
+		t.Fatal("Opened era1 archives without trusted roots")
+	}
+	db, err := newDatabaseWithFreezer(NewMemoryDatabase(), dir, []common.Hash{root}, "", false)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if !HasImmutableAncients(db) {
+		t.Fatal("Era1 archives reported as mutable")
+	}
+	for _, block := range blocks {
+		number := block.NumberU64()
+		if hash := ReadCanonicalHash(db, number); hash != block.Hash() {
+			t.Fatalf("Mismatched canonical hash %d: have %x, want %x", number, hash, block.Hash())
+		}
+		if have := ReadHeaderNumber(db, block.Hash()); have == nil || *have != number {
+			t.Fatalf("Missing hash to number mapping of block %d", number)
+		}
+		if have := ReadBlock(db, block.Hash(), number); have == nil || have.Hash() != block.Hash() || len(have.Transactions()) != len(block.Transactions()) {
+			t.Fatalf("Block %d not served from era1 archive", number)
+		}
+	}
+	if _, err := db.AncientDatadir(); err == nil {
+		t.Fatal("Shared era1 directory exposed as ancient root")
+	}
+}
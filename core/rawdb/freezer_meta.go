@@ -24,7 +24,10 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
-const freezerVersion = 1 // The initial version tag of freezer table metadata
+const (
+	freezerVersion      = 1 // The initial version tag of freezer table metadata
+	freezerCodecVersion = 2 // The version tag of freezer table metadata with codec ranges
+)
 
 // freezerTableMeta wraps all the metadata of the freezer table.
 type freezerTableMeta struct {
@@ -36,6 +39,10 @@ type freezerTableMeta struct {
 	// plus the number of items hidden in the table, so it should never
 	// be lower than the "actual tail".
 	VirtualTail uint64
+
+	// Codecs tracks the compression codecs of the data files, which is only
+	// recorded for compressed tables not using snappy exclusively.
+	Codecs []freezerCodecRange `rlp:"optional"`
 }
 
 // newMetadata initializes the metadata object with the given virtual tail.
@@ -67,6 +74,11 @@ func writeMetadata(file *os.File, meta *freezerTableMeta) error {
 	if err != nil {
 		return err
 	}
+	// Bump the version if codec ranges are present, so that they're not silently
+	// ignored by older versions.
+	if len(meta.Codecs) > 0 {
+		meta.Version = freezerCodecVersion
+	}
 	return rlp.Encode(file, meta)
 }
 
@@ -0,0 +1,356 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// recompressJournal records a rewritten data file which is about to replace
+// the original one. It's persisted before any original data is touched, so an
+// interrupted swap can be finished when the table is opened the next time.
+type recompressJournal struct {
+	File  uint32       // Number of the rewritten data file
+	Codec freezerCodec // Compression codec of the rewritten data file
+	First uint64       // Position of the index entry of the first item in the file
+	Ends  []uint32     // End offsets of the items in the rewritten data file
+}
+
+// journalPath returns the path of the recompression journal of the table.
+func (t *freezerTable) journalPath() string {
+	return filepath.Join(t.path, fmt.Sprintf("%s.recompress", t.name))
+}
+
+// recompressPath returns the path of the rewritten copy of a data file.
+func (t *freezerTable) recompressPath(num uint32) string {
+	return t.filePath(num) + ".recompress"
+}
+
+// recoverRecompress finishes the data file swap of an interrupted recompression
+// and cleans up the leftovers of an unfinished rewrite. It must be called before
+// the table is repaired, as the index may still point into the original file.
+func (t *freezerTable) recoverRecompress() error {
+	blob, err := os.ReadFile(t.journalPath())
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case t.readonly:
+		return fmt.Errorf("interrupted recompression of freezer table %s, open it in write mode to finish", t.name)
+	default:
+		var journal recompressJournal
+		if err := rlp.DecodeBytes(blob, &journal); err != nil {
+			return err
+		}
+		t.logger.Warn("Finishing interrupted recompression", "file", journal.File, "codec", journal.Codec)
+		if err := t.applyRecompress(&journal); err != nil {
+			return err
+		}
+	}
+	if t.readonly {
+		return nil
+	}
+	// The rewrite of a data file might have been interrupted before the journal
+	// was written, the original file is still intact then.
+	leftovers, err := filepath.Glob(filepath.Join(t.path, fmt.Sprintf("%s.*.recompress", t.name)))
+	if err != nil {
+		return err
+	}
+	leftovers = append(leftovers, t.journalPath()+".tmp")
+	for _, path := range leftovers {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRecompress swaps in a rewritten data file as recorded by the journal.
+// All steps are idempotent, so it's safe to retry after a crash. The data file
+// must not be open.
+func (t *freezerTable) applyRecompress(journal *recompressJournal) error {
+	// Replace the original data file, unless this already happened
+	err := os.Rename(t.recompressPath(journal.File), t.filePath(journal.File))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// Point the index to the rewritten items
+	buffer := make([]byte, 0, len(journal.Ends)*indexEntrySize)
+	for _, end := range journal.Ends {
+		entry := indexEntry{filenum: journal.File, offset: end}
+		buffer = entry.append(buffer)
+	}
+	if _, err := t.index.WriteAt(buffer, int64(journal.First*indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	if err := t.setCodec(journal.File, journal.Codec); err != nil {
+		return err
+	}
+	return os.Remove(t.journalPath())
+}
+
+// setCodec switches the given data file and all the ones after it to the new
+// codec in the metadata.
+func (t *freezerTable) setCodec(file uint32, codec freezerCodec) error {
+	meta, err := readMetadata(t.meta)
+	if err != nil {
+		return err
+	}
+	meta.Codecs = setCodec(meta.Codecs, file, codec)
+	if err := writeMetadata(t.meta, meta); err != nil {
+		return err
+	}
+	if err := t.meta.Sync(); err != nil {
+		return err
+	}
+	t.codecs = meta.Codecs
+	return nil
+}
+
+// writeRecompressJournal atomically persists the journal.
+func (t *freezerTable) writeRecompressJournal(journal *recompressJournal) error {
+	blob, err := rlp.EncodeToBytes(journal)
+	if err != nil {
+		return err
+	}
+	tmp := t.journalPath() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(blob); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.journalPath())
+}
+
+// dataFileItems returns the position of the first index entry and the number
+// of items of each data file holding any items.
+func (t *freezerTable) dataFileItems() (map[uint32][2]uint64, error) {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		entries = uint64(stat.Size() / indexEntrySize)
+		spans   = make(map[uint32][2]uint64)
+		buffer  = make([]byte, 1024*indexEntrySize)
+	)
+	// The first index entry is the tail marker, skip it
+	for pos := uint64(1); pos < entries; {
+		n := min(entries-pos, 1024)
+		if _, err := t.index.ReadAt(buffer[:n*indexEntrySize], int64(pos*indexEntrySize)); err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			var entry indexEntry
+			entry.unmarshalBinary(buffer[i*indexEntrySize:])
+
+			span, ok := spans[entry.filenum]
+			if !ok {
+				span[0] = pos + i
+			}
+			span[1]++
+			spans[entry.filenum] = span
+		}
+		pos += n
+	}
+	return spans, nil
+}
+
+// rewriteFile writes a copy of a data file with the items recompressed with the
+// given codec, including the items hidden or deleted from the tail. The journal
+// of the swap is persisted once the copy is durable. The caller must hold the
+// write lock.
+func (t *freezerTable) rewriteFile(num uint32, first, count uint64, codec freezerCodec) (*recompressJournal, error) {
+	var (
+		src     = t.files[num]
+		entries = make([]byte, count*indexEntrySize)
+		old     = codecOf(t.codecs, num)
+	)
+	if src == nil {
+		return nil, fmt.Errorf("missing data file %d", num)
+	}
+	if _, err := t.index.ReadAt(entries, int64(first*indexEntrySize)); err != nil {
+		return nil, err
+	}
+	dst, err := os.Create(t.recompressPath(num))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if dst != nil {
+			dst.Close()
+			os.Remove(dst.Name())
+		}
+	}()
+	var (
+		writer = bufio.NewWriterSize(dst, 1024*1024)
+		cb     = &compressBuffer{codec: codec}
+		ends   = make([]uint32, 0, count)
+		item   []byte
+		start  uint32 // items always start at the beginning of the data file
+		size   uint64
+	)
+	for i := uint64(0); i < count; i++ {
+		var entry indexEntry
+		entry.unmarshalBinary(entries[i*indexEntrySize:])
+
+		item = grow(item[:0], int(entry.offset-start))
+		if _, err := src.ReadAt(item, int64(start)); err != nil {
+			return nil, fmt.Errorf("%w, fileid: %d, start: %d, length: %d", err, num, start, len(item))
+		}
+		data, err := decompress(old, item)
+		if err != nil {
+			return nil, err
+		}
+		enc, err := cb.compress(data)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(enc); err != nil {
+			return nil, err
+		}
+		size += uint64(len(enc))
+		if size > math.MaxUint32 {
+			return nil, fmt.Errorf("recompressed data file %d exceeds the size limit", num)
+		}
+		ends = append(ends, uint32(size))
+		start = entry.offset
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	if err := dst.Sync(); err != nil {
+		return nil, err
+	}
+	if err := dst.Close(); err != nil {
+		return nil, err
+	}
+	dst = nil
+
+	journal := &recompressJournal{File: num, Codec: codec, First: first, Ends: ends}
+	if err := t.writeRecompressJournal(journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// recompressFile rewrites a data file with the given codec and swaps it in,
+// returning the new size of the file. The caller must hold the write lock.
+func (t *freezerTable) recompressFile(num uint32, first, count uint64, codec freezerCodec) (int64, error) {
+	journal, err := t.rewriteFile(num, first, count, codec)
+	if err != nil {
+		return 0, err
+	}
+	t.releaseFile(num)
+	if err := t.applyRecompress(journal); err != nil {
+		return 0, err
+	}
+	var size int64
+	if n := len(journal.Ends); n > 0 {
+		size = int64(journal.Ends[n-1])
+	}
+	if num == t.headId {
+		if t.head, err = t.openFile(num, openFreezerFileForAppend); err != nil {
+			return 0, err
+		}
+		t.headBytes = size
+	} else if _, err := t.openFile(num, openFreezerFileForReadOnly); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// recompress rewrites all data files of the table which are not compressed
+// with the given codec yet. The data files are processed from the head to the
+// tail, so that every file from the last processed one onwards uses the new
+// codec, including the ones created later.
+func (t *freezerTable) recompress(codec freezerCodec) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.config.noSnappy {
+		return fmt.Errorf("freezer table %s is not compressed", t.name)
+	}
+	spans, err := t.dataFileItems()
+	if err != nil {
+		return err
+	}
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		total    = int(t.headId - t.tailId + 1)
+		done     int
+		oldBytes int64
+		newBytes int64
+	)
+	for num := int64(t.headId); num >= int64(t.tailId); num-- {
+		file := uint32(num)
+		done++
+		if codecOf(t.codecs, file) == codec {
+			// Drop any stale codec ranges beyond the head, which would make the
+			// newly created data files fall back to another codec.
+			if file == t.headId && len(setCodec(t.codecs, file, codec)) != len(t.codecs) {
+				if err := t.setCodec(file, codec); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		stat, err := t.files[file].Stat()
+		if err != nil {
+			return err
+		}
+		size, err := t.recompressFile(file, spans[file][0], spans[file][1], codec)
+		if err != nil {
+			return err
+		}
+		oldBytes += stat.Size()
+		newBytes += size
+
+		if time.Since(logged) > 8*time.Second {
+			t.logger.Info("Recompressing freezer table", "codec", codec, "files", fmt.Sprintf("%d/%d", done, total),
+				"old", common.StorageSize(oldBytes), "new", common.StorageSize(newBytes), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	t.logger.Info("Recompressed freezer table", "codec", codec, "files", total,
+		"old", common.StorageSize(oldBytes), "new", common.StorageSize(newBytes), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -49,7 +49,7 @@ type resettableFreezer struct {
 //
 // The reset function will delete directory atomically and re-create the
 // freezer from scratch.
-func newResettableFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool) (*resettableFreezer, error) {
+func newResettableFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]freezerTableConfig) (*resettableFreezer, error) {
 	if err := cleanup(datadir); err != nil {
 		return nil, err
 	}
@@ -202,6 +202,15 @@ func (f *resettableFreezer) Sync() error {
 	return f.freezer.Sync()
 }
 
+// Checkpoint writes a consistent copy of the freezer into the given directory,
+// which must not exist yet.
+func (f *resettableFreezer) Checkpoint(dir string) error {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.freezer.Checkpoint(dir)
+}
+
 // MigrateTable processes the entries in a given table in sequence
 // converting them to a new format if they're of an old format.
 func (f *resettableFreezer) MigrateTable(kind string, convert convertLegacyFn) error {
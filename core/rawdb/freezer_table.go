@@ -30,7 +30,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
-	"github.com/golang/snappy"
 )
 
 var (
@@ -98,11 +97,12 @@ type freezerTable struct {
 	// should never be lower than itemOffset.
 	itemHidden atomic.Uint64
 
-	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
-	readonly      bool
-	maxFileSize   uint32 // Max file size for data-files
-	name          string
-	path          string
+	config      freezerTableConfig  // if config.noSnappy is true, compression is disabled. Note: does not work retroactively
+	codecs      []freezerCodecRange // Compression codecs of the data files, tracked in the metadata
+	readonly    bool
+	maxFileSize uint32 // Max file size for data-files
+	name        string
+	path        string
 
 	head   *os.File            // File descriptor for the data head of the table
 	index  *os.File            // File descriptor for the indexEntry file of the table
@@ -122,19 +122,19 @@ type freezerTable struct {
 
 // newFreezerTable opens the given path as a freezer table.
 func newFreezerTable(path, name string, disableSnappy, readonly bool) (*freezerTable, error) {
-	return newTable(path, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, disableSnappy, readonly)
+	return newTable(path, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, freezerTableConfig{noSnappy: disableSnappy}, readonly)
 }
 
 // newTable opens a freezer table, creating the data and index files if they are
 // non-existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
-func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression, readonly bool) (*freezerTable, error) {
+func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, config freezerTableConfig, readonly bool) (*freezerTable, error) {
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
 	var idxName string
-	if noCompression {
+	if config.noSnappy {
 		idxName = fmt.Sprintf("%s.ridx", name) // raw index file
 	} else {
 		idxName = fmt.Sprintf("%s.cidx", name) // compressed index file
@@ -166,18 +166,22 @@ func newTable(path string, name string, readMeter metrics.Meter, writeMeter metr
 	}
 	// Create the table and repair any past inconsistency
 	tab := &freezerTable{
-		index:         index,
-		meta:          meta,
-		files:         make(map[uint32]*os.File),
-		readMeter:     readMeter,
-		writeMeter:    writeMeter,
-		sizeGauge:     sizeGauge,
-		name:          name,
-		path:          path,
-		logger:        log.New("database", path, "table", name),
-		noCompression: noCompression,
-		readonly:      readonly,
-		maxFileSize:   maxFilesize,
+		index:       index,
+		meta:        meta,
+		files:       make(map[uint32]*os.File),
+		readMeter:   readMeter,
+		writeMeter:  writeMeter,
+		sizeGauge:   sizeGauge,
+		name:        name,
+		path:        path,
+		logger:      log.New("database", path, "table", name),
+		config:      config,
+		readonly:    readonly,
+		maxFileSize: maxFilesize,
+	}
+	if err := tab.recoverRecompress(); err != nil {
+		tab.Close()
+		return nil, err
 	}
 	if err := tab.repair(); err != nil {
 		tab.Close()
@@ -251,6 +255,7 @@ func (t *freezerTable) repair() error {
 		return err
 	}
 	t.itemHidden.Store(meta.VirtualTail)
+	t.codecs = meta.Codecs
 
 	// Read the last index, use the default value in case the freezer is empty
 	if offsetsSize == indexEntrySize {
@@ -314,6 +319,9 @@ func (t *freezerTable) repair() error {
 			if newLastIndex.filenum != lastIndex.filenum {
 				// Release earlier opened file
 				t.releaseFile(lastIndex.filenum)
+				if err := t.unshareFile(newLastIndex.filenum); err != nil {
+					return err
+				}
 				if t.head, err = t.openFile(newLastIndex.filenum, openFreezerFileForAppend); err != nil {
 					return err
 				}
@@ -436,6 +444,9 @@ func (t *freezerTable) truncateHead(items uint64) error {
 	if expected.filenum != t.headId {
 		// If already open for reading, force-reopen for writing
 		t.releaseFile(expected.filenum)
+		if err := t.unshareFile(expected.filenum); err != nil {
+			return err
+		}
 		newHead, err := t.openFile(expected.filenum, openFreezerFileForAppend)
 		if err != nil {
 			return err
@@ -517,7 +528,9 @@ func (t *freezerTable) truncateTail(items uint64) error {
 	}
 	// Update the virtual tail marker and hidden these entries in table.
 	t.itemHidden.Store(items)
-	if err := writeMetadata(t.meta, newMetadata(items)); err != nil {
+	meta := newMetadata(items)
+	meta.Codecs = t.codecs
+	if err := writeMetadata(t.meta, meta); err != nil {
 		return err
 	}
 	// Hidden items still fall in the current tail file, no data file
@@ -635,13 +648,7 @@ func (t *freezerTable) Close() error {
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.noCompression {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(t.filePath(num))
 		if err != nil {
 			return nil, err
 		}
@@ -650,6 +657,26 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	return f, err
 }
 
+// filePath returns the path of the data file with the given number.
+func (t *freezerTable) filePath(num uint32) string {
+	if t.config.noSnappy {
+		return filepath.Join(t.path, fmt.Sprintf("%s.%04d.rdat", t.name, num))
+	}
+	return filepath.Join(t.path, fmt.Sprintf("%s.%04d.cdat", t.name, num))
+}
+
+// unshareFile replaces the data file with the given number by a private copy of
+// itself. Sealed data files may be hard linked by checkpoints, so they need to
+// be unshared before being modified in place. Files without other links are
+// left untouched. The file must not be open.
+func (t *freezerTable) unshareFile(num uint32) error {
+	shared, err := isSharedFile(t.filePath(num))
+	if err != nil || !shared {
+		return err
+	}
+	return copyFrom(t.filePath(num), t.filePath(num), 0, nil)
+}
+
 // releaseFile closes a file, and removes it from the open file cache.
 // Assumes that the caller holds the write lock
 func (t *freezerTable) releaseFile(num uint32) {
@@ -738,7 +765,7 @@ func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
 // item, it _will_ return one element and possibly overflow the maxBytes.
 func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, error) {
 	// First we read the 'raw' data, which might be compressed.
-	diskData, sizes, err := t.retrieveItems(start, count, maxBytes)
+	diskData, sizes, files, err := t.retrieveItems(start, count, maxBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -751,23 +778,24 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 	for i, diskSize := range sizes {
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
-		decompressedSize := diskSize
-		if !t.noCompression {
-			decompressedSize, _ = snappy.DecodedLen(item)
+		// Check the decompressed size before doing the work, as the item
+		// is discarded anyway if it exceeds the limit.
+		size := diskSize
+		if !t.config.noSnappy {
+			if size, err = decodedLen(codecOf(t.codecs, files[i]), item); err != nil {
+				return nil, err
+			}
 		}
-		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
+		if i > 0 && maxBytes != 0 && uint64(outputSize+size) > maxBytes {
 			break
 		}
-		if !t.noCompression {
-			data, err := snappy.Decode(nil, item)
-			if err != nil {
+		if !t.config.noSnappy {
+			if item, err = decompress(codecOf(t.codecs, files[i]), item); err != nil {
 				return nil, err
 			}
-			output = append(output, data)
-		} else {
-			output = append(output, item)
 		}
-		outputSize += decompressedSize
+		output = append(output, item)
+		outputSize += size
 	}
 	return output, nil
 }
@@ -775,15 +803,15 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 // retrieveItems reads up to 'count' items from the table. It reads at least
 // one item, but otherwise avoids reading more than maxBytes bytes. Freezer
 // will ignore the size limitation and continuously allocate memory to store
-// data if maxBytes is 0. It returns the (potentially compressed) data, and
-// the sizes.
-func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []int, error) {
+// data if maxBytes is 0. It returns the (potentially compressed) data, the
+// sizes and the data files of the items.
+func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []int, []uint32, error) {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
 
 	// Ensure the table and the item are accessible
 	if t.index == nil || t.head == nil || t.meta == nil {
-		return nil, nil, errClosed
+		return nil, nil, nil, errClosed
 	}
 	var (
 		items  = t.items.Load()      // the total items(head + 1)
@@ -792,7 +820,7 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 	// Ensure the start is written, not deleted from the tail, and that the
 	// caller actually wants something
 	if items <= start || hidden > start || count == 0 {
-		return nil, nil, errOutOfBounds
+		return nil, nil, nil, errOutOfBounds
 	}
 	if start+count > items {
 		count = items - start
@@ -818,10 +846,11 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 	// Read all the indexes in one go
 	indices, err := t.getIndices(start, count)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	var (
 		sizes      []int               // The sizes for each element
+		files      []uint32            // The data files of each element
 		totalSize  = 0                 // The total size of all data read so far
 		readStart  = indices[0].offset // Where, in the file, to start reading
 		unreadSize = 0                 // The size of the as-yet-unread data
@@ -837,7 +866,7 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 			// If we have unread data in the first file, we need to do that read now.
 			if unreadSize > 0 {
 				if err := readData(firstIndex.filenum, readStart, unreadSize); err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 				unreadSize = 0
 			}
@@ -848,7 +877,7 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 			// read this last item, but we need to do the deferred reads now.
 			if unreadSize > 0 {
 				if err := readData(secondIndex.filenum, readStart, unreadSize); err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 			}
 			break
@@ -857,10 +886,11 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 		unreadSize += size
 		totalSize += size
 		sizes = append(sizes, size)
+		files = append(files, secondIndex.filenum)
 		if i == len(indices)-2 || (uint64(totalSize) > maxBytes && maxBytes != 0) {
 			// Last item, need to do the read now
 			if err := readData(secondIndex.filenum, readStart, unreadSize); err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			break
 		}
@@ -868,7 +898,7 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 
 	// Update metrics.
 	t.readMeter.Mark(int64(totalSize))
-	return output, sizes, nil
+	return output, sizes, files, nil
 }
 
 // has returns an indicator whether the specified number data is still accessible
@@ -929,6 +959,32 @@ func (t *freezerTable) advanceHead() error {
 	return nil
 }
 
+// checkpoint writes a consistent copy of the table into the given directory.
+// Sealed data files are hard linked if possible, as they are never modified in
+// place without being unshared first. The index, metadata and head data files
+// are copied. The caller must ensure that no items are written concurrently.
+func (t *freezerTable) checkpoint(dir string) error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.head == nil || t.meta == nil {
+		return errClosed
+	}
+	for _, f := range []*os.File{t.index, t.meta} {
+		if err := copyFile(f.Name(), filepath.Join(dir, filepath.Base(f.Name())), -1); err != nil {
+			return err
+		}
+	}
+	for num := t.tailId; num < t.headId; num++ {
+		src := t.filePath(num)
+		if err := linkOrCopyFile(src, filepath.Join(dir, filepath.Base(src))); err != nil {
+			return err
+		}
+	}
+	src := t.filePath(t.headId)
+	return copyFile(src, filepath.Join(dir, filepath.Base(src)), t.headBytes)
+}
+
 // Sync pushes any pending data from memory out to disk. This is an expensive
 // operation, so use it with care.
 func (t *freezerTable) Sync() error {
@@ -33,7 +33,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var freezerTestTableDef = map[string]bool{"test": true}
+var freezerTestTableDef = map[string]freezerTableConfig{"test": {noSnappy: true, prunable: true}}
 
 func TestFreezerModify(t *testing.T) {
 	t.Parallel()
@@ -49,7 +49,7 @@ func TestFreezerModify(t *testing.T) {
 		valuesRLP = append(valuesRLP, iv)
 	}
 
-	tables := map[string]bool{"raw": true, "rlp": false}
+	tables := map[string]freezerTableConfig{"raw": {noSnappy: true, prunable: true}, "rlp": {noSnappy: false, prunable: true}}
 	f, _ := newFreezerForTesting(t, tables)
 	defer f.Close()
 
@@ -113,7 +113,7 @@ func TestFreezerModifyRollback(t *testing.T) {
 	f.Close()
 
 	// Reopen and check that the rolled-back data doesn't reappear.
-	tables := map[string]bool{"test": true}
+	tables := map[string]freezerTableConfig{"test": {noSnappy: true, prunable: true}}
 	f2, err := NewFreezer(dir, "", false, 2049, tables)
 	if err != nil {
 		t.Fatalf("can't reopen freezer after failed ModifyAncients: %v", err)
@@ -251,7 +251,7 @@ func TestFreezerConcurrentModifyTruncate(t *testing.T) {
 }
 
 func TestFreezerReadonlyValidate(t *testing.T) {
-	tables := map[string]bool{"a": true, "b": true}
+	tables := map[string]freezerTableConfig{"a": {noSnappy: true, prunable: true}, "b": {noSnappy: true, prunable: true}}
 	dir := t.TempDir()
 	// Open non-readonly freezer and fill individual tables
 	// with different amount of data.
@@ -284,10 +284,60 @@ func TestFreezerReadonlyValidate(t *testing.T) {
 	}
 }
 
+func TestFreezerPrunableTail(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]freezerTableConfig{"a": {noSnappy: true, prunable: true}, "b": {noSnappy: true, prunable: false}}
+	f, dir := newFreezerForTesting(t, tables)
+
+	var item = make([]byte, 1024)
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := op.AppendRaw("a", i, item); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("b", i, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = f.TruncateTail(5)
+	require.NoError(t, err)
+
+	check := func(f *Freezer) {
+		t.Helper()
+		if tail, _ := f.Tail(); tail != 5 {
+			t.Fatalf("unexpected tail, want: 5, have: %d", tail)
+		}
+		if _, err := f.Ancient("a", 4); err == nil {
+			t.Fatal("expected pruned item in prunable table")
+		}
+		if _, err := f.Ancient("a", 5); err != nil {
+			t.Fatalf("missing item in prunable table: %v", err)
+		}
+		if _, err := f.Ancient("b", 0); err != nil {
+			t.Fatalf("missing item in non-prunable table: %v", err)
+		}
+	}
+	check(f)
+	require.NoError(t, f.Close())
+
+	// Reopen the freezer both in readonly and in normal mode, the differing
+	// tails of the non-prunable tables must be accepted.
+	for _, readonly := range []bool{true, false} {
+		f, err = NewFreezer(dir, "", readonly, 2049, tables)
+		require.NoError(t, err)
+		check(f)
+		require.NoError(t, f.Close())
+	}
+}
+
 func TestFreezerConcurrentReadonly(t *testing.T) {
 	t.Parallel()
 
-	tables := map[string]bool{"a": true}
+	tables := map[string]freezerTableConfig{"a": {noSnappy: true, prunable: true}}
 	dir := t.TempDir()
 
 	f, err := NewFreezer(dir, "", false, 2049, tables)
@@ -335,7 +385,7 @@ func TestFreezerConcurrentReadonly(t *testing.T) {
 	}
 }
 
-func newFreezerForTesting(t *testing.T, tables map[string]bool) (*Freezer, string) {
+func newFreezerForTesting(t *testing.T, tables map[string]freezerTableConfig) (*Freezer, string) {
 	t.Helper()
 
 	dir := t.TempDir()
@@ -462,7 +512,7 @@ func TestRenameWindows(t *testing.T) {
 
 func TestFreezerCloseSync(t *testing.T) {
 	t.Parallel()
-	f, _ := newFreezerForTesting(t, map[string]bool{"a": true, "b": true})
+	f, _ := newFreezerForTesting(t, map[string]freezerTableConfig{"a": {noSnappy: true, prunable: true}, "b": {noSnappy: true, prunable: true}})
 	defer f.Close()
 
 	// Now, close and sync. This mimics the behaviour if the node is shut down,
@@ -482,19 +532,80 @@ func TestFreezerCloseSync(t *testing.T) {
 	}
 }
 
+// This checks that a checkpoint of the freezer can be opened as a freezer and
+// isn't affected by subsequent modifications of the original one.
+func TestFreezerCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]freezerTableConfig{"raw": {noSnappy: true, prunable: true}, "comp": {noSnappy: false, prunable: true}}
+	f, dir := newFreezerForTesting(t, tables)
+	defer f.Close()
+
+	writeChunks := func(from, to, seed int) {
+		_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for i := from; i < to; i++ {
+				if err := op.AppendRaw("raw", uint64(i), getChunk(256, i+seed)); err != nil {
+					return err
+				}
+				if err := op.AppendRaw("comp", uint64(i), getChunk(256, i+seed)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal("ModifyAncients failed:", err)
+		}
+	}
+	writeChunks(0, 100, 0)
+	if _, err := f.TruncateTail(10); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "checkpoint")
+	if err := f.Checkpoint(path); err != nil {
+		t.Fatal("checkpoint failed:", err)
+	}
+	if err := f.Checkpoint(path); err == nil {
+		t.Fatal("checkpoint into existing directory succeeded")
+	}
+	// Rewrite the original freezer from within a sealed data file
+	if _, err := f.TruncateHead(20); err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(20, 120, 1)
+
+	cpy, err := NewFreezer(path, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal("can't open checkpoint", err)
+	}
+	defer cpy.Close()
+
+	checkAncientCount(t, cpy, "raw", 100)
+	if tail, _ := cpy.Tail(); tail != 10 {
+		t.Fatalf("checkpoint tail mismatch: have %d, want 10", tail)
+	}
+	for i := 10; i < 100; i++ {
+		for kind := range tables {
+			if v, err := cpy.Ancient(kind, uint64(i)); err != nil || !bytes.Equal(v, getChunk(256, i)) {
+				t.Fatalf("wrong %s value at %d: %x, %v", kind, i, v, err)
+			}
+		}
+	}
+}
+
 func TestFreezerSuite(t *testing.T) {
 	ancienttest.TestAncientSuite(t, func(kinds []string) ethdb.AncientStore {
-		tables := make(map[string]bool)
+		tables := make(map[string]freezerTableConfig)
 		for _, kind := range kinds {
-			tables[kind] = true
+			tables[kind] = freezerTableConfig{noSnappy: true, prunable: true}
 		}
 		f, _ := newFreezerForTesting(t, tables)
 		return f
 	})
 	ancienttest.TestResettableAncientSuite(t, func(kinds []string) ethdb.ResettableAncientStore {
-		tables := make(map[string]bool)
+		tables := make(map[string]freezerTableConfig)
 		for _, kind := range kinds {
-			tables[kind] = true
+			tables[kind] = freezerTableConfig{noSnappy: true, prunable: true}
 		}
 		f, _ := newResettableFreezer(t.TempDir(), "", false, 2048, tables)
 		return f
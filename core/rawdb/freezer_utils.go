@@ -129,3 +129,39 @@ func grow(buf []byte, n int) []byte {
 	buf = buf[:len(buf)+n]
 	return buf
 }
+
+// copyFile copies the first 'size' bytes of 'srcPath' into the newly created
+// file 'destPath', or the entire file if size is negative.
+func copyFile(srcPath, destPath string, size int64) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		_, err = io.Copy(dst, src)
+	} else {
+		_, err = io.CopyN(dst, src, size)
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// linkOrCopyFile creates a hard link of 'srcPath' at 'destPath', falling back
+// to copying the file if linking is not possible, e.g. across filesystems.
+func linkOrCopyFile(srcPath, destPath string) error {
+	if err := os.Link(srcPath, destPath); err == nil {
+		return nil
+	}
+	return copyFile(srcPath, destPath, -1)
+}
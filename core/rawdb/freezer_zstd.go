@@ -0,0 +1,45 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import "github.com/klauspost/compress/zstd"
+
+var (
+	// zstdEncoder compresses the freezer items, favouring the compression ratio
+	// as the items are written once and read rarely. Every item, empty ones too,
+	// is written as a single segment frame, which always carries its decoded size
+	// in the header.
+	zstdEncoder, _ = zstd.NewWriter(nil,
+		zstd.WithEncoderLevel(zstd.SpeedBetterCompression),
+		zstd.WithEncoderConcurrency(1),
+		zstd.WithSingleSegment(true),
+		zstd.WithZeroFrames(true),
+	)
+
+	// zstdDecoder decompresses the freezer items.
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// zstdCompress compresses the data with zstd, reusing the capacity of dst.
+func zstdCompress(dst, data []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(data, dst[:0]), nil
+}
+
+// zstdDecompress decompresses the zstd compressed data.
+func zstdDecompress(data []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(data, nil)
+}
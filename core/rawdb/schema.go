@@ -96,6 +96,12 @@ var (
 	// snapSyncStatusFlagKey flags that status of snap sync.
 	snapSyncStatusFlagKey = []byte("SnapSyncStatus")
 
+	// eraIndexKey tracks the verified era1 archives backing the ancient store.
+	eraIndexKey = []byte("EraIndex")
+
+	// databaseSizesKey tracks the approximate size of each data category.
+	databaseSizesKey = []byte("DatabaseSizes")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
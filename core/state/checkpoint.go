@@ -0,0 +1,117 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// checkpoint retains the original versions of the accounts accessed since it
+// was taken, allowing to revert the changes of multiple transactions. Snapshots
+// cannot do that, as the journal is discarded whenever a transaction finalises.
+type checkpoint struct {
+	objects   map[common.Address]*stateObject // Live objects at the checkpoint, nil if not live
+	destructs map[common.Address]*stateObject // Destructed objects at the checkpoint, nil if not destructed
+	mutations map[common.Address]*mutation    // Object state markers at the checkpoint, nil if unmarked
+	logs      map[common.Hash]int             // Number of logs per transaction at the checkpoint
+	logSize   uint
+}
+
+// track saves the original version of an account, unless already saved.
+func (c *checkpoint) track(s *StateDB, addr common.Address) {
+	if _, ok := c.objects[addr]; ok {
+		return
+	}
+	var obj *stateObject
+	if live := s.stateObjects[addr]; live != nil {
+		obj = live.deepCopy(s)
+	}
+	c.objects[addr] = obj
+	c.destructs[addr] = s.stateObjectsDestruct[addr]
+
+	var op *mutation
+	if m := s.mutations[addr]; m != nil {
+		op = m.copy()
+	}
+	c.mutations[addr] = op
+}
+
+// trackLogs saves the number of logs of a transaction, unless already saved.
+func (c *checkpoint) trackLogs(s *StateDB, txhash common.Hash) {
+	if _, ok := c.logs[txhash]; !ok {
+		c.logs[txhash] = len(s.logs[txhash])
+	}
+}
+
+// Checkpoint starts retaining the original versions of the accounts accessed,
+// so that all changes made from now on, across any number of transactions, can
+// be reverted via RevertToCheckpoint. Any previous checkpoint is discarded.
+//
+// The checkpoint must be taken in between transactions, and is invalidated by
+// IntermediateRoot as the changes are flushed into the tries.
+func (s *StateDB) Checkpoint() {
+	s.checkpoint = &checkpoint{
+		objects:   make(map[common.Address]*stateObject),
+		destructs: make(map[common.Address]*stateObject),
+		mutations: make(map[common.Address]*mutation),
+		logs:      make(map[common.Hash]int),
+		logSize:   s.logSize,
+	}
+}
+
+// RevertToCheckpoint reverts all state changes made since the checkpoint was
+// taken, and discards it.
+func (s *StateDB) RevertToCheckpoint() {
+	c := s.checkpoint
+	if c == nil {
+		panic("no checkpoint to revert to")
+	}
+	s.checkpoint = nil
+
+	for addr, obj := range c.objects {
+		if obj == nil {
+			delete(s.stateObjects, addr)
+		} else {
+			s.stateObjects[addr] = obj
+		}
+		if obj := c.destructs[addr]; obj == nil {
+			delete(s.stateObjectsDestruct, addr)
+		} else {
+			s.stateObjectsDestruct[addr] = obj
+		}
+		if op := c.mutations[addr]; op == nil {
+			delete(s.mutations, addr)
+		} else {
+			s.mutations[addr] = op
+		}
+	}
+	for txhash, n := range c.logs {
+		if n == 0 {
+			delete(s.logs, txhash)
+		} else {
+			s.logs[txhash] = s.logs[txhash][:n]
+		}
+	}
+	s.logSize = c.logSize
+	s.clearJournalAndRefund()
+}
+
+// DiscardCheckpoint stops retaining the original versions of the accounts,
+// keeping the state changes made since the checkpoint was taken.
+func (s *StateDB) DiscardCheckpoint() {
+	s.checkpoint = nil
+}
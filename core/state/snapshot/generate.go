@@ -115,6 +115,17 @@ func journalProgress(db ethdb.KeyValueWriter, marker []byte, stats *generatorSta
 	rawdb.WriteSnapshotGenerator(db, blob)
 }
 
+// MarkComplete marks the flat state stored in the database as a complete snapshot
+// of the state with the given root, e.g. after importing it from an external
+// source. Any journalled diff layers are discarded.
+func MarkComplete(db ethdb.KeyValueWriter, root common.Hash) {
+	rawdb.DeleteSnapshotDisabled(db)
+	rawdb.DeleteSnapshotJournal(db)
+	rawdb.DeleteSnapshotRecoveryNumber(db)
+	rawdb.WriteSnapshotRoot(db, root)
+	journalProgress(db, nil, nil)
+}
+
 // proofResult contains the output of range proving which can be used
 // for further processing regardless if it is successful or not.
 type proofResult struct {
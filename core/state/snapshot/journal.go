@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"time"
 
 	"github.com/VictoriaMetrics/fastcache"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/cachejournal"
 )
 
 const journalVersion uint64 = 0
@@ -361,3 +363,45 @@ func iterateJournal(db ethdb.KeyValueReader, callback journalCallback) error {
 		parent = root
 	}
 }
+
+// loadCache attaches the hot entry tracker to the disk layer and warms up its
+// cache with the entries of the cache journal, if it was created for the same
+// disk layer.
+func (t *Tree) loadCache() {
+	dl := t.disklayer()
+	if dl == nil {
+		return
+	}
+	dl.hot = t.hot
+
+	start := time.Now()
+	entries, size, err := cachejournal.Load(t.config.CacheJournal, dl.root, 0, dl.cache)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return
+	case errors.Is(err, cachejournal.ErrStale):
+		snapshotCleanJournalDroppedGauge.Update(int64(size))
+		log.Info("Dropped stale snapshot cache journal", "entries", entries, "size", common.StorageSize(size), "err", err)
+	case err != nil:
+		log.Warn("Failed to load snapshot cache journal", "err", err)
+	default:
+		snapshotCleanJournalLoadedGauge.Update(int64(size))
+		log.Info("Loaded snapshot cache journal", "entries", entries, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// journalCache persists the hot entries of the disk layer cache.
+func (t *Tree) journalCache() {
+	dl := t.disklayer()
+	if dl == nil {
+		return
+	}
+	start := time.Now()
+	entries, size, err := cachejournal.Save(t.config.CacheJournal, dl.root, 0, dl.cache, t.hot)
+	if err != nil {
+		log.Warn("Failed to journal snapshot cache", "err", err)
+		return
+	}
+	snapshotCleanJournalSavedGauge.Update(int64(size))
+	log.Info("Persisted snapshot cache journal", "entries", entries, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(start)))
+}
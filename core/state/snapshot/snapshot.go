@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/cachejournal"
 )
 
 var (
@@ -46,6 +47,10 @@ var (
 	snapshotCleanStorageReadMeter  = metrics.NewRegisteredMeter("state/snapshot/clean/storage/read", nil)
 	snapshotCleanStorageWriteMeter = metrics.NewRegisteredMeter("state/snapshot/clean/storage/write", nil)
 
+	snapshotCleanJournalSavedGauge   = metrics.NewRegisteredGauge("state/snapshot/clean/journal/saved", nil)
+	snapshotCleanJournalLoadedGauge  = metrics.NewRegisteredGauge("state/snapshot/clean/journal/loaded", nil)
+	snapshotCleanJournalDroppedGauge = metrics.NewRegisteredGauge("state/snapshot/clean/journal/dropped", nil)
+
 	snapshotDirtyAccountHitMeter   = metrics.NewRegisteredMeter("state/snapshot/dirty/account/hit", nil)
 	snapshotDirtyAccountMissMeter  = metrics.NewRegisteredMeter("state/snapshot/dirty/account/miss", nil)
 	snapshotDirtyAccountInexMeter  = metrics.NewRegisteredMeter("state/snapshot/dirty/account/inex", nil)
@@ -150,10 +155,12 @@ type snapshot interface {
 
 // Config includes the configurations for snapshots.
 type Config struct {
-	CacheSize  int  // Megabytes permitted to use for read caches
-	Recovery   bool // Indicator that the snapshots is in the recovery mode
-	NoBuild    bool // Indicator that the snapshots generation is disallowed
-	AsyncBuild bool // The snapshot generation is allowed to be constructed asynchronously
+	CacheSize        int    // Megabytes permitted to use for read caches
+	CacheJournal     string // File to persist the hot cache entries across restarts (empty = disabled)
+	CacheJournalSize int    // Megabytes of hot cache entries to persist
+	Recovery         bool   // Indicator that the snapshots is in the recovery mode
+	NoBuild          bool   // Indicator that the snapshots generation is disallowed
+	AsyncBuild       bool   // The snapshot generation is allowed to be constructed asynchronously
 }
 
 // Tree is an Ethereum state snapshot tree. It consists of one persistent base
@@ -170,6 +177,7 @@ type Tree struct {
 	diskdb ethdb.KeyValueStore      // Persistent database to store the snapshot
 	triedb *triedb.Database         // In-memory cache to access the trie through
 	layers map[common.Hash]snapshot // Collection of all known layers
+	hot    *cachejournal.Tracker    // Tracker of the hot disk layer cache entries, nil if disabled
 	lock   sync.RWMutex
 
 	// Test hooks
@@ -200,6 +208,9 @@ func New(config Config, diskdb ethdb.KeyValueStore, triedb *triedb.Database, roo
 		triedb: triedb,
 		layers: make(map[common.Hash]snapshot),
 	}
+	if config.CacheJournal != "" {
+		snap.hot = cachejournal.NewTracker(config.CacheJournalSize * 1024 * 1024)
+	}
 	// Attempt to load a previously persisted snapshot and rebuild one if failed
 	head, disabled, err := loadSnapshot(diskdb, triedb, root, config.CacheSize, config.Recovery, config.NoBuild)
 	if disabled {
@@ -223,6 +234,10 @@ func New(config Config, diskdb ethdb.KeyValueStore, triedb *triedb.Database, roo
 		snap.layers[head.Root()] = head
 		head = head.Parent()
 	}
+	// Warm up the disk layer cache with the hot entries of the last run
+	if snap.hot != nil {
+		snap.loadCache()
+	}
 	return snap, nil
 }
 
@@ -648,6 +663,7 @@ func diffToDisk(bottom *diffLayer) *diskLayer {
 		cache:      base.cache,
 		diskdb:     base.diskdb,
 		triedb:     base.triedb,
+		hot:        base.hot,
 		genMarker:  base.genMarker,
 		genPending: base.genPending,
 	}
@@ -711,6 +727,12 @@ func (t *Tree) Journal(root common.Hash) (common.Hash, error) {
 	}
 	// Store the journal into the database and return
 	rawdb.WriteSnapshotJournal(t.diskdb, journal.Bytes())
+
+	// Persist the hot disk layer cache entries too, they can be reused as long
+	// as the disk layer is not changed.
+	if t.hot != nil {
+		t.journalCache()
+	}
 	return base, nil
 }
 
@@ -754,8 +776,13 @@ func (t *Tree) Rebuild(root common.Hash) {
 	// Start generating a new snapshot from scratch on a background thread. The
 	// generator will run a wiper first if there's not one running right now.
 	log.Info("Rebuilding state snapshot")
+	base := generateSnapshot(t.diskdb, t.triedb, t.config.CacheSize, root)
+	if t.hot != nil {
+		t.hot.Reset()
+		base.hot = t.hot
+	}
 	t.layers = map[common.Hash]snapshot{
-		root: generateSnapshot(t.diskdb, t.triedb, t.config.CacheSize, root),
+		root: base,
 	}
 }
 
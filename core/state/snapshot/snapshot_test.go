@@ -17,10 +17,12 @@
 package snapshot
 
 import (
+	"bytes"
 	crand "crypto/rand"
 	"encoding/binary"
 	"fmt"
 	"math/rand"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -489,3 +491,50 @@ func TestReadStateDuringFlattening(t *testing.T) {
 		t.Fatal("Unexpected blocker")
 	}
 }
+
+// Tests that the hot entries of the disk layer cache, including the ones for
+// absent accounts, survive a restart if the cache journal is enabled.
+func TestCacheJournal(t *testing.T) {
+	helper := newHelper(rawdb.HashScheme)
+	for i := 0; i < 16; i++ {
+		helper.addTrieAccount(fmt.Sprintf("acc-%d", i), &types.StateAccount{Balance: uint256.NewInt(uint64(i)), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	}
+	root := helper.Commit()
+
+	config := Config{
+		CacheSize:        16,
+		CacheJournal:     filepath.Join(t.TempDir(), "snapcache"),
+		CacheJournalSize: 1,
+	}
+	snaps, err := New(config, helper.diskdb, helper.triedb, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		present = hashData([]byte("acc-3"))
+		absent  = hashData([]byte("acc-missing"))
+	)
+	for _, hash := range []common.Hash{present, absent} {
+		if _, err := snaps.Snapshot(root).AccountRLP(hash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := snaps.Journal(root); err != nil {
+		t.Fatal(err)
+	}
+	snaps.Release()
+
+	// Reopen the snapshot, the accessed entries should be cached
+	if snaps, err = New(config, helper.diskdb, helper.triedb, root); err != nil {
+		t.Fatal(err)
+	}
+	cache := snaps.disklayer().cache
+	for _, hash := range []common.Hash{present, absent} {
+		if !cache.Has(hash[:]) {
+			t.Fatalf("entry %x not loaded", hash)
+		}
+	}
+	if blob := cache.Get(nil, present[:]); !bytes.Equal(blob, rawdb.ReadAccountSnapshot(helper.diskdb, present)) {
+		t.Fatalf("entry %x mismatch", present)
+	}
+}
@@ -147,6 +147,10 @@ type StateDB struct {
 	validRevisions []revision
 	nextRevisionId int
 
+	// Original versions of the accounts changed since the last checkpoint,
+	// for reverting changes across transactions
+	checkpoint *checkpoint
+
 	// State witness if cross validation is needed
 	witness *stateless.Witness
 
@@ -257,6 +261,9 @@ func (s *StateDB) Error() error {
 
 func (s *StateDB) AddLog(log *types.Log) {
 	s.journal.append(addLogChange{txhash: s.thash})
+	if s.checkpoint != nil {
+		s.checkpoint.trackLogs(s, s.thash)
+	}
 
 	log.TxHash = s.thash
 	log.TxIndex = uint(s.txIndex)
@@ -585,6 +592,10 @@ func (s *StateDB) deleteStateObject(addr common.Address) {
 // getStateObject retrieves a state object given by the address, returning nil if
 // the object is not found or was deleted in this execution context.
 func (s *StateDB) getStateObject(addr common.Address) *stateObject {
+	// Retain the original object if it might be changed after a checkpoint
+	if s.checkpoint != nil {
+		s.checkpoint.track(s, addr)
+	}
 	// Prefer live objects if any is available
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
@@ -662,6 +673,9 @@ func (s *StateDB) getOrNewStateObject(addr common.Address) *stateObject {
 // createObject creates a new state object. The assumption is held there is no
 // existing account with the given address, otherwise it will be silently overwritten.
 func (s *StateDB) createObject(addr common.Address) *stateObject {
+	if s.checkpoint != nil {
+		s.checkpoint.track(s, addr)
+	}
 	obj := newObject(s, addr, nil)
 	s.journal.append(createObjectChange{account: &addr})
 	s.setStateObject(obj)
@@ -835,8 +849,10 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 // It is called in between transactions to get the root hash that
 // goes into transaction receipts.
 func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
-	// Finalise all the dirty storage states and write them into the tries
+	// Finalise all the dirty storage states and write them into the tries,
+	// which cannot be reverted to a checkpoint anymore
 	s.Finalise(deleteEmptyObjects)
+	s.checkpoint = nil
 
 	// If there was a trie prefetcher operating, terminate it async so that the
 	// individual storage tries can be updated as soon as the disk load finishes.
@@ -707,6 +707,57 @@ func TestTouchDelete(t *testing.T) {
 	}
 }
 
+// Tests that checkpoints revert the changes of multiple finalised transactions.
+func TestCheckpoint(t *testing.T) {
+	var (
+		state, _ = New(types.EmptyRootHash, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		a        = common.Address{0xaa}
+		b        = common.Address{0xbb}
+		c        = common.Address{0xcc}
+		slot     = common.Hash{0x01}
+	)
+	state.SetBalance(a, uint256.NewInt(100), tracing.BalanceChangeUnspecified)
+	state.SetState(a, slot, common.Hash{0x01})
+	state.SetBalance(b, uint256.NewInt(200), tracing.BalanceChangeUnspecified)
+	state.SetCode(b, []byte{0x01})
+	root := state.IntermediateRoot(true)
+
+	// Apply a few transactions on top of a checkpoint and revert them
+	state.Checkpoint()
+
+	state.SetTxContext(common.Hash{0x01}, 0)
+	state.AddBalance(a, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	state.SetState(a, slot, common.Hash{0x02})
+	state.CreateAccount(c)
+	state.SetBalance(c, uint256.NewInt(300), tracing.BalanceChangeUnspecified)
+	state.AddLog(&types.Log{Address: a})
+	state.Finalise(true)
+
+	state.SetTxContext(common.Hash{0x02}, 1)
+	state.SelfDestruct(b)
+	state.SetNonce(a, 1)
+	state.AddLog(&types.Log{Address: b})
+	state.Finalise(true)
+
+	state.RevertToCheckpoint()
+
+	if have := state.IntermediateRoot(true); have != root {
+		t.Fatalf("state root mismatch: have %x, want %x", have, root)
+	}
+	if have := state.GetState(a, slot); have != (common.Hash{0x01}) {
+		t.Errorf("storage mismatch: have %x, want %x", have, common.Hash{0x01})
+	}
+	if have := state.GetBalance(b); have.Uint64() != 200 {
+		t.Errorf("balance mismatch: have %v, want %v", have, 200)
+	}
+	if state.Exist(c) {
+		t.Error("created account retained")
+	}
+	if logs := state.Logs(); len(logs) != 0 {
+		t.Errorf("logs retained: %d", len(logs))
+	}
+}
+
 // TestCopyOfCopy tests that modified objects are carried over to the copy, and the copy of the copy.
 // See https://github.com/ethereum/go-ethereum/pull/15225#issuecomment-380191512
 func TestCopyOfCopy(t *testing.T) {
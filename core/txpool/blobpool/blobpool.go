@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -66,6 +67,11 @@ const (
 	// limit can never hurt.
 	txMaxSize = 1024 * 1024
 
+	// txCellProofsSize is the additional byte size of each blob in a version 1
+	// sidecar, which carries the RLP encoded proofs of all the cells of the
+	// extended blob instead of a single blob proof.
+	txCellProofsSize = uint32((kzg4844.CellProofsPerBlob - 1) * (len(kzg4844.Proof{}) + 1))
+
 	// maxTxsPerAccount is the maximum number of blob transactions admitted from
 	// a single account. The limit is enforced to minimize the DoS potential of
 	// a private tx cancelling publicly propagated blobs.
@@ -313,6 +319,13 @@ type BlobPool struct {
 
 	discoverFeed event.Feed // Event feed to send out new tx events on pool discovery (reorg excluded)
 	insertFeed   event.Feed // Event feed to send out new tx events on pool inclusion (reorg included)
+	eventFeed    event.Feed // Event feed to send out tx lifecycle events
+
+	events []core.TxLifecycleEvent // Lifecycle events waiting for the pool lock to be released
+
+	converting  bool           // Whether the sidecars are being converted to cell proofs
+	convertQuit chan struct{}  // Quit channel to interrupt the sidecar conversion
+	convertWg   sync.WaitGroup // Tracks the background sidecar conversion
 
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
 }
@@ -331,6 +344,8 @@ func New(config Config, chain BlockChain) *BlobPool {
 		lookup: make(map[common.Hash]uint64),
 		index:  make(map[common.Address][]*blobTxMeta),
 		spent:  make(map[common.Address]*uint256.Int),
+
+		convertQuit: make(chan struct{}),
 	}
 }
 
@@ -378,7 +393,7 @@ func (p *BlobPool) Init(gasTip uint64, head *types.Header, reserve txpool.Addres
 			fails = append(fails, id)
 		}
 	}
-	store, err := billy.Open(billy.Options{Path: queuedir, Repair: true}, newSlotter(), index)
+	store, err := billy.Open(billy.Options{Path: queuedir, Repair: true}, newSlotter(p.chain.Config().OsakaTime != nil), index)
 	if err != nil {
 		return err
 	}
@@ -411,7 +426,7 @@ func (p *BlobPool) Init(gasTip uint64, head *types.Header, reserve txpool.Addres
 
 	// Pool initialized, attach the blob limbo to it to track blobs included
 	// recently but not yet finalized
-	p.limbo, err = newLimbo(limbodir)
+	p.limbo, err = newLimbo(limbodir, p.chain.Config().OsakaTime != nil)
 	if err != nil {
 		p.Close()
 		return err
@@ -430,11 +445,20 @@ func (p *BlobPool) Init(gasTip uint64, head *types.Header, reserve txpool.Addres
 	// Update the metrics and return the constructed pool
 	datacapGauge.Update(int64(p.config.Datacap))
 	p.updateStorageMetrics()
+
+	// If the pool is already past Osaka, make sure no transactions are left with
+	// blob proofs in case the node was shut down mid-conversion
+	if p.chain.Config().IsOsaka(p.head.Number, p.head.Time) {
+		p.startConversion()
+	}
 	return nil
 }
 
 // Close closes down the underlying persistent store.
 func (p *BlobPool) Close() error {
+	close(p.convertQuit)
+	p.convertWg.Wait()
+
 	var errs []error
 	if p.limbo != nil { // Close might be invoked due to error in constructor, before p,limbo is set
 		if err := p.limbo.Close(); err != nil {
@@ -537,6 +561,11 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			if filled && inclusions != nil {
 				p.offload(addr, txs[i].nonce, txs[i].id, inclusions)
 			}
+			if gapped {
+				p.notify(core.TxEvicted, addr, txs[i], common.Hash{}, core.ErrNonceTooHigh)
+			} else {
+				p.notifyStale(addr, txs[i], inclusions)
+			}
 		}
 		delete(p.index, addr)
 		delete(p.spent, addr)
@@ -578,6 +607,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			if inclusions != nil {
 				p.offload(addr, txs[0].nonce, txs[0].id, inclusions)
 			}
+			p.notifyStale(addr, txs[0], inclusions)
 			txs = txs[1:]
 		}
 		log.Trace("Dropping overlapped blob transactions", "from", addr, "overlapped", nonces, "ids", ids, "left", len(txs))
@@ -651,6 +681,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[j].costCap)
 			p.stored -= uint64(txs[j].size)
 			delete(p.lookup, txs[j].hash)
+			p.notify(core.TxEvicted, addr, txs[j], common.Hash{}, core.ErrNonceTooHigh)
 		}
 		txs = txs[:i]
 
@@ -689,6 +720,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], last.costCap)
 			p.stored -= uint64(last.size)
 			delete(p.lookup, last.hash)
+			p.notify(core.TxEvicted, addr, last, common.Hash{}, core.ErrInsufficientFunds)
 		}
 		if len(txs) == 0 {
 			delete(p.index, addr)
@@ -729,6 +761,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], last.costCap)
 			p.stored -= uint64(last.size)
 			delete(p.lookup, last.hash)
+			p.notify(core.TxEvicted, addr, last, common.Hash{}, txpool.ErrAccountLimitExceeded)
 		}
 		p.index[addr] = txs
 
@@ -748,6 +781,16 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 	}
 }
 
+// notifyStale queues the lifecycle event of a transaction dropped due to its nonce
+// being used up, telling apart the ones included by the chain.
+func (p *BlobPool) notifyStale(addr common.Address, meta *blobTxMeta, inclusions map[common.Hash]uint64) {
+	if _, ok := inclusions[meta.hash]; ok {
+		p.notify(core.TxIncluded, addr, meta, common.Hash{}, nil)
+		return
+	}
+	p.notify(core.TxEvicted, addr, meta, common.Hash{}, core.ErrNonceTooLow)
+}
+
 // offload removes a tracked blob transaction from the pool and moves it into the
 // limbo for tracking until finality.
 //
@@ -780,6 +823,8 @@ func (p *BlobPool) offload(addr common.Address, nonce uint64, id uint64, inclusi
 // Reset implements txpool.SubPool, allowing the blob pool's internal state to be
 // kept in sync with the main transaction pool's internal state.
 func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
+	defer p.sendEvents()
+
 	waitStart := time.Now()
 	p.lock.Lock()
 	resetwaitHist.Update(time.Since(waitStart).Nanoseconds())
@@ -794,9 +839,17 @@ func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset blobpool state", "err", err)
 		return
 	}
+	oldOsaka := oldHead != nil && p.chain.Config().IsOsaka(oldHead.Number, oldHead.Time)
+
 	p.head = newHead
 	p.state = statedb
 
+	// Cell proofs are required from Osaka on, convert all the pooled sidecars
+	// when crossing the fork
+	if !oldOsaka && p.chain.Config().IsOsaka(newHead.Number, newHead.Time) {
+		p.startConversion()
+	}
+
 	// Run the reorg between the old and new head and figure out which accounts
 	// need to be rechecked and which transactions need to be readded
 	if reinject, inclusions := p.reorg(oldHead, newHead); reinject != nil {
@@ -977,6 +1030,12 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) error {
 		log.Error("Blobs unavailable, dropping reorged tx", "err", err)
 		return err
 	}
+	// Transactions included before Osaka might be reorged out after it, convert
+	// them directly as only a few may be affected
+	if tx, err = p.convertSidecar(tx, p.head); err != nil {
+		log.Error("Failed to convert reorged blob sidecar", "hash", txhash, "err", err)
+		return err
+	}
 	// TODO: seems like an easy optimization here would be getting the serialized tx
 	// from limbo instead of re-serializing it here.
 
@@ -1007,6 +1066,7 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) error {
 		p.spent[addr] = new(uint256.Int).Add(p.spent[addr], meta.costCap)
 	}
 	p.lookup[meta.hash] = meta.id
+	p.notify(core.TxAdded, addr, meta, common.Hash{}, nil)
 	p.stored += uint64(meta.size)
 	return nil
 }
@@ -1014,6 +1074,8 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) error {
 // SetGasTip implements txpool.SubPool, allowing the blob pool's gas requirements
 // to be kept in sync with the main transaction pool's gas requirements.
 func (p *BlobPool) SetGasTip(tip *big.Int) {
+	defer p.sendEvents()
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -1034,6 +1096,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 					p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[i].costCap)
 					p.stored -= uint64(tx.size)
 					delete(p.lookup, tx.hash)
+					p.notify(core.TxEvicted, addr, tx, common.Hash{}, txpool.ErrUnderpriced)
 					txs[i] = nil
 
 					// Drop everything afterwards, no gaps allowed
@@ -1044,6 +1107,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 						p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], tx.costCap)
 						p.stored -= uint64(tx.size)
 						delete(p.lookup, tx.hash)
+						p.notify(core.TxEvicted, addr, tx, common.Hash{}, txpool.ErrUnderpriced)
 						txs[i+1+j] = nil
 					}
 					// Clear out the dropped transactions from the index
@@ -1214,6 +1278,23 @@ func (p *BlobPool) Add(txs []*types.Transaction, local bool, sync bool) []error
 		errs = make([]error, len(txs))
 	)
 	for i, tx := range txs {
+		// Blob transactions are persisted without any local metadata, so their
+		// inclusion conditions could not be tracked
+		if tx.Conditional() != nil {
+			errs[i] = txpool.ErrConditionalUnsupported
+			continue
+		}
+		// Local submissions may still carry blob proofs after Osaka, compute the
+		// cell proofs on their behalf
+		if local {
+			p.lock.RLock()
+			head := p.head
+			p.lock.RUnlock()
+
+			if tx, errs[i] = p.convertSidecar(tx, head); errs[i] != nil {
+				continue
+			}
+		}
 		errs[i] = p.add(tx)
 		if errs[i] == nil {
 			adds = append(adds, tx.WithoutBlobTxSidecar())
@@ -1223,9 +1304,34 @@ func (p *BlobPool) Add(txs []*types.Transaction, local bool, sync bool) []error
 		p.discoverFeed.Send(core.NewTxsEvent{Txs: adds})
 		p.insertFeed.Send(core.NewTxsEvent{Txs: adds})
 	}
+	p.sendEvents()
 	return errs
 }
 
+// AddPrivate implements txpool.SubPool, rejecting all transactions. Blob txs
+// are persisted and resurrected across restarts, so they can't be kept private.
+func (p *BlobPool) AddPrivate(txs []*types.Transaction, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i := range txs {
+		errs[i] = txpool.ErrPrivateUnsupported
+	}
+	return errs
+}
+
+// AddDeprioritized implements txpool.SubPool, adding the transactions like remote
+// ones, as blob transactions have no local exemptions. Private ones are rejected.
+func (p *BlobPool) AddDeprioritized(txs []*types.Transaction, private bool, sync bool) []error {
+	if private {
+		return p.AddPrivate(txs, sync)
+	}
+	return p.Add(txs, false, sync)
+}
+
+// IsPrivate implements txpool.SubPool. Blob transactions are never private.
+func (p *BlobPool) IsPrivate(hash common.Hash) bool {
+	return false
+}
+
 // add inserts a new blob transaction into the pool if it passes validation (both
 // consensus validity and pool restrictions).
 func (p *BlobPool) add(tx *types.Transaction) (err error) {
@@ -1322,6 +1428,8 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 		delete(p.lookup, prev.hash)
 		p.lookup[meta.hash] = meta.id
 		p.stored += uint64(meta.size) - uint64(prev.size)
+
+		p.notify(core.TxReplaced, from, prev, meta.hash, nil)
 	} else {
 		// Transaction extends previously scheduled ones
 		p.index[from] = append(p.index[from], meta)
@@ -1333,6 +1441,8 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 		p.lookup[meta.hash] = meta.id
 		p.stored += uint64(meta.size)
 	}
+	p.notify(core.TxAdded, from, meta, common.Hash{}, nil)
+
 	// Recompute the rolling eviction fields. In case of a replacement, this will
 	// recompute all subsequent fields. In case of an append, this will only do
 	// the fresh calculation.
@@ -1420,6 +1530,7 @@ func (p *BlobPool) drop() {
 	}
 	p.stored -= uint64(drop.size)
 	delete(p.lookup, drop.hash)
+	p.notify(core.TxEvicted, from, drop, common.Hash{}, txpool.ErrUnderpriced)
 
 	// Remove the transaction from the pool's eviction heap:
 	//   - If the entire account was dropped, pop off the address
@@ -1525,24 +1636,21 @@ func (p *BlobPool) updateStorageMetrics() {
 		oversizedSlotused uint64
 		oversizedSlotgaps uint64
 	)
-	for _, shelf := range stats.Shelves {
-		slotDataused := shelf.FilledSlots * uint64(shelf.SlotSize)
-		slotDatagaps := shelf.GappedSlots * uint64(shelf.SlotSize)
-
-		dataused += slotDataused
-		datareal += slotDataused + slotDatagaps
-		slotused += shelf.FilledSlots
-
-		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfDatausedGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(slotDataused))
-		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfDatagapsGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(slotDatagaps))
-		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfSlotusedGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(shelf.FilledSlots))
-		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfSlotgapsGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(shelf.GappedSlots))
-
-		if shelf.SlotSize/blobSize > maxBlobsPerTransaction {
-			oversizedDataused += slotDataused
-			oversizedDatagaps += slotDatagaps
-			oversizedSlotused += shelf.FilledSlots
-			oversizedSlotgaps += shelf.GappedSlots
+	for blobs, shelf := range groupShelves(stats.Shelves) {
+		dataused += shelf.dataused
+		datareal += shelf.dataused + shelf.datagaps
+		slotused += shelf.slotused
+
+		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfDatausedGaugeName, blobs), nil).Update(int64(shelf.dataused))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfDatagapsGaugeName, blobs), nil).Update(int64(shelf.datagaps))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfSlotusedGaugeName, blobs), nil).Update(int64(shelf.slotused))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(shelfSlotgapsGaugeName, blobs), nil).Update(int64(shelf.slotgaps))
+
+		if blobs > maxBlobsPerTransaction {
+			oversizedDataused += shelf.dataused
+			oversizedDatagaps += shelf.datagaps
+			oversizedSlotused += shelf.slotused
+			oversizedSlotgaps += shelf.slotgaps
 		}
 	}
 	datausedGauge.Update(int64(dataused))
@@ -1567,24 +1675,47 @@ func (p *BlobPool) updateLimboMetrics() {
 		datareal uint64
 		slotused uint64
 	)
-	for _, shelf := range stats.Shelves {
-		slotDataused := shelf.FilledSlots * uint64(shelf.SlotSize)
-		slotDatagaps := shelf.GappedSlots * uint64(shelf.SlotSize)
+	for blobs, shelf := range groupShelves(stats.Shelves) {
+		dataused += shelf.dataused
+		datareal += shelf.dataused + shelf.datagaps
+		slotused += shelf.slotused
 
-		dataused += slotDataused
-		datareal += slotDataused + slotDatagaps
-		slotused += shelf.FilledSlots
-
-		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfDatausedGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(slotDataused))
-		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfDatagapsGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(slotDatagaps))
-		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfSlotusedGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(shelf.FilledSlots))
-		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfSlotgapsGaugeName, shelf.SlotSize/blobSize), nil).Update(int64(shelf.GappedSlots))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfDatausedGaugeName, blobs), nil).Update(int64(shelf.dataused))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfDatagapsGaugeName, blobs), nil).Update(int64(shelf.datagaps))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfSlotusedGaugeName, blobs), nil).Update(int64(shelf.slotused))
+		metrics.GetOrRegisterGauge(fmt.Sprintf(limboShelfSlotgapsGaugeName, blobs), nil).Update(int64(shelf.slotgaps))
 	}
 	limboDatausedGauge.Update(int64(dataused))
 	limboDatarealGauge.Update(int64(datareal))
 	limboSlotusedGauge.Update(int64(slotused))
 }
 
+// shelfStats is the aggregated usage of the shelves sized for the same blob count.
+type shelfStats struct {
+	dataused uint64
+	datagaps uint64
+	slotused uint64
+	slotgaps uint64
+}
+
+// groupShelves aggregates the usage of the store shelves by the blob count they
+// are sized for, merging the blob and cell proof shelves of the same blob count.
+func groupShelves(shelves []*billy.ShelfInfos) map[uint32]*shelfStats {
+	groups := make(map[uint32]*shelfStats)
+	for _, shelf := range shelves {
+		blobs := shelf.SlotSize / blobSize
+		if groups[blobs] == nil {
+			groups[blobs] = new(shelfStats)
+		}
+		group := groups[blobs]
+		group.dataused += shelf.FilledSlots * uint64(shelf.SlotSize)
+		group.datagaps += shelf.GappedSlots * uint64(shelf.SlotSize)
+		group.slotused += shelf.FilledSlots
+		group.slotgaps += shelf.GappedSlots
+	}
+	return groups
+}
+
 // SubscribeTransactions registers a subscription for new transaction events,
 // supporting feeding only newly seen or also resurrected transactions.
 func (p *BlobPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
@@ -1595,6 +1726,38 @@ func (p *BlobPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool
 	}
 }
 
+// SubscribeLifecycleEvents registers a subscription for the state changes of the
+// pooled transactions, from their addition until their inclusion or eviction.
+func (p *BlobPool) SubscribeLifecycleEvents(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return p.eventFeed.Subscribe(ch)
+}
+
+// notify queues a lifecycle event of a transaction, to be sent out once the pool
+// lock is released. The pool lock must be held.
+func (p *BlobPool) notify(typ core.TxLifecycle, from common.Address, meta *blobTxMeta, replacement common.Hash, reason error) {
+	p.events = append(p.events, core.TxLifecycleEvent{
+		Type:        typ,
+		Hash:        meta.hash,
+		From:        from,
+		Nonce:       meta.nonce,
+		Replacement: replacement,
+		Reason:      reason,
+	})
+}
+
+// sendEvents sends out the lifecycle events queued since the previous call. The
+// pool lock must not be held.
+func (p *BlobPool) sendEvents() {
+	p.lock.Lock()
+	events := p.events
+	p.events = nil
+	p.lock.Unlock()
+
+	for _, event := range events {
+		p.eventFeed.Send(event)
+	}
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (p *BlobPool) Nonce(addr common.Address) uint64 {
@@ -1646,6 +1809,92 @@ func (p *BlobPool) Locals() []common.Address {
 	return []common.Address{}
 }
 
+// SetLocals replaces the set of accounts considered local by the pool.
+//
+// There is no notion of local accounts in the blob pool.
+func (p *BlobPool) SetLocals(addrs []common.Address) {}
+
+// Remove evicts a transaction from the pool, returning whether it was found.
+//
+// Since the blob pool does not allow nonce gaps, all the subsequent transactions
+// of the sender are also dropped.
+func (p *BlobPool) Remove(hash common.Hash) bool {
+	defer p.sendEvents()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.lookup[hash]; !ok {
+		return false
+	}
+	for addr, txs := range p.index {
+		for i, tx := range txs {
+			if tx.hash == hash {
+				p.dropFrom(addr, i, txpool.ErrRemoved)
+				p.updateStorageMetrics()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveSender evicts all the transactions of an account from the pool,
+// returning the number of transactions removed.
+func (p *BlobPool) RemoveSender(addr common.Address) int {
+	defer p.sendEvents()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.index[addr]; !ok {
+		return 0
+	}
+	dropped := p.dropFrom(addr, 0, txpool.ErrRemoved)
+	p.updateStorageMetrics()
+	return dropped
+}
+
+// dropFrom removes all the transactions of an account starting at the given
+// index, cleaning up all the associated metadata and deleting the transactions
+// from the data store. The number of dropped transactions is returned.
+//
+// The method assumes the pool lock is held.
+func (p *BlobPool) dropFrom(addr common.Address, i int, reason error) int {
+	var (
+		txs = p.index[addr]
+		ids = make([]uint64, 0, len(txs)-i)
+	)
+	for j, tx := range txs[i:] {
+		ids = append(ids, tx.id)
+
+		p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], tx.costCap)
+		p.stored -= uint64(tx.size)
+		delete(p.lookup, tx.hash)
+		p.notify(core.TxEvicted, addr, tx, common.Hash{}, reason)
+		txs[i+j] = nil
+	}
+	// Clear out the dropped transactions from the index
+	if i > 0 {
+		p.index[addr] = txs[:i]
+		heap.Fix(p.evict, p.evict.index[addr])
+	} else {
+		delete(p.index, addr)
+		delete(p.spent, addr)
+
+		heap.Remove(p.evict, p.evict.index[addr])
+		p.reserve(addr, false)
+	}
+	// Clear out the transactions from the data store
+	log.Info("Dropping blob transactions", "from", addr, "ids", ids, "reason", reason)
+	for _, id := range ids {
+		if err := p.store.Delete(id); err != nil {
+			log.Error("Failed to delete dropped transaction", "id", id, "err", err)
+		}
+	}
+	return len(ids)
+}
+
 // Status returns the known status (unknown/pending/queued) of a transaction
 // identified by their hashes.
 func (p *BlobPool) Status(hash common.Hash) txpool.TxStatus {
@@ -44,6 +44,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/holiman/billy"
 	"github.com/holiman/uint256"
 )
@@ -74,6 +75,7 @@ type testBlockChain struct {
 	basefee *uint256.Int
 	blobfee *uint256.Int
 	statedb *state.StateDB
+	blocks  map[common.Hash]*types.Block
 }
 
 func (bc *testBlockChain) Config() *params.ChainConfig {
@@ -144,7 +146,7 @@ func (bc *testBlockChain) CurrentFinalBlock() *types.Header {
 }
 
 func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
-	return nil
+	return bc.blocks[hash]
 }
 
 func (bc *testBlockChain) StateAt(common.Hash) (*state.StateDB, error) {
@@ -324,7 +326,7 @@ func TestOpenDrops(t *testing.T) {
 	defer os.RemoveAll(storage)
 
 	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
-	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(), nil)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(false), nil)
 
 	// Insert a malformed transaction to verify that decoding errors (or format
 	// changes) are handled gracefully (case 1)
@@ -647,7 +649,7 @@ func TestOpenIndex(t *testing.T) {
 	defer os.RemoveAll(storage)
 
 	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
-	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(), nil)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(false), nil)
 
 	// Insert a sequence of transactions with varying price points to check that
 	// the cumulative minimum will be maintained.
@@ -736,7 +738,7 @@ func TestOpenHeap(t *testing.T) {
 	defer os.RemoveAll(storage)
 
 	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
-	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(), nil)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(false), nil)
 
 	// Insert a few transactions from a few accounts. To remove randomness from
 	// the heap initialization, use a deterministic account/tx/priority ordering.
@@ -813,6 +815,212 @@ func TestOpenHeap(t *testing.T) {
 	verifyPoolInternals(t, pool)
 }
 
+// Tests that transactions can be explicitly removed from the pool, dropping any
+// subsequent transactions of the sender to avoid nonce gaps.
+func TestRemove(t *testing.T) {
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelTrace, true)))
+
+	// Create a temporary folder for the persistent backend
+	storage, _ := os.MkdirTemp("", "blobpool-")
+	defer os.RemoveAll(storage)
+
+	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(false), nil)
+
+	// Insert a few transactions from a couple of accounts
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+
+		addr1 = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2 = crypto.PubkeyToAddress(key2.PublicKey)
+
+		txs1 = []*types.Transaction{makeTx(0, 1, 1000, 100, key1), makeTx(1, 1, 1000, 100, key1), makeTx(2, 1, 1000, 100, key1)}
+		txs2 = []*types.Transaction{makeTx(0, 1, 1000, 100, key2), makeTx(1, 1, 1000, 100, key2)}
+	)
+	for _, tx := range append(txs1, txs2...) {
+		blob, _ := rlp.EncodeToBytes(tx)
+		store.Put(blob)
+	}
+	store.Close()
+
+	// Create a blob pool out of the pre-seeded data
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewDatabase(memorydb.New())), nil)
+	statedb.AddBalance(addr1, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.AddBalance(addr2, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.Commit(0, true)
+
+	chain := &testBlockChain{
+		config:  testChainConfig,
+		basefee: uint256.NewInt(1050),
+		blobfee: uint256.NewInt(105),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: storage}, chain)
+	if err := pool.Init(1, chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	// Removing a transaction drops all the subsequent ones from the sender
+	if pool.Remove(common.Hash{0x01}) {
+		t.Errorf("removed unknown transaction")
+	}
+	if !pool.Remove(txs1[1].Hash()) {
+		t.Errorf("failed to remove transaction")
+	}
+	if len(pool.index[addr1]) != 1 {
+		t.Errorf("sender transaction count mismatch: have %d, want %d", len(pool.index[addr1]), 1)
+	}
+	for _, tx := range txs1[1:] {
+		if pool.Has(tx.Hash()) {
+			t.Errorf("removed transaction %x still in pool", tx.Hash())
+		}
+	}
+	verifyPoolInternals(t, pool)
+
+	// Removing a sender drops all its transactions
+	if removed := pool.RemoveSender(addr2); removed != len(txs2) {
+		t.Errorf("removed transaction count mismatch: have %d, want %d", removed, len(txs2))
+	}
+	if removed := pool.RemoveSender(addr2); removed != 0 {
+		t.Errorf("removed transaction count mismatch: have %d, want %d", removed, 0)
+	}
+	if _, ok := pool.index[addr2]; ok {
+		t.Errorf("removed sender still indexed")
+	}
+	verifyPoolInternals(t, pool)
+}
+
+// Tests that the sidecars of the pooled transactions are converted to cell
+// proofs past Osaka, both when opening the pool and when crossing the fork, that
+// reorged transactions are converted on reinjection, and that only local
+// transactions are converted on entry.
+func TestSidecarConversion(t *testing.T) {
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelTrace, true)))
+
+	// Create a temporary folder for the persistent backend
+	storage, _ := os.MkdirTemp("", "blobpool-")
+	defer os.RemoveAll(storage)
+
+	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(true), nil)
+
+	// Insert a transaction with blob proofs, as stored before the fork
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		stored = makeTx(0, 1, 1000, 100, key)
+	)
+	blob, _ := rlp.EncodeToBytes(stored)
+	store.Put(blob)
+	store.Close()
+
+	// Create a blob pool past Osaka out of the pre-seeded data
+	config := *testChainConfig
+	config.OsakaTime = testChainConfig.CancunTime
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewDatabase(memorydb.New())), nil)
+	statedb.AddBalance(addr, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.Commit(0, true)
+
+	chain := &testBlockChain{
+		config:  &config,
+		basefee: uint256.NewInt(1050),
+		blobfee: uint256.NewInt(105),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: storage}, chain)
+	if err := pool.Init(1, chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	pool.convertWg.Wait()
+	verifyPoolInternals(t, pool)
+
+	checkConverted := func(hash common.Hash) {
+		t.Helper()
+
+		tx := pool.Get(hash)
+		if tx == nil {
+			t.Fatalf("transaction %x missing from pool", hash)
+		}
+		sidecar := tx.BlobTxSidecar()
+		if sidecar.Version != types.BlobSidecarVersion1 {
+			t.Fatalf("sidecar version mismatch: have %d, want %d", sidecar.Version, types.BlobSidecarVersion1)
+		}
+		if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+			t.Fatalf("invalid cell proofs: %v", err)
+		}
+	}
+	checkConverted(stored.Hash())
+
+	// Remote transactions with blob proofs are rejected, local ones are converted
+	tx := makeTx(1, 1, 1000, 100, key)
+	if errs := pool.Add([]*types.Transaction{tx}, false, true); !errors.Is(errs[0], txpool.ErrSidecarVersion) {
+		t.Fatalf("remote transaction error mismatch: have %v, want %v", errs[0], txpool.ErrSidecarVersion)
+	}
+	if errs := pool.Add([]*types.Transaction{tx}, true, true); errs[0] != nil {
+		t.Fatalf("failed to add local transaction: %v", errs[0])
+	}
+	checkConverted(tx.Hash())
+	verifyPoolInternals(t, pool)
+
+	// Create a blob pool before Osaka and add a transaction with blob proofs
+	storage, _ = os.MkdirTemp("", "blobpool-")
+	defer os.RemoveAll(storage)
+
+	osaka := *testChainConfig.CancunTime + 2
+	config.OsakaTime = &osaka
+
+	chain.blocks = make(map[common.Hash]*types.Block)
+	pool = New(Config{Datadir: storage}, chain)
+	if err := pool.Init(1, chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	pending := makeTx(0, 1, 1000, 100, key)
+	if errs := pool.Add([]*types.Transaction{pending}, false, true); errs[0] != nil {
+		t.Fatalf("failed to add remote transaction: %v", errs[0])
+	}
+	if sidecar := pool.Get(pending.Hash()).BlobTxSidecar(); sidecar.Version != types.BlobSidecarVersion0 {
+		t.Fatalf("sidecar converted before Osaka: version %d", sidecar.Version)
+	}
+	// Include a transaction with blob proofs before Osaka, then reorg it out by
+	// a sibling block past Osaka
+	var (
+		head     = chain.CurrentBlock()
+		included = makeTx(1, 1, 1000, 100, key)
+	)
+	parent := types.NewBlockWithHeader(head)
+	chain.blocks[parent.Hash()] = parent
+
+	header := types.CopyHeader(head)
+	header.ParentHash = parent.Hash()
+	header.Number = new(big.Int).Add(head.Number, common.Big1)
+	header.Time = osaka - 1
+	oldBlock := types.NewBlock(header, &types.Body{Transactions: []*types.Transaction{included.WithoutBlobTxSidecar()}}, nil, trie.NewStackTrie(nil))
+	chain.blocks[oldBlock.Hash()] = oldBlock
+
+	header = types.CopyHeader(header)
+	header.Time = osaka
+	newBlock := types.NewBlockWithHeader(header)
+	chain.blocks[newBlock.Hash()] = newBlock
+
+	if err := pool.limbo.push(included, oldBlock.NumberU64()); err != nil {
+		t.Fatalf("failed to push transaction into limbo: %v", err)
+	}
+	// Crossing the fork converts the pooled transaction and the reinjected one
+	pool.Reset(oldBlock.Header(), newBlock.Header())
+	pool.convertWg.Wait()
+
+	checkConverted(pending.Hash())
+	checkConverted(included.Hash())
+	verifyPoolInternals(t, pool)
+}
+
 // Tests that after the pool's previous state is loaded back, any transactions
 // over the new storage cap will get dropped.
 func TestOpenCap(t *testing.T) {
@@ -823,7 +1031,7 @@ func TestOpenCap(t *testing.T) {
 	defer os.RemoveAll(storage)
 
 	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
-	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(), nil)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(false), nil)
 
 	// Insert a few transactions from a few accounts
 	var (
@@ -1259,7 +1467,7 @@ func TestAdd(t *testing.T) {
 		defer os.RemoveAll(storage) // late defer, still ok
 
 		os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
-		store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(), nil)
+		store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(false), nil)
 
 		// Insert the seed transactions for the pool startup
 		var (
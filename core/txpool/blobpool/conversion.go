@@ -0,0 +1,171 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blobpool
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// convertSidecar returns the transaction with its sidecar converted to version 1
+// if the given head is past the Osaka fork and the sidecar is still of version
+// 0. Otherwise the transaction is returned unmodified.
+//
+// Computing the cell proofs is expensive, so the method should not be called
+// with the pool lock held unless the number of transactions is small.
+func (p *BlobPool) convertSidecar(tx *types.Transaction, head *types.Header) (*types.Transaction, error) {
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil || sidecar.Version != types.BlobSidecarVersion0 {
+		return tx, nil
+	}
+	if !p.chain.Config().IsOsaka(head.Number, head.Time) {
+		return tx, nil
+	}
+	sidecar = sidecar.Copy()
+	if err := sidecar.ToV1(); err != nil {
+		return nil, err
+	}
+	return tx.WithBlobTxSidecar(sidecar), nil
+}
+
+// startConversion launches a background conversion of the sidecars of all the
+// pooled transactions to version 1, if none is running yet.
+//
+// The method assumes the pool lock is held.
+func (p *BlobPool) startConversion() {
+	if p.converting {
+		return
+	}
+	p.converting = true
+
+	hashes := make([]common.Hash, 0, len(p.lookup))
+	for hash := range p.lookup {
+		hashes = append(hashes, hash)
+	}
+	p.convertWg.Add(1)
+	go p.convertSidecars(hashes)
+}
+
+// convertSidecars converts the sidecars of the given pooled transactions to
+// version 1 one by one, only holding the pool lock while accessing the store,
+// not while computing the cell proofs.
+func (p *BlobPool) convertSidecars(hashes []common.Hash) {
+	defer p.convertWg.Done()
+	defer func() {
+		p.lock.Lock()
+		p.converting = false
+		p.lock.Unlock()
+	}()
+
+	var (
+		start     = time.Now()
+		converted int
+		logged    = time.Now()
+	)
+	log.Info("Converting pooled blob sidecars to cell proofs", "txs", len(hashes))
+	for i, hash := range hashes {
+		select {
+		case <-p.convertQuit:
+			log.Info("Blob sidecar conversion interrupted", "converted", converted, "left", len(hashes)-i)
+			return
+		default:
+		}
+		if p.convertStored(hash) {
+			converted++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Converting pooled blob sidecars to cell proofs", "done", i+1, "left", len(hashes)-i-1, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Converted pooled blob sidecars to cell proofs", "converted", converted, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// convertStored converts the sidecar of a single pooled transaction to version
+// 1, replacing its stored entry. False is returned if the transaction did not
+// need converting, or was dropped or replaced while being converted.
+func (p *BlobPool) convertStored(hash common.Hash) bool {
+	p.lock.RLock()
+	id, ok := p.lookup[hash]
+	if !ok {
+		p.lock.RUnlock()
+		return false
+	}
+	head := p.head
+	data, err := p.store.Get(id)
+	p.lock.RUnlock()
+
+	if err != nil {
+		log.Error("Tracked blob transaction missing from store", "hash", hash, "id", id, "err", err)
+		return false
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(data, tx); err != nil {
+		log.Error("Blobs corrupted for traced transaction", "hash", hash, "id", id, "err", err)
+		return false
+	}
+	conv, err := p.convertSidecar(tx, head)
+	if err != nil {
+		log.Warn("Failed to convert blob sidecar", "hash", hash, "err", err)
+		return false
+	}
+	if conv == tx {
+		return false
+	}
+	blob, err := rlp.EncodeToBytes(conv)
+	if err != nil {
+		log.Error("Failed to encode transaction for storage", "hash", hash, "err", err)
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// The transaction might have been dropped or replaced while converting
+	if cur, ok := p.lookup[hash]; !ok || cur != id {
+		return false
+	}
+	from, _ := types.Sender(p.signer, tx) // already validated above
+	var meta *blobTxMeta
+	for _, m := range p.index[from] {
+		if m.hash == hash {
+			meta = m
+			break
+		}
+	}
+	if meta == nil {
+		log.Error("Blob transaction missing from index", "hash", hash, "from", from)
+		return false
+	}
+	newid, err := p.store.Put(blob)
+	if err != nil {
+		log.Error("Failed to write converted transaction into storage", "hash", hash, "err", err)
+		return false
+	}
+	if err := p.store.Delete(id); err != nil {
+		log.Error("Failed to delete unconverted transaction", "hash", hash, "id", id, "err", err)
+	}
+	size := p.store.Size(newid)
+	p.stored += uint64(size) - uint64(meta.size)
+
+	meta.id, meta.size = newid, size
+	p.lookup[hash] = newid
+	return true
+}
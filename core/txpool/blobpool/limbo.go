@@ -47,8 +47,9 @@ type limbo struct {
 	groups map[uint64]map[uint64]common.Hash // Set of txs included in past blocks
 }
 
-// newLimbo opens and indexes a set of limboed blob transactions.
-func newLimbo(datadir string) (*limbo, error) {
+// newLimbo opens and indexes a set of limboed blob transactions. The cellProofs
+// flag sizes the store for version 1 sidecars too, see newSlotter.
+func newLimbo(datadir string, cellProofs bool) (*limbo, error) {
 	l := &limbo{
 		index:  make(map[common.Hash]uint64),
 		groups: make(map[uint64]map[uint64]common.Hash),
@@ -60,7 +61,7 @@ func newLimbo(datadir string) (*limbo, error) {
 			fails = append(fails, id)
 		}
 	}
-	store, err := billy.Open(billy.Options{Path: datadir, Repair: true}, newSlotter(), index)
+	store, err := billy.Open(billy.Options{Path: datadir, Repair: true}, newSlotter(cellProofs), index)
 	if err != nil {
 		return nil, err
 	}
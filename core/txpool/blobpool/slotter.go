@@ -25,14 +25,31 @@ package blobpool
 // The slotter also creates a shelf for 0-blob transactions. Whilst those are not
 // allowed in the current protocol, having an empty shelf is not a relevant use
 // of resources, but it makes stress testing with junk transactions simpler.
-func newSlotter() func() (uint32, bool) {
-	slotsize := uint32(txAvgSize)
-	slotsize -= uint32(blobSize) // underflows, it's ok, will overflow back in the first return
-
+//
+// If cell proofs are requested (Osaka scheduled), the slotter additionally creates
+// a shelf after each blob count for version 1 sidecars, which carry a proof per
+// cell instead of per blob and would otherwise overflow into the next blob count.
+// The blob proof shelves are retained to keep serving pre-Osaka transactions and
+// to avoid having to migrate existing stores.
+func newSlotter(cellProofs bool) func() (uint32, bool) {
+	limit := uint32(maxBlobsPerTransaction*blobSize + txMaxSize)
+	if cellProofs {
+		limit += maxBlobsPerTransaction * txCellProofsSize
+	}
+	var (
+		blobs uint32 // Number of blobs the next shelf is sized for
+		cells bool   // Whether the next shelf is sized for cell proofs
+	)
 	return func() (size uint32, done bool) {
-		slotsize += blobSize
-		finished := slotsize > maxBlobsPerTransaction*blobSize+txMaxSize
-
-		return slotsize, finished
+		size = blobs*blobSize + txAvgSize
+		if cells {
+			size += blobs * txCellProofsSize
+		}
+		if cellProofs && blobs > 0 && !cells {
+			cells = true
+		} else {
+			blobs, cells = blobs+1, false
+		}
+		return size, size > limit
 	}
 }
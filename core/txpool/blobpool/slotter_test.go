@@ -21,7 +21,7 @@ import "testing"
 // Tests that the slotter creates the expected database shelves.
 func TestNewSlotter(t *testing.T) {
 	// Generate the database shelve sizes
-	slotter := newSlotter()
+	slotter := newSlotter(false)
 
 	var shelves []uint32
 	for {
@@ -58,3 +58,39 @@ func TestNewSlotter(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the slotter creates the expected database shelves when cell proofs
+// are enabled, retaining the blob proof shelves.
+func TestNewSlotterCellProofs(t *testing.T) {
+	// Generate the database shelve sizes
+	slotter := newSlotter(true)
+
+	var shelves []uint32
+	for {
+		shelf, done := slotter()
+		shelves = append(shelves, shelf)
+		if done {
+			break
+		}
+	}
+	// Compare the database shelves to the expected ones
+	want := []uint32{0*blobSize + txAvgSize} // 0 blob + some expected tx infos
+	for blobs := uint32(1); blobs <= 13; blobs++ {
+		want = append(want,
+			blobs*blobSize+txAvgSize,                        // blobs with blob proofs + some expected tx infos
+			blobs*blobSize+blobs*txCellProofsSize+txAvgSize, // blobs with cell proofs + some expected tx infos
+		)
+	}
+	want = append(want,
+		14*blobSize+txAvgSize,                     // 1-6 blobs + unexpectedly large tx infos
+		14*blobSize+14*txCellProofsSize+txAvgSize, // 1-6 blobs + unexpectedly large tx infos >= 6 blobs with cell proofs + max tx metadata size
+	)
+	if len(shelves) != len(want) {
+		t.Errorf("shelves count mismatch: have %d, want %d", len(shelves), len(want))
+	}
+	for i := 0; i < len(shelves) && i < len(want); i++ {
+		if shelves[i] != want[i] {
+			t.Errorf("shelf %d mismatch: have %d, want %d", i, shelves[i], want[i])
+		}
+	}
+}
@@ -60,4 +60,28 @@ var (
 	// input transaction of non-blob type when a blob transaction from this sender
 	// remains pending (and vice-versa).
 	ErrAlreadyReserved = errors.New("address already reserved")
+
+	// ErrPrivateUnsupported is returned if a transaction is submitted privately
+	// to a subpool which can't keep it from being announced to the network.
+	ErrPrivateUnsupported = errors.New("private transactions not supported")
+
+	// ErrConditionalUnsupported is returned if a transaction with inclusion
+	// conditions is submitted to a subpool which can't track them.
+	ErrConditionalUnsupported = errors.New("transaction conditionals not supported")
+
+	// ErrExpired is the reason of evicting a transaction which outlived the time
+	// it was allowed to stay in the pool.
+	ErrExpired = errors.New("transaction expired")
+
+	// ErrRemoved is the reason of evicting a transaction which was explicitly
+	// removed from the pool by the node operator.
+	ErrRemoved = errors.New("transaction removed by operator")
+
+	// ErrPolicyRejected is returned if a transaction is refused by one of the
+	// admission policies registered on the pool.
+	ErrPolicyRejected = errors.New("rejected by pool policy")
+
+	// ErrSidecarVersion is returned if a blob transaction carries a sidecar of a
+	// version not accepted at the current fork.
+	ErrSidecarVersion = errors.New("unsupported blob sidecar version")
 )
@@ -87,6 +87,10 @@ var (
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
 
+	// conditionalEvictMeter counts how many transactions are evicted due to their
+	// inclusion conditions failing.
+	conditionalEvictMeter = metrics.NewRegisteredMeter("txpool/conditional/eviction", nil)
+
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
 	throttleTxMeter = metrics.NewRegisteredMeter("txpool/throttle", nil)
@@ -125,7 +129,12 @@ type Config struct {
 	Locals    []common.Address // Addresses that should be treated by default as local
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
-	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	Rejournal time.Duration    // Time interval to regenerate the local transaction journal and remote snapshot
+
+	Snapshot     string        // Snapshot of remote transactions to survive node restarts (disabled if empty)
+	SnapshotTxs  uint64        // Maximum number of remote transactions to snapshot
+	SnapshotSize uint64        // Maximum byte size of the remote transactions to snapshot
+	SnapshotAge  time.Duration // Maximum age of the snapshotted remote transactions to restore
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -136,6 +145,8 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	PrivateLifetime uint64 // Number of blocks a private transaction is kept for local inclusion
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -143,6 +154,10 @@ var DefaultConfig = Config{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	SnapshotTxs:  4096 + 1024 + 1024, // pending + queued capacity
+	SnapshotSize: 32 * 1024 * 1024,
+	SnapshotAge:  time.Hour,
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -152,6 +167,8 @@ var DefaultConfig = Config{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	PrivateLifetime: 25,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -162,6 +179,18 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.SnapshotTxs < 1 {
+		log.Warn("Sanitizing invalid txpool snapshot transactions", "provided", conf.SnapshotTxs, "updated", DefaultConfig.SnapshotTxs)
+		conf.SnapshotTxs = DefaultConfig.SnapshotTxs
+	}
+	if conf.SnapshotSize < 1 {
+		log.Warn("Sanitizing invalid txpool snapshot size", "provided", conf.SnapshotSize, "updated", DefaultConfig.SnapshotSize)
+		conf.SnapshotSize = DefaultConfig.SnapshotSize
+	}
+	if conf.SnapshotAge < 1 {
+		log.Warn("Sanitizing invalid txpool snapshot age", "provided", conf.SnapshotAge, "updated", DefaultConfig.SnapshotAge)
+		conf.SnapshotAge = DefaultConfig.SnapshotAge
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultConfig.PriceLimit)
 		conf.PriceLimit = DefaultConfig.PriceLimit
@@ -190,6 +219,10 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	if conf.PrivateLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool private lifetime", "provided", conf.PrivateLifetime, "updated", DefaultConfig.PrivateLifetime)
+		conf.PrivateLifetime = DefaultConfig.PrivateLifetime
+	}
 	return conf
 }
 
@@ -206,6 +239,7 @@ type LegacyPool struct {
 	chain       BlockChain
 	gasTip      atomic.Pointer[uint256.Int]
 	txFeed      event.Feed
+	eventFeed   event.Feed
 	signer      types.Signer
 	mu          sync.RWMutex
 
@@ -213,8 +247,9 @@ type LegacyPool struct {
 	currentState  *state.StateDB               // Current state in the blockchain head
 	pendingNonces *noncer                      // Pending state tracking virtual nonces
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *journal    // Journal of local transaction to back up to disk
+	locals   *accountSet // Set of local transaction to exempt from eviction rules
+	journal  *journal    // Journal of local transaction to back up to disk
+	snapshot *snapshot   // Snapshot of remote transactions to back up to disk
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -223,6 +258,15 @@ type LegacyPool struct {
 	all     *lookup                      // All transactions to allow lookups
 	priced  *pricedList                  // All transactions sorted by price
 
+	private        map[common.Hash]privateMark // Private transactions mapped to their sender and expiry
+	privateSenders map[common.Address]int      // Number of private transactions marked per sender
+	privateLock    sync.RWMutex                // Lock protecting the private transaction marks
+
+	events   []core.TxLifecycleEvent  // Lifecycle events waiting for the pool lock to be released
+	included map[common.Hash]struct{} // Transactions included by the chain since the previous reset
+
+	includedConds map[common.Hash]includedConditional // Conditions of recently included transactions, restored on reorgs
+
 	reqResetCh      chan *txpoolResetRequest
 	reqPromoteCh    chan *accountSet
 	queueTxEventCh  chan *types.Transaction
@@ -254,6 +298,9 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		queue:           make(map[common.Address]*list),
 		beats:           make(map[common.Address]time.Time),
 		all:             newLookup(),
+		private:         make(map[common.Hash]privateMark),
+		privateSenders:  make(map[common.Address]int),
+		includedConds:   make(map[common.Hash]includedConditional),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
 		queueTxEventCh:  make(chan *types.Transaction),
@@ -271,6 +318,9 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
+	if config.Snapshot != "" {
+		pool.snapshot = newTxSnapshot(config.Snapshot, config.SnapshotTxs, config.SnapshotSize, config.SnapshotAge)
+	}
 	return pool
 }
 
@@ -324,6 +374,12 @@ func (pool *LegacyPool) Init(gasTip uint64, head *types.Header, reserve txpool.A
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote transaction snapshotting is enabled, restore and revalidate them
+	if pool.snapshot != nil {
+		if err := pool.snapshot.load(pool.addRemotesSync); err != nil {
+			log.Warn("Failed to load transaction snapshot", "err", err)
+		}
+	}
 	pool.wg.Add(1)
 	go pool.loop()
 	return nil
@@ -379,12 +435,14 @@ func (pool *LegacyPool) loop() {
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
+						pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrExpired)
 						pool.removeTx(tx.Hash(), true, true)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
 			pool.mu.Unlock()
+			pool.sendEvents()
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
@@ -395,6 +453,11 @@ func (pool *LegacyPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.snapshot != nil {
+				if err := pool.snapshot.write(pool.remote()); err != nil {
+					log.Warn("Failed to regenerate remote tx snapshot", "err", err)
+				}
+			}
 		}
 	}
 }
@@ -408,6 +471,11 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.snapshot != nil {
+		if err := pool.snapshot.write(pool.remote()); err != nil {
+			log.Warn("Failed to write remote tx snapshot", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -429,9 +497,71 @@ func (pool *LegacyPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs
 	return pool.txFeed.Subscribe(ch)
 }
 
+// SubscribeLifecycleEvents registers a subscription for the state changes of the
+// pooled transactions, from their addition until their inclusion or eviction.
+func (pool *LegacyPool) SubscribeLifecycleEvents(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return pool.eventFeed.Subscribe(ch)
+}
+
+// notify queues a lifecycle event of a transaction, to be sent out once the pool
+// lock is released. The pool lock must be held.
+func (pool *LegacyPool) notify(typ core.TxLifecycle, tx *types.Transaction, replacement common.Hash, reason error) {
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	pool.events = append(pool.events, core.TxLifecycleEvent{
+		Type:        typ,
+		Hash:        tx.Hash(),
+		From:        from,
+		Nonce:       tx.Nonce(),
+		Replacement: replacement,
+		Reason:      reason,
+		Private:     pool.IsPrivate(tx.Hash()),
+	})
+}
+
+// notifyStale queues the lifecycle event of a transaction dropped due to its nonce
+// being used up, telling apart the ones included by the chain.
+func (pool *LegacyPool) notifyStale(tx *types.Transaction) {
+	if _, ok := pool.included[tx.Hash()]; ok {
+		if cond := tx.Conditional(); cond != nil {
+			pool.includedConds[tx.Hash()] = includedConditional{
+				cond:   cond,
+				number: pool.currentHead.Load().Number.Uint64(),
+			}
+		}
+		pool.notify(core.TxIncluded, tx, common.Hash{}, nil)
+		return
+	}
+	pool.notify(core.TxEvicted, tx, common.Hash{}, core.ErrNonceTooLow)
+}
+
+// notifyUnpayable queues the eviction event of a transaction dropped due to its
+// sender's balance or the block gas limit.
+func (pool *LegacyPool) notifyUnpayable(tx *types.Transaction, gasLimit uint64) {
+	reason := core.ErrInsufficientFunds
+	if tx.Gas() > gasLimit {
+		reason = txpool.ErrGasLimit
+	}
+	pool.notify(core.TxEvicted, tx, common.Hash{}, reason)
+}
+
+// sendEvents sends out the lifecycle events queued since the previous call. The
+// pool lock must not be held.
+func (pool *LegacyPool) sendEvents() {
+	pool.mu.Lock()
+	events := pool.events
+	pool.events = nil
+	pool.mu.Unlock()
+
+	for _, event := range events {
+		pool.eventFeed.Send(event)
+	}
+}
+
 // SetGasTip updates the minimum gas tip required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *LegacyPool) SetGasTip(tip *big.Int) {
+	defer pool.sendEvents()
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
@@ -445,6 +575,7 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		// pool.priced is sorted by GasFeeCap, so we have to iterate through pool.all instead
 		drop := pool.all.RemotesBelowTip(tip)
 		for _, tx := range drop {
+			pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrUnderpriced)
 			pool.removeTx(tx.Hash(), false, true)
 		}
 		pool.priced.Removed(len(drop))
@@ -576,30 +707,194 @@ func (pool *LegacyPool) Pending(filter txpool.PendingFilter) map[common.Address]
 	return pending
 }
 
-// Locals retrieves the accounts currently considered local by the pool.
+// Locals retrieves the accounts currently considered local by the pool, along
+// with the senders of private transactions, which are not made local but are
+// prioritized the same way by the block producer.
 func (pool *LegacyPool) Locals() []common.Address {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	return pool.locals.flatten()
+	locals := pool.locals.flatten()
+
+	pool.privateLock.RLock()
+	defer pool.privateLock.RUnlock()
+
+	for addr := range pool.privateSenders {
+		if !pool.locals.contains(addr) {
+			locals = append(locals, addr)
+		}
+	}
+	return locals
+}
+
+// SetLocals replaces the set of accounts considered local by the pool. The
+// transactions of the accounts dropped from the set lose their local exemptions
+// and become subject to the usual pricing and eviction rules.
+func (pool *LegacyPool) SetLocals(addrs []common.Address) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.locals = newAccountSet(pool.signer, addrs...)
+
+	// Migrate the transactions between the local and remote sets, rebuilding
+	// the price heap if anything changed as it only tracks remotes. Private
+	// transactions keep their exemptions regardless of their sender.
+	demoted := pool.all.LocalToRemotes(pool.locals, pool.isLocalPrivate)
+	promoted := pool.all.RemoteToLocals(pool.locals)
+	if demoted+promoted > 0 {
+		pool.priced.Reheap()
+	}
+	localGauge.Update(int64(pool.all.LocalCount()))
+
+	if pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	log.Info("Updated local accounts", "accounts", len(addrs))
+}
+
+// Remove evicts a transaction from the pool, returning whether it was found.
+// Any subsequent transactions of the sender which become non-executable are
+// moved back into the future queue.
+func (pool *LegacyPool) Remove(hash common.Hash) bool {
+	defer pool.sendEvents()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx := pool.all.Get(hash)
+	if tx == nil {
+		return false
+	}
+	pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrRemoved)
+	pool.removeTx(hash, true, true)
+
+	// Make sure the removed transaction is not resurrected from the journal
+	if addr, _ := types.Sender(pool.signer, tx); pool.locals.contains(addr) && pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	return true
+}
+
+// RemoveSender evicts all the transactions of an account from the pool,
+// returning the number of transactions removed.
+func (pool *LegacyPool) RemoveSender(addr common.Address) int {
+	defer pool.sendEvents()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var txs types.Transactions
+	if queued := pool.queue[addr]; queued != nil {
+		txs = append(txs, queued.Flatten()...)
+	}
+	// Drop the pending transactions in reverse nonce order to avoid needlessly
+	// demoting the remainder into the queue
+	if pending := pool.pending[addr]; pending != nil {
+		flat := pending.Flatten()
+		for i := len(flat) - 1; i >= 0; i-- {
+			txs = append(txs, flat[i])
+		}
+	}
+	for _, tx := range txs {
+		pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrRemoved)
+		pool.removeTx(tx.Hash(), true, true)
+	}
+	if len(txs) > 0 && pool.locals.contains(addr) && pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	return len(txs)
 }
 
 // local retrieves all currently known local transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
+//
+// Private transactions are omitted, as they must not be journaled to disk.
 func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 	txs := make(map[common.Address]types.Transactions)
 	for addr := range pool.locals.accounts {
 		if pending := pool.pending[addr]; pending != nil {
-			txs[addr] = append(txs[addr], pending.Flatten()...)
+			txs[addr] = append(txs[addr], pool.public(pending.Flatten())...)
 		}
 		if queued := pool.queue[addr]; queued != nil {
-			txs[addr] = append(txs[addr], queued.Flatten()...)
+			txs[addr] = append(txs[addr], pool.public(queued.Flatten())...)
 		}
 	}
 	return txs
 }
 
+// remote retrieves the remote transactions currently in the pool as snapshot
+// entries, ordered by priority. Executable transactions come first, then the
+// accounts are ordered by the effective tip of their first transaction.
+func (pool *LegacyPool) remote() []*snapshotEntry {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var (
+		baseFee = pool.priced.urgent.baseFee
+		entries []*snapshotEntry
+	)
+	for i, set := range []map[common.Address]*list{pool.pending, pool.queue} {
+		var accounts [][]*snapshotEntry
+		for addr, list := range set {
+			if pool.locals.contains(addr) {
+				continue
+			}
+			var account []*snapshotEntry
+			for _, tx := range pool.public(list.Flatten()) {
+				// The snapshot cannot hold the inclusion conditions, and the
+				// transactions must never be restored without them
+				if tx.Conditional() != nil {
+					continue
+				}
+				tip := tx.EffectiveGasTipValue(baseFee)
+				if tip.Sign() < 0 {
+					tip = new(big.Int) // fee cap below the base fee
+				}
+				account = append(account, &snapshotEntry{
+					Tx:      tx,
+					Time:    uint64(tx.Time().UnixMilli()),
+					Tip:     tip,
+					Pending: i == 0,
+				})
+			}
+			if len(account) > 0 {
+				accounts = append(accounts, account)
+			}
+		}
+		sort.SliceStable(accounts, func(i, j int) bool {
+			return accounts[i][0].Tip.Cmp(accounts[j][0].Tip) > 0
+		})
+		for _, account := range accounts {
+			entries = append(entries, account...)
+		}
+	}
+	return entries
+}
+
+// public filters out the private transactions from the given list.
+func (pool *LegacyPool) public(txs types.Transactions) types.Transactions {
+	pool.privateLock.RLock()
+	defer pool.privateLock.RUnlock()
+
+	if len(pool.private) == 0 {
+		return txs
+	}
+	filtered := txs[:0]
+	for _, tx := range txs {
+		if _, ok := pool.private[tx.Hash()]; !ok {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
 // validateTxBasics checks whether a transaction is valid according to the consensus
 // rules, but does not check state-dependent validation such as sufficient balance.
 // This check is meant as an early check which only needs to be performed once,
@@ -658,17 +953,58 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
 		return err
 	}
+	if cond := tx.Conditional(); cond != nil {
+		if err := txpool.ValidateConditional(cond, pool.pendingHeader(), pool.currentState); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// pendingHeader returns an approximation of the header of the next block, for
+// checking the inclusion conditions of transactions against.
+func (pool *LegacyPool) pendingHeader() *types.Header {
+	head := pool.currentHead.Load()
+	return &types.Header{
+		Number: new(big.Int).Add(head.Number, common.Big1),
+		Time:   max(head.Time+1, uint64(time.Now().Unix())),
+	}
+}
+
+// includedConditional is the inclusion condition of a transaction included by
+// the chain, retained for restoring the condition if the block is reorged out.
+type includedConditional struct {
+	cond   *types.TransactionConditional
+	number uint64 // Head number the transaction was seen included at
+}
+
+// evictConditionals drops the transactions whose inclusion conditions don't hold
+// anymore. The pool lock must be held.
+func (pool *LegacyPool) evictConditionals() {
+	var (
+		header  = pool.pendingHeader()
+		evicted int
+	)
+	for _, tx := range pool.all.Conditionals() {
+		if err := txpool.ValidateConditional(tx.Conditional(), header, pool.currentState); err != nil {
+			log.Trace("Evicting transaction with failed conditional", "hash", tx.Hash(), "err", err)
+			pool.notify(core.TxEvicted, tx, common.Hash{}, err)
+			pool.removeTx(tx.Hash(), true, true)
+			evicted++
+		}
+	}
+	conditionalEvictMeter.Mark(int64(evicted))
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
 //
 // If a newly added transaction is marked as local, its sending account will be
 // added to the allowlist, preventing any associated transaction from being dropped
-// out of the pool due to pricing constraints.
-func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
+// out of the pool due to pricing constraints. Unless exempt is set, the transaction
+// itself is never treated as local.
+func (pool *LegacyPool) add(tx *types.Transaction, local, exempt bool) (replaced bool, err error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
 	if pool.all.Get(hash) != nil {
@@ -678,7 +1014,9 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	}
 	// Make the local flag. If it's from local source or it's from the network but
 	// the sender is marked as local previously, treat it as the local transaction.
-	isLocal := local || pool.locals.containsTx(tx)
+	// Private transactions are submitted locally too, but without making their
+	// sender local. Deprioritized transactions get no exemptions at all.
+	isLocal := exempt && (local || pool.locals.containsTx(tx) || pool.isLocalPrivate(hash))
 
 	// If the transaction fails basic validation, discard it
 	if err := pool.validateTx(tx, isLocal); err != nil {
@@ -766,6 +1104,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 
+			pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrUnderpriced)
 			sender, _ := types.Sender(pool.signer, tx)
 			dropped := pool.removeTx(tx.Hash(), false, sender != from) // Don't unreserve the sender of the tx being added if last from the acc
 
@@ -786,11 +1125,13 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.notify(core.TxReplaced, old, hash, nil)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
+		pool.notify(core.TxAdded, tx, common.Hash{}, nil)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// Successful promotion, bump the heartbeat
@@ -802,6 +1143,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	if err != nil {
 		return false, err
 	}
+	pool.notify(core.TxAdded, tx, common.Hash{}, nil)
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
@@ -861,6 +1203,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.notify(core.TxReplaced, old, hash, nil)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -884,8 +1227,9 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 // journalTx adds the specified transaction to the local disk journal if it is
 // deemed to have been sent from a local account.
 func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	// Only journal if it's enabled and the transaction is local, but neither
+	// private nor conditional, as the journal cannot hold the conditions
+	if pool.journal == nil || !pool.locals.contains(from) || pool.IsPrivate(tx.Hash()) || tx.Conditional() != nil {
 		return
 	}
 	if err := pool.journal.insert(tx); err != nil {
@@ -910,6 +1254,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.notify(core.TxReplaced, tx, list.txs.Get(tx.Nonce()).Hash(), nil)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -917,6 +1262,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.notify(core.TxReplaced, old, hash, nil)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
@@ -975,8 +1321,25 @@ func (pool *LegacyPool) addRemoteSync(tx *types.Transaction) error {
 // If sync is set, the method will block until all internal maintenance related
 // to the add is finished. Only use this during tests for determinism!
 func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error {
+	return pool.addTxs(txs, local, true, sync)
+}
+
+// AddDeprioritized enqueues a batch of transactions deprioritized by the
+// admission policies, without any of the exemptions of local transactions, not
+// even if their sender is local or they are private.
+func (pool *LegacyPool) AddDeprioritized(txs []*types.Transaction, private bool, sync bool) []error {
+	if private {
+		return pool.addPrivate(txs, false, sync)
+	}
+	return pool.addTxs(txs, false, false, sync)
+}
+
+// addTxs enqueues a batch of transactions into the pool if they are valid. If
+// exempt is not set, the transactions are treated as remote ones regardless of
+// the local flag, their sender being local or them being private.
+func (pool *LegacyPool) addTxs(txs []*types.Transaction, local, exempt, sync bool) []error {
 	// Do not treat as local if local transactions have been disabled
-	local = local && !pool.config.NoLocals
+	local = local && exempt && !pool.config.NoLocals
 
 	// Filter out known ones without obtaining the pool lock or recovering signatures
 	var (
@@ -993,7 +1356,7 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 		// Exclude transactions with basic errors, e.g invalid signatures and
 		// insufficient intrinsic gas as soon as possible and cache senders
 		// in transactions before obtaining lock
-		if err := pool.validateTxBasics(tx, local); err != nil {
+		if err := pool.validateTxBasics(tx, local || (exempt && pool.isLocalPrivate(tx.Hash()))); err != nil {
 			errs[i] = err
 			log.Trace("Discarding invalid transaction", "hash", tx.Hash(), "err", err)
 			invalidTxMeter.Mark(1)
@@ -1008,7 +1371,7 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
-	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
+	newErrs, dirtyAddrs := pool.addTxsLocked(news, local, exempt)
 	pool.mu.Unlock()
 
 	var nilSlot = 0
@@ -1027,13 +1390,130 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 	return errs
 }
 
+// AddPrivate enqueues a batch of local transactions which are never announced to
+// the network, only included by the local block producer. The transactions are
+// dropped if they are not included within the configured number of blocks.
+//
+// Unlike with Add, the senders are not marked local, so their other transactions
+// are neither journaled nor exempt from the pricing rules. The private marks are
+// tracked by hash, so they survive the transactions being temporarily removed by
+// resets and reinjected after reorgs.
+func (pool *LegacyPool) AddPrivate(txs []*types.Transaction, sync bool) []error {
+	return pool.addPrivate(txs, true, sync)
+}
+
+// addPrivate enqueues a batch of private transactions, granting them the local
+// exemptions only if requested.
+func (pool *LegacyPool) addPrivate(txs []*types.Transaction, exempt, sync bool) []error {
+	// Mark the transactions before insertion to never announce them
+	var (
+		deadline = pool.currentHead.Load().Number.Uint64() + pool.config.PrivateLifetime
+		marked   = make([]bool, len(txs))
+	)
+	pool.privateLock.Lock()
+	for i, tx := range txs {
+		if _, ok := pool.private[tx.Hash()]; ok {
+			continue
+		}
+		from, err := types.Sender(pool.signer, tx)
+		if err != nil {
+			continue // rejected by the insertion anyway
+		}
+		pool.markPrivate(tx.Hash(), privateMark{sender: from, deadline: deadline})
+		marked[i] = true
+	}
+	pool.privateLock.Unlock()
+
+	errs := pool.addTxs(txs, false, exempt, sync)
+
+	// Drop the marks of the rejected transactions
+	pool.privateLock.Lock()
+	for i, err := range errs {
+		if err != nil && marked[i] {
+			pool.unmarkPrivate(txs[i].Hash())
+		}
+	}
+	pool.privateLock.Unlock()
+	return errs
+}
+
+// isLocalPrivate returns whether a transaction is private and gets the same
+// exemptions as local ones, unless those are disabled.
+func (pool *LegacyPool) isLocalPrivate(hash common.Hash) bool {
+	return !pool.config.NoLocals && pool.IsPrivate(hash)
+}
+
+// privateMark is the bookkeeping of a private transaction.
+type privateMark struct {
+	sender   common.Address
+	deadline uint64 // Block number at which the transaction expires
+}
+
+// markPrivate marks a transaction as private. The private lock must be held.
+func (pool *LegacyPool) markPrivate(hash common.Hash, mark privateMark) {
+	pool.private[hash] = mark
+	pool.privateSenders[mark.sender]++
+}
+
+// unmarkPrivate removes the private mark of a transaction. The private lock
+// must be held.
+func (pool *LegacyPool) unmarkPrivate(hash common.Hash) {
+	mark, ok := pool.private[hash]
+	if !ok {
+		return
+	}
+	delete(pool.private, hash)
+	if pool.privateSenders[mark.sender]--; pool.privateSenders[mark.sender] <= 0 {
+		delete(pool.privateSenders, mark.sender)
+	}
+}
+
+// IsPrivate returns whether a transaction was submitted privately and thus must
+// not be announced to the network.
+func (pool *LegacyPool) IsPrivate(hash common.Hash) bool {
+	pool.privateLock.RLock()
+	defer pool.privateLock.RUnlock()
+
+	_, ok := pool.private[hash]
+	return ok
+}
+
+// expirePrivate drops the private transactions which were not included before
+// their deadline. The marks of included ones are retained until then too, so
+// they stay private if reorged out. The marks are only removed after queueing
+// the evictions, so those are flagged private too. The pool lock must be held.
+func (pool *LegacyPool) expirePrivate(number uint64) {
+	var expired []common.Hash
+
+	pool.privateLock.RLock()
+	for hash, mark := range pool.private {
+		if mark.deadline <= number {
+			expired = append(expired, hash)
+		}
+	}
+	pool.privateLock.RUnlock()
+
+	for _, hash := range expired {
+		if tx := pool.all.Get(hash); tx != nil {
+			log.Debug("Dropping expired private transaction", "hash", hash)
+			pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrExpired)
+			pool.removeTx(hash, true, true)
+		}
+	}
+	pool.privateLock.Lock()
+	for _, hash := range expired {
+		pool.unmarkPrivate(hash)
+	}
+	pool.privateLock.Unlock()
+}
+
 // addTxsLocked attempts to queue a batch of transactions if they are valid.
 // The transaction pool lock must be held.
-func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
+func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction, local, exempt bool) ([]error, *accountSet) {
 	dirty := newAccountSet(pool.signer)
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
-		replaced, err := pool.add(tx, local)
+		replaced, err := pool.add(tx, local, exempt)
 		errs[i] = err
 		if err == nil && !replaced {
 			dirty.addTx(tx)
@@ -1133,6 +1613,7 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 			for _, tx := range invalids {
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(tx.Hash(), tx, false, false)
+				pool.notify(core.TxDemoted, tx, common.Hash{}, nil)
 			}
 			// Update the account nonce if needed
 			pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1276,6 +1757,9 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 		// Reset from the old head to the new, rescheduling any reorged transactions
 		pool.reset(reset.oldHead, reset.newHead)
 
+		// Evict the transactions whose conditions were invalidated by the new head
+		pool.evictConditionals()
+
 		// Nonces were reset, discard any events that became stale
 		for addr := range events {
 			events[addr].Forward(pool.pendingNonces.get(addr))
@@ -1319,8 +1803,16 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
+
+	lifecycle := pool.events
+	pool.events = nil
 	pool.mu.Unlock()
 
+	// Notify subsystems for the state changes of the transactions
+	for _, event := range lifecycle {
+		pool.eventFeed.Send(event)
+	}
+
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
 		addr, _ := types.Sender(pool.signer, tx)
@@ -1342,7 +1834,8 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 // of the transaction pool is valid with regard to the chain state.
 func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
-	var reinject types.Transactions
+	var reinject, included types.Transactions
+	pool.included = nil
 
 	if oldHead != nil && oldHead.Hash() != newHead.ParentHash {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
@@ -1381,7 +1874,7 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 					log.Warn("Transaction pool reset with missing new head", "number", newHead.Number, "hash", newHead.Hash())
 					return
 				}
-				var discarded, included types.Transactions
+				var discarded types.Transactions
 				for rem.NumberU64() > add.NumberU64() {
 					discarded = append(discarded, rem.Transactions()...)
 					if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
@@ -1431,10 +1924,60 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingNonces = newNoncer(statedb)
 
-	// Inject any transactions discarded due to reorgs
+	// Track the transactions included by the chain to tell them apart from the
+	// ones dropped for reusing their nonces
+	if oldHead != nil && oldHead.Hash() == newHead.ParentHash {
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			included = block.Transactions()
+		}
+	}
+	pool.included = make(map[common.Hash]struct{}, len(included))
+	for _, tx := range included {
+		pool.included[tx.Hash()] = struct{}{}
+	}
+	// Inject any transactions discarded due to reorgs, along with their conditions
+	reinject = pool.restoreConditionals(reinject, newHead.Number.Uint64())
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	core.SenderCacher.Recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, true)
+
+	// Drop the private transactions not included in time
+	pool.expirePrivate(newHead.Number.Uint64())
+}
+
+// restoreConditionals reattaches the inclusion conditions to the reorged out
+// transactions that were included with one, and forgets the conditions of the
+// transactions included too deep to be reorged anymore.
+func (pool *LegacyPool) restoreConditionals(txs types.Transactions, number uint64) types.Transactions {
+	if len(pool.includedConds) == 0 {
+		return txs
+	}
+	for i, tx := range txs {
+		rec, ok := pool.includedConds[tx.Hash()]
+		if !ok {
+			continue
+		}
+		delete(pool.includedConds, tx.Hash())
+
+		// The transaction is shared with the chain's block caches, copy it
+		// before attaching the condition
+		blob, err := tx.MarshalBinary()
+		if err != nil {
+			continue
+		}
+		cpy := new(types.Transaction)
+		if err := cpy.UnmarshalBinary(blob); err != nil {
+			continue
+		}
+		cpy.SetConditional(rec.cond)
+		txs[i] = cpy
+	}
+	for hash, rec := range pool.includedConds {
+		if rec.number+64 < number {
+			delete(pool.includedConds, hash)
+		}
+	}
+	return txs
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1456,6 +1999,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range forwards {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.notifyStale(tx)
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
@@ -1463,6 +2007,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.notifyUnpayable(tx, gasLimit)
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
@@ -1473,6 +2018,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
 				promoted = append(promoted, tx)
+				pool.notify(core.TxPromoted, tx, common.Hash{}, nil)
 			}
 		}
 		log.Trace("Promoted queued transactions", "count", len(promoted))
@@ -1485,6 +2031,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
+				pool.notify(core.TxEvicted, tx, common.Hash{}, txpool.ErrAccountLimitExceeded)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 			queuedRateLimitMeter.Mark(int64(len(caps)))
@@ -1550,6 +2097,7 @@ func (pool *LegacyPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
+						pool.notify(core.TxEvicted, tx, common.Hash{}, ErrTxPoolOverflow)
 
 						// Update the account nonce to the dropped transaction
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
@@ -1577,6 +2125,7 @@ func (pool *LegacyPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					pool.all.Remove(hash)
+					pool.notify(core.TxEvicted, tx, common.Hash{}, ErrTxPoolOverflow)
 
 					// Update the account nonce to the dropped transaction
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1623,6 +2172,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
+				pool.notify(core.TxEvicted, tx, common.Hash{}, ErrTxPoolOverflow)
 				pool.removeTx(tx.Hash(), true, true)
 			}
 			drop -= size
@@ -1632,6 +2182,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+			pool.notify(core.TxEvicted, txs[i], common.Hash{}, ErrTxPoolOverflow)
 			pool.removeTx(txs[i].Hash(), true, true)
 			drop--
 			queuedRateLimitMeter.Mark(1)
@@ -1657,6 +2208,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range olds {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.notifyStale(tx)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
@@ -1665,6 +2217,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
+			pool.notifyUnpayable(tx, gasLimit)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
@@ -1674,6 +2227,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 
 			// Internal shuffle shouldn't touch the lookup set.
 			pool.enqueueTx(hash, tx, false, false)
+			pool.notify(core.TxDemoted, tx, common.Hash{}, nil)
 		}
 		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
 		if pool.locals.contains(addr) {
@@ -1688,6 +2242,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(hash, tx, false, false)
+				pool.notify(core.TxDemoted, tx, common.Hash{}, nil)
 			}
 			pendingGauge.Dec(int64(len(gapped)))
 		}
@@ -1790,17 +2345,19 @@ func (as *accountSet) merge(other *accountSet) {
 // This lookup set combines the notion of "local transactions", which is useful
 // to build upper-level structure.
 type lookup struct {
-	slots   int
-	lock    sync.RWMutex
-	locals  map[common.Hash]*types.Transaction
-	remotes map[common.Hash]*types.Transaction
+	slots        int
+	lock         sync.RWMutex
+	locals       map[common.Hash]*types.Transaction
+	remotes      map[common.Hash]*types.Transaction
+	conditionals map[common.Hash]*types.Transaction // Transactions with inclusion conditions
 }
 
 // newLookup returns a new lookup structure.
 func newLookup() *lookup {
 	return &lookup{
-		locals:  make(map[common.Hash]*types.Transaction),
-		remotes: make(map[common.Hash]*types.Transaction),
+		locals:       make(map[common.Hash]*types.Transaction),
+		remotes:      make(map[common.Hash]*types.Transaction),
+		conditionals: make(map[common.Hash]*types.Transaction),
 	}
 }
 
@@ -1899,6 +2456,9 @@ func (t *lookup) Add(tx *types.Transaction, local bool) {
 	} else {
 		t.remotes[tx.Hash()] = tx
 	}
+	if tx.Conditional() != nil {
+		t.conditionals[tx.Hash()] = tx
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1919,6 +2479,19 @@ func (t *lookup) Remove(hash common.Hash) {
 
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
+	delete(t.conditionals, hash)
+}
+
+// Conditionals returns the transactions with inclusion conditions in the lookup.
+func (t *lookup) Conditionals() []*types.Transaction {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	txs := make([]*types.Transaction, 0, len(t.conditionals))
+	for _, tx := range t.conditionals {
+		txs = append(txs, tx)
+	}
+	return txs
 }
 
 // RemoteToLocals migrates the transactions belongs to the given locals to locals
@@ -1938,6 +2511,24 @@ func (t *lookup) RemoteToLocals(locals *accountSet) int {
 	return migrated
 }
 
+// LocalToRemotes migrates the transactions not belonging to the given locals
+// to the remotes set, except the ones local on their own as reported by keep.
+// The assumption is held the locals set is thread-safe to be used.
+func (t *lookup) LocalToRemotes(locals *accountSet, keep func(hash common.Hash) bool) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var migrated int
+	for hash, tx := range t.locals {
+		if !locals.containsTx(tx) && !keep(hash) {
+			t.remotes[hash] = tx
+			delete(t.locals, hash)
+			migrated += 1
+		}
+	}
+	return migrated
+}
+
 // RemotesBelowTip finds all remote transactions below the given tip threshold.
 func (t *lookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	found := make(types.Transactions, 0, 128)
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -67,6 +68,7 @@ type testBlockChain struct {
 	gasLimit      atomic.Uint64
 	statedb       *state.StateDB
 	chainHeadFeed *event.Feed
+	blocks        map[common.Hash]*types.Block // Blocks returned instead of empty ones, if set
 }
 
 func newTestBlockChain(config *params.ChainConfig, gasLimit uint64, statedb *state.StateDB, chainHeadFeed *event.Feed) *testBlockChain {
@@ -87,6 +89,9 @@ func (bc *testBlockChain) CurrentBlock() *types.Header {
 }
 
 func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block, ok := bc.blocks[hash]; ok {
+		return block
+	}
 	return types.NewBlock(bc.CurrentBlock(), nil, nil, trie.NewStackTrie(nil))
 }
 
@@ -477,14 +482,14 @@ func TestChainFork(t *testing.T) {
 	resetState()
 
 	tx := transaction(0, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, true); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	pool.removeTx(tx.Hash(), true, true)
 
 	// reset the pool's internal state
 	resetState()
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, true); err != nil {
 		t.Error("didn't expect error", err)
 	}
 }
@@ -511,10 +516,10 @@ func TestDoubleNonce(t *testing.T) {
 	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
 
 	// Add the first two transaction, ensure higher priced stays only
-	if replace, err := pool.add(tx1, false); err != nil || replace {
+	if replace, err := pool.add(tx1, false, true); err != nil || replace {
 		t.Errorf("first transaction insert failed (%v) or reported replacement (%v)", err, replace)
 	}
-	if replace, err := pool.add(tx2, false); err != nil || !replace {
+	if replace, err := pool.add(tx2, false, true); err != nil || !replace {
 		t.Errorf("second transaction insert failed (%v) or not reported replacement (%v)", err, replace)
 	}
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
@@ -526,7 +531,7 @@ func TestDoubleNonce(t *testing.T) {
 	}
 
 	// Add the third transaction and ensure it's not saved (smaller price)
-	pool.add(tx3, false)
+	pool.add(tx3, false, true)
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
 	if pool.pending[addr].Len() != 1 {
 		t.Error("expected 1 pending transactions, got", pool.pending[addr].Len())
@@ -549,7 +554,7 @@ func TestMissingNonce(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	testAddBalance(pool, addr, big.NewInt(100000000000000))
 	tx := transaction(1, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, true); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	if len(pool.pending) != 0 {
@@ -2458,6 +2463,85 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that remote transactions are snapshotted on shutdown and restored on
+// startup, honouring the size caps and the age cutoff.
+func TestSnapshotting(t *testing.T) {
+	t.Parallel()
+
+	var (
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+		local, _  = crypto.GenerateKey()
+		cheap, _  = crypto.GenerateKey()
+		pricey, _ = crypto.GenerateKey()
+	)
+	config := testTxPoolConfig
+	config.Snapshot = filepath.Join(t.TempDir(), "snapshot.rlp")
+	config.SnapshotTxs = 3
+
+	pool := New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	for _, key := range []*ecdsa.PrivateKey{local, cheap, pricey} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	// Add a local transaction and a few pending and queued remote ones, more
+	// than the snapshot can hold
+	if err := pool.addLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	var (
+		arrival = time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+		txs     = []*types.Transaction{
+			pricedTransaction(0, 100000, big.NewInt(1), cheap),
+			pricedTransaction(0, 100000, big.NewInt(2), pricey),
+			pricedTransaction(2, 100000, big.NewInt(2), pricey),
+			pricedTransaction(1, 100000, big.NewInt(2), pricey),
+		}
+	)
+	for _, tx := range txs {
+		tx.SetTime(arrival)
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	pool.Close()
+
+	// Restore the snapshot into a new pool and ensure only the best remotes survive
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("restored transactions mismatched: have %d pending %d queued, want 3 pending 0 queued", pending, queued)
+	}
+	for _, tx := range txs[1:] {
+		restored := pool.Get(tx.Hash())
+		if restored == nil {
+			t.Fatalf("transaction %x not restored", tx.Hash())
+		}
+		if !restored.Time().Equal(arrival) {
+			t.Fatalf("arrival time mismatch: have %v, want %v", restored.Time(), arrival)
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	pool.Close()
+
+	// Restore the snapshot with an age cutoff and ensure nothing survives
+	config.SnapshotAge = time.Second
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("stale transactions restored: have %d pending %d queued", pending, queued)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
@@ -2513,6 +2597,475 @@ func TestStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that private transactions are tracked until their deadline, are not
+// journaled, and are dropped if not included in time.
+func TestPrivateTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	// Add a private transaction and a rejected one
+	other, _ := crypto.GenerateKey()
+	var (
+		tx       = transaction(0, 100000, key)
+		unfunded = transaction(0, 100000, other)
+	)
+	errs := pool.AddPrivate([]*types.Transaction{tx, unfunded}, true)
+	if errs[0] != nil {
+		t.Fatalf("failed to add private transaction: %v", errs[0])
+	}
+	if errs[1] == nil {
+		t.Fatal("added unfunded private transaction")
+	}
+	if !pool.IsPrivate(tx.Hash()) {
+		t.Fatal("transaction not marked private")
+	}
+	if pool.IsPrivate(unfunded.Hash()) {
+		t.Fatal("rejected transaction marked private")
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	// Private transactions must not make their sender local, but the sender must
+	// be prioritized by the block producer
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	if pool.locals.contains(from) {
+		t.Fatal("private transaction sender marked local")
+	}
+	if locals := pool.local(); len(locals[from]) != 0 {
+		t.Fatalf("private transaction retained for journaling: %v", locals)
+	}
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != from {
+		t.Fatalf("private transaction sender not prioritized: %v", locals)
+	}
+	// Replacing the local accounts must not strip the private exemptions
+	pool.SetLocals(nil)
+	if pool.all.GetLocal(tx.Hash()) == nil {
+		t.Fatal("private transaction demoted to remote by local account update")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	head := func(number uint64) *types.Header {
+		return &types.Header{Number: new(big.Int).SetUint64(number), GasLimit: 1000000, BaseFee: common.Big1}
+	}
+	events := make(chan core.TxLifecycleEvent, 16)
+	sub := pool.SubscribeLifecycleEvents(events)
+	defer sub.Unsubscribe()
+
+	// Include the transaction and reorg it out, it must be reinjected as private
+	var (
+		chain   = pool.chain.(*testBlockChain)
+		genesis = chain.GetBlock(common.Hash{}, 0)
+		mined   = head(1)
+		reorged = head(1)
+	)
+	mined.ParentHash, mined.Extra = genesis.Hash(), []byte("mined")
+	reorged.ParentHash, reorged.Extra = genesis.Hash(), []byte("reorged")
+	chain.blocks = map[common.Hash]*types.Block{
+		mined.Hash():   types.NewBlock(mined, &types.Body{Transactions: types.Transactions{tx}}, nil, trie.NewStackTrie(nil)),
+		reorged.Hash(): types.NewBlock(reorged, nil, nil, trie.NewStackTrie(nil)),
+	}
+	chain.statedb.SetNonce(from, 1)
+	<-pool.requestReset(genesis.Header(), mined)
+	if pool.Has(tx.Hash()) || !pool.IsPrivate(tx.Hash()) {
+		t.Fatal("included private transaction retained or unmarked")
+	}
+	chain.statedb.SetNonce(from, 0)
+	<-pool.requestReset(mined, reorged)
+	if !pool.Has(tx.Hash()) || !pool.IsPrivate(tx.Hash()) {
+		t.Fatal("reorged private transaction not reinjected as private")
+	}
+	if pool.locals.contains(from) {
+		t.Fatal("reinjected private transaction sender marked local")
+	}
+	// Move the chain to right before the deadline, the transaction must stay
+	<-pool.requestReset(nil, head(testTxPoolConfig.PrivateLifetime-1))
+	if !pool.Has(tx.Hash()) || !pool.IsPrivate(tx.Hash()) {
+		t.Fatal("private transaction dropped before its deadline")
+	}
+	// Reach the deadline, the transaction must be dropped
+	<-pool.requestReset(nil, head(testTxPoolConfig.PrivateLifetime))
+	if pool.Has(tx.Hash()) || pool.IsPrivate(tx.Hash()) {
+		t.Fatal("private transaction retained after its deadline")
+	}
+	if locals := pool.Locals(); len(locals) != 0 {
+		t.Fatalf("expired private transaction sender still prioritized: %v", locals)
+	}
+	// All the lifecycle events of the transaction, including its expiry, must be
+	// flagged private to not be revealed to subscribers
+	for evicted := false; !evicted; {
+		select {
+		case event := <-events:
+			if event.Hash != tx.Hash() {
+				continue
+			}
+			if !event.Private {
+				t.Fatalf("%v event of private transaction not flagged private", event.Type)
+			}
+			evicted = event.Type == core.TxEvicted && errors.Is(event.Reason, txpool.ErrExpired)
+		case <-time.After(time.Second):
+			t.Fatal("expiry event not received")
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions with failing inclusion conditions are rejected, and
+// that pooled ones are evicted once their conditions are invalidated.
+func TestConditionalTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	var (
+		account = common.HexToAddress("0xaa")
+		slot    = common.HexToHash("0x01")
+		value   = common.HexToHash("0x02")
+	)
+	pool.currentState.SetState(account, slot, value)
+
+	conditional := func(nonce uint64, want common.Hash) *types.Transaction {
+		tx := transaction(nonce, 100000, key)
+		tx.SetConditional(&types.TransactionConditional{
+			KnownAccounts: types.KnownAccounts{
+				account: {StorageSlots: map[common.Hash]common.Hash{slot: want}},
+			},
+		})
+		return tx
+	}
+	// Transactions with failing conditions are rejected on admission
+	if err := pool.addRemoteSync(conditional(0, common.Hash{})); !errors.Is(err, types.ErrConditionalFailed) {
+		t.Fatalf("unexpected error for failing conditional: have %v, want %v", err, types.ErrConditionalFailed)
+	}
+	tx := conditional(0, value)
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	// Invalidate the condition and ensure the transaction is evicted on reset
+	events := make(chan core.TxLifecycleEvent, 1)
+	sub := pool.SubscribeLifecycleEvents(events)
+	defer sub.Unsubscribe()
+
+	pool.currentState.SetState(account, slot, common.HexToHash("0x03"))
+	<-pool.requestReset(nil, nil)
+
+	if pool.Has(tx.Hash()) {
+		t.Fatal("transaction with invalidated conditional not evicted")
+	}
+	select {
+	case event := <-events:
+		if event.Type != core.TxEvicted || event.Hash != tx.Hash() || !errors.Is(event.Reason, types.ErrConditionalFailed) {
+			t.Fatalf("eviction event mismatch: have %v %x (%v), want %x", event.Type, event.Hash, event.Reason, tx.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("eviction event not received")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that conditional transactions are neither journaled nor snapshotted, so
+// they never survive a restart without their conditions.
+func TestConditionalTransactionsRestart(t *testing.T) {
+	t.Parallel()
+
+	var (
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+		local, _  = crypto.GenerateKey()
+		remote, _ = crypto.GenerateKey()
+	)
+	config := testTxPoolConfig
+	config.Journal = filepath.Join(t.TempDir(), "journal.rlp")
+	config.Snapshot = filepath.Join(t.TempDir(), "snapshot.rlp")
+
+	pool := New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	for _, key := range []*ecdsa.PrivateKey{local, remote} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	conditional := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := transaction(nonce, 100000, key)
+		tx.SetConditional(&types.TransactionConditional{BlockNumberMin: big.NewInt(1)})
+		return tx
+	}
+	var (
+		localCond  = conditional(0, local)
+		localTx    = transaction(1, 100000, local)
+		remoteCond = conditional(0, remote)
+		remoteTx   = transaction(1, 100000, remote)
+	)
+	if err := pool.addLocal(localCond); err != nil {
+		t.Fatalf("failed to add local conditional transaction: %v", err)
+	}
+	if err := pool.addLocal(localTx); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	for i, err := range pool.addRemotesSync([]*types.Transaction{remoteCond, remoteTx}) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	pool.Close()
+
+	// Restart the pool and ensure only the unconditional transactions survive
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	for _, tx := range []*types.Transaction{localCond, remoteCond} {
+		if pool.Has(tx.Hash()) {
+			t.Fatalf("conditional transaction %x restored without its conditions", tx.Hash())
+		}
+	}
+	for _, tx := range []*types.Transaction{localTx, remoteTx} {
+		if !pool.Has(tx.Hash()) {
+			t.Fatalf("transaction %x not restored", tx.Hash())
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the state changes of the pooled transactions are reported through
+// the lifecycle event feed.
+func TestLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	events := make(chan core.TxLifecycleEvent, 16)
+	sub := pool.SubscribeLifecycleEvents(events)
+	defer sub.Unsubscribe()
+
+	checkEvent := func(typ core.TxLifecycle, tx *types.Transaction) core.TxLifecycleEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			if event.Type != typ || event.Hash != tx.Hash() || event.From != from || event.Nonce != tx.Nonce() {
+				t.Fatalf("event mismatch: have %v %x from %x nonce %d, want %v %x", event.Type, event.Hash, event.From, event.Nonce, typ, tx.Hash())
+			}
+			return event
+		case <-time.After(time.Second):
+			t.Fatalf("%v event not received for %x", typ, tx.Hash())
+		}
+		return core.TxLifecycleEvent{}
+	}
+	// Added transactions are promoted once executable
+	tx := pricedTransaction(0, 100000, big.NewInt(1), key)
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	checkEvent(core.TxAdded, tx)
+	checkEvent(core.TxPromoted, tx)
+
+	// Replaced transactions report their replacement
+	repl := pricedTransaction(0, 100000, big.NewInt(2), key)
+	if err := pool.addRemoteSync(repl); err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	if event := checkEvent(core.TxReplaced, tx); event.Replacement != repl.Hash() {
+		t.Fatalf("replacement mismatch: have %x, want %x", event.Replacement, repl.Hash())
+	}
+	checkEvent(core.TxAdded, repl)
+
+	// Transactions whose nonce was used up outside of the chain are evicted
+	pool.currentState.SetNonce(from, 1)
+	<-pool.requestReset(nil, nil)
+
+	if event := checkEvent(core.TxEvicted, repl); !errors.Is(event.Reason, core.ErrNonceTooLow) {
+		t.Fatalf("eviction reason mismatch: have %v, want %v", event.Reason, core.ErrNonceTooLow)
+	}
+}
+
+// Tests that transactions can be explicitly removed from the pool, individually
+// or per sender, and that the local accounts can be replaced.
+func TestRemoveTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	other, _ := crypto.GenerateKey()
+	var (
+		from  = crypto.PubkeyToAddress(key.PublicKey)
+		owner = crypto.PubkeyToAddress(other.PublicKey)
+	)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+	testAddBalance(pool, owner, big.NewInt(1000000000))
+
+	// Add a few pending and queued transactions from both accounts
+	var txs []*types.Transaction
+	for _, nonce := range []uint64{0, 1, 2, 4} {
+		txs = append(txs, transaction(nonce, 100000, key))
+	}
+	for _, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	for _, nonce := range []uint64{0, 1, 3} {
+		if err := pool.addLocal(transaction(nonce, 100000, other)); err != nil {
+			t.Fatalf("failed to add local transaction: %v", err)
+		}
+	}
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, owner))
+
+	// Removing a pending transaction demotes the subsequent ones
+	if pool.Remove(common.Hash{0x01}) {
+		t.Fatalf("removed unknown transaction")
+	}
+	if !pool.Remove(txs[1].Hash()) {
+		t.Fatalf("failed to remove transaction")
+	}
+	if pool.all.Get(txs[1].Hash()) != nil {
+		t.Fatalf("removed transaction still in pool")
+	}
+	if pending, queued := pool.ContentFrom(from); len(pending) != 1 || len(queued) != 2 {
+		t.Fatalf("sender content mismatch: have %d/%d pending/queued, want %d/%d", len(pending), len(queued), 1, 2)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Demoting the local account subjects its transactions to the price heap
+	pool.SetLocals(nil)
+	if locals := pool.Locals(); len(locals) != 0 {
+		t.Fatalf("local accounts mismatch: have %v, want none", locals)
+	}
+	if count := pool.all.LocalCount(); count != 0 {
+		t.Fatalf("local transaction count mismatch: have %d, want %d", count, 0)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Promoting the accounts exempts their transactions again
+	pool.SetLocals([]common.Address{from})
+	if count := pool.all.LocalCount(); count != 3 {
+		t.Fatalf("local transaction count mismatch: have %d, want %d", count, 3)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Removing a sender drops all its transactions
+	if removed := pool.RemoveSender(owner); removed != 3 {
+		t.Fatalf("removed transaction count mismatch: have %d, want %d", removed, 3)
+	}
+	if removed := pool.RemoveSender(owner); removed != 0 {
+		t.Fatalf("removed transaction count mismatch: have %d, want %d", removed, 0)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d pending/queued, want %d/%d", pending, queued, 1, 2)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the admission policies registered on the pool reject transactions
+// with their reason, and deprioritize them for block production.
+func TestAdmissionPolicies(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	legacy := New(testTxPoolConfig, blockchain)
+	pool, err := txpool.New(testTxPoolConfig.PriceLimit, blockchain, []txpool.SubPool{legacy})
+	if err != nil {
+		t.Fatalf("failed to create transaction pool: %v", err)
+	}
+	defer pool.Close()
+
+	var (
+		banned, _  = crypto.GenerateKey()
+		demoted, _ = crypto.GenerateKey()
+		normal, _  = crypto.GenerateKey()
+		errBanned  = errors.New("sender banned")
+	)
+	for _, key := range []*ecdsa.PrivateKey{banned, demoted, normal} {
+		testAddBalance(legacy, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	}
+	pool.RegisterPolicy(txpool.PolicyFunc(func(req *txpool.AdmissionRequest) (txpool.Verdict, error) {
+		if req.From == crypto.PubkeyToAddress(banned.PublicKey) {
+			return txpool.Reject, errBanned
+		}
+		return txpool.Accept, nil
+	}))
+	pool.RegisterPolicy(txpool.PolicyFunc(func(req *txpool.AdmissionRequest) (txpool.Verdict, error) {
+		if req.From == crypto.PubkeyToAddress(demoted.PublicKey) {
+			return txpool.Deprioritize, nil
+		}
+		if req.From == crypto.PubkeyToAddress(normal.PublicKey) && req.Tx.Nonce() > 0 {
+			return txpool.Deprioritize, nil
+		}
+		return txpool.Accept, nil
+	}))
+	errs := pool.Add([]*types.Transaction{
+		transaction(0, 100000, banned),
+		transaction(0, 100000, demoted),
+		transaction(0, 100000, normal),
+	}, true, true)
+
+	if !errors.Is(errs[0], txpool.ErrPolicyRejected) || !errors.Is(errs[0], errBanned) {
+		t.Fatalf("banned transaction error mismatch: have %v, want %v", errs[0], errBanned)
+	}
+	if errs[1] != nil || errs[2] != nil {
+		t.Fatalf("failed to add admitted transactions: %v, %v", errs[1], errs[2])
+	}
+	// Deprioritized transactions lose their local exemptions and are flagged
+	if locals := legacy.Locals(); len(locals) != 1 || locals[0] != crypto.PubkeyToAddress(normal.PublicKey) {
+		t.Fatalf("local accounts mismatch: have %v, want %v", locals, crypto.PubkeyToAddress(normal.PublicKey))
+	}
+	pending := pool.Pending(txpool.PendingFilter{})
+	if txs := pending[crypto.PubkeyToAddress(demoted.PublicKey)]; len(txs) != 1 || !txs[0].Deprioritized {
+		t.Fatalf("deprioritized transaction not ordered last: %v", txs)
+	}
+	if txs := pending[crypto.PubkeyToAddress(normal.PublicKey)]; len(txs) != 1 || txs[0].Deprioritized {
+		t.Fatalf("accepted transaction deprioritized: %v", txs)
+	}
+	// Deprioritized transactions of an already local sender get no exemptions
+	// either, whether submitted normally or privately
+	var (
+		remote  = transaction(1, 100000, normal)
+		private = transaction(2, 100000, normal)
+	)
+	if err := pool.Add([]*types.Transaction{remote}, false, true)[0]; err != nil {
+		t.Fatalf("failed to add deprioritized transaction: %v", err)
+	}
+	if err := pool.AddPrivate([]*types.Transaction{private}, true)[0]; err != nil {
+		t.Fatalf("failed to add deprioritized private transaction: %v", err)
+	}
+	for _, tx := range []*types.Transaction{remote, private} {
+		if legacy.all.GetLocal(tx.Hash()) != nil || legacy.all.GetRemote(tx.Hash()) == nil {
+			t.Fatalf("deprioritized transaction %x of local sender treated as local", tx.Hash())
+		}
+	}
+	if !legacy.IsPrivate(private.Hash()) {
+		t.Fatal("deprioritized private transaction not kept private")
+	}
+	pending = pool.Pending(txpool.PendingFilter{})
+	if txs := pending[crypto.PubkeyToAddress(normal.PublicKey)]; len(txs) != 3 || txs[0].Deprioritized || !txs[1].Deprioritized || !txs[2].Deprioritized {
+		t.Fatalf("local sender transactions deprioritization mismatch: %v", txs)
+	}
+}
+
 // Test the transaction slots consumption is computed correctly
 func TestSlotCount(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,156 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// snapshotEntry is a remote transaction stored in the pool snapshot, along with
+// the metadata needed to restore it.
+type snapshotEntry struct {
+	Tx      *types.Transaction
+	Time    uint64   // Arrival time of the transaction in unix milliseconds
+	Tip     *big.Int // Effective gas tip of the transaction when snapshotted, used for ordering
+	Pending bool     // Whether the transaction was executable when snapshotted
+}
+
+// snapshot is a periodically regenerated dump of the remote transactions, with
+// the aim of allowing them to survive node restarts.
+type snapshot struct {
+	path    string        // Filesystem path to store the transactions at
+	maxTxs  uint64        // Maximum number of transactions to store
+	maxSize uint64        // Maximum byte size of the transactions to store
+	maxAge  time.Duration // Maximum age of the transactions to restore
+}
+
+// newTxSnapshot creates a new remote transaction snapshot.
+func newTxSnapshot(path string, maxTxs uint64, maxSize uint64, maxAge time.Duration) *snapshot {
+	return &snapshot{
+		path:    path,
+		maxTxs:  maxTxs,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+	}
+}
+
+// load parses a transaction snapshot from disk, loading the transactions not older
+// than the age cutoff into the specified pool, in the order they were stored.
+func (snap *snapshot) load(add func([]*types.Transaction) []error) error {
+	input, err := os.Open(snap.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Skip the parsing if the snapshot file doesn't exist at all
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var (
+		stream  = rlp.NewStream(bufio.NewReader(input), 0)
+		cutoff  = time.Now().Add(-snap.maxAge)
+		batch   types.Transactions
+		failure error
+
+		total, pending, stale, dropped int
+	)
+	loadBatch := func(txs types.Transactions) {
+		for _, err := range add(txs) {
+			if err != nil {
+				log.Trace("Failed to add snapshotted transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		entry := new(snapshotEntry)
+		if err = stream.Decode(entry); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			if batch.Len() > 0 {
+				loadBatch(batch)
+			}
+			break
+		}
+		total++
+
+		// Drop the transactions which are too old to be worth restoring
+		arrival := time.UnixMilli(int64(entry.Time))
+		if arrival.Before(cutoff) {
+			stale++
+			continue
+		}
+		if entry.Pending {
+			pending++
+		}
+		entry.Tx.SetTime(arrival)
+		if batch = append(batch, entry.Tx); batch.Len() > 1024 {
+			loadBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	log.Info("Loaded remote transaction snapshot", "transactions", total, "pending", pending, "stale", stale, "dropped", dropped)
+	return failure
+}
+
+// write regenerates the transaction snapshot from the given entries, which are
+// expected to be in priority order. Entries beyond the size caps are discarded.
+func (snap *snapshot) write(entries []*snapshotEntry) error {
+	output, err := os.OpenFile(snap.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var (
+		writer = bufio.NewWriter(output)
+		count  uint64
+		size   uint64
+	)
+	for _, entry := range entries {
+		if count >= snap.maxTxs || size+entry.Tx.Size() > snap.maxSize {
+			break
+		}
+		if err = rlp.Encode(writer, entry); err != nil {
+			output.Close()
+			return err
+		}
+		count, size = count+1, size+entry.Tx.Size()
+	}
+	if err = writer.Flush(); err != nil {
+		output.Close()
+		return err
+	}
+	output.Close()
+
+	// Replace the previous snapshot with the newly generated one
+	if err = os.Rename(snap.path+".new", snap.path); err != nil {
+		return err
+	}
+	log.Debug("Regenerated remote transaction snapshot", "transactions", count, "size", size, "skipped", len(entries)-int(count))
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// senderHistoryLimit is the number of senders whose admission statistics are
+// retained by the pool.
+const senderHistoryLimit = 4096
+
+// Verdict is the decision of an admission policy about a transaction.
+type Verdict int
+
+const (
+	// Accept admits the transaction into the pool.
+	Accept Verdict = iota
+
+	// Deprioritize admits the transaction into the pool, but without any local
+	// exemptions, even if its sender is local or it is private, and orders it
+	// last for local block production.
+	Deprioritize
+
+	// Reject refuses the transaction, reporting the reason to the submitter.
+	Reject
+)
+
+// String implements fmt.Stringer.
+func (v Verdict) String() string {
+	switch v {
+	case Accept:
+		return "accept"
+	case Deprioritize:
+		return "deprioritize"
+	case Reject:
+		return "reject"
+	default:
+		return fmt.Sprintf("verdict(%d)", int(v))
+	}
+}
+
+// Policy is an admission rule run by the pool on every inbound transaction,
+// before it is handed to the subpools. Policies are registered by embedders to
+// enforce network specific rules without modifying the subpools.
+//
+// Policies are called concurrently and must be cheap, as they run for every
+// transaction received from the network.
+type Policy interface {
+	// Admit decides whether the transaction may enter the pool. A rejection must
+	// be accompanied by an error describing the reason, which is returned to the
+	// submitter of the transaction.
+	Admit(req *AdmissionRequest) (Verdict, error)
+}
+
+// PolicyFunc is an adapter to allow the use of ordinary functions as admission
+// policies.
+type PolicyFunc func(req *AdmissionRequest) (Verdict, error)
+
+// Admit implements Policy, calling f(req).
+func (f PolicyFunc) Admit(req *AdmissionRequest) (Verdict, error) {
+	return f(req)
+}
+
+// SenderHistory is what the pool knows about the past of a sender.
+type SenderHistory struct {
+	Nonce    uint64 // Next nonce of the sender, with its pooled transactions applied
+	Admitted uint64 // Number of transactions recently admitted by the policies
+	Rejected uint64 // Number of transactions recently rejected by the policies
+}
+
+// AdmissionRequest is a transaction to be admitted into the pool, along with the
+// details needed by the admission policies.
+type AdmissionRequest struct {
+	Tx    *types.Transaction // Transaction to be admitted
+	From  common.Address     // Sender of the transaction
+	Local bool               // Whether the transaction was submitted locally
+
+	pool    *TxPool
+	history *SenderHistory
+}
+
+// To returns the recipient of the transaction, or nil for contract creations.
+func (req *AdmissionRequest) To() *common.Address {
+	return req.Tx.To()
+}
+
+// Selector returns the 4 byte function selector of the calldata, or nil if the
+// calldata is too short to contain one.
+func (req *AdmissionRequest) Selector() []byte {
+	if data := req.Tx.Data(); len(data) >= 4 {
+		return data[:4]
+	}
+	return nil
+}
+
+// History returns what the pool knows about the past of the sender.
+func (req *AdmissionRequest) History() SenderHistory {
+	if req.history == nil {
+		history := req.pool.senderHistory(req.From)
+		req.history = &history
+	}
+	return *req.history
+}
+
+// ComposePolicies merges multiple policies into one, running them in order. The
+// first rejection is final, otherwise the transaction is deprioritized if any
+// of the policies deprioritized it.
+func ComposePolicies(policies ...Policy) Policy {
+	return PolicyFunc(func(req *AdmissionRequest) (Verdict, error) {
+		verdict := Accept
+		for _, policy := range policies {
+			v, err := policy.Admit(req)
+			switch v {
+			case Reject:
+				return Reject, err
+			case Deprioritize:
+				verdict = Deprioritize
+			}
+		}
+		return verdict, nil
+	})
+}
+
+// senderStats is the admission statistics of a sender, retained for the most
+// recently seen senders.
+type senderStats struct {
+	admitted uint64
+	rejected uint64
+}
+
+// RegisterPolicy adds an admission policy to the pool. Policies run in the order
+// of registration.
+func (p *TxPool) RegisterPolicy(policy Policy) {
+	p.policyLock.Lock()
+	defer p.policyLock.Unlock()
+
+	p.policies = append(p.policies, policy)
+}
+
+// senderHistory assembles what the pool knows about the past of a sender.
+func (p *TxPool) senderHistory(addr common.Address) SenderHistory {
+	p.policyLock.Lock()
+	stats, _ := p.senders.Get(addr)
+	p.policyLock.Unlock()
+
+	return SenderHistory{
+		Nonce:    p.Nonce(addr),
+		Admitted: stats.admitted,
+		Rejected: stats.rejected,
+	}
+}
+
+// admit runs the registered admission policies on a batch of transactions. It
+// returns the verdict for each, along with the reasons of the rejections.
+func (p *TxPool) admit(txs []*types.Transaction, local bool) ([]Verdict, []error) {
+	p.policyLock.RLock()
+	policies := p.policies
+	p.policyLock.RUnlock()
+
+	verdicts := make([]Verdict, len(txs))
+	errs := make([]error, len(txs))
+	if len(policies) == 0 {
+		return verdicts, errs
+	}
+	policy := ComposePolicies(policies...)
+	for i, tx := range txs {
+		// Invalid signatures are rejected by the subpools, leave them be
+		signer := types.LatestSignerForChainID(tx.ChainId())
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		req := &AdmissionRequest{Tx: tx, From: from, Local: local, pool: p}
+		verdicts[i], errs[i] = policy.Admit(req)
+
+		if verdicts[i] == Reject {
+			if errs[i] == nil {
+				errs[i] = ErrPolicyRejected
+			} else {
+				errs[i] = fmt.Errorf("%w: %w", ErrPolicyRejected, errs[i])
+			}
+		} else {
+			errs[i] = nil
+		}
+		p.policyLock.Lock()
+		stats, _ := p.senders.Get(from)
+		if verdicts[i] == Reject {
+			stats.rejected++
+		} else {
+			stats.admitted++
+		}
+		p.senders.Add(from, stats)
+		p.policyLock.Unlock()
+	}
+	return verdicts, errs
+}
@@ -41,6 +41,8 @@ type LazyTransaction struct {
 
 	Gas     uint64 // Amount of gas required by the transaction
 	BlobGas uint64 // Amount of blob gas required by the transaction
+
+	Deprioritized bool // Whether an admission policy ordered the transaction last
 }
 
 // Resolve retrieves the full transaction belonging to a lazy handle if it is still
@@ -128,6 +130,21 @@ type SubPool interface {
 	// to a later point to batch multiple ones together.
 	Add(txs []*types.Transaction, local bool, sync bool) []error
 
+	// AddPrivate enqueues a batch of local transactions which are never announced
+	// to the network, only included by the local block producer. Subpools unable
+	// to keep transactions private reject them.
+	AddPrivate(txs []*types.Transaction, sync bool) []error
+
+	// AddDeprioritized enqueues a batch of transactions deprioritized by the
+	// admission policies. They are never granted the exemptions of local ones,
+	// not even if their sender is local or they are private, in which case they
+	// are added like by AddPrivate otherwise.
+	AddDeprioritized(txs []*types.Transaction, private bool, sync bool) []error
+
+	// IsPrivate returns whether a transaction was submitted privately and thus
+	// must not be announced to the network.
+	IsPrivate(hash common.Hash) bool
+
 	// Pending retrieves all currently processable transactions, grouped by origin
 	// account and sorted by nonce.
 	//
@@ -140,6 +157,10 @@ type SubPool interface {
 	// or also for reorged out ones.
 	SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription
 
+	// SubscribeLifecycleEvents subscribes to the state changes of the pooled
+	// transactions, from their addition until their inclusion or eviction.
+	SubscribeLifecycleEvents(ch chan<- core.TxLifecycleEvent) event.Subscription
+
 	// Nonce returns the next nonce of an account, with all transactions executable
 	// by the pool already applied on top.
 	Nonce(addr common.Address) uint64
@@ -159,6 +180,19 @@ type SubPool interface {
 	// Locals retrieves the accounts currently considered local by the pool.
 	Locals() []common.Address
 
+	// SetLocals replaces the set of accounts considered local by the pool, with
+	// the transactions of the affected accounts migrated accordingly.
+	SetLocals(addrs []common.Address)
+
+	// Remove evicts a transaction from the pool, returning whether it was found.
+	// Depending on the subpool, some of the sender's subsequent transactions may
+	// also be dropped.
+	Remove(hash common.Hash) bool
+
+	// RemoveSender evicts all the transactions of an account from the pool,
+	// returning the number of transactions removed.
+	RemoveSender(addr common.Address) int
+
 	// Status returns the known status (unknown/pending/queued) of a transaction
 	// identified by their hashes.
 	Status(hash common.Hash) TxStatus
@@ -23,6 +23,7 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
@@ -75,6 +76,13 @@ type TxPool struct {
 	term chan struct{}           // Termination channel to detect a closed pool
 
 	sync chan chan error // Testing / simulator channel to block until internal reset is done
+
+	policies   []Policy                                  // Admission policies to run on inbound transactions
+	senders    lru.BasicLRU[common.Address, senderStats] // Admission statistics of recently seen senders
+	policyLock sync.RWMutex                              // Lock protecting the policies and sender statistics
+
+	deprioritized map[common.Hash]struct{} // Transactions ordered last for local block production
+	depriLock     sync.RWMutex             // Lock protecting the deprioritized transactions
 }
 
 // New creates a new transaction pool to gather, sort and filter inbound
@@ -91,6 +99,9 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 		quit:         make(chan chan error),
 		term:         make(chan struct{}),
 		sync:         make(chan chan error),
+
+		senders:       lru.NewBasicLRU[common.Address, senderStats](senderHistoryLimit),
+		deprioritized: make(map[common.Hash]struct{}),
 	}
 	for i, subpool := range subpools {
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
@@ -250,6 +261,9 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 			oldHead = head
 			<-resetBusy
 
+			// Forget the deprioritized transactions which left the pool
+			p.pruneDeprioritized()
+
 			// If someone is waiting for a reset to finish, notify them, unless
 			// the forced op is still pending. In that case, wait another round
 			// of resets.
@@ -309,6 +323,70 @@ func (p *TxPool) Get(hash common.Hash) *types.Transaction {
 // to the large transaction churn, add may postpone fully integrating the tx
 // to a later point to batch multiple ones together.
 func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	return p.add(txs, local, false, sync)
+}
+
+// AddPrivate enqueues a batch of local transactions which are never announced
+// to the network, only included by the local block producer.
+func (p *TxPool) AddPrivate(txs []*types.Transaction, sync bool) []error {
+	return p.add(txs, true, true, sync)
+}
+
+// add runs the admission policies on the transactions and inserts the admitted
+// ones into the subpools, privately if requested. Deprioritized transactions
+// are inserted without any of the exemptions of local ones.
+func (p *TxPool) add(txs []*types.Transaction, local bool, private bool, sync bool) []error {
+	verdicts, errs := p.admit(txs, local)
+
+	var (
+		accepted, demoted []*types.Transaction
+		acceptIdx, demIdx []int
+	)
+	for i, tx := range txs {
+		switch verdicts[i] {
+		case Accept:
+			accepted, acceptIdx = append(accepted, tx), append(acceptIdx, i)
+		case Deprioritize:
+			demoted, demIdx = append(demoted, tx), append(demIdx, i)
+		}
+	}
+	if len(accepted) > 0 {
+		insertErrs := p.split(accepted, func(subpool SubPool, txs []*types.Transaction) []error {
+			if private {
+				return subpool.AddPrivate(txs, sync)
+			}
+			return subpool.Add(txs, local, sync)
+		})
+		for i, err := range insertErrs {
+			errs[acceptIdx[i]] = err
+		}
+	}
+	if len(demoted) > 0 {
+		// Mark the transactions before insertion to never order them normally
+		p.depriLock.Lock()
+		for _, tx := range demoted {
+			p.deprioritized[tx.Hash()] = struct{}{}
+		}
+		p.depriLock.Unlock()
+
+		insertErrs := p.split(demoted, func(subpool SubPool, txs []*types.Transaction) []error {
+			return subpool.AddDeprioritized(txs, private, sync)
+		})
+		p.depriLock.Lock()
+		for i, err := range insertErrs {
+			if err != nil {
+				delete(p.deprioritized, demoted[i].Hash())
+			}
+			errs[demIdx[i]] = err
+		}
+		p.depriLock.Unlock()
+	}
+	return errs
+}
+
+// split divides the transactions between the subpools, inserts them with the
+// given method and pieces the errors back together in the original order.
+func (p *TxPool) split(txs []*types.Transaction, insert func(subpool SubPool, txs []*types.Transaction) []error) []error {
 	// Split the input transactions between the subpools. It shouldn't really
 	// happen that we receive merged batches, but better graceful than strange
 	// errors.
@@ -335,7 +413,7 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	// back the errors into the original sort order.
 	errsets := make([][]error, len(p.subpools))
 	for i := 0; i < len(p.subpools); i++ {
-		errsets[i] = p.subpools[i].Add(txsets[i], local, sync)
+		errsets[i] = insert(p.subpools[i], txsets[i])
 	}
 	errs := make([]error, len(txs))
 	for i, split := range splits {
@@ -351,6 +429,17 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	return errs
 }
 
+// IsPrivate returns whether a transaction was submitted privately and thus must
+// not be announced to the network.
+func (p *TxPool) IsPrivate(hash common.Hash) bool {
+	for _, subpool := range p.subpools {
+		if subpool.IsPrivate(hash) {
+			return true
+		}
+	}
+	return false
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce.
 //
@@ -363,9 +452,37 @@ func (p *TxPool) Pending(filter PendingFilter) map[common.Address][]*LazyTransac
 			txs[addr] = set
 		}
 	}
+	// Flag the deprioritized transactions for the miner to order them last
+	p.depriLock.RLock()
+	defer p.depriLock.RUnlock()
+
+	if len(p.deprioritized) > 0 {
+		for _, set := range txs {
+			for i, ltx := range set {
+				if _, ok := p.deprioritized[ltx.Hash]; ok {
+					cpy := *ltx
+					cpy.Deprioritized = true
+					set[i] = &cpy
+				}
+			}
+		}
+	}
 	return txs
 }
 
+// pruneDeprioritized drops the marks of the deprioritized transactions which are
+// not in the pool anymore.
+func (p *TxPool) pruneDeprioritized() {
+	p.depriLock.Lock()
+	defer p.depriLock.Unlock()
+
+	for hash := range p.deprioritized {
+		if !p.Has(hash) {
+			delete(p.deprioritized, hash)
+		}
+	}
+}
+
 // SubscribeTransactions registers a subscription for new transaction events,
 // supporting feeding only newly seen or also resurrected transactions.
 func (p *TxPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
@@ -376,6 +493,16 @@ func (p *TxPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool)
 	return p.subs.Track(event.JoinSubscriptions(subs...))
 }
 
+// SubscribeLifecycleEvents registers a subscription for the state changes of the
+// pooled transactions, from their addition until their inclusion or eviction.
+func (p *TxPool) SubscribeLifecycleEvents(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	subs := make([]event.Subscription, len(p.subpools))
+	for i, subpool := range p.subpools {
+		subs[i] = subpool.SubscribeLifecycleEvents(ch)
+	}
+	return p.subs.Track(event.JoinSubscriptions(subs...))
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (p *TxPool) Nonce(addr common.Address) uint64 {
@@ -453,6 +580,33 @@ func (p *TxPool) Locals() []common.Address {
 	return flat
 }
 
+// SetLocals replaces the set of accounts considered local by the pool.
+func (p *TxPool) SetLocals(addrs []common.Address) {
+	for _, subpool := range p.subpools {
+		subpool.SetLocals(addrs)
+	}
+}
+
+// Remove evicts a transaction from the pool, returning whether it was found.
+func (p *TxPool) Remove(hash common.Hash) bool {
+	for _, subpool := range p.subpools {
+		if subpool.Remove(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSender evicts all the transactions of an account from the pool,
+// returning the number of transactions removed.
+func (p *TxPool) RemoveSender(addr common.Address) int {
+	var removed int
+	for _, subpool := range p.subpools {
+		removed += subpool.RemoveSender(addr)
+	}
+	return removed
+}
+
 // Status returns the known status (unknown/pending/queued) of a transaction
 // identified by its hash.
 func (p *TxPool) Status(hash common.Hash) TxStatus {
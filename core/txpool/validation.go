@@ -132,6 +132,15 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 		if len(hashes) > params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob {
 			return fmt.Errorf("too many blobs in transaction: have %d, permitted %d", len(hashes), params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob)
 		}
+		// Ensure the sidecar version matches the fork, cell proofs being required
+		// from Osaka on and rejected before
+		if opts.Config.IsOsaka(head.Number, head.Time) {
+			if sidecar.Version != types.BlobSidecarVersion1 {
+				return fmt.Errorf("%w: sidecar version %d rejected, pool in Osaka", ErrSidecarVersion, sidecar.Version)
+			}
+		} else if sidecar.Version != types.BlobSidecarVersion0 {
+			return fmt.Errorf("%w: sidecar version %d rejected, pool not yet in Osaka", ErrSidecarVersion, sidecar.Version)
+		}
 		// Ensure commitments, proofs and hashes are valid
 		if err := validateBlobSidecar(hashes, sidecar); err != nil {
 			return err
@@ -147,7 +156,11 @@ func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) err
 	if len(sidecar.Commitments) != len(hashes) {
 		return fmt.Errorf("invalid number of %d blob commitments compared to %d blob hashes", len(sidecar.Commitments), len(hashes))
 	}
-	if len(sidecar.Proofs) != len(hashes) {
+	proofs := len(hashes)
+	if sidecar.Version == types.BlobSidecarVersion1 {
+		proofs *= kzg4844.CellProofsPerBlob
+	}
+	if len(sidecar.Proofs) != proofs {
 		return fmt.Errorf("invalid number of %d blob proofs compared to %d blob hashes", len(sidecar.Proofs), len(hashes))
 	}
 	// Blob quantities match up, validate that the provers match with the
@@ -161,6 +174,12 @@ func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) err
 	}
 	// Blob commitments match with the hashes in the transaction, verify the
 	// blobs themselves via KZG
+	if sidecar.Version == types.BlobSidecarVersion1 {
+		if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+			return fmt.Errorf("invalid blobs: %v", err)
+		}
+		return nil
+	}
 	for i := range sidecar.Blobs {
 		if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
 			return fmt.Errorf("invalid blob %d: %v", i, err)
@@ -248,3 +267,29 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 	}
 	return nil
 }
+
+// ValidateConditional checks whether the inclusion conditions of a transaction
+// hold for a block with the given header, built on top of the given state.
+func ValidateConditional(cond *types.TransactionConditional, header *types.Header, state *state.StateDB) error {
+	if err := cond.CheckBlock(header.Number, header.Time); err != nil {
+		return err
+	}
+	for addr, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			root := state.GetStorageRoot(addr)
+			if root == (common.Hash{}) {
+				root = types.EmptyRootHash
+			}
+			if root != *account.StorageRoot {
+				return fmt.Errorf("%w: storage root mismatch for %v: have %x, want %x", types.ErrConditionalFailed, addr, root, *account.StorageRoot)
+			}
+			continue
+		}
+		for slot, want := range account.StorageSlots {
+			if have := state.GetState(addr, slot); have != want {
+				return fmt.Errorf("%w: storage slot %x mismatch for %v: have %x, want %x", types.ErrConditionalFailed, slot, addr, have, want)
+			}
+		}
+	}
+	return nil
+}
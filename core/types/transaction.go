@@ -60,6 +60,10 @@ type Transaction struct {
 	hash atomic.Pointer[common.Hash]
 	size atomic.Uint64
 	from atomic.Pointer[sigCache]
+
+	// conditional is the optional set of conditions required for inclusion. It
+	// is local metadata, not part of the consensus encoding.
+	conditional atomic.Pointer[TransactionConditional]
 }
 
 // NewTx creates a new transaction.
@@ -473,6 +477,18 @@ func (tx *Transaction) SetTime(t time.Time) {
 	tx.time = t
 }
 
+// Conditional returns the conditions required for the inclusion of the
+// transaction, or nil if there are none.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	return tx.conditional.Load()
+}
+
+// SetConditional sets the conditions required for the inclusion of the
+// transaction.
+func (tx *Transaction) SetConditional(cond *TransactionConditional) {
+	tx.conditional.Store(cond)
+}
+
 // Time returns the time when the transaction was first seen on the network. It
 // is a heuristic to prefer mining older txs vs new all other things equal.
 func (tx *Transaction) Time() time.Time {
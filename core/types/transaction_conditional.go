@@ -0,0 +1,151 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrConditionalFailed is returned if the conditions of a transaction don't hold.
+var ErrConditionalFailed = errors.New("transaction conditional failed")
+
+// KnownAccount is the expected storage of an account, given either as the root
+// of its storage trie, or as the values of a set of its storage slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// MarshalJSON encodes the expected storage either as a root hash or as a map of
+// storage slots to their values.
+func (ka KnownAccount) MarshalJSON() ([]byte, error) {
+	if ka.StorageRoot != nil {
+		return json.Marshal(ka.StorageRoot)
+	}
+	return json.Marshal(ka.StorageSlots)
+}
+
+// UnmarshalJSON decodes the expected storage from either a root hash or a map
+// of storage slots to their values.
+func (ka *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		ka.StorageRoot, ka.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or a map of storage slots")
+	}
+	ka.StorageRoot, ka.StorageSlots = nil, slots
+	return nil
+}
+
+// KnownAccounts is the expected storage of a set of accounts.
+type KnownAccounts map[common.Address]KnownAccount
+
+// TransactionConditional is a set of conditions which must hold for a transaction
+// to be included in a block. Unset bounds are not checked.
+type TransactionConditional struct {
+	KnownAccounts  KnownAccounts
+	BlockNumberMin *big.Int
+	BlockNumberMax *big.Int
+	TimestampMin   *uint64
+	TimestampMax   *uint64
+}
+
+// conditionalJSON is the JSON representation of a transaction conditional.
+type conditionalJSON struct {
+	KnownAccounts  KnownAccounts   `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Big    `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big    `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64 `json:"timestampMax,omitempty"`
+}
+
+// MarshalJSON marshals as JSON.
+func (c TransactionConditional) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&conditionalJSON{
+		KnownAccounts:  c.KnownAccounts,
+		BlockNumberMin: (*hexutil.Big)(c.BlockNumberMin),
+		BlockNumberMax: (*hexutil.Big)(c.BlockNumberMax),
+		TimestampMin:   (*hexutil.Uint64)(c.TimestampMin),
+		TimestampMax:   (*hexutil.Uint64)(c.TimestampMax),
+	})
+}
+
+// UnmarshalJSON unmarshals from JSON.
+func (c *TransactionConditional) UnmarshalJSON(input []byte) error {
+	var dec conditionalJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	c.KnownAccounts = dec.KnownAccounts
+	c.BlockNumberMin = (*big.Int)(dec.BlockNumberMin)
+	c.BlockNumberMax = (*big.Int)(dec.BlockNumberMax)
+	c.TimestampMin = (*uint64)(dec.TimestampMin)
+	c.TimestampMax = (*uint64)(dec.TimestampMax)
+	return nil
+}
+
+// Cost returns the number of storage lookups needed to check the conditions.
+func (c *TransactionConditional) Cost() int {
+	var cost int
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		} else {
+			cost += len(account.StorageSlots)
+		}
+	}
+	return cost
+}
+
+// Validate checks that the conditions are well formed.
+func (c *TransactionConditional) Validate() error {
+	if c.BlockNumberMin != nil && c.BlockNumberMax != nil && c.BlockNumberMin.Cmp(c.BlockNumberMax) > 0 {
+		return fmt.Errorf("block number range empty: min %v, max %v", c.BlockNumberMin, c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && c.TimestampMax != nil && *c.TimestampMin > *c.TimestampMax {
+		return fmt.Errorf("timestamp range empty: min %d, max %d", *c.TimestampMin, *c.TimestampMax)
+	}
+	return nil
+}
+
+// CheckBlock checks whether the block number and timestamp bounds hold for a
+// block with the given number and timestamp.
+func (c *TransactionConditional) CheckBlock(number *big.Int, time uint64) error {
+	if c.BlockNumberMin != nil && number.Cmp(c.BlockNumberMin) < 0 {
+		return fmt.Errorf("%w: block number %v below minimum %v", ErrConditionalFailed, number, c.BlockNumberMin)
+	}
+	if c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax) > 0 {
+		return fmt.Errorf("%w: block number %v above maximum %v", ErrConditionalFailed, number, c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && time < *c.TimestampMin {
+		return fmt.Errorf("%w: timestamp %d below minimum %d", ErrConditionalFailed, time, *c.TimestampMin)
+	}
+	if c.TimestampMax != nil && time > *c.TimestampMax {
+		return fmt.Errorf("%w: timestamp %d above maximum %d", ErrConditionalFailed, time, *c.TimestampMax)
+	}
+	return nil
+}
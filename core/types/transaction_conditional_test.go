@@ -0,0 +1,112 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTransactionConditionalJSON(t *testing.T) {
+	input := `{
+		"knownAccounts": {
+			"0x0000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002",
+			"0x0000000000000000000000000000000000000003": {
+				"0x0000000000000000000000000000000000000000000000000000000000000004": "0x0000000000000000000000000000000000000000000000000000000000000005"
+			}
+		},
+		"blockNumberMin": "0x10",
+		"timestampMax": "0x20"
+	}`
+	var cond TransactionConditional
+	if err := json.Unmarshal([]byte(input), &cond); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		root = common.HexToHash("0x02")
+		max  = uint64(0x20)
+		want = TransactionConditional{
+			KnownAccounts: KnownAccounts{
+				common.HexToAddress("0x01"): {StorageRoot: &root},
+				common.HexToAddress("0x03"): {StorageSlots: map[common.Hash]common.Hash{
+					common.HexToHash("0x04"): common.HexToHash("0x05"),
+				}},
+			},
+			BlockNumberMin: big.NewInt(0x10),
+			TimestampMax:   &max,
+		}
+	)
+	if !reflect.DeepEqual(cond, want) {
+		t.Fatalf("conditional mismatch: have %+v, want %+v", cond, want)
+	}
+	if cost := cond.Cost(); cost != 2 {
+		t.Fatalf("cost mismatch: have %d, want %d", cost, 2)
+	}
+	// Ensure the conditional survives a round trip
+	blob, err := json.Marshal(cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec TransactionConditional
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, want) {
+		t.Fatalf("round trip mismatch: have %+v, want %+v", dec, want)
+	}
+}
+
+func TestTransactionConditionalCheckBlock(t *testing.T) {
+	var (
+		min, max = uint64(100), uint64(200)
+		cond     = &TransactionConditional{
+			BlockNumberMin: big.NewInt(10),
+			BlockNumberMax: big.NewInt(20),
+			TimestampMin:   &min,
+			TimestampMax:   &max,
+		}
+	)
+	tests := []struct {
+		number int64
+		time   uint64
+		fail   bool
+	}{
+		{10, 100, false},
+		{20, 200, false},
+		{9, 150, true},
+		{21, 150, true},
+		{15, 99, true},
+		{15, 201, true},
+	}
+	for i, tt := range tests {
+		err := cond.CheckBlock(big.NewInt(tt.number), tt.time)
+		if fail := err != nil; fail != tt.fail {
+			t.Errorf("test %d: failure mismatch: have %v, want %t", i, err, tt.fail)
+		}
+		if err != nil && !errors.Is(err, ErrConditionalFailed) {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+	if err := (&TransactionConditional{BlockNumberMin: big.NewInt(2), BlockNumberMax: big.NewInt(1)}).Validate(); err == nil {
+		t.Error("empty block range accepted")
+	}
+}
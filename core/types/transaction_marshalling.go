@@ -49,9 +49,10 @@ type txJSON struct {
 	YParity              *hexutil.Uint64 `json:"yParity,omitempty"`
 
 	// Blob transaction sidecar encoding:
-	Blobs       []kzg4844.Blob       `json:"blobs,omitempty"`
-	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
-	Proofs      []kzg4844.Proof      `json:"proofs,omitempty"`
+	SidecarVersion *hexutil.Uint64      `json:"sidecarVersion,omitempty"`
+	Blobs          []kzg4844.Blob       `json:"blobs,omitempty"`
+	Commitments    []kzg4844.Commitment `json:"commitments,omitempty"`
+	Proofs         []kzg4844.Proof      `json:"proofs,omitempty"`
 
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
@@ -149,6 +150,10 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 		yparity := itx.V.Uint64()
 		enc.YParity = (*hexutil.Uint64)(&yparity)
 		if sidecar := itx.Sidecar; sidecar != nil {
+			if sidecar.Version != BlobSidecarVersion0 {
+				version := uint64(sidecar.Version)
+				enc.SidecarVersion = (*hexutil.Uint64)(&version)
+			}
 			enc.Blobs = itx.Sidecar.Blobs
 			enc.Commitments = itx.Sidecar.Commitments
 			enc.Proofs = itx.Sidecar.Proofs
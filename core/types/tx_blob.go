@@ -19,6 +19,8 @@ package types
 import (
 	"bytes"
 	"crypto/sha256"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -52,13 +54,53 @@ type BlobTx struct {
 	S *uint256.Int `json:"s" gencodec:"required"`
 }
 
+// Versions of the blob transaction sidecar, differing in the proofs carried.
+const (
+	BlobSidecarVersion0 = byte(0) // One proof per blob
+	BlobSidecarVersion1 = byte(1) // One proof per cell of the extended blobs
+)
+
 // BlobTxSidecar contains the blobs of a blob transaction.
 type BlobTxSidecar struct {
+	Version     byte                 // Version of the sidecar, determining the kind of proofs
 	Blobs       []kzg4844.Blob       // Blobs needed by the blob pool
 	Commitments []kzg4844.Commitment // Commitments needed by the blob pool
 	Proofs      []kzg4844.Proof      // Proofs needed by the blob pool
 }
 
+// ToV1 converts the sidecar to version 1 in place, replacing the blob proofs with
+// the proofs of all the cells of the extended blobs. Sidecars which are already
+// of version 1 are left untouched.
+func (sc *BlobTxSidecar) ToV1() error {
+	switch sc.Version {
+	case BlobSidecarVersion1:
+		return nil
+	case BlobSidecarVersion0:
+	default:
+		return fmt.Errorf("unsupported sidecar version %d", sc.Version)
+	}
+	proofs := make([]kzg4844.Proof, 0, len(sc.Blobs)*kzg4844.CellProofsPerBlob)
+	for i := range sc.Blobs {
+		cellProofs, err := kzg4844.ComputeCellProofs(&sc.Blobs[i])
+		if err != nil {
+			return err
+		}
+		proofs = append(proofs, cellProofs...)
+	}
+	sc.Version, sc.Proofs = BlobSidecarVersion1, proofs
+	return nil
+}
+
+// Copy returns a deep copy of the sidecar.
+func (sc *BlobTxSidecar) Copy() *BlobTxSidecar {
+	return &BlobTxSidecar{
+		Version:     sc.Version,
+		Blobs:       append([]kzg4844.Blob(nil), sc.Blobs...),
+		Commitments: append([]kzg4844.Commitment(nil), sc.Commitments...),
+		Proofs:      append([]kzg4844.Proof(nil), sc.Proofs...),
+	}
+}
+
 // BlobHashes computes the blob hashes of the given blobs.
 func (sc *BlobTxSidecar) BlobHashes() []common.Hash {
 	hasher := sha256.New()
@@ -82,7 +124,11 @@ func (sc *BlobTxSidecar) encodedSize() uint64 {
 	for i := range sc.Proofs {
 		proofs += rlp.BytesSize(sc.Proofs[i][:])
 	}
-	return rlp.ListSize(blobs) + rlp.ListSize(commitments) + rlp.ListSize(proofs)
+	size := rlp.ListSize(blobs) + rlp.ListSize(commitments) + rlp.ListSize(proofs)
+	if sc.Version != BlobSidecarVersion0 {
+		size += uint64(rlp.IntSize(uint64(sc.Version)))
+	}
+	return size
 }
 
 // blobTxWithBlobs is used for encoding of transactions when blobs are present.
@@ -93,6 +139,16 @@ type blobTxWithBlobs struct {
 	Proofs      []kzg4844.Proof
 }
 
+// blobTxWithBlobsV1 is used for encoding of transactions when blobs are present
+// along with cell proofs, as of version 1 of the sidecar.
+type blobTxWithBlobsV1 struct {
+	BlobTx      *BlobTx
+	Version     byte
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
 // copy creates a deep copy of the transaction data and initializes all fields.
 func (tx *BlobTx) copy() TxData {
 	cpy := &BlobTx{
@@ -140,11 +196,7 @@ func (tx *BlobTx) copy() TxData {
 		cpy.S.Set(tx.S)
 	}
 	if tx.Sidecar != nil {
-		cpy.Sidecar = &BlobTxSidecar{
-			Blobs:       append([]kzg4844.Blob(nil), tx.Sidecar.Blobs...),
-			Commitments: append([]kzg4844.Commitment(nil), tx.Sidecar.Commitments...),
-			Proofs:      append([]kzg4844.Proof(nil), tx.Sidecar.Proofs...),
-		}
+		cpy.Sidecar = tx.Sidecar.Copy()
 	}
 	return cpy
 }
@@ -198,16 +250,32 @@ func (tx *BlobTx) withSidecar(sideCar *BlobTxSidecar) *BlobTx {
 }
 
 func (tx *BlobTx) encode(b *bytes.Buffer) error {
-	if tx.Sidecar == nil {
+	switch {
+	case tx.Sidecar == nil:
 		return rlp.Encode(b, tx)
+
+	case tx.Sidecar.Version == BlobSidecarVersion0:
+		inner := &blobTxWithBlobs{
+			BlobTx:      tx,
+			Blobs:       tx.Sidecar.Blobs,
+			Commitments: tx.Sidecar.Commitments,
+			Proofs:      tx.Sidecar.Proofs,
+		}
+		return rlp.Encode(b, inner)
+
+	case tx.Sidecar.Version == BlobSidecarVersion1:
+		inner := &blobTxWithBlobsV1{
+			BlobTx:      tx,
+			Version:     tx.Sidecar.Version,
+			Blobs:       tx.Sidecar.Blobs,
+			Commitments: tx.Sidecar.Commitments,
+			Proofs:      tx.Sidecar.Proofs,
+		}
+		return rlp.Encode(b, inner)
+
+	default:
+		return fmt.Errorf("unsupported sidecar version %d", tx.Sidecar.Version)
 	}
-	inner := &blobTxWithBlobs{
-		BlobTx:      tx,
-		Blobs:       tx.Sidecar.Blobs,
-		Commitments: tx.Sidecar.Commitments,
-		Proofs:      tx.Sidecar.Proofs,
-	}
-	return rlp.Encode(b, inner)
 }
 
 func (tx *BlobTx) decode(input []byte) error {
@@ -229,16 +297,42 @@ func (tx *BlobTx) decode(input []byte) error {
 	if firstElemKind != rlp.List {
 		return rlp.DecodeBytes(input, tx)
 	}
-	// It's a tx with blobs.
-	var inner blobTxWithBlobs
-	if err := rlp.DecodeBytes(input, &inner); err != nil {
+	// It's a tx with blobs. The sidecar versions are distinguished by the number
+	// of elements, as version 1 includes the version explicitly.
+	elems, err := rlp.CountValues(outerList)
+	if err != nil {
 		return err
 	}
-	*tx = *inner.BlobTx
-	tx.Sidecar = &BlobTxSidecar{
-		Blobs:       inner.Blobs,
-		Commitments: inner.Commitments,
-		Proofs:      inner.Proofs,
+	switch elems {
+	case 4:
+		var inner blobTxWithBlobs
+		if err := rlp.DecodeBytes(input, &inner); err != nil {
+			return err
+		}
+		*tx = *inner.BlobTx
+		tx.Sidecar = &BlobTxSidecar{
+			Version:     BlobSidecarVersion0,
+			Blobs:       inner.Blobs,
+			Commitments: inner.Commitments,
+			Proofs:      inner.Proofs,
+		}
+	case 5:
+		var inner blobTxWithBlobsV1
+		if err := rlp.DecodeBytes(input, &inner); err != nil {
+			return err
+		}
+		if inner.Version != BlobSidecarVersion1 {
+			return fmt.Errorf("unsupported sidecar version %d", inner.Version)
+		}
+		*tx = *inner.BlobTx
+		tx.Sidecar = &BlobTxSidecar{
+			Version:     inner.Version,
+			Blobs:       inner.Blobs,
+			Commitments: inner.Commitments,
+			Proofs:      inner.Proofs,
+		}
+	default:
+		return errors.New("invalid blob transaction network encoding")
 	}
 	return nil
 }
@@ -58,6 +58,44 @@ func TestBlobTxSize(t *testing.T) {
 	}
 }
 
+// This test verifies that version 1 sidecars with cell proofs survive the network
+// encoding, which remains distinguishable from version 0.
+func TestBlobTxSidecarV1(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx := createEmptyBlobTx(key, true)
+
+	sidecar := tx.BlobTxSidecar().Copy()
+	if err := sidecar.ToV1(); err != nil {
+		t.Fatalf("failed to convert sidecar: %v", err)
+	}
+	if have, want := len(sidecar.Proofs), kzg4844.CellProofsPerBlob; have != want {
+		t.Fatalf("cell proof count mismatch: have %d, want %d", have, want)
+	}
+	if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+		t.Fatalf("failed to verify cell proofs: %v", err)
+	}
+	for _, tx := range []*Transaction{tx, tx.WithBlobTxSidecar(sidecar)} {
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode transaction: %v", err)
+		}
+		if size := tx.Size(); size != uint64(len(enc)) {
+			t.Errorf("wrong size: have %d, encoded length %d", size, len(enc))
+		}
+		dec := new(Transaction)
+		if err := dec.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if dec.Hash() != tx.Hash() {
+			t.Errorf("hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+		}
+		have, want := dec.BlobTxSidecar(), tx.BlobTxSidecar()
+		if have.Version != want.Version || len(have.Proofs) != len(want.Proofs) {
+			t.Errorf("sidecar mismatch: have version %d with %d proofs, want version %d with %d proofs", have.Version, len(have.Proofs), want.Version, len(want.Proofs))
+		}
+	}
+}
+
 var (
 	emptyBlob          = new(kzg4844.Blob)
 	emptyBlobCommit, _ = kzg4844.BlobToCommitment(emptyBlob)
@@ -16,6 +16,13 @@
 
 package vm
 
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
 const (
 	set2BitsMask = uint16(0b11)
 	set3BitsMask = uint16(0b111)
@@ -116,3 +123,79 @@ func codeBitmapInternal(code, bits bitvec) bitvec {
 	}
 	return bits
 }
+
+// basicBlock is a straight-line sequence of instructions, entered only at its
+// first instruction. All instructions but the last one have a constant gas cost,
+// cannot fail and cannot observe the remaining gas, so the gas and stack checks
+// of the whole block can be done once when it is entered.
+type basicBlock struct {
+	start    uint64 // Program counter of the first instruction
+	ops      uint32 // Number of instructions in the block
+	gas      uint64 // Aggregated constant gas of all instructions
+	minStack int    // Minimum stack height required when entering the block
+	maxStack int    // Maximum stack height allowed when entering the block
+}
+
+// endsBasicBlock reports whether the instruction must be the last one of a basic
+// block: it has a dynamic gas cost, alters the control flow, can fail or exposes
+// the remaining gas to the program.
+func endsBasicBlock(op OpCode, operation *operation) bool {
+	if operation.dynamicGas != nil || operation.undefined {
+		return true
+	}
+	switch op {
+	case STOP, JUMP, JUMPI, RETURN, REVERT, SELFDESTRUCT, INVALID, GAS, TSTORE:
+		return true
+	}
+	return false
+}
+
+// codeBlocks splits the code into basic blocks under the given instruction set.
+// A new block is started at every JUMPDEST, since those are the only valid jump
+// destinations, and after every instruction ending a block. The returned blocks
+// are ordered by their start position.
+func codeBlocks(code []byte, table *JumpTable) []basicBlock {
+	var (
+		blocks []basicBlock
+		block  = basicBlock{maxStack: int(params.StackLimit)}
+		height int // Stack height relative to the entry of the block
+	)
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		op := OpCode(code[pc])
+		if op == JUMPDEST && block.ops > 0 {
+			blocks = append(blocks, block)
+			block, height = basicBlock{start: pc, maxStack: int(params.StackLimit)}, 0
+		}
+		operation := table[op]
+
+		block.ops++
+		block.gas += operation.constantGas
+		block.minStack = max(block.minStack, operation.minStack-height)
+		block.maxStack = min(block.maxStack, operation.maxStack-height)
+		height += int(params.StackLimit) - operation.maxStack
+
+		if op.IsPush() {
+			pc += uint64(op - PUSH0)
+		}
+		if endsBasicBlock(op, operation) {
+			blocks = append(blocks, block)
+			block, height = basicBlock{start: pc + 1, maxStack: int(params.StackLimit)}, 0
+		}
+	}
+	if block.ops > 0 {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// findBasicBlock returns the index of the basic block starting at the given
+// program counter. The hint is the index of the last entered block, used to
+// avoid a search when execution falls through to the next block.
+func findBasicBlock(blocks []basicBlock, hint int, pc uint64) (int, bool) {
+	if next := hint + 1; next < len(blocks) && blocks[next].start == pc {
+		return next, true
+	}
+	return slices.BinarySearchFunc(blocks, pc, func(b basicBlock, pc uint64) int {
+		return cmp.Compare(b.start, pc)
+	})
+}
@@ -18,6 +18,7 @@ package vm
 
 import (
 	"math/bits"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -105,3 +106,48 @@ func BenchmarkJumpdestOpAnalysis(bench *testing.B) {
 	op = STOP
 	bench.Run(op.String(), bencher)
 }
+
+func TestCodeBlocks(t *testing.T) {
+	table := &cancunInstructionSet
+	tests := []struct {
+		code []byte
+		exp  []basicBlock
+	}{
+		// push1 push1 add stop
+		{[]byte{byte(PUSH1), 0x01, byte(PUSH1), 0x02, byte(ADD), byte(STOP)}, []basicBlock{
+			{start: 0, ops: 4, gas: 9, minStack: 0, maxStack: 1022},
+		}},
+		// add jumpdest pop: the jumpdest starts a new block
+		{[]byte{byte(ADD), byte(JUMPDEST), byte(POP)}, []basicBlock{
+			{start: 0, ops: 1, gas: 3, minStack: 2, maxStack: 1024},
+			{start: 1, ops: 2, gas: 3, minStack: 1, maxStack: 1024},
+		}},
+		// push1 jump push32 (truncated): jumps end a block, push data is skipped
+		{[]byte{byte(PUSH1), 0x03, byte(JUMP), byte(PUSH32), byte(JUMPDEST)}, []basicBlock{
+			{start: 0, ops: 2, gas: 11, minStack: 0, maxStack: 1023},
+			{start: 3, ops: 1, gas: 3, minStack: 0, maxStack: 1023},
+		}},
+		// dup2 mstore gas: dynamic gas and gas introspection end blocks
+		{[]byte{byte(DUP2), byte(MSTORE), byte(GAS), byte(POP)}, []basicBlock{
+			{start: 0, ops: 2, gas: 6, minStack: 2, maxStack: 1023},
+			{start: 2, ops: 1, gas: 2, minStack: 0, maxStack: 1023},
+			{start: 3, ops: 1, gas: 2, minStack: 1, maxStack: 1024},
+		}},
+	}
+	for i, test := range tests {
+		blocks := codeBlocks(test.code, table)
+		if !reflect.DeepEqual(blocks, test.exp) {
+			t.Errorf("test %d: block mismatch\nhave %+v\nwant %+v", i, blocks, test.exp)
+		}
+	}
+}
+
+func BenchmarkCodeBlocks_1200k(bench *testing.B) {
+	code := make([]byte, analysisCodeSize)
+	bench.SetBytes(analysisCodeSize)
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		codeBlocks(code, &cancunInstructionSet)
+	}
+	bench.StopTimer()
+}
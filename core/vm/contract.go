@@ -52,6 +52,9 @@ type Contract struct {
 	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
 	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
 
+	blockCache map[common.Hash][]basicBlock // Aggregated result of basic block analysis
+	blocks     []basicBlock                 // Locally cached result of basic block analysis
+
 	Code     []byte
 	CodeHash common.Hash
 	CodeAddr *common.Address
@@ -69,10 +72,12 @@ func NewContract(caller ContractRef, object ContractRef, value *uint256.Int, gas
 	c := &Contract{CallerAddress: caller.Address(), caller: caller, self: object}
 
 	if parent, ok := caller.(*Contract); ok {
-		// Reuse JUMPDEST analysis from parent context if available.
+		// Reuse JUMPDEST and basic block analysis from parent context if available.
 		c.jumpdests = parent.jumpdests
+		c.blockCache = parent.blockCache
 	} else {
 		c.jumpdests = make(map[common.Hash]bitvec)
+		c.blockCache = make(map[common.Hash][]basicBlock)
 	}
 
 	// Gas should be a pointer so it can safely be reduced through the run
@@ -131,6 +136,29 @@ func (c *Contract) isCode(udest uint64) bool {
 	return c.analysis.codeSegment(udest)
 }
 
+// basicBlocks returns the basic blocks of the contract code under the given
+// instruction set. The analysis of regular contracts is shared with the parent
+// context, similarly to the JUMPDEST analysis. The instruction set is fixed for
+// the lifetime of an EVM, so it does not need to be part of the cache key.
+func (c *Contract) basicBlocks(table *JumpTable) []basicBlock {
+	if c.blocks != nil {
+		return c.blocks
+	}
+	if c.CodeHash != (common.Hash{}) {
+		blocks, exist := c.blockCache[c.CodeHash]
+		if !exist {
+			blocks = codeBlocks(c.Code, table)
+			c.blockCache[c.CodeHash] = blocks
+		}
+		c.blocks = blocks
+		return blocks
+	}
+	// We don't have the code hash, analyze the code locally without saving it
+	// in the parent context
+	c.blocks = codeBlocks(c.Code, table)
+	return c.blocks
+}
+
 // AsDelegate sets the contract to be a delegate call and returns the current
 // contract (for chaining calls)
 func (c *Contract) AsDelegate() *Contract {
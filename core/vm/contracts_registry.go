@@ -0,0 +1,189 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/forks"
+)
+
+// precompileOverride is a single registration in a PrecompileRegistry.
+type precompileOverride struct {
+	fork     forks.Fork
+	address  common.Address
+	contract PrecompiledContract // nil if the precompile is removed
+}
+
+// PrecompileRegistry is a set of precompiled contracts supplied by a program
+// embedding go-ethereum, which are activated on top of the protocol defined ones.
+// Registrations are tied to a fork: they take effect once the fork is enabled by
+// the chain rules and stay in effect until overridden at a later fork.
+//
+// The registry is attached to a chain via params.ChainConfig.PrecompileOverrides,
+// which makes it visible to every EVM, tracer and access list of that chain.
+type PrecompileRegistry struct {
+	overrides []precompileOverride
+	lock      sync.RWMutex
+}
+
+// NewPrecompileRegistry creates an empty precompile registry.
+func NewPrecompileRegistry() *PrecompileRegistry {
+	return new(PrecompileRegistry)
+}
+
+// Register adds a precompiled contract at the given address, activated from the
+// given fork onwards. If the address already holds a precompile, it is replaced.
+func (r *PrecompileRegistry) Register(fork forks.Fork, addr common.Address, p PrecompiledContract) {
+	r.insert(precompileOverride{fork: fork, address: addr, contract: p})
+}
+
+// Remove deactivates the precompiled contract at the given address from the
+// given fork onwards.
+func (r *PrecompileRegistry) Remove(fork forks.Fork, addr common.Address) {
+	r.insert(precompileOverride{fork: fork, address: addr})
+}
+
+// insert adds a new override, keeping the list ordered by activation fork while
+// preserving the registration order within the same fork.
+func (r *PrecompileRegistry) insert(o precompileOverride) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.overrides = append(r.overrides, o)
+	slices.SortStableFunc(r.overrides, func(a, b precompileOverride) int {
+		return int(a.fork) - int(b.fork)
+	})
+}
+
+// OverriddenPrecompiles implements params.PrecompileOverrides, returning the
+// addresses touched by the registrations active under the given rules.
+func (r *PrecompileRegistry) OverriddenPrecompiles(rules params.Rules) []common.Address {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var addrs []common.Address
+	for _, o := range r.overrides {
+		if isForkActive(rules, o.fork) && !slices.Contains(addrs, o.address) {
+			addrs = append(addrs, o.address)
+		}
+	}
+	return addrs
+}
+
+// OverriddenPrecompile implements params.PrecompileOverrides, returning the
+// contract of the latest registration active at the given address under the
+// given rules, or nil if the address is removed.
+func (r *PrecompileRegistry) OverriddenPrecompile(rules params.Rules, addr common.Address) any {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var contract PrecompiledContract
+	for _, o := range r.overrides {
+		if o.address == addr && isForkActive(rules, o.fork) {
+			contract = o.contract
+		}
+	}
+	if contract == nil {
+		return nil
+	}
+	return contract
+}
+
+// isForkActive reports whether the given fork is enabled by the chain rules.
+// Forks without their own rule flag (difficulty bomb delays, the DAO fork) are
+// treated as their preceding fork.
+func isForkActive(rules params.Rules, fork forks.Fork) bool {
+	switch fork {
+	case forks.Frontier, forks.FrontierThawing:
+		return true
+	case forks.Homestead, forks.DAO:
+		return rules.IsHomestead
+	case forks.TangerineWhistle:
+		return rules.IsEIP150
+	case forks.SpuriousDragon:
+		return rules.IsEIP158
+	case forks.Byzantium:
+		return rules.IsByzantium
+	case forks.Constantinople:
+		return rules.IsConstantinople
+	case forks.Petersburg:
+		return rules.IsPetersburg
+	case forks.Istanbul, forks.MuirGlacier:
+		return rules.IsIstanbul
+	case forks.Berlin:
+		return rules.IsBerlin
+	case forks.London, forks.ArrowGlacier, forks.GrayGlacier:
+		return rules.IsLondon
+	case forks.Paris:
+		return rules.IsMerge
+	case forks.Shanghai:
+		return rules.IsShanghai
+	case forks.Cancun:
+		return rules.IsCancun
+	case forks.Prague:
+		return rules.IsPrague
+	case forks.Osaka:
+		return rules.IsOsaka
+	case forks.Verkle:
+		return rules.IsVerkle
+	default:
+		return false
+	}
+}
+
+// ChainPrecompiledContracts returns the precompiled contracts active on the given
+// chain under the given rules, including any overrides configured on the chain.
+// The returned set must not be modified.
+func ChainPrecompiledContracts(config *params.ChainConfig, rules params.Rules) PrecompiledContracts {
+	set := activePrecompiledContracts(rules)
+	if config == nil || config.PrecompileOverrides == nil {
+		return set
+	}
+	addrs := config.PrecompileOverrides.OverriddenPrecompiles(rules)
+	if len(addrs) == 0 {
+		return set
+	}
+	set = maps.Clone(set)
+	for _, addr := range addrs {
+		if p, ok := config.PrecompileOverrides.OverriddenPrecompile(rules, addr).(PrecompiledContract); ok {
+			set[addr] = p
+		} else {
+			delete(set, addr)
+		}
+	}
+	return set
+}
+
+// ChainPrecompiles returns the addresses of the precompiled contracts active on
+// the given chain under the given rules, including any overrides configured on
+// the chain.
+func ChainPrecompiles(config *params.ChainConfig, rules params.Rules) []common.Address {
+	if config == nil || config.PrecompileOverrides == nil {
+		return ActivePrecompiles(rules)
+	}
+	set := ChainPrecompiledContracts(config, rules)
+	addrs := make([]common.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
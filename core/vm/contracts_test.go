@@ -21,10 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -67,6 +69,8 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{0x0f, 0x10}): &bls12381Pairing{},
 	common.BytesToAddress([]byte{0x0f, 0x11}): &bls12381MapG1{},
 	common.BytesToAddress([]byte{0x0f, 0x12}): &bls12381MapG2{},
+
+	common.BytesToAddress([]byte{0x01, 0x00}): &p256Verify{},
 }
 
 // EIP-152 test vectors
@@ -318,6 +322,36 @@ func TestPrecompiledPointEvaluation(t *testing.T) { testJson("pointEvaluation",
 
 func BenchmarkPrecompiledPointEvaluation(b *testing.B) { benchJson("pointEvaluation", "0a", b) }
 
+func TestPrecompiledP256Verify(t *testing.T)      { testJson("p256Verify", "100", t) }
+func BenchmarkPrecompiledP256Verify(b *testing.B) { benchJson("p256Verify", "100", b) }
+
+// Tests that the P256VERIFY precompile is only active if enabled in the chain config.
+func TestP256VerifyActivation(t *testing.T) {
+	var (
+		addr       = common.BytesToAddress([]byte{0x01, 0x00})
+		activation = uint64(1000)
+		config     = *params.TestChainConfig
+	)
+	config.P256VerifyTime = &activation
+
+	for _, tt := range []struct {
+		time   uint64
+		active bool
+	}{{activation - 1, false}, {activation, true}, {activation + 1, true}} {
+		rules := config.Rules(common.Big0, true, tt.time)
+		if _, ok := activePrecompiledContracts(rules)[addr]; ok != tt.active {
+			t.Errorf("time %d: precompile active mismatch: have %v, want %v", tt.time, ok, tt.active)
+		}
+		if ok := slices.Contains(ActivePrecompiles(rules), addr); ok != tt.active {
+			t.Errorf("time %d: precompile address active mismatch: have %v, want %v", tt.time, ok, tt.active)
+		}
+	}
+	// Ensure the protocol defined sets were not modified
+	if _, ok := PrecompiledContractsCancun[addr]; ok {
+		t.Error("P256VERIFY leaked into the Cancun precompiles")
+	}
+}
+
 func BenchmarkPrecompiledBLS12381G1Add(b *testing.B)      { benchJson("blsG1Add", "f0a", b) }
 func BenchmarkPrecompiledBLS12381G1Mul(b *testing.B)      { benchJson("blsG1Mul", "f0b", b) }
 func BenchmarkPrecompiledBLS12381G1MultiExp(b *testing.B) { benchJson("blsG1MultiExp", "f0c", b) }
@@ -192,7 +192,15 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		logged  bool   // deferred EVMLogger should ignore already logged steps
 		res     []byte // result of the opcode execution function
 		debug   = in.evm.Config.Tracer != nil
+
+		// basic block accounting, used if no per-instruction hooks are needed
+		blocks  []basicBlock // basic blocks of the code, nil if disabled
+		block   = -1         // index of the last entered basic block
+		prepaid uint32       // instructions left in the block with gas and stack prepaid
 	)
+	if !debug && !in.evm.chainRules.IsEIP4762 {
+		blocks = contract.basicBlocks(in.table)
+	}
 	// Don't move this deferred function, it's placed before the OnOpcode-deferred method,
 	// so that it gets executed _after_: the OnOpcode needs the stacks before
 	// they are returned to the pools
@@ -236,14 +244,31 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		op = contract.GetOp(pc)
 		operation := in.table[op]
 		cost = operation.constantGas // For tracing
-		// Validate stack
-		if sLen := stack.len(); sLen < operation.minStack {
-			return nil, &ErrStackUnderflow{stackLen: sLen, required: operation.minStack}
-		} else if sLen > operation.maxStack {
-			return nil, &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
+
+		// If a new basic block is entered, try to validate the stack and charge the
+		// constant gas for all of its instructions at once. If that fails, fall back
+		// to checking each instruction individually to fail at the exact same spot.
+		if prepaid == 0 && blocks != nil {
+			if idx, ok := findBasicBlock(blocks, block, pc); ok {
+				block = idx
+				if b := &blocks[idx]; b.minStack <= stack.len() && stack.len() <= b.maxStack && contract.Gas >= b.gas {
+					contract.Gas -= b.gas
+					prepaid = b.ops
+				}
+			}
 		}
-		if !contract.UseGas(cost, in.evm.Config.Tracer, tracing.GasChangeIgnored) {
-			return nil, ErrOutOfGas
+		if prepaid > 0 {
+			prepaid--
+		} else {
+			// Validate stack
+			if sLen := stack.len(); sLen < operation.minStack {
+				return nil, &ErrStackUnderflow{stackLen: sLen, required: operation.minStack}
+			} else if sLen > operation.maxStack {
+				return nil, &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
+			}
+			if !contract.UseGas(cost, in.evm.Config.Tracer, tracing.GasChangeIgnored) {
+				return nil, ErrOutOfGas
+			}
 		}
 
 		if operation.dynamicGas != nil {
@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
@@ -75,3 +78,69 @@ func TestLoopInterrupt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that charging gas and validating the stack per basic block produces the
+// same results as doing it per instruction, which is what happens when tracing.
+func TestBasicBlockAccounting(t *testing.T) {
+	var (
+		address = common.BytesToAddress([]byte("contract"))
+		vmctx   = BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			GetHash:     func(uint64) common.Hash { return common.Hash{} },
+			BlockNumber: common.Big0,
+			Difficulty:  common.Big0,
+			BaseFee:     common.Big0,
+			BlobBaseFee: common.Big0,
+			Random:      &common.Hash{},
+		}
+		rng   = rand.New(rand.NewSource(1))
+		codes = [][]byte{
+			// loop: push(2) jumpdest push(1) add dup1 push(100) gt push(2) jumpi stop
+			common.Hex2Bytes("60025b600101806064116002575700"),
+			// stack underflow in the middle of a block
+			common.Hex2Bytes("6001600201010100"),
+			// memory expansion following constant gas instructions
+			common.Hex2Bytes("6001600260036004600555600052596000f3"),
+			// remaining gas observed in the middle of straight-line code
+			common.Hex2Bytes("60016002035a60005260206000f3"),
+		}
+	)
+	for i := 0; i < 200; i++ {
+		code := make([]byte, 1+rng.Intn(64))
+		for j := range code {
+			// Bias towards cheap, non-terminating instructions
+			switch rng.Intn(4) {
+			case 0:
+				code[j] = byte(PUSH1) + byte(rng.Intn(4))
+			case 1:
+				code[j] = byte(DUP1) + byte(rng.Intn(4))
+			case 2:
+				code[j] = []byte{byte(ADD), byte(MUL), byte(POP), byte(JUMPDEST), byte(MSTORE), byte(GAS), byte(JUMPI)}[rng.Intn(7)]
+			default:
+				code[j] = byte(rng.Intn(256))
+			}
+		}
+		codes = append(codes, code)
+	}
+	run := func(code []byte, gas uint64, config Config) string {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, code)
+		statedb.Finalise(true)
+		statedb.AddAddressToAccessList(address)
+
+		evm := NewEVM(vmctx, TxContext{GasPrice: common.Big0}, statedb, params.MergedTestChainConfig, config)
+		ret, left, err := evm.Call(AccountRef(common.Address{}), address, nil, gas, new(uint256.Int))
+		return fmt.Sprintf("ret=%x left=%d err=%v", ret, left, err)
+	}
+	for i, code := range codes {
+		for _, gas := range []uint64{0, 3, 10, 25, 100, 1000, 100000} {
+			have := run(code, gas, Config{})
+			want := run(code, gas, Config{Tracer: &tracing.Hooks{}})
+			if have != want {
+				t.Errorf("code %d (%x), gas %d: result mismatch\nhave %s\nwant %s", i, code, gas, have, want)
+			}
+		}
+	}
+}
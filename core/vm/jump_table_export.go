@@ -74,3 +74,14 @@ func (op *operation) HasCost() bool {
 	// filter out
 	return op.dynamicGas != nil || op.constantGas != 0
 }
+
+// ConstantGas returns the static gas cost of the opcode, which is charged on top
+// of any dynamic cost.
+func (op *operation) ConstantGas() uint64 {
+	return op.constantGas
+}
+
+// Undefined returns true if the opcode is not defined in the instruction set.
+func (op *operation) Undefined() bool {
+	return op.undefined
+}
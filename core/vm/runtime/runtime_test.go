@@ -17,9 +17,11 @@
 package runtime
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -35,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/forks"
 
 	// force-load js tracers to trigger registration
 	_ "github.com/ethereum/go-ethereum/eth/tracers/js"
@@ -125,6 +128,75 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// constantPrecompile is a test precompile returning a fixed output.
+type constantPrecompile []byte
+
+func (c constantPrecompile) RequiredGas(input []byte) uint64  { return 100 }
+func (c constantPrecompile) Run(input []byte) ([]byte, error) { return c, nil }
+
+// Tests that precompiles registered on the chain config are callable, are added
+// to the access list and that removed precompiles are no longer active.
+func TestPrecompileRegistry(t *testing.T) {
+	var (
+		custom   = common.BytesToAddress([]byte{0x01, 0x00})
+		ecrecov  = common.BytesToAddress([]byte{0x01})
+		address  = common.HexToAddress("0xaa")
+		output   = common.LeftPadBytes([]byte{0x42}, 32)
+		registry = vm.NewPrecompileRegistry()
+	)
+	registry.Register(forks.Berlin, custom, constantPrecompile(output))
+	registry.Remove(forks.Berlin, ecrecov)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(address, []byte{
+		byte(vm.PUSH1), 32, // retSize
+		byte(vm.PUSH1), 0, // retOffset
+		byte(vm.PUSH1), 0, // argsSize
+		byte(vm.PUSH1), 0, // argsOffset
+		byte(vm.PUSH2), 0x01, 0x00, // address
+		byte(vm.GAS),
+		byte(vm.STATICCALL),
+		byte(vm.POP),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+	cfg := &Config{State: statedb}
+	setDefaults(cfg)
+	cfg.ChainConfig.PrecompileOverrides = registry
+
+	ret, _, err := Call(address, nil, cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if !bytes.Equal(ret, output) {
+		t.Fatalf("precompile output mismatch: have %x, want %x", ret, output)
+	}
+	if !statedb.AddressInAccessList(custom) {
+		t.Error("registered precompile missing from access list")
+	}
+	if statedb.AddressInAccessList(ecrecov) {
+		t.Error("removed precompile present in access list")
+	}
+	// Ensure the overrides are not active before their activation fork
+	rules := cfg.ChainConfig.Rules(common.Big0, false, 0)
+	rules.IsBerlin = false
+	if slices.Contains(vm.ChainPrecompiles(cfg.ChainConfig, rules), custom) {
+		t.Error("registered precompile active before its fork")
+	}
+	if !slices.Contains(vm.ChainPrecompiles(cfg.ChainConfig, rules), ecrecov) {
+		t.Error("removed precompile inactive before its fork")
+	}
+	// Ensure overrides not backed directly by a registry are honoured too
+	cfg.ChainConfig.PrecompileOverrides = struct{ *vm.PrecompileRegistry }{registry}
+	if ret, _, err = Call(address, nil, cfg); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if !bytes.Equal(ret, output) {
+		t.Fatalf("wrapped precompile output mismatch: have %x, want %x", ret, output)
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
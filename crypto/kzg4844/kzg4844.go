@@ -20,6 +20,7 @@ package kzg4844
 import (
 	"embed"
 	"errors"
+	"fmt"
 	"hash"
 	"reflect"
 	"sync/atomic"
@@ -34,8 +35,13 @@ var (
 	blobT       = reflect.TypeOf(Blob{})
 	commitmentT = reflect.TypeOf(Commitment{})
 	proofT      = reflect.TypeOf(Proof{})
+	cellT       = reflect.TypeOf(Cell{})
 )
 
+// CellProofsPerBlob is the number of cells an extended blob is split into, and
+// thus the number of cell proofs computed for each blob.
+const CellProofsPerBlob = 128
+
 // Blob represents a 4844 data blob.
 type Blob [131072]byte
 
@@ -75,6 +81,19 @@ func (p Proof) MarshalText() ([]byte, error) {
 	return hexutil.Bytes(p[:]).MarshalText()
 }
 
+// Cell is a chunk of an extended blob, the unit of data availability sampling.
+type Cell [2048]byte
+
+// UnmarshalJSON parses a cell in hex syntax.
+func (c *Cell) UnmarshalJSON(input []byte) error {
+	return hexutil.UnmarshalFixedJSON(cellT, input, c[:])
+}
+
+// MarshalText returns the hex representation of c.
+func (c Cell) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(c[:]).MarshalText()
+}
+
 // Point is a BLS field element.
 type Point [32]byte
 
@@ -149,6 +168,58 @@ func VerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	return gokzgVerifyBlobProof(blob, commitment, proof)
 }
 
+// ComputeCells computes the cells of the extended blob.
+func ComputeCells(blob *Blob) ([]Cell, error) {
+	if useCKZG.Load() {
+		return ckzgComputeCells(blob)
+	}
+	return gokzgComputeCells(blob)
+}
+
+// ComputeCellProofs computes the KZG proofs of all the cells of the extended
+// blob, in cell order.
+func ComputeCellProofs(blob *Blob) ([]Proof, error) {
+	if useCKZG.Load() {
+		return ckzgComputeCellProofs(blob)
+	}
+	return gokzgComputeCellProofs(blob)
+}
+
+// VerifyCellProofs verifies the cell proofs of a batch of blobs against their
+// commitments. The proofs must contain CellProofsPerBlob proofs for each blob,
+// in blob and then cell order.
+func VerifyCellProofs(blobs []Blob, commitments []Commitment, proofs []Proof) error {
+	if len(blobs) != len(commitments) || len(proofs) != len(blobs)*CellProofsPerBlob {
+		return fmt.Errorf("invalid number of proofs: have %d, want %d", len(proofs), len(blobs)*CellProofsPerBlob)
+	}
+	var (
+		commits = make([]Commitment, 0, len(proofs))
+		indices = make([]uint64, 0, len(proofs))
+		cells   = make([]Cell, 0, len(proofs))
+	)
+	for i := range blobs {
+		extended, err := ComputeCells(&blobs[i])
+		if err != nil {
+			return err
+		}
+		for j := range extended {
+			commits = append(commits, commitments[i])
+			indices = append(indices, uint64(j))
+		}
+		cells = append(cells, extended...)
+	}
+	return verifyCellProofBatch(commits, indices, cells, proofs)
+}
+
+// verifyCellProofBatch verifies a batch of cell proofs, each against the
+// commitment of the blob the cell belongs to.
+func verifyCellProofBatch(commitments []Commitment, indices []uint64, cells []Cell, proofs []Proof) error {
+	if useCKZG.Load() {
+		return ckzgVerifyCellProofBatch(commitments, indices, cells, proofs)
+	}
+	return gokzgVerifyCellProofBatch(commitments, indices, cells, proofs)
+}
+
 // CalcBlobHashV1 calculates the 'versioned blob hash' of a commitment.
 // The given hasher must be a sha256 hash instance, otherwise the result will be invalid!
 func CalcBlobHashV1(hasher hash.Hash, commit *Commitment) (vh [32]byte) {
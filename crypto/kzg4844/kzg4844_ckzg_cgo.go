@@ -23,8 +23,8 @@ import (
 	"errors"
 	"sync"
 
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
-	ckzg4844 "github.com/ethereum/c-kzg-4844/bindings/go"
+	gokzg4844 "github.com/crate-crypto/go-eth-kzg"
+	ckzg4844 "github.com/ethereum/c-kzg-4844/v2/bindings/go"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
@@ -47,15 +47,21 @@ func ckzgInit() {
 	if err = gokzg4844.CheckTrustedSetupIsWellFormed(params); err != nil {
 		panic(err)
 	}
-	g1s := make([]byte, len(params.SetupG1Lagrange)*(len(params.SetupG1Lagrange[0])-2)/2)
+	g1Lag := make([]byte, len(params.SetupG1Lagrange)*(len(params.SetupG1Lagrange[0])-2)/2)
 	for i, g1 := range params.SetupG1Lagrange {
+		copy(g1Lag[i*(len(g1)-2)/2:], hexutil.MustDecode(g1))
+	}
+	g1s := make([]byte, len(params.SetupG1Monomial)*(len(params.SetupG1Monomial[0])-2)/2)
+	for i, g1 := range params.SetupG1Monomial {
 		copy(g1s[i*(len(g1)-2)/2:], hexutil.MustDecode(g1))
 	}
 	g2s := make([]byte, len(params.SetupG2)*(len(params.SetupG2[0])-2)/2)
 	for i, g2 := range params.SetupG2 {
 		copy(g2s[i*(len(g2)-2)/2:], hexutil.MustDecode(g2))
 	}
-	if err = ckzg4844.LoadTrustedSetup(g1s, g2s); err != nil {
+	// The last parameter is the size of the precomputed multiplication tables
+	// used for the cell proofs, trading memory for speed
+	if err = ckzg4844.LoadTrustedSetup(g1s, g1Lag, g2s, 6); err != nil {
 		panic(err)
 	}
 }
@@ -125,3 +131,63 @@ func ckzgVerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	}
 	return nil
 }
+
+// ckzgComputeCells computes the cells of the extended blob.
+func ckzgComputeCells(blob *Blob) ([]Cell, error) {
+	ckzgIniter.Do(ckzgInit)
+
+	cells, err := ckzg4844.ComputeCells((*ckzg4844.Blob)(blob))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Cell, len(cells))
+	for i, cell := range cells {
+		res[i] = (Cell)(cell)
+	}
+	return res, nil
+}
+
+// ckzgComputeCellProofs computes the KZG proofs of all the cells of the extended
+// blob, in cell order.
+func ckzgComputeCellProofs(blob *Blob) ([]Proof, error) {
+	ckzgIniter.Do(ckzgInit)
+
+	_, proofs, err := ckzg4844.ComputeCellsAndKZGProofs((*ckzg4844.Blob)(blob))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Proof, len(proofs))
+	for i, proof := range proofs {
+		res[i] = (Proof)(proof)
+	}
+	return res, nil
+}
+
+// ckzgVerifyCellProofBatch verifies a batch of cell proofs, each against the
+// commitment of the blob the cell belongs to.
+func ckzgVerifyCellProofBatch(commitments []Commitment, indices []uint64, cells []Cell, proofs []Proof) error {
+	ckzgIniter.Do(ckzgInit)
+
+	var (
+		commits = make([]ckzg4844.Bytes48, len(commitments))
+		cellcs  = make([]ckzg4844.Cell, len(cells))
+		kproofs = make([]ckzg4844.Bytes48, len(proofs))
+	)
+	for i := range commitments {
+		commits[i] = (ckzg4844.Bytes48)(commitments[i])
+	}
+	for i := range cells {
+		cellcs[i] = (ckzg4844.Cell)(cells[i])
+	}
+	for i := range proofs {
+		kproofs[i] = (ckzg4844.Bytes48)(proofs[i])
+	}
+	valid, err := ckzg4844.VerifyCellKZGProofBatch(commits, indices, cellcs, kproofs)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("invalid proof")
+	}
+	return nil
+}
@@ -60,3 +60,20 @@ func ckzgComputeBlobProof(blob *Blob, commitment Commitment) (Proof, error) {
 func ckzgVerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error {
 	panic("unsupported platform")
 }
+
+// ckzgComputeCells computes the cells of the extended blob.
+func ckzgComputeCells(blob *Blob) ([]Cell, error) {
+	panic("unsupported platform")
+}
+
+// ckzgComputeCellProofs computes the KZG proofs of all the cells of the extended
+// blob, in cell order.
+func ckzgComputeCellProofs(blob *Blob) ([]Proof, error) {
+	panic("unsupported platform")
+}
+
+// ckzgVerifyCellProofBatch verifies a batch of cell proofs, each against the
+// commitment of the blob the cell belongs to.
+func ckzgVerifyCellProofBatch(commitments []Commitment, indices []uint64, cells []Cell, proofs []Proof) error {
+	panic("unsupported platform")
+}
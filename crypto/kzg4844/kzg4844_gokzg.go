@@ -20,7 +20,7 @@ import (
 	"encoding/json"
 	"sync"
 
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	gokzg4844 "github.com/crate-crypto/go-eth-kzg"
 )
 
 // context is the crypto primitive pre-seeded with the trusted setup parameters.
@@ -96,3 +96,56 @@ func gokzgVerifyBlobProof(blob *Blob, commitment Commitment, proof Proof) error
 
 	return context.VerifyBlobKZGProof((*gokzg4844.Blob)(blob), (gokzg4844.KZGCommitment)(commitment), (gokzg4844.KZGProof)(proof))
 }
+
+// gokzgComputeCells computes the cells of the extended blob.
+func gokzgComputeCells(blob *Blob) ([]Cell, error) {
+	gokzgIniter.Do(gokzgInit)
+
+	cells, _, err := context.ComputeCellsAndKZGProofs((*gokzg4844.Blob)(blob), 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Cell, len(cells))
+	for i, cell := range cells {
+		res[i] = (Cell)(*cell)
+	}
+	return res, nil
+}
+
+// gokzgComputeCellProofs computes the KZG proofs of all the cells of the extended
+// blob, in cell order.
+func gokzgComputeCellProofs(blob *Blob) ([]Proof, error) {
+	gokzgIniter.Do(gokzgInit)
+
+	_, proofs, err := context.ComputeCellsAndKZGProofs((*gokzg4844.Blob)(blob), 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Proof, len(proofs))
+	for i, proof := range proofs {
+		res[i] = (Proof)(proof)
+	}
+	return res, nil
+}
+
+// gokzgVerifyCellProofBatch verifies a batch of cell proofs, each against the
+// commitment of the blob the cell belongs to.
+func gokzgVerifyCellProofBatch(commitments []Commitment, indices []uint64, cells []Cell, proofs []Proof) error {
+	gokzgIniter.Do(gokzgInit)
+
+	var (
+		commits = make([]gokzg4844.KZGCommitment, len(commitments))
+		cellps  = make([]*gokzg4844.Cell, len(cells))
+		kproofs = make([]gokzg4844.KZGProof, len(proofs))
+	)
+	for i := range commitments {
+		commits[i] = (gokzg4844.KZGCommitment)(commitments[i])
+	}
+	for i := range cells {
+		cellps[i] = (*gokzg4844.Cell)(&cells[i])
+	}
+	for i := range proofs {
+		kproofs[i] = (gokzg4844.KZGProof)(proofs[i])
+	}
+	return context.VerifyCellKZGProofBatch(commits, indices, cellps, kproofs)
+}
@@ -17,11 +17,17 @@
 package kzg4844
 
 import (
+	"bytes"
 	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
-	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	gokzg4844 "github.com/crate-crypto/go-eth-kzg"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/yaml.v3"
 )
 
 func randFieldElement() [32]byte {
@@ -193,3 +199,226 @@ func benchmarkVerifyBlobProof(b *testing.B, ckzg bool) {
 		VerifyBlobProof(blob, commitment, proof)
 	}
 }
+
+// Tests that the cells of an extended blob start with the blob itself, and that
+// cell proofs verify in batches and reject tampered data.
+func TestCKZGCellProofs(t *testing.T)  { testCellProofs(t, true) }
+func TestGoKZGCellProofs(t *testing.T) { testCellProofs(t, false) }
+func testCellProofs(t *testing.T, ckzg bool) {
+	if ckzg && !ckzgAvailable {
+		t.Skip("CKZG unavailable in this test build")
+	}
+	defer func(old bool) { useCKZG.Store(old) }(useCKZG.Load())
+	useCKZG.Store(ckzg)
+
+	var (
+		blobs       = []Blob{*randBlob(), *randBlob()}
+		commitments []Commitment
+		proofs      []Proof
+	)
+	for i := range blobs {
+		cells, err := ComputeCells(&blobs[i])
+		if err != nil {
+			t.Fatalf("failed to compute cells: %v", err)
+		}
+		for j := 0; j < CellProofsPerBlob/2; j++ {
+			if !bytes.Equal(cells[j][:], blobs[i][j*len(cells[j]):(j+1)*len(cells[j])]) {
+				t.Fatalf("blob %d cell %d mismatch", i, j)
+			}
+		}
+		commitment, err := BlobToCommitment(&blobs[i])
+		if err != nil {
+			t.Fatalf("failed to create KZG commitment from blob: %v", err)
+		}
+		cellProofs, err := ComputeCellProofs(&blobs[i])
+		if err != nil {
+			t.Fatalf("failed to compute cell proofs: %v", err)
+		}
+		commitments = append(commitments, commitment)
+		proofs = append(proofs, cellProofs...)
+	}
+	if err := VerifyCellProofs(blobs, commitments, proofs); err != nil {
+		t.Fatalf("failed to verify cell proofs: %v", err)
+	}
+	if err := VerifyCellProofs(blobs, commitments, proofs[1:]); err == nil {
+		t.Fatalf("missing proofs accepted")
+	}
+	swapped := append([]Proof{}, proofs...)
+	swapped[3], swapped[4] = swapped[4], swapped[3]
+	if err := VerifyCellProofs(blobs, commitments, swapped); err == nil {
+		t.Fatalf("swapped proofs accepted")
+	}
+	tampered := append([]Blob{}, blobs...)
+	tampered[1][31] ^= 0x01
+	if err := VerifyCellProofs(tampered, commitments, proofs); err == nil {
+		t.Fatalf("tampered blob accepted")
+	}
+}
+
+func BenchmarkCKZGComputeCellProofs(b *testing.B)  { benchmarkComputeCellProofs(b, true) }
+func BenchmarkGoKZGComputeCellProofs(b *testing.B) { benchmarkComputeCellProofs(b, false) }
+func benchmarkComputeCellProofs(b *testing.B, ckzg bool) {
+	if ckzg && !ckzgAvailable {
+		b.Skip("CKZG unavailable in this test build")
+	}
+	defer func(old bool) { useCKZG.Store(old) }(useCKZG.Load())
+	useCKZG.Store(ckzg)
+
+	blob := randBlob()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ComputeCellProofs(blob)
+	}
+}
+
+// decodeFixed decodes a hex string into a fixed size byte array.
+func decodeFixed(input string, out []byte) error {
+	blob, err := hexutil.Decode(input)
+	if err != nil {
+		return err
+	}
+	if len(blob) != len(out) {
+		return fmt.Errorf("invalid length: have %d, want %d", len(blob), len(out))
+	}
+	copy(out, blob)
+	return nil
+}
+
+// Tests the cell computation against the EIP-7594 consensus spec vectors.
+func TestCKZGComputeCellsSpec(t *testing.T)  { testComputeCellsSpec(t, true) }
+func TestGoKZGComputeCellsSpec(t *testing.T) { testComputeCellsSpec(t, false) }
+func testComputeCellsSpec(t *testing.T, ckzg bool) {
+	if ckzg && !ckzgAvailable {
+		t.Skip("CKZG unavailable in this test build")
+	}
+	defer func(old bool) { useCKZG.Store(old) }(useCKZG.Load())
+	useCKZG.Store(ckzg)
+
+	tests, err := filepath.Glob("testdata/compute_cells_and_kzg_proofs/*/*/data.yaml")
+	if err != nil || len(tests) == 0 {
+		t.Fatalf("failed to find test vectors: %v", err)
+	}
+	for _, path := range tests {
+		t.Run(filepath.Base(filepath.Dir(path)), func(t *testing.T) {
+			var test struct {
+				Input struct {
+					Blob string `yaml:"blob"`
+				}
+				Output *[2][]string `yaml:"output"`
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read test: %v", err)
+			}
+			if err := yaml.Unmarshal(data, &test); err != nil {
+				t.Fatalf("failed to parse test: %v", err)
+			}
+			var blob Blob
+			if err := decodeFixed(test.Input.Blob, blob[:]); err != nil {
+				if test.Output != nil {
+					t.Fatalf("failed to decode blob: %v", err)
+				}
+				return
+			}
+			cells, err := ComputeCells(&blob)
+			if test.Output == nil {
+				if err == nil {
+					t.Fatalf("invalid blob accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to compute cells: %v", err)
+			}
+			proofs, err := ComputeCellProofs(&blob)
+			if err != nil {
+				t.Fatalf("failed to compute cell proofs: %v", err)
+			}
+			for i, want := range test.Output[0] {
+				if have := hexutil.Encode(cells[i][:]); have != want {
+					t.Fatalf("cell %d mismatch: have %s, want %s", i, have, want)
+				}
+			}
+			for i, want := range test.Output[1] {
+				if have := hexutil.Encode(proofs[i][:]); have != want {
+					t.Fatalf("proof %d mismatch: have %s, want %s", i, have, want)
+				}
+			}
+		})
+	}
+}
+
+// Tests the cell proof verification against the EIP-7594 consensus spec vectors.
+func TestCKZGVerifyCellProofBatchSpec(t *testing.T)  { testVerifyCellProofBatchSpec(t, true) }
+func TestGoKZGVerifyCellProofBatchSpec(t *testing.T) { testVerifyCellProofBatchSpec(t, false) }
+func testVerifyCellProofBatchSpec(t *testing.T, ckzg bool) {
+	if ckzg && !ckzgAvailable {
+		t.Skip("CKZG unavailable in this test build")
+	}
+	defer func(old bool) { useCKZG.Store(old) }(useCKZG.Load())
+	useCKZG.Store(ckzg)
+
+	tests, err := filepath.Glob("testdata/verify_cell_kzg_proof_batch/*/*/data.yaml")
+	if err != nil || len(tests) == 0 {
+		t.Fatalf("failed to find test vectors: %v", err)
+	}
+	for _, path := range tests {
+		t.Run(filepath.Base(filepath.Dir(path)), func(t *testing.T) {
+			var test struct {
+				Input struct {
+					Commitments []string `yaml:"commitments"`
+					CellIndices []uint64 `yaml:"cell_indices"`
+					Cells       []string `yaml:"cells"`
+					Proofs      []string `yaml:"proofs"`
+				}
+				Output *bool `yaml:"output"`
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read test: %v", err)
+			}
+			if err := yaml.Unmarshal(data, &test); err != nil {
+				t.Fatalf("failed to parse test: %v", err)
+			}
+			valid := test.Output != nil && *test.Output
+
+			var (
+				commitments = make([]Commitment, len(test.Input.Commitments))
+				cells       = make([]Cell, len(test.Input.Cells))
+				proofs      = make([]Proof, len(test.Input.Proofs))
+			)
+			for i, input := range test.Input.Commitments {
+				if err := decodeFixed(input, commitments[i][:]); err != nil {
+					if valid {
+						t.Fatalf("failed to decode commitment: %v", err)
+					}
+					return
+				}
+			}
+			for i, input := range test.Input.Cells {
+				if err := decodeFixed(input, cells[i][:]); err != nil {
+					if valid {
+						t.Fatalf("failed to decode cell: %v", err)
+					}
+					return
+				}
+			}
+			for i, input := range test.Input.Proofs {
+				if err := decodeFixed(input, proofs[i][:]); err != nil {
+					if valid {
+						t.Fatalf("failed to decode proof: %v", err)
+					}
+					return
+				}
+			}
+			err = verifyCellProofBatch(commitments, test.Input.CellIndices, cells, proofs)
+			if valid && err != nil {
+				t.Fatalf("failed to verify valid batch: %v", err)
+			}
+			if !valid && err == nil {
+				t.Fatalf("invalid batch accepted")
+			}
+		})
+	}
+}
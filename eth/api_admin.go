@@ -22,13 +22,22 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+// maxContentPageAccounts is the maximum number of accounts returned by a single
+// TxPoolContentPage call.
+const maxContentPageAccounts = 1024
+
 // AdminAPI is the collection of Ethereum full node related APIs for node
 // administration.
 type AdminAPI struct {
@@ -79,6 +88,25 @@ func (api *AdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool
 	return true, nil
 }
 
+// Backup writes a consistent copy of the chain database into the given directory
+// while the node keeps running. The database is placed at the same location in
+// the directory as in the datadir, so the directory can be used as a datadir.
+func (api *AdminAPI) Backup(path string) (bool, error) {
+	if api.eth.chainDbPath == "" {
+		return false, errors.New("backup not supported for ephemeral databases")
+	}
+	if _, err := os.Stat(path); err == nil {
+		// Directory already exists. Allowing to write into it could be a DoS
+		// vector, since the 'path' may point to arbitrary paths on the drive.
+		return false, errors.New("location would overwrite an existing directory")
+	}
+	dir := filepath.Join(path, api.eth.chainDbPath)
+	if err := core.BackupDatabase(api.eth.ChainDb(), api.eth.BlockChain().TrieDB(), dir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
 		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
@@ -141,3 +169,103 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// TxPoolRemove evicts a transaction from the transaction pool, returning whether
+// it was found.
+func (api *AdminAPI) TxPoolRemove(hash common.Hash) bool {
+	removed := api.eth.TxPool().Remove(hash)
+	if removed {
+		log.Info("Removed transaction from the pool", "hash", hash)
+	}
+	return removed
+}
+
+// TxPoolRemoveSender evicts all the transactions of an account from the
+// transaction pool, returning the number of transactions removed.
+func (api *AdminAPI) TxPoolRemoveSender(addr common.Address) int {
+	removed := api.eth.TxPool().RemoveSender(addr)
+	if removed > 0 {
+		log.Info("Removed sender transactions from the pool", "sender", addr, "count", removed)
+	}
+	return removed
+}
+
+// TxPoolSetLocals replaces the set of accounts considered local by the
+// transaction pool.
+func (api *AdminAPI) TxPoolSetLocals(addrs []common.Address) bool {
+	api.eth.TxPool().SetLocals(addrs)
+	return true
+}
+
+// TxPoolContentPage is a page of the transaction pool content, grouped by account
+// and nonce.
+type TxPoolContentPage struct {
+	Pending map[string]map[string]*ethapi.RPCTransaction `json:"pending"`
+	Queued  map[string]map[string]*ethapi.RPCTransaction `json:"queued"`
+	Next    *common.Address                              `json:"next"` // Cursor to retrieve the next page with, nil if none left
+}
+
+// TxPoolContentPage returns the transactions of at most limit accounts in the
+// transaction pool, in increasing order of the account address. The retrieval
+// starts after the cursor account, or at the beginning if no cursor is given.
+func (api *AdminAPI) TxPoolContentPage(cursor *common.Address, limit int) (*TxPoolContentPage, error) {
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	if limit > maxContentPageAccounts {
+		limit = maxContentPageAccounts
+	}
+	var (
+		pool            = api.eth.TxPool()
+		pending, queued = pool.Content()
+		accounts        = make([]common.Address, 0, len(pending)+len(queued))
+	)
+	for addr := range pending {
+		accounts = append(accounts, addr)
+	}
+	for addr := range queued {
+		if _, ok := pending[addr]; !ok {
+			accounts = append(accounts, addr)
+		}
+	}
+	slices.SortFunc(accounts, common.Address.Cmp)
+
+	// Skip all accounts up to and including the cursor
+	if cursor != nil {
+		start, found := slices.BinarySearchFunc(accounts, *cursor, common.Address.Cmp)
+		if found {
+			start++
+		}
+		accounts = accounts[start:]
+	}
+	page := &TxPoolContentPage{
+		Pending: make(map[string]map[string]*ethapi.RPCTransaction),
+		Queued:  make(map[string]map[string]*ethapi.RPCTransaction),
+	}
+	if len(accounts) > limit {
+		accounts = accounts[:limit]
+		page.Next = &accounts[limit-1]
+	}
+	var (
+		head   = api.eth.BlockChain().CurrentHeader()
+		config = api.eth.BlockChain().Config()
+	)
+	dump := func(txs []*types.Transaction) map[string]*ethapi.RPCTransaction {
+		content := make(map[string]*ethapi.RPCTransaction, len(txs))
+		for _, tx := range txs {
+			rpcTx := ethapi.NewRPCPendingTransaction(tx, head, config)
+			rpcTx.Private = pool.IsPrivate(tx.Hash())
+			content[fmt.Sprintf("%d", tx.Nonce())] = rpcTx
+		}
+		return content
+	}
+	for _, addr := range accounts {
+		if txs, ok := pending[addr]; ok {
+			page.Pending[addr.Hex()] = dump(txs)
+		}
+		if txs, ok := queued[addr]; ok {
+			page.Queued[addr.Hex()] = dump(txs)
+		}
+	}
+	return page, nil
+}
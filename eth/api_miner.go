@@ -17,9 +17,14 @@
 package eth
 
 import (
+	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
 )
 
 // MinerAPI provides an API to control the miner.
@@ -56,3 +61,51 @@ func (api *MinerAPI) SetGasLimit(gasLimit hexutil.Uint64) bool {
 	api.e.Miner().SetGasCeil(uint64(gasLimit))
 	return true
 }
+
+// GetPayloadReport returns the report of how a recently built payload was put
+// together, iteration by iteration, including the transactions left out and why.
+func (api *MinerAPI) GetPayloadReport(id engine.PayloadID) (*miner.PayloadReport, error) {
+	return api.e.Miner().PayloadReport(id)
+}
+
+// BundleArgs represents the arguments to submit a transaction bundle.
+type BundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	MinBlock          *hexutil.Uint64 `json:"minBlock"`
+	MaxBlock          *hexutil.Uint64 `json:"maxBlock"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+	MinProfit         *hexutil.Big    `json:"minProfit"`
+}
+
+// SendBundle submits a bundle of signed transactions to be included atomically
+// into the locally built blocks, returning the hash of the bundle. If no target
+// block range is given, the bundle only targets a single block, the next one by
+// default.
+func (api *MinerAPI) SendBundle(args BundleArgs) (common.Hash, error) {
+	bundle := &miner.Bundle{
+		Txs:          make(types.Transactions, len(args.Txs)),
+		RevertingTxs: args.RevertingTxHashes,
+	}
+	for i, input := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			return common.Hash{}, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		bundle.Txs[i] = tx
+	}
+	bundle.MinBlock = api.e.BlockChain().CurrentHeader().Number.Uint64() + 1
+	if args.MinBlock != nil {
+		bundle.MinBlock = uint64(*args.MinBlock)
+	}
+	bundle.MaxBlock = bundle.MinBlock
+	if args.MaxBlock != nil {
+		bundle.MaxBlock = uint64(*args.MaxBlock)
+	}
+	if args.MinProfit != nil {
+		bundle.MinProfit = args.MinProfit.ToInt()
+	}
+	if err := api.e.Miner().AddBundle(bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 
@@ -44,7 +45,9 @@ import (
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/remotedb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/shutdowncheck"
 	"github.com/ethereum/go-ethereum/log"
@@ -73,7 +76,8 @@ type Ethereum struct {
 	discmix *enode.FairMix
 
 	// DB interfaces
-	chainDb ethdb.Database // Block chain database
+	chainDb     ethdb.Database // Block chain database
+	chainDbPath string         // Location of the chain database within the datadir, empty if ephemeral
 
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
@@ -90,6 +94,7 @@ type Ethereum struct {
 
 	networkID     uint64
 	netRPCService *ethapi.NetAPI
+	dbRPCService  *remotedb.Service
 
 	p2pServer *p2p.Server
 
@@ -121,7 +126,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	log.Info("Allocated trie memory caches", "clean", common.StorageSize(config.TrieCleanCache)*1024*1024, "dirty", common.StorageSize(config.TrieDirtyCache)*1024*1024)
 
 	// Assemble the Ethereum object
-	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", false)
+	var eraRoots []common.Hash
+	if config.DatabaseEraRoots != "" {
+		var err error
+		if eraRoots, err = era.ReadRoots(config.DatabaseEraRoots); err != nil {
+			return nil, fmt.Errorf("failed to read era1 accumulator roots: %v", err)
+		}
+	}
+	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, eraRoots, "eth/db/chaindata/", false)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +175,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		discmix:           enode.NewFairMix(0),
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
 	}
+	// Remember the location of the chain database within the datadir, backups
+	// are placed at the same location within the backup directory.
+	if stack.DataDir() != "" {
+		if path, err := filepath.Rel(stack.DataDir(), stack.ResolvePath("chaindata")); err == nil {
+			eth.chainDbPath = path
+		}
+	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
 	if bcVersion != nil {
@@ -195,8 +214,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
 			StateScheme:         scheme,
+			HistoryEra:          config.HistoryEra,
 		}
 	)
+	if config.CacheJournalSize > 0 {
+		cacheConfig.CacheJournal = stack.ResolvePath("cachejournal")
+		cacheConfig.CacheJournalSize = config.CacheJournalSize
+	}
 	if config.VMTrace != "" {
 		var traceConfig json.RawMessage
 		if config.VMTraceJsonConfig != "" {
@@ -234,6 +258,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Snapshot != "" {
+		config.TxPool.Snapshot = stack.ResolvePath(config.TxPool.Snapshot)
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
 	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
@@ -267,6 +294,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, networkID)
+	if config.RemoteDB {
+		eth.dbRPCService = remotedb.NewService(chainDb)
+	}
 
 	// Register the backend on the node
 	stack.RegisterAPIs(eth.APIs())
@@ -304,6 +334,10 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the database service if it's enabled
+	if s.dbRPCService != nil {
+		apis = append(apis, s.dbRPCService.APIs()...)
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -419,6 +453,9 @@ func (s *Ethereum) Stop() error {
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
 
+	if s.dbRPCService != nil {
+		s.dbRPCService.Close()
+	}
 	s.chainDb.Close()
 	s.eventMux.Stop()
 
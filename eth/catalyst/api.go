@@ -453,7 +453,16 @@ func (api *ConsensusAPI) GetPayloadV3(payloadID engine.PayloadID) (*engine.Execu
 	if !payloadID.Is(engine.PayloadV3) {
 		return nil, engine.UnsupportedFork
 	}
-	return api.getPayload(payloadID, false)
+	data, err := api.getPayload(payloadID, false)
+	if err != nil {
+		return nil, err
+	}
+	// Cell proofs can only be delivered in a BlobsBundleV2, refuse to pass
+	// them off as blob proofs
+	if bundle := data.BlobsBundle; bundle != nil && len(bundle.Proofs) != len(bundle.Blobs) {
+		return nil, engine.UnsupportedFork
+	}
+	return data, nil
 }
 
 func (api *ConsensusAPI) getPayload(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
@@ -1575,6 +1575,41 @@ func TestBlockToPayloadWithBlobs(t *testing.T) {
 	}
 }
 
+func TestBlockToPayloadWithCellProofs(t *testing.T) {
+	inner := types.BlobTx{
+		BlobHashes: make([]common.Hash, 2),
+	}
+	txs := []*types.Transaction{types.NewTx(&inner)}
+	sidecars := []*types.BlobTxSidecar{
+		{
+			Version:     types.BlobSidecarVersion1,
+			Blobs:       make([]kzg4844.Blob, 2),
+			Commitments: make([]kzg4844.Commitment, 2),
+			Proofs:      make([]kzg4844.Proof, 2*kzg4844.CellProofsPerBlob),
+		},
+	}
+	for i := range sidecars[0].Proofs {
+		sidecars[0].Proofs[i][0] = byte(i)
+	}
+	block := types.NewBlock(&types.Header{}, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+	envelope := engine.BlockToExecutableData(block, nil, sidecars)
+
+	if got, want := len(envelope.BlobsBundle.Blobs), 2; got != want {
+		t.Fatalf("invalid number of blobs: got %v, want %v", got, want)
+	}
+	if got, want := len(envelope.BlobsBundle.Commitments), 2; got != want {
+		t.Fatalf("invalid number of commitments: got %v, want %v", got, want)
+	}
+	if got, want := len(envelope.BlobsBundle.Proofs), 2*kzg4844.CellProofsPerBlob; got != want {
+		t.Fatalf("invalid number of proofs: got %v, want %v", got, want)
+	}
+	for i, proof := range envelope.BlobsBundle.Proofs {
+		if !bytes.Equal(proof, sidecars[0].Proofs[i][:]) {
+			t.Fatalf("proof %d mismatch: got %x, want %x", i, proof, sidecars[0].Proofs[i])
+		}
+	}
+}
+
 // This checks that beaconRoot is applied to the state from the engine API.
 func TestParentBeaconBlockRoot(t *testing.T) {
 	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(colorable.NewColorableStderr(), log.LevelTrace, true)))
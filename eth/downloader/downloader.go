@@ -67,6 +67,7 @@ var (
 	errCancelContentProcessing = errors.New("content processing canceled (requested)")
 	errCanceled                = errors.New("syncing canceled (requested)")
 	errNoPivotHeader           = errors.New("pivot header is not found")
+	errImmutableAncients       = errors.New("cannot rewind read-only ancient store")
 )
 
 // peerDropFn is a callback type for dropping a peer detected as malicious.
@@ -497,6 +498,12 @@ func (d *Downloader) syncToHead() (err error) {
 		}
 		frozen, _ := d.stateDB.Ancients() // Ignore the error here since light client can also hit here.
 
+		// A read-only ancient store cannot be appended to, cap the limit at the
+		// archive head so everything newer lands in the active store.
+		immutable := rawdb.HasImmutableAncients(d.stateDB)
+		if immutable && frozen > 0 && d.ancientLimit >= frozen {
+			d.ancientLimit = frozen - 1
+		}
 		// If a part of blockchain data has already been written into active store,
 		// disable the ancient style insertion explicitly.
 		if origin >= frozen && frozen != 0 {
@@ -507,6 +514,9 @@ func (d *Downloader) syncToHead() (err error) {
 		}
 		// Rewind the ancient store and blockchain if reorg happens.
 		if origin+1 < frozen {
+			if immutable {
+				return fmt.Errorf("%w: ancestor #%d, ancient head #%d", errImmutableAncients, origin, frozen-1)
+			}
 			if err := d.blockchain.SetHead(origin); err != nil {
 				return err
 			}
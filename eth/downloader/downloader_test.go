@@ -19,6 +19,8 @@ package downloader
 import (
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -33,7 +35,9 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -60,6 +64,12 @@ func newTesterWithNotification(t *testing.T, success func()) *downloadTester {
 	if err != nil {
 		panic(err)
 	}
+	return newTesterWithDatabase(t, db, success)
+}
+
+// newTesterWithDatabase creates a new downloader test mocker on top of the
+// given chain database.
+func newTesterWithDatabase(t *testing.T, db ethdb.Database, success func()) *downloadTester {
 	t.Cleanup(func() {
 		db.Close()
 	})
@@ -421,6 +431,81 @@ func testCanonSync(t *testing.T, protocol uint, mode SyncMode) {
 	}
 }
 
+// Tests that snap syncing on top of an era1-backed ancient store leaves the
+// read-only archive untouched and keeps all newer blocks in the active store.
+func TestEraBackedSnapSync68(t *testing.T) {
+	var (
+		chain   = testChainBase.shorten(blockCacheMaxItems - 15)
+		archive = 500
+		dir     = t.TempDir()
+		eraDir  = filepath.Join(dir, "era")
+	)
+	// Archive the first part of the chain from a fully synced node
+	source := newTestBlockchain(chain.blocks[1:])
+	if err := os.Mkdir(eraDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.CreateTemp(eraDir, "era1-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := era.NewBuilder(f)
+	for _, block := range chain.blocks[:archive] {
+		hash, number := block.Hash(), block.NumberU64()
+		if err := builder.Add(block, source.GetReceiptsByHash(hash), source.GetTd(hash, number)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.Rename(f.Name(), filepath.Join(eraDir, era.Filename("test", 0, root))); err != nil {
+		t.Fatal(err)
+	}
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:              "pebble",
+		Directory:         filepath.Join(dir, "chaindata"),
+		AncientsDirectory: eraDir,
+		EraRoots:          []common.Hash{root},
+		Ephemeral:         true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open era-backed database: %v", err)
+	}
+	success := make(chan struct{})
+	tester := newTesterWithDatabase(t, db, func() {
+		close(success)
+	})
+	defer tester.terminate()
+
+	if head := tester.chain.CurrentSnapBlock().Number.Uint64(); head != uint64(archive-1) {
+		t.Fatalf("snap head not moved to archive head: have %d, want %d", head, archive-1)
+	}
+	tester.newPeer("peer", eth.ETH68, chain.blocks[1:])
+
+	// Finalize the head, which would move all blocks into the ancient store
+	head := chain.blocks[len(chain.blocks)-1].Header()
+	if err := tester.downloader.BeaconSync(SnapSync, head, head); err != nil {
+		t.Fatalf("failed to beacon-sync chain: %v", err)
+	}
+	select {
+	case <-success:
+		assertOwnChain(t, tester, len(chain.blocks))
+	case <-time.NewTimer(time.Second * 3).C:
+		t.Fatalf("Failed to sync chain in three seconds")
+	}
+	if frozen, _ := db.Ancients(); frozen != uint64(archive) {
+		t.Fatalf("ancient store modified: have %d items, want %d", frozen, archive)
+	}
+	for _, block := range chain.blocks[archive:] {
+		if !rawdb.HasBody(db, block.Hash(), block.NumberU64()) || !rawdb.HasReceipts(db, block.Hash(), block.NumberU64()) {
+			t.Fatalf("block #%d missing from the active store", block.NumberU64())
+		}
+	}
+}
+
 // Tests that if a large batch of blocks are being downloaded, it is throttled
 // until the cached blocks are retrieved.
 func TestThrottling68Full(t *testing.T) { testThrottling(t, eth.ETH68, FullSync) }
@@ -95,6 +95,7 @@ type Config struct {
 
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	HistoryEra         string `toml:",omitempty"` // Directory of era1 archives serving the pruned chain history.
 
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
@@ -111,6 +112,7 @@ type Config struct {
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
 	DatabaseFreezer    string
+	DatabaseEraRoots   string // File of trusted accumulator roots if DatabaseFreezer holds era1 archives
 
 	TrieCleanCache int
 	TrieDirtyCache int
@@ -118,6 +120,11 @@ type Config struct {
 	SnapshotCache  int
 	Preimages      bool
 
+	// CacheJournalSize is the memory allowance (MB) of hot trie nodes and snapshot
+	// entries persisted across restarts, per cache. Trie nodes are only persisted
+	// with the path scheme. Zero disables the cache journal.
+	CacheJournalSize int
+
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
@@ -154,6 +161,10 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RemoteDB enables serving the chain database over the remotedb RPC
+	// namespace. Writes are only accepted over authenticated transports.
+	RemoteDB bool
+
 	// OverrideCancun (TODO: remove after the fork)
 	OverrideCancun *uint64 `toml:",omitempty"`
 
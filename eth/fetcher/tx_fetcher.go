@@ -120,8 +120,37 @@ type txAnnounce struct {
 // txMetadata is a set of extra data transmitted along the announcement for better
 // fetch scheduling.
 type txMetadata struct {
-	kind byte   // Transaction consensus type
-	size uint32 // Transaction size in bytes
+	kind   byte   // Transaction consensus type
+	size   uint32 // Transaction size in bytes
+	sizeV0 uint32 // Size with blob proofs of delivered blob transactions with cell proofs
+}
+
+// matchesSize reports whether the size of a delivered transaction matches the
+// announced one. A few bytes of wiggle room are allowed due to the RLP vs
+// consensus format messyness. Blob transactions might have had their sidecars
+// converted to cell proofs since being announced, so their size with the blob
+// proofs is accepted too.
+func (m *txMetadata) matchesSize(announced uint32) bool {
+	// TODO(karalabe): Get rid of this relaxation when clients are proven stable.
+	if math.Abs(float64(m.size)-float64(announced)) <= 8 {
+		return true
+	}
+	return m.sizeV0 != 0 && math.Abs(float64(m.sizeV0)-float64(announced)) <= 8
+}
+
+// blobTxSizeV0 returns the size a blob transaction delivered with cell proofs
+// would have with blob proofs, or zero for any other transaction.
+func blobTxSizeV0(tx *types.Transaction) uint32 {
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil || sidecar.Version == types.BlobSidecarVersion0 || len(sidecar.Proofs) < len(sidecar.Blobs) {
+		return 0
+	}
+	legacy := &types.BlobTxSidecar{
+		Blobs:       sidecar.Blobs,
+		Commitments: sidecar.Commitments,
+		Proofs:      sidecar.Proofs[:len(sidecar.Blobs)],
+	}
+	return uint32(tx.WithBlobTxSidecar(legacy).Size())
 }
 
 // txRequest represents an in-flight transaction retrieval request destined to
@@ -354,8 +383,9 @@ func (f *TxFetcher) Enqueue(peer string, txs []*types.Transaction, direct bool)
 			}
 			added = append(added, batch[j].Hash())
 			metas = append(metas, txMetadata{
-				kind: batch[j].Type(),
-				size: uint32(batch[j].Size()),
+				kind:   batch[j].Type(),
+				size:   uint32(batch[j].Size()),
+				sizeV0: blobTxSizeV0(batch[j]),
 			})
 		}
 		knownMeter.Mark(duplicate)
@@ -594,17 +624,9 @@ func (f *TxFetcher) loop() {
 							if delivery.metas[i].kind != meta.kind {
 								log.Warn("Announced transaction type mismatch", "peer", peer, "tx", hash, "type", delivery.metas[i].kind, "ann", meta.kind)
 								f.dropPeer(peer)
-							} else if delivery.metas[i].size != meta.size {
-								if math.Abs(float64(delivery.metas[i].size)-float64(meta.size)) > 8 {
-									log.Warn("Announced transaction size mismatch", "peer", peer, "tx", hash, "size", delivery.metas[i].size, "ann", meta.size)
-
-									// Normally we should drop a peer considering this is a protocol violation.
-									// However, due to the RLP vs consensus format messyness, allow a few bytes
-									// wiggle-room where we only warn, but don't drop.
-									//
-									// TODO(karalabe): Get rid of this relaxation when clients are proven stable.
-									f.dropPeer(peer)
-								}
+							} else if !delivery.metas[i].matchesSize(meta.size) {
+								log.Warn("Announced transaction size mismatch", "peer", peer, "tx", hash, "size", delivery.metas[i].size, "ann", meta.size)
+								f.dropPeer(peer)
 							}
 						}
 						delete(txset, hash)
@@ -620,17 +642,9 @@ func (f *TxFetcher) loop() {
 							if delivery.metas[i].kind != meta.kind {
 								log.Warn("Announced transaction type mismatch", "peer", peer, "tx", hash, "type", delivery.metas[i].kind, "ann", meta.kind)
 								f.dropPeer(peer)
-							} else if delivery.metas[i].size != meta.size {
-								if math.Abs(float64(delivery.metas[i].size)-float64(meta.size)) > 8 {
-									log.Warn("Announced transaction size mismatch", "peer", peer, "tx", hash, "size", delivery.metas[i].size, "ann", meta.size)
-
-									// Normally we should drop a peer considering this is a protocol violation.
-									// However, due to the RLP vs consensus format messyness, allow a few bytes
-									// wiggle-room where we only warn, but don't drop.
-									//
-									// TODO(karalabe): Get rid of this relaxation when clients are proven stable.
-									f.dropPeer(peer)
-								}
+							} else if !delivery.metas[i].matchesSize(meta.size) {
+								log.Warn("Announced transaction size mismatch", "peer", peer, "tx", hash, "size", delivery.metas[i].size, "ann", meta.size)
+								f.dropPeer(peer)
 							}
 						}
 						delete(txset, hash)
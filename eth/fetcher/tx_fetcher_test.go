@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -2114,3 +2115,37 @@ func TestTransactionForgotten(t *testing.T) {
 		t.Fatal("transaction should be known underpriced")
 	}
 }
+
+// Tests that blob transactions delivered with cell proofs match announcements
+// made before their sidecars were converted from blob proofs.
+func TestTransactionFetcherConvertedSidecarSize(t *testing.T) {
+	var (
+		blobs  = []kzg4844.Blob{{}}
+		commit = []kzg4844.Commitment{{}}
+		legacy = types.NewTx(&types.BlobTx{
+			BlobHashes: []common.Hash{{0x01}},
+			Sidecar:    &types.BlobTxSidecar{Blobs: blobs, Commitments: commit, Proofs: make([]kzg4844.Proof, 1)},
+		})
+		converted = legacy.WithBlobTxSidecar(&types.BlobTxSidecar{
+			Version:     types.BlobSidecarVersion1,
+			Blobs:       blobs,
+			Commitments: commit,
+			Proofs:      make([]kzg4844.Proof, kzg4844.CellProofsPerBlob),
+		})
+	)
+	if size := blobTxSizeV0(legacy); size != 0 {
+		t.Errorf("blob proof size reported for unconverted transaction: %d", size)
+	}
+	meta := &txMetadata{kind: types.BlobTxType, size: uint32(converted.Size()), sizeV0: blobTxSizeV0(converted)}
+	if meta.sizeV0 != uint32(legacy.Size()) {
+		t.Errorf("blob proof size mismatch: have %d, want %d", meta.sizeV0, legacy.Size())
+	}
+	for _, announced := range []uint64{legacy.Size(), converted.Size()} {
+		if !meta.matchesSize(uint32(announced)) {
+			t.Errorf("announced size %d rejected", announced)
+		}
+	}
+	if meta.matchesSize(uint32(legacy.Size()) - 100) {
+		t.Errorf("mismatching announced size accepted")
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -39,6 +40,7 @@ var (
 	errInvalidBlockRange      = errors.New("invalid block range params")
 	errPendingLogsUnsupported = errors.New("pending logs are not supported")
 	errExceedMaxTopics        = errors.New("exceed max topics")
+	errTxLifecycleUnsupported = errors.New("transaction lifecycle events are not supported")
 )
 
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
@@ -190,6 +192,69 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	return rpcSub, nil
 }
 
+// txpoolEvent is the notification of a state change of a pooled transaction.
+type txpoolEvent struct {
+	Type        string         `json:"type"`
+	Hash        common.Hash    `json:"hash"`
+	From        common.Address `json:"from"`
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Replacement *common.Hash   `json:"replacedBy,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+}
+
+// newTxpoolEvent converts a transaction lifecycle event into its notification.
+func newTxpoolEvent(ev core.TxLifecycleEvent) *txpoolEvent {
+	event := &txpoolEvent{
+		Type:  ev.Type.String(),
+		Hash:  ev.Hash,
+		From:  ev.From,
+		Nonce: hexutil.Uint64(ev.Nonce),
+	}
+	if ev.Type == core.TxReplaced {
+		event.Replacement = &ev.Replacement
+	}
+	if ev.Reason != nil {
+		event.Reason = ev.Reason.Error()
+	}
+	return event
+}
+
+// TxpoolEvents creates a subscription that is triggered each time a transaction
+// changes state in the transaction pool: when it's added, promoted, demoted,
+// replaced, evicted or included in the chain.
+func (api *FilterAPI) TxpoolEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	backend, ok := api.sys.backend.(txLifecycleBackend)
+	if !ok {
+		return &rpc.Subscription{}, errTxLifecycleUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan core.TxLifecycleEvent, 128)
+		eventSub := backend.SubscribeTxLifecycleEvent(events)
+		defer eventSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				if ev.Private {
+					continue
+				}
+				notifier.Notify(rpcSub.ID, newTxpoolEvent(ev))
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *FilterAPI) NewBlockFilter() rpc.ID {
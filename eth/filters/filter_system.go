@@ -73,6 +73,18 @@ type Backend interface {
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
+// txLifecycleBackend is implemented by backends able to report the lifecycle of
+// transactions in the transaction pool.
+type txLifecycleBackend interface {
+	SubscribeTxLifecycleEvent(chan<- core.TxLifecycleEvent) event.Subscription
+}
+
+// privateTxBackend is implemented by backends accepting private transactions,
+// which must not be revealed to subscribers.
+type privateTxBackend interface {
+	IsPrivateTx(txHash common.Hash) bool
+}
+
 // FilterSystem holds resources shared by all filters.
 type FilterSystem struct {
 	backend   Backend
@@ -384,8 +396,20 @@ func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
 }
 
 func (es *EventSystem) handleTxsEvent(filters filterIndex, ev core.NewTxsEvent) {
+	txs := ev.Txs
+	if backend, ok := es.backend.(privateTxBackend); ok {
+		txs = make([]*types.Transaction, 0, len(ev.Txs))
+		for _, tx := range ev.Txs {
+			if !backend.IsPrivateTx(tx.Hash()) {
+				txs = append(txs, tx)
+			}
+		}
+		if len(txs) == 0 {
+			return
+		}
+	}
 	for _, f := range filters[PendingTransactionsSubscription] {
-		f.txs <- ev.Txs
+		f.txs <- txs
 	}
 }
 
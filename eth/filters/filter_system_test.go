@@ -43,11 +43,13 @@ type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
 	txFeed          event.Feed
+	txEventFeed     event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	chainFeed       event.Feed
 	pendingBlock    *types.Block
 	pendingReceipts types.Receipts
+	private         map[common.Hash]bool
 }
 
 func (b *testBackend) ChainConfig() *params.ChainConfig {
@@ -121,10 +123,18 @@ func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash, number uint
 	return logs, nil
 }
 
+func (b *testBackend) IsPrivateTx(txHash common.Hash) bool {
+	return b.private[txHash]
+}
+
 func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeTxLifecycleEvent(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return b.txEventFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -294,6 +304,44 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestPendingTxFilterPrivate tests that private transactions are not revealed
+// to pending transaction filters.
+func TestPendingTxFilterPrivate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys)
+
+		public  = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil)
+		private = types.NewTransaction(1, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil)
+	)
+	backend.private = map[common.Hash]bool{private.Hash(): true}
+
+	fid0 := api.NewPendingTransactionFilter(nil)
+
+	time.Sleep(1 * time.Second)
+	backend.txFeed.Send(core.NewTxsEvent{Txs: []*types.Transaction{private}})
+	backend.txFeed.Send(core.NewTxsEvent{Txs: []*types.Transaction{public, private}})
+
+	var (
+		hashes  []common.Hash
+		timeout = time.Now().Add(1 * time.Second)
+	)
+	for time.Now().Before(timeout) {
+		results, err := api.GetFilterChanges(fid0)
+		if err != nil {
+			t.Fatalf("Unable to retrieve logs: %v", err)
+		}
+		hashes = append(hashes, results.([]common.Hash)...)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(hashes) != 1 || hashes[0] != public.Hash() {
+		t.Fatalf("private transaction leaked: want [%x], got %x", public.Hash(), hashes)
+	}
+}
+
 // TestPendingTxFilterFullTx tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilterFullTx(t *testing.T) {
 	t.Parallel()
@@ -628,3 +676,60 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 		}
 	}
 }
+
+// TestTxpoolEvents tests that the transaction lifecycle events are delivered to
+// the txpoolEvents subscribers.
+func TestTxpoolEvents(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		server       = rpc.NewServer()
+	)
+	defer server.Stop()
+
+	if err := server.RegisterName("eth", NewFilterAPI(sys)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	events := make(chan map[string]interface{})
+	sub, err := client.EthSubscribe(context.Background(), events, "txpoolEvents")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	var (
+		hash = common.HexToHash("0x01")
+		repl = common.HexToHash("0x02")
+	)
+	// The subscription is created asynchronously, keep sending until it arrives.
+	// Events of private transactions must never be delivered.
+	go func() {
+		for i := 0; i < 100; i++ {
+			backend.txEventFeed.Send(core.TxLifecycleEvent{Type: core.TxEvicted, Hash: repl, Nonce: 1, Private: true})
+			backend.txEventFeed.Send(core.TxLifecycleEvent{Type: core.TxReplaced, Hash: hash, Nonce: 1, Replacement: repl})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	select {
+	case event := <-events:
+		want := map[string]interface{}{
+			"type":       "replaced",
+			"hash":       hash.Hex(),
+			"from":       common.Address{}.Hex(),
+			"nonce":      "0x1",
+			"replacedBy": repl.Hex(),
+		}
+		if !reflect.DeepEqual(event, want) {
+			t.Fatalf("event mismatch: have %v, want %v", event, want)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+}
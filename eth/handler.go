@@ -71,6 +71,10 @@ type txPool interface {
 	// Add should add the given transactions to the pool.
 	Add(txs []*types.Transaction, local bool, sync bool) []error
 
+	// IsPrivate returns whether a transaction was submitted privately and thus
+	// must not be announced to the network.
+	IsPrivate(hash common.Hash) bool
+
 	// Pending should return pending transactions.
 	// The slice should be modifiable by the caller.
 	Pending(filter txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction
@@ -483,6 +487,11 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		hash   = make([]byte, 32)
 	)
 	for _, tx := range txs {
+		// Private and conditional transactions are only included locally, never
+		// propagate them (peers would drop the conditions anyway)
+		if tx.Conditional() != nil || h.txpool.IsPrivate(tx.Hash()) {
+			continue
+		}
 		var maybeDirect bool
 		switch {
 		case tx.Type() == types.BlobTxType:
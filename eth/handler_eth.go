@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
@@ -31,7 +32,27 @@ import (
 type ethHandler handler
 
 func (h *ethHandler) Chain() *core.BlockChain { return h.chain }
-func (h *ethHandler) TxPool() eth.TxPool      { return h.txpool }
+func (h *ethHandler) TxPool() eth.TxPool      { return &publicTxPool{h.txpool} }
+
+// publicTxPool is a view of the transaction pool hiding the private transactions,
+// and conditional transactions, so they are neither served to nor broadcast to
+// remote peers.
+type publicTxPool struct {
+	txpool txPool
+}
+
+// Get retrieves the transaction from the pool with the given hash, unless it
+// is private or conditional.
+func (p *publicTxPool) Get(hash common.Hash) *types.Transaction {
+	if p.txpool.IsPrivate(hash) {
+		return nil
+	}
+	tx := p.txpool.Get(hash)
+	if tx != nil && tx.Conditional() != nil {
+		return nil
+	}
+	return tx
+}
 
 // RunPeer is invoked when a peer joins on the `eth` protocol.
 func (h *ethHandler) RunPeer(peer *eth.Peer, hand eth.Handler) error {
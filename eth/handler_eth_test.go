@@ -438,3 +438,103 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that private and conditional transactions are not propagated to any
+// attached peers.
+func TestPrivateTransactionPropagation68(t *testing.T) {
+	testLocalOnlyTransactionPropagation(t, eth.ETH68, false)
+}
+func TestConditionalTransactionPropagation68(t *testing.T) {
+	testLocalOnlyTransactionPropagation(t, eth.ETH68, true)
+}
+
+func testLocalOnlyTransactionPropagation(t *testing.T, protocol uint, conditional bool) {
+	t.Parallel()
+
+	// Create a source handler to send transactions from and a few sinks to
+	// receive them, interconnecting all of them.
+	source := newTestHandler()
+	source.handler.snapSync.Store(false) // Avoid requiring snap, otherwise some will be dropped below
+	defer source.close()
+
+	sinks := make([]*testHandler, 4)
+	for i := 0; i < len(sinks); i++ {
+		sinks[i] = newTestHandler()
+		defer sinks[i].close()
+
+		sinks[i].handler.synced.Store(true) // mark synced to accept transactions
+	}
+	for i, sink := range sinks {
+		sink := sink // Closure for goroutine below
+
+		sourcePipe, sinkPipe := p2p.MsgPipe()
+		defer sourcePipe.Close()
+		defer sinkPipe.Close()
+
+		sourcePeer := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{byte(i + 1)}, "", nil, sourcePipe), sourcePipe, source.txpool)
+		sinkPeer := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{0}, "", nil, sinkPipe), sinkPipe, sink.txpool)
+		defer sourcePeer.Close()
+		defer sinkPeer.Close()
+
+		go source.handler.runEthPeer(sourcePeer, func(peer *eth.Peer) error {
+			return eth.Handle((*ethHandler)(source.handler), peer)
+		})
+		go sink.handler.runEthPeer(sinkPeer, func(peer *eth.Peer) error {
+			return eth.Handle((*ethHandler)(sink.handler), peer)
+		})
+	}
+	txChs := make([]chan core.NewTxsEvent, len(sinks))
+	for i := 0; i < len(sinks); i++ {
+		txChs[i] = make(chan core.NewTxsEvent, 1024)
+
+		sub := sinks[i].txpool.SubscribeTransactions(txChs[i], false)
+		defer sub.Unsubscribe()
+	}
+	// Fill the source pool with local-only transactions, then with public ones
+	txs := make([]*types.Transaction, 64)
+	for nonce := range txs {
+		tx := types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+		txs[nonce] = tx
+	}
+	private, public := txs[:len(txs)/2], txs[len(txs)/2:]
+	if conditional {
+		for _, tx := range private {
+			tx.SetConditional(&types.TransactionConditional{BlockNumberMin: big.NewInt(1)})
+		}
+		source.txpool.Add(private, false, false)
+	} else {
+		source.txpool.AddPrivate(private, false)
+	}
+	source.txpool.Add(public, false, false)
+
+	hidden := make(map[common.Hash]bool)
+	for _, tx := range private {
+		hidden[tx.Hash()] = true
+	}
+
+	// Ensure the sinks only ever receive the public transactions
+	for i := range sinks {
+		for arrived, timeout := 0, false; arrived < len(public) && !timeout; {
+			select {
+			case event := <-txChs[i]:
+				for _, tx := range event.Txs {
+					if hidden[tx.Hash()] {
+						t.Fatalf("sink %d: local-only transaction propagated: %x", i, tx.Hash())
+					}
+				}
+				arrived += len(event.Txs)
+			case <-time.After(2 * time.Second):
+				t.Errorf("sink %d: transaction propagation timed out: have %d, want %d", i, arrived, len(public))
+				timeout = true
+			}
+		}
+	}
+	for i, sink := range sinks {
+		for _, tx := range private {
+			if sink.txpool.Has(tx.Hash()) {
+				t.Errorf("sink %d: local-only transaction propagated: %x", i, tx.Hash())
+			}
+		}
+	}
+}
@@ -225,14 +225,28 @@ func ServiceGetBlockBodiesQuery(chain *core.BlockChain, query GetBlockBodiesRequ
 			lookups >= 2*maxBodiesServe {
 			break
 		}
-		if data := chain.GetBodyRLP(hash); len(data) != 0 {
-			bodies = append(bodies, data)
-			bytes += len(data)
+		data := chain.GetBodyRLP(hash)
+		if len(data) == 0 {
+			// Responses are matched by position, stop at the pruned history
+			// instead of leaving a gap in the middle.
+			if historyPruned(chain, hash) {
+				break
+			}
+			continue
 		}
+		bodies = append(bodies, data)
+		bytes += len(data)
 	}
 	return bodies
 }
 
+// historyPruned reports whether the body and receipts of the block with the
+// given hash have been pruned from the chain.
+func historyPruned(chain *core.BlockChain, hash common.Hash) bool {
+	header := chain.GetHeaderByHash(hash)
+	return header != nil && chain.HistoryPruned(header.Number.Uint64())
+}
+
 func handleGetReceipts(backend Backend, msg Decoder, peer *Peer) error {
 	// Decode the block receipts retrieval message
 	var query GetReceiptsPacket
@@ -259,7 +273,14 @@ func ServiceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest) [
 		// Retrieve the requested block's receipts
 		results := chain.GetReceiptsByHash(hash)
 		if results == nil {
-			if header := chain.GetHeaderByHash(hash); header == nil || header.ReceiptHash != types.EmptyRootHash {
+			header := chain.GetHeaderByHash(hash)
+			if header == nil {
+				continue
+			}
+			if header.ReceiptHash != types.EmptyRootHash {
+				if chain.HistoryPruned(header.Number.Uint64()) {
+					break
+				}
 				continue
 			}
 		}
@@ -125,6 +125,70 @@ func (ec *Client) GetProof(ctx context.Context, account common.Address, keys []s
 	return &result, err
 }
 
+// MultiProofResult is the result of a GetMultiProof operation.
+type MultiProofResult struct {
+	Address     common.Address      `json:"address"`
+	Balance     *big.Int            `json:"balance"`
+	CodeHash    common.Hash         `json:"codeHash"`
+	Nonce       uint64              `json:"nonce"`
+	StorageHash common.Hash         `json:"storageHash"`
+	Storage     []MultiProofStorage `json:"storage"`
+	Proof       []string            `json:"proof"`
+}
+
+// MultiProofStorage is a key-value pair proven by a MultiProofResult.
+type MultiProofStorage struct {
+	Key   string   `json:"key"`
+	Value *big.Int `json:"value"`
+}
+
+// GetMultiProof returns the account and storage values of the specified account,
+// along with a single deduplicated set of trie nodes proving all of them.
+// The block number can be nil, in which case the value is taken from the latest known block.
+func (ec *Client) GetMultiProof(ctx context.Context, account common.Address, keys []string, blockNumber *big.Int) (*MultiProofResult, error) {
+	type storageResult struct {
+		Key   string       `json:"key"`
+		Value *hexutil.Big `json:"value"`
+	}
+
+	type multiProofResult struct {
+		Address     common.Address  `json:"address"`
+		Balance     *hexutil.Big    `json:"balance"`
+		CodeHash    common.Hash     `json:"codeHash"`
+		Nonce       hexutil.Uint64  `json:"nonce"`
+		StorageHash common.Hash     `json:"storageHash"`
+		Storage     []storageResult `json:"storage"`
+		Proof       []string        `json:"proof"`
+	}
+
+	// Avoid keys being 'null'.
+	if keys == nil {
+		keys = []string{}
+	}
+
+	var res multiProofResult
+	if err := ec.c.CallContext(ctx, &res, "eth_getMultiProof", account, keys, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	// Turn hexutils back to normal datatypes
+	storage := make([]MultiProofStorage, 0, len(res.Storage))
+	for _, st := range res.Storage {
+		storage = append(storage, MultiProofStorage{
+			Key:   st.Key,
+			Value: st.Value.ToInt(),
+		})
+	}
+	return &MultiProofResult{
+		Address:     res.Address,
+		Balance:     res.Balance.ToInt(),
+		CodeHash:    res.CodeHash,
+		Nonce:       uint64(res.Nonce),
+		StorageHash: res.StorageHash,
+		Storage:     storage,
+		Proof:       res.Proof,
+	}, nil
+}
+
 // CallContract executes a message call transaction, which is directly executed in the VM
 // of the node, but never mined into the blockchain.
 //
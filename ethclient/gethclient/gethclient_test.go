@@ -33,9 +33,12 @@ import (
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 var (
@@ -123,6 +126,15 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestGetProofCanonicalizeKeys",
 			func(t *testing.T) { testGetProofCanonicalizeKeys(t, client) },
+		}, {
+			"TestGetMultiProof1",
+			func(t *testing.T) { testGetMultiProof(t, client, testAddr) },
+		}, {
+			"TestGetMultiProof2",
+			func(t *testing.T) { testGetMultiProof(t, client, testContract) },
+		}, {
+			"TestGetMultiProofNonExistent",
+			func(t *testing.T) { testGetMultiProof(t, client, common.HexToAddress("0x0001")) },
 		}, {
 			"TestGCStats",
 			func(t *testing.T) { testGCStats(t, client) },
@@ -316,6 +328,77 @@ func testGetProofNonExistent(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testGetMultiProof(t *testing.T, client *rpc.Client, addr common.Address) {
+	ec := New(client)
+	ethcl := ethclient.NewClient(client)
+	keys := []string{testSlot.String(), "0x01", "0x02"}
+	result, err := ec.GetMultiProof(context.Background(), addr, keys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Address != addr {
+		t.Fatalf("unexpected address, have: %v want: %v", result.Address, addr)
+	}
+	head, err := ethcl.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := memorydb.New()
+	for _, node := range result.Proof {
+		blob := common.FromHex(node)
+		if has, _ := proof.Has(crypto.Keccak256(blob)); has {
+			t.Fatalf("duplicate proof node %s", node)
+		}
+		proof.Put(crypto.Keccak256(blob), blob)
+	}
+	// Verify the account against the state root
+	values, err := trie.VerifyMultiProof(head.Root, [][]byte{crypto.Keccak256(addr.Bytes())}, proof)
+	if err != nil {
+		t.Fatalf("failed to verify account proof: %v", err)
+	}
+	if values[0] == nil {
+		if result.Nonce != 0 || result.Balance.Sign() != 0 || result.StorageHash != (common.Hash{}) {
+			t.Fatalf("non-empty result for absent account: %+v", result)
+		}
+		return
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(values[0], &account); err != nil {
+		t.Fatal(err)
+	}
+	if account.Nonce != result.Nonce || account.Balance.ToBig().Cmp(result.Balance) != 0 || account.Root != result.StorageHash || common.BytesToHash(account.CodeHash) != result.CodeHash {
+		t.Fatalf("account mismatch: proven %+v, have %+v", account, result)
+	}
+	// Verify the storage slots against the storage root, an empty trie has
+	// no nodes to prove it.
+	if account.Root == types.EmptyRootHash {
+		for _, slot := range result.Storage {
+			if slot.Value.Sign() != 0 {
+				t.Fatalf("non-zero slot %s in empty storage", slot.Key)
+			}
+		}
+		return
+	}
+	slots := make([][]byte, len(keys))
+	for i, key := range keys {
+		slots[i] = crypto.Keccak256(common.HexToHash(key).Bytes())
+	}
+	if values, err = trie.VerifyMultiProof(account.Root, slots, proof); err != nil {
+		t.Fatalf("failed to verify storage proof: %v", err)
+	}
+	for i, value := range values {
+		var want []byte
+		if value != nil {
+			if want, _, err = rlp.SplitString(value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if have := result.Storage[i].Value; have.Cmp(new(big.Int).SetBytes(want)) != 0 {
+			t.Fatalf("slot %s mismatch: proven %x, have %v", keys[i], want, have)
+		}
+	}
+}
+
 func testGCStats(t *testing.T, client *rpc.Client) {
 	ec := New(client)
 	_, err := ec.GCStats(context.Background())
@@ -55,6 +55,45 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// Checkpointer wraps the Checkpoint method of a backing data store. It's an
+// optional capability of both key-value stores and full databases, which can
+// be detected via a type assertion.
+type Checkpointer interface {
+	// Checkpoint writes a consistent, point-in-time copy of the data store into
+	// the given directory without interrupting the operation of the store. The
+	// directory must not exist yet, and the copy can be opened as a regular data
+	// store of the same kind.
+	Checkpoint(dir string) error
+}
+
+// AncientMutability wraps the ImmutableAncients method of a backing database.
+// It's an optional capability, which can be detected via a type assertion.
+type AncientMutability interface {
+	// ImmutableAncients reports whether the chain segment in the ancient store
+	// can't be modified, e.g. because it's served from read-only archives. Such
+	// an ancient store can neither be extended nor truncated.
+	ImmutableAncients() bool
+}
+
+// Snapshot is a read-only, point-in-time view of a key-value data store, which
+// is not affected by any writes made after its creation.
+type Snapshot interface {
+	KeyValueReader
+
+	// Release releases associated resources. Release should always succeed and can
+	// be called multiple times without causing error.
+	Release()
+}
+
+// Snapshotter wraps the NewSnapshot method of a backing data store. It's an
+// optional capability of both key-value stores and full databases, which can
+// be detected via a type assertion.
+type Snapshotter interface {
+	// NewSnapshot creates a read-only view of the data store at the current point
+	// in time. The snapshot must be released after use.
+	NewSnapshot() (Snapshot, error)
+}
+
 // KeyValueStore contains all the methods required to allow handling different
 // key-value data stores backing the high level database.
 type KeyValueStore interface {
@@ -318,6 +318,43 @@ func TestDatabaseSuite(t *testing.T, New func() ethdb.KeyValueStore) {
 		}
 	})
 
+	t.Run("Snapshot", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		snapshotter, ok := db.(ethdb.Snapshotter)
+		if !ok {
+			t.Skip("snapshots not supported")
+		}
+		db.Put([]byte("k1"), []byte("v1"))
+		db.Put([]byte("k2"), []byte("v2"))
+
+		snap, err := snapshotter.NewSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snap.Release()
+
+		db.Put([]byte("k1"), []byte("v1-new"))
+		db.Delete([]byte("k2"))
+		db.Put([]byte("k3"), []byte("v3"))
+
+		if got, err := snap.Get([]byte("k1")); err != nil || !bytes.Equal(got, []byte("v1")) {
+			t.Errorf("snapshot value mismatch: got %q, err %v", got, err)
+		}
+		if has, err := snap.Has([]byte("k2")); err != nil || !has {
+			t.Errorf("deleted key missing from snapshot: %t, err %v", has, err)
+		}
+		if has, err := snap.Has([]byte("k3")); err != nil || has {
+			t.Errorf("new key present in snapshot: %t, err %v", has, err)
+		}
+		if _, err := snap.Get([]byte("k3")); err == nil {
+			t.Error("expected error on Get of new key")
+		}
+		snap.Release()
+		snap.Release() // releasing twice must not fail
+	})
+
 	t.Run("OperationsAfterClose", func(t *testing.T) {
 		db := New()
 		db.Put([]byte("key"), []byte("value"))
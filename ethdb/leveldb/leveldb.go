@@ -22,6 +22,7 @@ package leveldb
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -290,6 +291,68 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
 }
 
+// NewSnapshot creates a read-only view of the database at the current point in
+// time. The snapshot must be released after use.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{db: snap}, nil
+}
+
+// Checkpoint writes a consistent copy of the database into the given directory,
+// which must not exist yet. LevelDB has no native checkpoint support, so the
+// content of a database snapshot is copied over into a fresh database.
+func (db *Database) Checkpoint(dir string) error {
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		if err == nil {
+			err = &os.PathError{Op: "checkpoint", Path: dir, Err: os.ErrExist}
+		}
+		return err
+	}
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	cpy, err := leveldb.OpenFile(dir, configureOptions(func(options *opt.Options) {
+		options.ErrorIfExist = true
+	}))
+	if err != nil {
+		return err
+	}
+	if err := copySnapshot(snap, cpy); err != nil {
+		cpy.Close()
+		os.RemoveAll(dir)
+		return err
+	}
+	return cpy.Close()
+}
+
+// copySnapshot copies the entire content of the given snapshot into the
+// destination database.
+func copySnapshot(snap *leveldb.Snapshot, dst *leveldb.DB) error {
+	it := snap.NewIterator(nil, nil)
+	defer it.Release()
+
+	batch := new(leveldb.Batch)
+	for it.Next() {
+		batch.Put(it.Key(), it.Value())
+		if len(batch.Dump()) >= ethdb.IdealBatchSize {
+			if err := dst.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return dst.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
 // Path returns the path to the database directory.
 func (db *Database) Path() string {
 	return db.fn
@@ -485,3 +548,23 @@ func bytesPrefixRange(prefix, start []byte) *util.Range {
 	r.Start = append(r.Start, start...)
 	return r
 }
+
+// snapshot wraps a leveldb snapshot for implementing the Snapshot interface.
+type snapshot struct {
+	db *leveldb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	return snap.db.Has(key, nil)
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return snap.db.Get(key, nil)
+}
+
+// Release releases the resources held by the snapshot.
+func (snap *snapshot) Release() {
+	snap.db.Release()
+}
@@ -394,6 +394,35 @@ func (d *Database) Compact(start []byte, limit []byte) error {
 	return d.db.Compact(start, limit, true) // Parallelization is preferred
 }
 
+// NewSnapshot creates a read-only view of the database at the current point in
+// time. The snapshot must be released after use.
+func (d *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return nil, pebble.ErrClosed
+	}
+	return &snapshot{db: d.db.NewSnapshot()}, nil
+}
+
+// Checkpoint writes a consistent copy of the database into the given directory,
+// which must not exist yet. Immutable table files are hard linked into the new
+// directory if it's on the same filesystem, otherwise they are copied.
+func (d *Database) Checkpoint(dir string) error {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return pebble.ErrClosed
+	}
+	// Writes are not synced in ephemeral mode, so they might still be buffered
+	// in memory. Flush them into the WAL to make them part of the checkpoint.
+	var opts []pebble.CheckpointOption
+	if !d.writeOptions.Sync {
+		opts = append(opts, pebble.WithFlushedWAL())
+	}
+	return d.db.Checkpoint(dir, opts...)
+}
+
 // Path returns the path to the database directory.
 func (d *Database) Path() string {
 	return d.fn
@@ -633,3 +662,38 @@ func (iter *pebbleIterator) Release() {
 		iter.released = true
 	}
 }
+
+// snapshot wraps a pebble snapshot for implementing the Snapshot interface.
+type snapshot struct {
+	db   *pebble.Snapshot
+	once sync.Once
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	_, closer, err := snap.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	dat, closer, err := snap.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, len(dat))
+	copy(ret, dat)
+	closer.Close()
+	return ret, nil
+}
+
+// Release releases the resources held by the snapshot.
+func (snap *snapshot) Release() {
+	snap.once.Do(func() { snap.db.Close() })
+}
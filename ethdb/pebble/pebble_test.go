@@ -17,6 +17,8 @@
 package pebble
 
 import (
+	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/cockroachdb/pebble"
@@ -41,6 +43,45 @@ func TestPebbleDB(t *testing.T) {
 	})
 }
 
+func TestCheckpoint(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "checkpoint")
+	)
+	db, err := New(filepath.Join(dir, "db"), 16, 16, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 1024; i++ {
+		db.Put([]byte(fmt.Sprintf("key-%04d", i)), []byte(fmt.Sprintf("value-%04d", i)))
+	}
+	if err := db.Checkpoint(path); err != nil {
+		t.Fatalf("failed to create checkpoint: %v", err)
+	}
+	if err := db.Checkpoint(path); err == nil {
+		t.Fatal("checkpoint into existing directory succeeded")
+	}
+	db.Put([]byte("late"), []byte("value"))
+
+	cpy, err := New(path, 16, 16, "", true, false)
+	if err != nil {
+		t.Fatalf("failed to open checkpoint: %v", err)
+	}
+	defer cpy.Close()
+
+	for i := 0; i < 1024; i++ {
+		value, err := cpy.Get([]byte(fmt.Sprintf("key-%04d", i)))
+		if err != nil || string(value) != fmt.Sprintf("value-%04d", i) {
+			t.Fatalf("item %d mismatch: %q, %v", i, value, err)
+		}
+	}
+	if ok, _ := cpy.Has([]byte("late")); ok {
+		t.Fatal("write after the checkpoint is present")
+	}
+}
+
 func BenchmarkPebbleDB(b *testing.B) {
 	dbtest.BenchDatabaseSuite(b, func() ethdb.KeyValueStore {
 		db, err := pebble.Open("", &pebble.Options{
@@ -14,137 +14,460 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package remotedb implements the key-value database layer based on a remote geth
-// node. Under the hood, it utilises the `debug_dbGet` method to implement a
-// read-only database.
-// There really are no guarantees in this database, since the local geth does not
-// exclusive access, but it can be used for basic diagnostics of a remote node.
+// Package remotedb implements the database layer based on a remote geth node.
+// Under the hood, it utilises the methods of the `remotedb` RPC namespace, which
+// are served by the Service of this package on the owning node. Writes are only
+// served over authenticated transports. If the remote node doesn't serve the
+// namespace, it falls back to the read-only `debug_dbGet` and `debug_dbAncient`
+// methods.
+// There really are no guarantees in this database beyond the ones of the single
+// operations, since the owning node keeps on using its database concurrently. For
+// consistent reads, use a snapshot.
 package remotedb
 
 import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
-// Database is a key-value lookup for a remote database via debug_dbGet.
+// iteratePage is the number of entries requested by an iterator at once.
+const iteratePage = 256
+
+// errcodeMethodNotFound is the JSON-RPC error code of calls to unknown methods.
+const errcodeMethodNotFound = -32601
+
+var (
+	// errNotSupported is returned for operations which are meaningless remotely.
+	errNotSupported = errors.New("not supported by remote database")
+
+	// errLegacyNotSupported is returned for operations which are not available
+	// through the debug namespace, if the remote node doesn't serve the remotedb
+	// namespace.
+	errLegacyNotSupported = errors.New("not supported by remote node without remotedb namespace")
+)
+
+// Database is a key-value and ancient store backed by a remote node.
 type Database struct {
 	remote *rpc.Client
+	legacy atomic.Bool // Whether the remote node only serves the debug methods
+}
+
+// isMethodNotFound reports whether the error was caused by the remote node not
+// serving the called method.
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == errcodeMethodNotFound
+}
+
+// call invokes the given method of the remotedb namespace. If the remote node
+// doesn't serve the namespace, the call fails with errLegacyNotSupported.
+func (db *Database) call(result interface{}, method string, args ...interface{}) error {
+	if db.legacy.Load() {
+		return errLegacyNotSupported
+	}
+	return db.remote.Call(result, Namespace+"_"+method, args...)
+}
+
+// callWithFallback invokes the given method of the remotedb namespace, falling
+// back to the given method of the debug namespace if the remote node doesn't
+// serve the former.
+func (db *Database) callWithFallback(result interface{}, method string, fallback string, args ...interface{}) error {
+	if !db.legacy.Load() {
+		err := db.remote.Call(result, Namespace+"_"+method, args...)
+		if !isMethodNotFound(err) {
+			return err
+		}
+		db.legacy.Store(true)
+	}
+	return db.remote.Call(result, fallback, args...)
+}
+
+// New creates a database backed by the node behind the given client.
+func New(client *rpc.Client) ethdb.Database {
+	return &Database{
+		remote: client,
+	}
 }
 
+// Has retrieves if a key is present in the key-value store.
 func (db *Database) Has(key []byte) (bool, error) {
-	if _, err := db.Get(key); err != nil {
-		return false, nil
+	if db.legacy.Load() {
+		if _, err := db.Get(key); err != nil {
+			return false, nil
+		}
+		return true, nil
 	}
-	return true, nil
+	var resp bool
+	err := db.call(&resp, "has", hexutil.Bytes(key))
+	if isMethodNotFound(err) {
+		db.legacy.Store(true)
+		return db.Has(key)
+	}
+	return resp, err
 }
 
+// Get retrieves the given key if it's present in the key-value store.
 func (db *Database) Get(key []byte) ([]byte, error) {
 	var resp hexutil.Bytes
-	err := db.remote.Call(&resp, "debug_dbGet", hexutil.Bytes(key))
-	if err != nil {
+	if err := db.callWithFallback(&resp, "get", "debug_dbGet", hexutil.Bytes(key)); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// Put inserts the given value into the key-value store.
+func (db *Database) Put(key []byte, value []byte) error {
+	return db.call(nil, "put", hexutil.Bytes(key), hexutil.Bytes(value))
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	return db.call(nil, "delete", hexutil.Bytes(key))
+}
+
+// NewBatch creates a write-only key-value store that buffers changes until a
+// final write is called, which applies them atomically on the remote node.
+func (db *Database) NewBatch() ethdb.Batch {
+	return &batch{db: db}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{db: db, ops: make([]BatchOp, 0, size)}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of database
+// content with a particular key prefix, starting at a particular initial key
+// (or after, if it does not exist). The entries are fetched in pages, every page
+// reflecting the remote database at the time of its retrieval.
+func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return &iterator{
+		db:     db,
+		prefix: common.CopyBytes(prefix),
+		start:  common.CopyBytes(start),
+		pos:    -1,
+	}
+}
+
+// NewSnapshot creates a read-only view of the remote key-value store at the
+// current point in time. The snapshot must be released after use, otherwise it
+// is only dropped by the remote node after a period of inactivity.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	var id string
+	if err := db.call(&id, "newSnapshot"); err != nil {
+		return nil, err
+	}
+	return &snapshot{db: db, id: id}, nil
+}
+
+// Stat returns the statistic data of the remote database.
+func (db *Database) Stat() (string, error) {
+	var resp string
+	err := db.call(&resp, "stat")
+	return resp, err
+}
+
+// Compact flattens the remote key-value store for the given key range.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	return db.call(nil, "compact", hexutil.Bytes(start), hexutil.Bytes(limit))
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// ancient store.
 func (db *Database) HasAncient(kind string, number uint64) (bool, error) {
-	if _, err := db.Ancient(kind, number); err != nil {
-		return false, nil
+	if db.legacy.Load() {
+		if _, err := db.Ancient(kind, number); err != nil {
+			return false, nil
+		}
+		return true, nil
+	}
+	var resp bool
+	err := db.call(&resp, "hasAncient", kind, number)
+	if isMethodNotFound(err) {
+		db.legacy.Store(true)
+		return db.HasAncient(kind, number)
 	}
-	return true, nil
+	return resp, err
 }
 
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
 func (db *Database) Ancient(kind string, number uint64) ([]byte, error) {
 	var resp hexutil.Bytes
-	err := db.remote.Call(&resp, "debug_dbAncient", kind, number)
-	if err != nil {
+	if err := db.callWithFallback(&resp, "ancient", "debug_dbAncient", kind, number); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// AncientRange retrieves multiple items in sequence, starting from the index
+// 'start'.
 func (db *Database) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
-	panic("not supported")
+	var resp []hexutil.Bytes
+	if err := db.call(&resp, "ancientRange", kind, start, count, maxBytes); err != nil {
+		return nil, err
+	}
+	items := make([][]byte, len(resp))
+	for i, item := range resp {
+		items[i] = item
+	}
+	return items, nil
 }
 
+// Ancients returns the ancient item numbers in the ancient store.
 func (db *Database) Ancients() (uint64, error) {
 	var resp uint64
-	err := db.remote.Call(&resp, "debug_dbAncients")
+	err := db.callWithFallback(&resp, "ancients", "debug_dbAncients")
 	return resp, err
 }
 
+// Tail returns the number of first stored item in the ancient store.
 func (db *Database) Tail() (uint64, error) {
-	panic("not supported")
+	var resp uint64
+	err := db.call(&resp, "tail")
+	return resp, err
 }
 
+// AncientSize returns the ancient size of the specified category.
 func (db *Database) AncientSize(kind string) (uint64, error) {
-	panic("not supported")
+	var resp uint64
+	err := db.call(&resp, "ancientSize", kind)
+	return resp, err
 }
 
+// ReadAncients runs the given read operation. Note, writes of the remote node
+// are not blocked in the meantime.
 func (db *Database) ReadAncients(fn func(op ethdb.AncientReaderOp) error) (err error) {
 	return fn(db)
 }
 
-func (db *Database) Put(key []byte, value []byte) error {
-	panic("not supported")
+// ModifyAncients runs a write operation on the ancient store. The appended items
+// are buffered locally, and only sent to the remote node if the operation was
+// successful, where they are applied atomically.
+func (db *Database) ModifyAncients(fn func(ethdb.AncientWriteOp) error) (int64, error) {
+	op := new(ancientWriteOp)
+	if err := fn(op); err != nil {
+		return 0, err
+	}
+	var resp int64
+	err := db.call(&resp, "modifyAncients", op.ops)
+	return resp, err
 }
 
-func (db *Database) Delete(key []byte) error {
-	panic("not supported")
+// TruncateHead discards all but the first n ancient data from the ancient store.
+func (db *Database) TruncateHead(n uint64) (uint64, error) {
+	var resp uint64
+	err := db.call(&resp, "truncateHead", n)
+	return resp, err
 }
 
-func (db *Database) ModifyAncients(f func(ethdb.AncientWriteOp) error) (int64, error) {
-	panic("not supported")
+// TruncateTail discards the first n ancient data from the ancient store.
+func (db *Database) TruncateTail(n uint64) (uint64, error) {
+	var resp uint64
+	err := db.call(&resp, "truncateTail", n)
+	return resp, err
 }
 
-func (db *Database) TruncateHead(n uint64) (uint64, error) {
-	panic("not supported")
+// Sync flushes all in-memory ancient store data of the remote node to disk.
+func (db *Database) Sync() error {
+	return db.call(nil, "sync")
 }
 
-func (db *Database) TruncateTail(n uint64) (uint64, error) {
-	panic("not supported")
+// MigrateTable is not supported remotely.
+func (db *Database) MigrateTable(s string, f func([]byte) ([]byte, error)) error {
+	return errNotSupported
 }
 
-func (db *Database) Sync() error {
+// AncientDatadir is not supported, as the ancient directory of the remote node
+// is not accessible locally.
+func (db *Database) AncientDatadir() (string, error) {
+	return "", errNotSupported
+}
+
+// Close closes the connection to the remote node.
+func (db *Database) Close() error {
+	db.remote.Close()
 	return nil
 }
 
-func (db *Database) MigrateTable(s string, f func([]byte) ([]byte, error)) error {
-	panic("not supported")
+// batch is a write-only key-value store that buffers changes to the remote
+// database until a final write is called.
+type batch struct {
+	db   *Database
+	ops  []BatchOp
+	size int
 }
 
-func (db *Database) NewBatch() ethdb.Batch {
-	panic("not supported")
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.ops = append(b.ops, BatchOp{Key: common.CopyBytes(key), Value: common.CopyBytes(value)})
+	b.size += len(key) + len(value)
+	return nil
 }
 
-func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
-	panic("not supported")
+// Delete inserts the key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, BatchOp{Key: common.CopyBytes(key), Delete: true})
+	b.size += len(key)
+	return nil
 }
 
-func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
-	panic("not supported")
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
 }
 
-func (db *Database) Stat() (string, error) {
-	return "", nil
+// Write flushes any accumulated data to the remote database.
+func (b *batch) Write() error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	return b.db.call(nil, "writeBatch", b.ops)
 }
 
-func (db *Database) AncientDatadir() (string, error) {
-	panic("not supported")
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
 }
 
-func (db *Database) Compact(start []byte, limit []byte) error {
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, op := range b.ops {
+		if op.Delete {
+			if err := w.Delete(op.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.Put(op.Key, op.Value); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (db *Database) Close() error {
-	db.remote.Close()
-	return nil
+// iterator iterates over the remote database, fetching the entries in pages.
+type iterator struct {
+	db     *Database
+	prefix []byte
+	start  []byte // Start key of the next page, excluding the prefix
+
+	keys   []hexutil.Bytes
+	values []hexutil.Bytes
+	pos    int
+	done   bool // Whether the remote iteration is exhausted
+	err    error
 }
 
-func New(client *rpc.Client) ethdb.Database {
-	return &Database{
-		remote: client,
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos+1 < len(it.keys) {
+		it.pos++
+		return true
+	}
+	if it.done {
+		it.keys, it.values, it.pos = nil, nil, -1
+		return false
+	}
+	var page IteratorPage
+	if err := it.db.call(&page, "iterate", hexutil.Bytes(it.prefix), hexutil.Bytes(it.start), iteratePage); err != nil {
+		it.err = err
+		it.keys, it.values, it.pos = nil, nil, -1
+		return false
 	}
+	it.keys, it.values, it.pos, it.done = page.Keys, page.Values, 0, page.Done
+	if len(it.keys) == 0 {
+		it.done = true
+		return false
+	}
+	// Continue right after the last key of the page
+	last := it.keys[len(it.keys)-1]
+	it.start = append(common.CopyBytes(last[len(it.prefix):]), 0)
+	return true
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.pos]
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.pos < 0 || it.pos >= len(it.values) {
+		return nil
+	}
+	return it.values[it.pos]
+}
+
+// Release releases associated resources.
+func (it *iterator) Release() {
+	it.keys, it.values, it.pos, it.done = nil, nil, -1, true
+}
+
+// snapshot is a point-in-time view of the remote key-value store, held open by
+// the remote node.
+type snapshot struct {
+	db *Database
+	id string
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	var resp bool
+	err := snap.db.call(&resp, "snapshotHas", snap.id, hexutil.Bytes(key))
+	return resp, err
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	var resp hexutil.Bytes
+	if err := snap.db.call(&resp, "snapshotGet", snap.id, hexutil.Bytes(key)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Release releases the snapshot on the remote node. Failures are ignored, the
+// remote node drops the snapshot after a while anyway.
+func (snap *snapshot) Release() {
+	snap.db.call(nil, "releaseSnapshot", snap.id)
+}
+
+// ancientWriteOp buffers the items appended to the ancient store.
+type ancientWriteOp struct {
+	ops []AncientOp
+}
+
+// Append adds an RLP-encoded item.
+func (op *ancientWriteOp) Append(kind string, number uint64, item interface{}) error {
+	blob, err := rlp.EncodeToBytes(item)
+	if err != nil {
+		return err
+	}
+	return op.AppendRaw(kind, number, blob)
+}
+
+// AppendRaw adds an item without RLP-encoding it.
+func (op *ancientWriteOp) AppendRaw(kind string, number uint64, item []byte) error {
+	op.ops = append(op.ops, AncientOp{Kind: kind, Number: number, Item: common.CopyBytes(item)})
+	return nil
 }
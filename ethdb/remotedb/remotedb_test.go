@@ -0,0 +1,249 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/dbtest"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newRemote serves the given database over an in-process RPC connection and
+// returns the remote database accessing it.
+func newRemote(t *testing.T, db ethdb.Database) ethdb.Database {
+	return newRemoteWithAuth(t, db, true)
+}
+
+// newRemoteWithAuth serves the given database like newRemote, but only exposes
+// the APIs reserved for authenticated transports if requested.
+func newRemoteWithAuth(t *testing.T, db ethdb.Database, authenticated bool) ethdb.Database {
+	service := NewService(db)
+	server := rpc.NewServer()
+	for _, api := range service.APIs() {
+		if api.Authenticated && !authenticated {
+			continue
+		}
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		server.Stop()
+		service.Close()
+	})
+	return New(rpc.DialInProc(server))
+}
+
+func TestRemoteDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return newRemote(t, rawdb.NewMemoryDatabase())
+		})
+	})
+}
+
+// Tests that iterators spanning multiple pages return every entry exactly once.
+func TestRemoteIteratorPaging(t *testing.T) {
+	var (
+		local  = rawdb.NewMemoryDatabase()
+		remote = newRemote(t, local)
+		prefix = []byte("p")
+	)
+	for i := uint64(0); i < 3*iteratePage+10; i++ {
+		key := binary.BigEndian.AppendUint64(bytes.Clone(prefix), i)
+		local.Put(key, key)
+	}
+	local.Put([]byte("q"), nil) // outside of the prefix
+
+	it := remote.NewIterator(prefix, binary.BigEndian.AppendUint64(nil, 5))
+	defer it.Release()
+
+	next := uint64(5)
+	for it.Next() {
+		want := binary.BigEndian.AppendUint64(bytes.Clone(prefix), next)
+		if !bytes.Equal(it.Key(), want) || !bytes.Equal(it.Value(), want) {
+			t.Fatalf("entry %d mismatch: have %x, want %x", next, it.Key(), want)
+		}
+		next++
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if next != 3*iteratePage+10 {
+		t.Fatalf("iteration ended early: have %d, want %d", next, 3*iteratePage+10)
+	}
+}
+
+func TestRemoteAncients(t *testing.T) {
+	local, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	remote := newRemote(t, local)
+
+	size, err := remote.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 4; i++ {
+			for _, kind := range []string{rawdb.ChainFreezerHeaderTable, rawdb.ChainFreezerHashTable, rawdb.ChainFreezerBodiesTable, rawdb.ChainFreezerReceiptTable, rawdb.ChainFreezerDifficultyTable} {
+				if err := op.AppendRaw(kind, i, []byte{byte(i)}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size == 0 {
+		t.Fatal("no ancient data written")
+	}
+	if frozen, err := remote.Ancients(); err != nil || frozen != 4 {
+		t.Fatalf("ancients mismatch: have %d, want %d (err %v)", frozen, 4, err)
+	}
+	if blob, err := remote.Ancient(rawdb.ChainFreezerHeaderTable, 2); err != nil || !bytes.Equal(blob, []byte{2}) {
+		t.Fatalf("ancient mismatch: have %x (err %v)", blob, err)
+	}
+	items, err := remote.AncientRange(rawdb.ChainFreezerBodiesTable, 1, 10, 0)
+	if err != nil || len(items) != 3 {
+		t.Fatalf("ancient range mismatch: have %d items (err %v)", len(items), err)
+	}
+	if _, err := remote.TruncateHead(2); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := remote.HasAncient(rawdb.ChainFreezerHeaderTable, 2); err != nil || has {
+		t.Fatalf("truncated ancient still present: %t (err %v)", has, err)
+	}
+	if local, _ := local.Ancients(); local != 2 {
+		t.Fatalf("local ancients mismatch: have %d, want 2", local)
+	}
+}
+
+// Tests that writes are refused if the database is served on an unauthenticated
+// transport, while reads keep working.
+func TestRemoteUnauthenticated(t *testing.T) {
+	var (
+		local  = rawdb.NewMemoryDatabase()
+		remote = newRemoteWithAuth(t, local, false)
+	)
+	local.Put([]byte("key"), []byte("value"))
+
+	if blob, err := remote.Get([]byte("key")); err != nil || !bytes.Equal(blob, []byte("value")) {
+		t.Fatalf("read mismatch: have %x (err %v)", blob, err)
+	}
+	if err := remote.Put([]byte("key"), []byte("other")); err == nil {
+		t.Fatal("put accepted on unauthenticated transport")
+	}
+	if err := remote.Delete([]byte("key")); err == nil {
+		t.Fatal("delete accepted on unauthenticated transport")
+	}
+	batch := remote.NewBatch()
+	batch.Put([]byte("other"), []byte("value"))
+	if err := batch.Write(); err == nil {
+		t.Fatal("batch accepted on unauthenticated transport")
+	}
+	if _, err := remote.TruncateHead(0); err == nil {
+		t.Fatal("head truncation accepted on unauthenticated transport")
+	}
+	if blob, _ := local.Get([]byte("key")); !bytes.Equal(blob, []byte("value")) {
+		t.Fatalf("local database modified: have %x", blob)
+	}
+	// The unavailable writes must not switch the client to legacy mode
+	if has, err := remote.Has([]byte("other")); err != nil || has {
+		t.Fatalf("unexpected presence: %t (err %v)", has, err)
+	}
+}
+
+// legacyDebugAPI mimics the database methods of the debug namespace of nodes
+// which don't serve the remotedb namespace.
+type legacyDebugAPI struct {
+	db ethdb.Database
+}
+
+func (api *legacyDebugAPI) DbGet(key string) (hexutil.Bytes, error) {
+	blob, err := common.ParseHexOrString(key)
+	if err != nil {
+		return nil, err
+	}
+	return api.db.Get(blob)
+}
+
+func (api *legacyDebugAPI) DbAncient(kind string, number uint64) (hexutil.Bytes, error) {
+	return api.db.Ancient(kind, number)
+}
+
+func (api *legacyDebugAPI) DbAncients() (uint64, error) {
+	return api.db.Ancients()
+}
+
+// Tests that the remote database falls back to the debug namespace for reads if
+// the remote node doesn't serve the remotedb namespace.
+func TestRemoteLegacyFallback(t *testing.T) {
+	local, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	local.Put([]byte("key"), []byte("value"))
+	if _, err := local.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for _, kind := range []string{rawdb.ChainFreezerHeaderTable, rawdb.ChainFreezerHashTable, rawdb.ChainFreezerBodiesTable, rawdb.ChainFreezerReceiptTable, rawdb.ChainFreezerDifficultyTable} {
+			if err := op.AppendRaw(kind, 0, []byte{1}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", &legacyDebugAPI{db: local}); err != nil {
+		t.Fatal(err)
+	}
+	remote := New(rpc.DialInProc(server))
+	defer remote.Close()
+
+	if has, err := remote.Has([]byte("key")); err != nil || !has {
+		t.Fatalf("key not found: %t (err %v)", has, err)
+	}
+	if blob, err := remote.Get([]byte("key")); err != nil || !bytes.Equal(blob, []byte("value")) {
+		t.Fatalf("read mismatch: have %x (err %v)", blob, err)
+	}
+	if has, _ := remote.Has([]byte("missing")); has {
+		t.Fatal("missing key found")
+	}
+	if frozen, err := remote.Ancients(); err != nil || frozen != 1 {
+		t.Fatalf("ancients mismatch: have %d, want 1 (err %v)", frozen, err)
+	}
+	if has, err := remote.HasAncient(rawdb.ChainFreezerHeaderTable, 0); err != nil || !has {
+		t.Fatalf("ancient not found: %t (err %v)", has, err)
+	}
+	if blob, err := remote.Ancient(rawdb.ChainFreezerHeaderTable, 0); err != nil || !bytes.Equal(blob, []byte{1}) {
+		t.Fatalf("ancient mismatch: have %x (err %v)", blob, err)
+	}
+	if _, err := remote.Tail(); err != errLegacyNotSupported {
+		t.Fatalf("unexpected error: have %v, want %v", err, errLegacyNotSupported)
+	}
+}
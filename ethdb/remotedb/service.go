@@ -0,0 +1,357 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Namespace is the RPC namespace the database service is served in.
+const Namespace = "remotedb"
+
+const (
+	// maxIteratePage is the maximum number of entries returned by one
+	// iteration request.
+	maxIteratePage = 1024
+
+	// maxIterateBytes is the soft limit of the data returned by one iteration
+	// request. At least one entry is always returned.
+	maxIterateBytes = 4 * 1024 * 1024
+
+	// maxSnapshots is the maximum number of concurrently open snapshots.
+	maxSnapshots = 64
+
+	// snapshotTimeout is the idle time after which an unreleased snapshot is
+	// dropped, so that abandoned clients don't pin resources forever.
+	snapshotTimeout = 5 * time.Minute
+)
+
+var (
+	// errUnknownSnapshot is returned if a snapshot is requested which doesn't
+	// exist, or which was already released or dropped.
+	errUnknownSnapshot = errors.New("unknown snapshot")
+
+	// errTooManySnapshots is returned if the snapshot limit is reached.
+	errTooManySnapshots = errors.New("too many open snapshots")
+
+	// errServiceClosed is returned if the service was already shut down.
+	errServiceClosed = errors.New("service closed")
+)
+
+// BatchOp is a buffered key-value write of a remote batch.
+type BatchOp struct {
+	Key    hexutil.Bytes `json:"key"`
+	Value  hexutil.Bytes `json:"value,omitempty"`
+	Delete bool          `json:"delete,omitempty"`
+}
+
+// AncientOp is a buffered item append of a remote ancient write operation.
+type AncientOp struct {
+	Kind   string        `json:"kind"`
+	Number uint64        `json:"number"`
+	Item   hexutil.Bytes `json:"item"`
+}
+
+// IteratorPage is a chunk of consecutive entries of an iteration.
+type IteratorPage struct {
+	Keys   []hexutil.Bytes `json:"keys"`
+	Values []hexutil.Bytes `json:"values"`
+	Done   bool            `json:"done"` // Whether the iteration is exhausted
+}
+
+// snapshotSession is an open snapshot, tracked along with its last use.
+type snapshotSession struct {
+	snap ethdb.Snapshot
+	used time.Time
+}
+
+// Service exposes the reads of a database over RPC, serving the remote database
+// backend of this package. The writes are served by WriteService.
+type Service struct {
+	db    ethdb.Database
+	snaps map[string]*snapshotSession
+	lock  sync.Mutex
+}
+
+// NewService creates the RPC service for the given database.
+func NewService(db ethdb.Database) *Service {
+	return &Service{
+		db:    db,
+		snaps: make(map[string]*snapshotSession),
+	}
+}
+
+// APIs returns the RPC APIs serving the database. Reads are available on every
+// transport the namespace is enabled on, but writes are only accepted through
+// authenticated ones, i.e. IPC, in-process and the authenticated endpoint.
+func (s *Service) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: Namespace,
+			Service:   s,
+		}, {
+			Namespace:     Namespace,
+			Service:       &WriteService{db: s.db},
+			Authenticated: true,
+		},
+	}
+}
+
+// Close releases all open snapshots. It must be called before the database is
+// closed.
+func (s *Service) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, session := range s.snaps {
+		session.snap.Release()
+	}
+	s.snaps = nil
+}
+
+// Has retrieves if a key is present in the database.
+func (s *Service) Has(key hexutil.Bytes) (bool, error) {
+	return s.db.Has(key)
+}
+
+// Get retrieves the given key if it's present in the database.
+func (s *Service) Get(key hexutil.Bytes) (hexutil.Bytes, error) {
+	return s.db.Get(key)
+}
+
+// Iterate returns up to limit consecutive entries with the given prefix,
+// starting at the given key (or after, if it does not exist). The start key
+// excludes the prefix.
+func (s *Service) Iterate(prefix hexutil.Bytes, start hexutil.Bytes, limit int) (*IteratorPage, error) {
+	if limit <= 0 || limit > maxIteratePage {
+		limit = maxIteratePage
+	}
+	it := s.db.NewIterator(prefix, start)
+	defer it.Release()
+
+	var (
+		page = new(IteratorPage)
+		size int
+	)
+	for len(page.Keys) < limit && size < maxIterateBytes {
+		if !it.Next() {
+			page.Done = true
+			break
+		}
+		page.Keys = append(page.Keys, common.CopyBytes(it.Key()))
+		page.Values = append(page.Values, common.CopyBytes(it.Value()))
+		size += len(it.Key()) + len(it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// NewSnapshot creates a read-only view of the database at the current point in
+// time, returning its identifier. Snapshots not used for a while are dropped.
+func (s *Service) NewSnapshot() (string, error) {
+	snapshotter, ok := s.db.(ethdb.Snapshotter)
+	if !ok {
+		return "", errors.New("snapshots not supported")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.snaps == nil {
+		return "", errServiceClosed
+	}
+	for id, session := range s.snaps {
+		if time.Since(session.used) > snapshotTimeout {
+			session.snap.Release()
+			delete(s.snaps, id)
+		}
+	}
+	if len(s.snaps) >= maxSnapshots {
+		return "", errTooManySnapshots
+	}
+	snap, err := snapshotter.NewSnapshot()
+	if err != nil {
+		return "", err
+	}
+	id := string(rpc.NewID())
+	s.snaps[id] = &snapshotSession{snap: snap, used: time.Now()}
+	return id, nil
+}
+
+// snapshot retrieves an open snapshot, refreshing its last use.
+func (s *Service) snapshot(id string) (ethdb.Snapshot, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	session, ok := s.snaps[id]
+	if !ok {
+		return nil, errUnknownSnapshot
+	}
+	session.used = time.Now()
+	return session.snap, nil
+}
+
+// SnapshotHas retrieves if a key is present in the given snapshot.
+func (s *Service) SnapshotHas(id string, key hexutil.Bytes) (bool, error) {
+	snap, err := s.snapshot(id)
+	if err != nil {
+		return false, err
+	}
+	return snap.Has(key)
+}
+
+// SnapshotGet retrieves the given key if it's present in the given snapshot.
+func (s *Service) SnapshotGet(id string, key hexutil.Bytes) (hexutil.Bytes, error) {
+	snap, err := s.snapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Get(key)
+}
+
+// ReleaseSnapshot releases the given snapshot. Releasing an unknown snapshot
+// is not an error.
+func (s *Service) ReleaseSnapshot(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if session, ok := s.snaps[id]; ok {
+		session.snap.Release()
+		delete(s.snaps, id)
+	}
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// ancient store.
+func (s *Service) HasAncient(kind string, number uint64) (bool, error) {
+	return s.db.HasAncient(kind, number)
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (s *Service) Ancient(kind string, number uint64) (hexutil.Bytes, error) {
+	return s.db.Ancient(kind, number)
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index
+// 'start'.
+func (s *Service) AncientRange(kind string, start, count, maxBytes uint64) ([]hexutil.Bytes, error) {
+	items, err := s.db.AncientRange(kind, start, count, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]hexutil.Bytes, len(items))
+	for i, item := range items {
+		blobs[i] = item
+	}
+	return blobs, nil
+}
+
+// Ancients returns the ancient item numbers in the ancient store.
+func (s *Service) Ancients() (uint64, error) {
+	return s.db.Ancients()
+}
+
+// Tail returns the number of first stored item in the ancient store.
+func (s *Service) Tail() (uint64, error) {
+	return s.db.Tail()
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (s *Service) AncientSize(kind string) (uint64, error) {
+	return s.db.AncientSize(kind)
+}
+
+// Sync flushes all in-memory ancient store data to disk.
+func (s *Service) Sync() error {
+	return s.db.Sync()
+}
+
+// Stat returns the statistic data of the database.
+func (s *Service) Stat() (string, error) {
+	return s.db.Stat()
+}
+
+// WriteService exposes the modifying operations of a database over RPC. It is
+// served in the same namespace as Service, but only on authenticated transports.
+type WriteService struct {
+	db ethdb.Database
+}
+
+// Put inserts the given value into the database.
+func (s *WriteService) Put(key hexutil.Bytes, value hexutil.Bytes) error {
+	return s.db.Put(key, value)
+}
+
+// Delete removes the key from the database.
+func (s *WriteService) Delete(key hexutil.Bytes) error {
+	return s.db.Delete(key)
+}
+
+// WriteBatch atomically applies the given writes to the database.
+func (s *WriteService) WriteBatch(ops []BatchOp) error {
+	batch := s.db.NewBatch()
+	for _, op := range ops {
+		var err error
+		if op.Delete {
+			err = batch.Delete(op.Key)
+		} else {
+			err = batch.Put(op.Key, op.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// ModifyAncients atomically appends the given items to the ancient store,
+// returning the total size of the written data.
+func (s *WriteService) ModifyAncients(ops []AncientOp) (int64, error) {
+	return s.db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for _, item := range ops {
+			if err := op.AppendRaw(item.Kind, item.Number, item.Item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TruncateHead discards all but the first n ancient data from the ancient store,
+// returning the previous head.
+func (s *WriteService) TruncateHead(n uint64) (uint64, error) {
+	return s.db.TruncateHead(n)
+}
+
+// TruncateTail discards the first n ancient data from the ancient store,
+// returning the previous tail.
+func (s *WriteService) TruncateTail(n uint64) (uint64, error) {
+	return s.db.TruncateTail(n)
+}
+
+// Compact flattens the underlying data store for the given key range.
+func (s *WriteService) Compact(start hexutil.Bytes, limit hexutil.Bytes) error {
+	return s.db.Compact(start, limit)
+}
@@ -20,8 +20,11 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ssz "github.com/ferranbt/fastssz"
 )
 
@@ -47,6 +50,28 @@ func ComputeAccumulator(hashes []common.Hash, tds []*big.Int) (common.Hash, erro
 	return hh.HashRoot()
 }
 
+// ReadRoots reads a file of newline-delimited accumulator roots, the root of the
+// era1 archive of each epoch in order.
+func ReadRoots(path string) ([]common.Hash, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var roots []common.Hash
+	for i, line := range strings.Split(string(blob), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		root, err := hexutil.Decode(line)
+		if err != nil || len(root) != common.HashLength {
+			return nil, fmt.Errorf("invalid accumulator root on line %d: %q", i+1, line)
+		}
+		roots = append(roots, common.BytesToHash(root))
+	}
+	return roots, nil
+}
+
 // headerRecord is an individual record for a historical header.
 //
 // See https://github.com/ethereum/portal-network-specs/blob/master/history-network.md#the-header-accumulator
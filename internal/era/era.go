@@ -154,6 +154,74 @@ func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
 	return types.NewBlockWithHeader(&header).WithBody(body), nil
 }
 
+// GetRawHeaderByNumber returns the RLP-encoded header for the given block number.
+func (e *Era) GetRawHeaderByNumber(num uint64) ([]byte, error) {
+	return e.readEntry(num, TypeCompressedHeader, 0)
+}
+
+// GetRawBodyByNumber returns the RLP-encoded body for the given block number.
+func (e *Era) GetRawBodyByNumber(num uint64) ([]byte, error) {
+	return e.readEntry(num, TypeCompressedBody, 1)
+}
+
+// GetRawReceiptsByNumber returns the RLP-encoded receipts for the given block
+// number.
+func (e *Era) GetRawReceiptsByNumber(num uint64) ([]byte, error) {
+	return e.readEntry(num, TypeCompressedReceipts, 2)
+}
+
+// GetTotalDifficultyByNumber returns the total difficulty after the given block
+// number is applied.
+func (e *Era) GetTotalDifficultyByNumber(num uint64) (*big.Int, error) {
+	off, err := e.skipEntries(num, 3)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := e.s.ReaderAt(TypeTotalDifficulty, off)
+	if err != nil {
+		return nil, err
+	}
+	rawTd, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(reverseOrder(rawTd)), nil
+}
+
+// readEntry reads and decompresses an entry of the given block, skipping over
+// the preceding entries of the block.
+func (e *Era) readEntry(num uint64, expectedType uint16, skip int) ([]byte, error) {
+	off, err := e.skipEntries(num, skip)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := newSnappyReader(e.s, expectedType, off)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// skipEntries returns the offset of an entry of the given block, skipping over
+// the preceding entries of the block.
+func (e *Era) skipEntries(num uint64, skip int) (int64, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return 0, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < skip; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return 0, err
+		}
+		off += length
+	}
+	return off, nil
+}
+
 // Accumulator reads the accumulator entry in the Era1 file.
 func (e *Era) Accumulator() (common.Hash, error) {
 	entry, err := e.s.Find(TypeAccumulator)
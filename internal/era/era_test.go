@@ -122,6 +122,24 @@ func TestEra1Builder(t *testing.T) {
 			t.Fatalf("mismatched tds: want %s, got %s", chain.tds[i], td)
 		}
 	}
+	// Check random access to the raw entries.
+	for _, i := range []uint64{0, 63, 127} {
+		if header, err := e.GetRawHeaderByNumber(i); err != nil || !bytes.Equal(header, chain.headers[i]) {
+			t.Fatalf("mismatched header %d: want %s, got %s (err %v)", i, chain.headers[i], header, err)
+		}
+		if body, err := e.GetRawBodyByNumber(i); err != nil || !bytes.Equal(body, chain.bodies[i]) {
+			t.Fatalf("mismatched body %d: want %s, got %s (err %v)", i, chain.bodies[i], body, err)
+		}
+		if receipts, err := e.GetRawReceiptsByNumber(i); err != nil || !bytes.Equal(receipts, chain.receipts[i]) {
+			t.Fatalf("mismatched receipts %d: want %s, got %s (err %v)", i, chain.receipts[i], receipts, err)
+		}
+		if td, err := e.GetTotalDifficultyByNumber(i); err != nil || td.Cmp(chain.tds[i]) != 0 {
+			t.Fatalf("mismatched td %d: want %s, got %s (err %v)", i, chain.tds[i], td, err)
+		}
+	}
+	if _, err := e.GetRawBodyByNumber(128); err == nil {
+		t.Fatalf("expected out-of-bounds error")
+	}
 }
 
 func TestEraFilename(t *testing.T) {
@@ -48,6 +48,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/holiman/uint256"
 	"github.com/tyler-smith/go-bip39"
 )
@@ -186,6 +187,14 @@ func NewTxPoolAPI(b Backend) *TxPoolAPI {
 	return &TxPoolAPI{b}
 }
 
+// rpcTransaction returns the RPC representation of a pooled transaction, along
+// with whether it was submitted privately.
+func (api *TxPoolAPI) rpcTransaction(tx *types.Transaction, current *types.Header) *RPCTransaction {
+	result := NewRPCPendingTransaction(tx, current, api.b.ChainConfig())
+	result.Private = api.b.IsPrivateTx(tx.Hash())
+	return result
+}
+
 // Content returns the transactions contained within the transaction pool.
 func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction {
 	content := map[string]map[string]map[string]*RPCTransaction{
@@ -198,7 +207,7 @@ func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction
 	for account, txs := range pending {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx, curHeader, api.b.ChainConfig())
+			dump[fmt.Sprintf("%d", tx.Nonce())] = api.rpcTransaction(tx, curHeader)
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -206,7 +215,7 @@ func (api *TxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction
 	for account, txs := range queue {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx, curHeader, api.b.ChainConfig())
+			dump[fmt.Sprintf("%d", tx.Nonce())] = api.rpcTransaction(tx, curHeader)
 		}
 		content["queued"][account.Hex()] = dump
 	}
@@ -222,14 +231,14 @@ func (api *TxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RP
 	// Build the pending transactions
 	dump := make(map[string]*RPCTransaction, len(pending))
 	for _, tx := range pending {
-		dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx, curHeader, api.b.ChainConfig())
+		dump[fmt.Sprintf("%d", tx.Nonce())] = api.rpcTransaction(tx, curHeader)
 	}
 	content["pending"] = dump
 
 	// Build the queued transactions
 	dump = make(map[string]*RPCTransaction, len(queue))
 	for _, tx := range queue {
-		dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx, curHeader, api.b.ChainConfig())
+		dump[fmt.Sprintf("%d", tx.Nonce())] = api.rpcTransaction(tx, curHeader)
 	}
 	content["queued"] = dump
 
@@ -736,16 +745,7 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 		}
 		// Create the proofs for the storageKeys.
 		for i, key := range keys {
-			// Output key encoding is a bit special: if the input was a 32-byte hash, it is
-			// returned as such. Otherwise, we apply the QUANTITY encoding mandated by the
-			// JSON-RPC spec for getProof. This behavior exists to preserve backwards
-			// compatibility with older client versions.
-			var outputKey string
-			if keyLengths[i] != 32 {
-				outputKey = hexutil.EncodeBig(key.Big())
-			} else {
-				outputKey = hexutil.Encode(key[:])
-			}
+			outputKey := encodeProofKey(key, keyLengths[i])
 			if storageTrie == nil {
 				storageProof[i] = StorageResult{outputKey, &hexutil.Big{}, []string{}}
 				continue
@@ -779,6 +779,105 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 	}, statedb.Error()
 }
 
+// MultiProofResult is the result of GetMultiProof. Instead of a separate branch
+// for the account and each storage slot, it contains a single set of trie nodes
+// proving all of them.
+type MultiProofResult struct {
+	Address     common.Address      `json:"address"`
+	Balance     *hexutil.Big        `json:"balance"`
+	CodeHash    common.Hash         `json:"codeHash"`
+	Nonce       hexutil.Uint64      `json:"nonce"`
+	StorageHash common.Hash         `json:"storageHash"`
+	Storage     []MultiProofStorage `json:"storage"`
+	Proof       []string            `json:"proof"`
+}
+
+// MultiProofStorage is a storage slot proven by a MultiProofResult.
+type MultiProofStorage struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+}
+
+// GetMultiProof returns the Merkle-proof for a given account and optionally some
+// storage keys, like GetProof. The proof is a single deduplicated set of the trie
+// nodes from both the account trie and the storage trie, with nodes shared by
+// multiple paths included only once.
+func (api *BlockChainAPI) GetMultiProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*MultiProofResult, error) {
+	var (
+		keys       = make([]common.Hash, len(storageKeys))
+		keyLengths = make([]int, len(storageKeys))
+		storage    = make([]MultiProofStorage, len(storageKeys))
+		proof      = trienode.NewProofSet()
+	)
+	// Deserialize all keys. This prevents state access on invalid input.
+	for i, hexKey := range storageKeys {
+		var err error
+		keys[i], keyLengths[i], err = decodeHash(hexKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	statedb, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	// Create the proof for the account first, so that the nodes are ordered
+	// from the state root downwards.
+	tr, err := trie.NewStateTrie(trie.StateTrieID(header.Root), statedb.Database().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	if err := tr.Prove(crypto.Keccak256(address.Bytes()), proof); err != nil {
+		return nil, err
+	}
+	storageRoot := statedb.GetStorageRoot(address)
+	if len(keys) > 0 && storageRoot != types.EmptyRootHash && storageRoot != (common.Hash{}) {
+		id := trie.StorageTrieID(header.Root, crypto.Keccak256Hash(address.Bytes()), storageRoot)
+		st, err := trie.NewStateTrie(id, statedb.Database().TrieDB())
+		if err != nil {
+			return nil, err
+		}
+		hashes := make([][]byte, len(keys))
+		for i, key := range keys {
+			hashes[i] = crypto.Keccak256(key.Bytes())
+		}
+		if err := st.ProveMulti(hashes, proof); err != nil {
+			return nil, err
+		}
+	}
+	for i, key := range keys {
+		storage[i] = MultiProofStorage{
+			Key:   encodeProofKey(key, keyLengths[i]),
+			Value: (*hexutil.Big)(statedb.GetState(address, key).Big()),
+		}
+	}
+	nodes := make([]string, 0, proof.KeyCount())
+	for _, node := range proof.List() {
+		nodes = append(nodes, hexutil.Encode(node))
+	}
+	return &MultiProofResult{
+		Address:     address,
+		Balance:     (*hexutil.Big)(statedb.GetBalance(address).ToBig()),
+		CodeHash:    statedb.GetCodeHash(address),
+		Nonce:       hexutil.Uint64(statedb.GetNonce(address)),
+		StorageHash: storageRoot,
+		Storage:     storage,
+		Proof:       nodes,
+	}, statedb.Error()
+}
+
+// encodeProofKey encodes a storage key of a proof for the output. The encoding
+// is a bit special: if the input was a 32-byte hash, it is returned as such.
+// Otherwise, we apply the QUANTITY encoding mandated by the JSON-RPC spec for
+// getProof. This behavior exists to preserve backwards compatibility with older
+// client versions.
+func encodeProofKey(key common.Hash, inputLength int) string {
+	if inputLength != 32 {
+		return hexutil.EncodeBig(key.Big())
+	}
+	return hexutil.Encode(key[:])
+}
+
 // decodeHash parses a hex-encoded 32-byte hash. The input may optionally
 // be prefixed by 0x and can have a byte length up to 32.
 func decodeHash(s string) (h common.Hash, inputLength int, err error) {
@@ -1348,6 +1447,7 @@ type RPCTransaction struct {
 	R                   *hexutil.Big      `json:"r"`
 	S                   *hexutil.Big      `json:"s"`
 	YParity             *hexutil.Uint64   `json:"yParity,omitempty"`
+	Private             bool              `json:"private,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1521,7 +1621,7 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 	}
 	isPostMerge := header.Difficulty.Sign() == 0
 	// Retrieve the precompiles since they don't need to be added to the access list
-	precompiles := vm.ActivePrecompiles(b.ChainConfig().Rules(header.Number, isPostMerge, header.Time))
+	precompiles := vm.ChainPrecompiles(b.ChainConfig(), b.ChainConfig().Rules(header.Number, isPostMerge, header.Time))
 
 	// Create an initial tracer
 	prevTracer := logger.NewAccessListTracer(nil, args.from(), to, precompiles)
@@ -1762,6 +1862,12 @@ func (api *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*ty
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, b.SendTx)
+}
+
+// submitTransaction checks the transaction, submits it to the txPool with the
+// given method and logs a message.
+func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, send func(context.Context, *types.Transaction) error) (common.Hash, error) {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
@@ -1771,7 +1877,7 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
 		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
-	if err := b.SendTx(ctx, tx); err != nil {
+	if err := send(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
 	// Print a log with full tx details for manual investigations and interventions
@@ -1855,6 +1961,41 @@ func (api *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil
 	return SubmitTransaction(ctx, api.b, tx)
 }
 
+// SendPrivateRawTransaction will add the signed transaction to the transaction
+// pool without announcing it to the network. It is only included in blocks built
+// by this node, and dropped if not included within the pool's configured number
+// of blocks.
+func (api *TransactionAPI) SendPrivateRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, api.b, tx, api.b.SendPrivateTx)
+}
+
+// maxConditionalCost is the maximum number of storage lookups the inclusion
+// conditions of a transaction may require.
+const maxConditionalCost = 1000
+
+// SendRawTransactionConditional will add the signed transaction to the transaction
+// pool, along with conditions which must hold for its inclusion. The transaction
+// is rejected if the conditions don't hold for the next block, and evicted from
+// the pool once they are invalidated.
+func (api *TransactionAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := cond.Validate(); err != nil {
+		return common.Hash{}, err
+	}
+	if cost := cond.Cost(); cost > maxConditionalCost {
+		return common.Hash{}, fmt.Errorf("conditional too expensive: cost %d, limit %d", cost, maxConditionalCost)
+	}
+	tx.SetConditional(&cond)
+	return SubmitTransaction(ctx, api.b, tx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
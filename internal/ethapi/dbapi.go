@@ -17,8 +17,11 @@
 package ethapi
 
 import (
+	"errors"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 )
 
 // DbGet returns the raw value of a key stored in the database.
@@ -41,3 +44,14 @@ func (api *DebugAPI) DbAncient(kind string, number uint64) (hexutil.Bytes, error
 func (api *DebugAPI) DbAncients() (uint64, error) {
 	return api.b.ChainDb().Ancients()
 }
+
+// DbSizes returns the approximate size of each data category of the key-value
+// store, as tracked by the database if enabled. The sizes are persisted
+// periodically, so they might lag behind by a minute.
+func (api *DebugAPI) DbSizes() (*rawdb.DatabaseSizes, error) {
+	sizes := rawdb.ReadDatabaseSizes(api.b.ChainDb())
+	if sizes == nil {
+		return nil, errors.New("database sizes not tracked, enable with --db.sizes")
+	}
+	return sizes, nil
+}
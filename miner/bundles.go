@@ -0,0 +1,328 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/holiman/uint256"
+)
+
+// maxBundles is the maximum number of bundles tracked by the miner at once.
+const maxBundles = 1024
+
+var (
+	// errBundleEmpty is returned if a bundle without transactions is submitted.
+	errBundleEmpty = errors.New("bundle has no transactions")
+
+	// errBundleBlobTx is returned if a bundle contains a blob transaction, which
+	// are not supported as their sidecars are not tracked by the miner.
+	errBundleBlobTx = errors.New("blob transactions not supported in bundles")
+
+	// errBundleRange is returned if the target block range of a bundle is empty.
+	errBundleRange = errors.New("invalid bundle block range")
+
+	// errBundleExpired is returned if the target block range of a bundle has
+	// already passed.
+	errBundleExpired = errors.New("bundle expired")
+
+	// errBundlesFull is returned if the miner tracks too many bundles to accept
+	// a new one.
+	errBundlesFull = errors.New("too many bundles")
+
+	// errBundleReverted is returned if a bundle transaction reverts without being
+	// explicitly allowed to.
+	errBundleReverted = errors.New("bundle transaction reverted")
+
+	// errBundleUnprofitable is returned if a bundle pays the fee recipient less
+	// than its minimum profit.
+	errBundleUnprofitable = errors.New("bundle below minimum profit")
+)
+
+// Bundle is an ordered list of transactions to be included atomically, in the
+// given order, into a block within the target range.
+type Bundle struct {
+	Txs          types.Transactions // Transactions to include in order
+	MinBlock     uint64             // First block number the bundle may be included in
+	MaxBlock     uint64             // Last block number the bundle may be included in
+	RevertingTxs []common.Hash      // Transactions which are allowed to revert
+	MinProfit    *big.Int           // Minimum payment to the fee recipient, nil if any
+}
+
+// Hash returns the identifier of the bundle, the hash of its transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// canRevert returns whether the given bundle transaction is allowed to revert.
+func (b *Bundle) canRevert(hash common.Hash) bool {
+	return slices.Contains(b.RevertingTxs, hash)
+}
+
+// bundlePool is the set of bundles submitted to the miner, awaiting inclusion.
+type bundlePool struct {
+	bundles map[common.Hash]*Bundle
+	lock    sync.Mutex
+}
+
+// newBundlePool creates an empty bundle set.
+func newBundlePool() *bundlePool {
+	return &bundlePool{
+		bundles: make(map[common.Hash]*Bundle),
+	}
+}
+
+// add inserts a bundle into the set, or replaces it if already known. Bundles
+// which expired before the given head are dropped first.
+func (p *bundlePool) add(bundle *Bundle, head uint64) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.prune(head + 1)
+
+	hash := bundle.Hash()
+	if _, ok := p.bundles[hash]; !ok && len(p.bundles) >= maxBundles {
+		return errBundlesFull
+	}
+	p.bundles[hash] = bundle
+	return nil
+}
+
+// pending retrieves the bundles which may be included in a block of the given
+// number, dropping the ones which already expired.
+func (p *bundlePool) pending(number uint64) []*Bundle {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.prune(number)
+
+	var bundles []*Bundle
+	for _, bundle := range p.bundles {
+		if bundle.MinBlock <= number {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles
+}
+
+// remove drops a bundle from the set.
+func (p *bundlePool) remove(hash common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.bundles, hash)
+}
+
+// prune drops all the bundles which cannot be included in a block of the given
+// number or later.
+//
+// The method assumes the pool lock is held.
+func (p *bundlePool) prune(number uint64) {
+	for hash, bundle := range p.bundles {
+		if bundle.MaxBlock < number {
+			delete(p.bundles, hash)
+		}
+	}
+}
+
+// AddBundle submits a bundle for inclusion into the locally built blocks within
+// its target range.
+func (miner *Miner) AddBundle(bundle *Bundle) error {
+	if len(bundle.Txs) == 0 {
+		return errBundleEmpty
+	}
+	if bundle.MinBlock > bundle.MaxBlock {
+		return errBundleRange
+	}
+	head := miner.chain.CurrentHeader()
+	if bundle.MaxBlock <= head.Number.Uint64() {
+		return errBundleExpired
+	}
+	signer := types.LatestSigner(miner.chainConfig)
+	for _, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType {
+			return errBundleBlobTx
+		}
+		if _, err := types.Sender(signer, tx); err != nil {
+			return fmt.Errorf("transaction %x: %w", tx.Hash(), err)
+		}
+	}
+	return miner.bundles.add(bundle, head.Number.Uint64())
+}
+
+// simulatedBundle is a bundle which succeeded executing in isolation on top of
+// the parent state, along with its payment to the fee recipient per unit of gas.
+type simulatedBundle struct {
+	bundle *Bundle
+	price  *uint256.Int
+}
+
+// applyBundle executes the transactions of a bundle on top of the environment,
+// returning the payment made to the fee recipient, the gas used and a function
+// to undo the bundle. If any transaction fails or reverts without being allowed
+// to, or the payment is below the bundle's minimum profit, the environment is
+// rolled back and an error returned.
+//
+// On success, the caller must either undo the bundle or keep it by discarding
+// the state checkpoint.
+func (miner *Miner) applyBundle(env *environment, bundle *Bundle) (*uint256.Int, uint64, func(), error) {
+	// State snapshots do not survive across transactions as each of them is
+	// finalised, so revert to a checkpoint spanning the bundle instead
+	var (
+		gas     = env.gasPool.Gas()
+		gasUsed = env.header.GasUsed
+		tcount  = env.tcount
+		txs     = len(env.txs)
+		balance = env.state.GetBalance(env.coinbase).Clone()
+	)
+	env.state.Checkpoint()
+
+	revert := func() {
+		env.state.RevertToCheckpoint()
+		env.gasPool.SetGas(gas)
+		env.header.GasUsed = gasUsed
+		env.tcount = tcount
+		env.txs = env.txs[:txs]
+		env.receipts = env.receipts[:txs]
+	}
+	for _, tx := range bundle.Txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			revert()
+			return nil, 0, nil, fmt.Errorf("transaction %x: %w", tx.Hash(), err)
+		}
+		if env.receipts[len(env.receipts)-1].Status == types.ReceiptStatusFailed && !bundle.canRevert(tx.Hash()) {
+			revert()
+			return nil, 0, nil, fmt.Errorf("%w: %x", errBundleReverted, tx.Hash())
+		}
+	}
+	// Measure the payment as the balance change of the fee recipient, covering
+	// both the transaction fees and any direct transfers
+	payment := new(uint256.Int)
+	if after := env.state.GetBalance(env.coinbase); after.Gt(balance) {
+		payment.Sub(after, balance)
+	}
+	if bundle.MinProfit != nil && payment.ToBig().Cmp(bundle.MinProfit) < 0 {
+		revert()
+		return nil, 0, nil, fmt.Errorf("%w: have %v, want %v", errBundleUnprofitable, payment, bundle.MinProfit)
+	}
+	return payment, env.header.GasUsed - gasUsed, revert, nil
+}
+
+// simulateBundles executes the bundles targeting the block being built in
+// isolation on top of its environment, and queues the successful ones in the
+// order of their payment per gas, to be merged with the pool transactions.
+// Bundles whose transactions were already included by the chain are dropped.
+func (miner *Miner) simulateBundles(env *environment, interrupt *atomic.Int32) error {
+	// Checkpoints are flushed by the intermediate roots of the pre-Byzantium
+	// receipts, bundles are not supported there
+	if !miner.chainConfig.IsByzantium(env.header.Number) {
+		return nil
+	}
+	bundles := miner.bundles.pending(env.header.Number.Uint64())
+	if len(bundles) == 0 {
+		return nil
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, bundle := range bundles {
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				return signalToErr(signal)
+			}
+		}
+		if miner.bundleIncluded(env, bundle) {
+			log.Trace("Dropping included bundle", "hash", bundle.Hash())
+			miner.bundles.remove(bundle.Hash())
+			continue
+		}
+		payment, gasUsed, revert, err := miner.applyBundle(env, bundle)
+		if err != nil {
+			log.Trace("Bundle simulation failed", "hash", bundle.Hash(), "err", err)
+			continue
+		}
+		revert()
+		env.bundles = append(env.bundles, &simulatedBundle{
+			bundle: bundle,
+			price:  new(uint256.Int).Div(payment, uint256.NewInt(gasUsed)),
+		})
+	}
+	slices.SortStableFunc(env.bundles, func(a, b *simulatedBundle) int {
+		return b.price.Cmp(a.price)
+	})
+	return nil
+}
+
+// bundleIncluded reports whether any of the transactions of a bundle has its
+// nonce already used in the parent state, meaning the bundle was included or
+// can never be anymore.
+func (miner *Miner) bundleIncluded(env *environment, bundle *Bundle) bool {
+	for _, tx := range bundle.Txs {
+		from, _ := types.Sender(env.signer, tx) // already validated on submission
+		if env.state.GetNonce(from) > tx.Nonce() {
+			return true
+		}
+	}
+	return false
+}
+
+// commitBundle includes the next queued bundle into the block being built,
+// re-executing it as the transactions included before may have changed its
+// outcome. Bundles failing on top of the block are skipped.
+func (miner *Miner) commitBundle(env *environment) {
+	item := env.bundles[0]
+	env.bundles = env.bundles[1:]
+
+	if _, _, _, err := miner.applyBundle(env, item.bundle); err != nil {
+		log.Trace("Skipping conflicting bundle", "hash", item.bundle.Hash(), "err", err)
+		for _, tx := range item.bundle.Txs {
+			env.skip(tx.Hash(), fmt.Sprintf("bundle %x: %v", item.bundle.Hash(), err))
+		}
+		return
+	}
+	env.state.DiscardCheckpoint()
+	log.Debug("Included bundle", "hash", item.bundle.Hash(), "txs", len(item.bundle.Txs), "price", item.price)
+}
+
+// commitBundles includes all the remaining queued bundles into the block being
+// built, after the pool transactions paying more than them.
+func (miner *Miner) commitBundles(env *environment, interrupt *atomic.Int32) error {
+	for len(env.bundles) > 0 {
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				return signalToErr(signal)
+			}
+		}
+		miner.commitBundle(env)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that bundles are validated on submission, and included atomically among
+// the pool transactions by their payment if they execute successfully and are
+// profitable.
+func TestBundles(t *testing.T) {
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		recipient = common.HexToAddress("0xdeadbeef")
+		signer    = types.LatestSigner(params.TestChainConfig)
+	)
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), db, 0)
+
+	makeTx := func(nonce uint64, tip int64, data []byte) *types.Transaction {
+		tx := &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+			Gas:       100000,
+			Data:      data,
+		}
+		if data == nil {
+			tx.To = &testUserAddress
+			tx.Value = big.NewInt(1000)
+		}
+		return types.MustSignNewTx(testBankKey, signer, tx)
+	}
+	build := func() *types.Block {
+		t.Helper()
+
+		res := w.generateWork(&generateParams{
+			timestamp:  uint64(time.Now().Unix()),
+			parentHash: b.chain.CurrentBlock().Hash(),
+			coinbase:   recipient,
+		})
+		if res.err != nil {
+			t.Fatalf("failed to build block: %v", res.err)
+		}
+		return res.block
+	}
+	// Ensure invalid bundles are rejected on submission
+	var (
+		transfer = makeTx(0, 2*params.GWei, nil)
+		reverter = makeTx(1, 2*params.GWei, []byte{0x60, 0x00, 0x60, 0x00, 0xfd}) // PUSH1 0, PUSH1 0, REVERT
+		cheap    = makeTx(0, 1, nil)
+	)
+	for i, tt := range []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{bundle: &Bundle{MinBlock: 1, MaxBlock: 1}, err: errBundleEmpty},
+		{bundle: &Bundle{Txs: types.Transactions{transfer}, MinBlock: 2, MaxBlock: 1}, err: errBundleRange},
+		{bundle: &Bundle{Txs: types.Transactions{transfer}, MinBlock: 0, MaxBlock: 0}, err: errBundleExpired},
+	} {
+		if err := w.AddBundle(tt.bundle); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Unprofitable and unexpectedly reverting bundles are skipped
+	unprofitable := &Bundle{Txs: types.Transactions{transfer}, MinBlock: 1, MaxBlock: 1, MinProfit: big.NewInt(params.Ether)}
+	if err := w.AddBundle(unprofitable); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	reverting := &Bundle{Txs: types.Transactions{transfer, reverter}, MinBlock: 1, MaxBlock: 1}
+	if err := w.AddBundle(reverting); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	// Bundles paying less than the pool transactions are included after them,
+	// here conflicting with them
+	underpaying := &Bundle{Txs: types.Transactions{cheap}, MinBlock: 1, MaxBlock: 1}
+	if err := w.AddBundle(underpaying); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	if block := build(); len(block.Transactions()) != 1 || block.Transactions()[0].Hash() != pendingTxs[0].Hash() {
+		t.Fatalf("unexpected transactions included: have %d, want pool transaction only", len(block.Transactions()))
+	}
+	// Bundles with permitted reverts are included ahead of the pool transactions,
+	// which they conflict with
+	permitted := &Bundle{Txs: types.Transactions{transfer, reverter}, MinBlock: 1, MaxBlock: 1, RevertingTxs: []common.Hash{reverter.Hash()}}
+	if err := w.AddBundle(permitted); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	block := build()
+	if have := len(block.Transactions()); have != 2 {
+		t.Fatalf("included transaction count mismatch: have %d, want %d", have, 2)
+	}
+	for i, tx := range permitted.Txs {
+		if block.Transactions()[i].Hash() != tx.Hash() {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, block.Transactions()[i].Hash(), tx.Hash())
+		}
+	}
+	// Bundles are dropped once their target range passed
+	if pending := w.bundles.pending(2); len(pending) != 0 {
+		t.Errorf("expired bundles retained: %d", len(pending))
+	}
+	// Bundles are dropped once included by the chain
+	if _, err := b.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	included := &Bundle{Txs: types.Transactions{transfer}, MinBlock: 2, MaxBlock: 3}
+	if err := w.AddBundle(included); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	build()
+	if pending := w.bundles.pending(2); len(pending) != 0 {
+		t.Errorf("included bundles retained: %d", len(pending))
+	}
+}
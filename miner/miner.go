@@ -23,13 +23,16 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -48,6 +51,10 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+	Ordering            string         `toml:",omitempty"` // Transaction ordering strategy, "price" (default) or "fcfs"
+	SenderCap           int            `toml:",omitempty"` // Maximum number of transactions per sender in a block, 0 if unlimited
+	CustomOrdering      Ordering       `toml:"-"`          // Ordering strategy supplied by an embedding program, overriding the above
+	Reports             bool           `toml:",omitempty"` // Whether to keep the build reports of the recent payloads
 }
 
 // DefaultConfig contains default settings for miner.
@@ -72,11 +79,23 @@ type Miner struct {
 	txpool      *txpool.TxPool
 	chain       *core.BlockChain
 	pending     *pending
-	pendingMu   sync.Mutex // Lock protects the pending block
+	pendingMu   sync.Mutex  // Lock protects the pending block
+	bundles     *bundlePool // Bundles awaiting inclusion into the built blocks
+	ordering    Ordering    // Strategy to order the transactions included into blocks
+
+	reports *lru.Cache[engine.PayloadID, *PayloadReport] // Build reports of the recent payloads
 }
 
 // New creates a new miner with provided config.
 func New(eth Backend, config Config, engine consensus.Engine) *Miner {
+	ordering := config.CustomOrdering
+	if ordering == nil {
+		var err error
+		if ordering, err = MakeOrdering(config.Ordering, config.SenderCap); err != nil {
+			log.Warn("Invalid transaction ordering, using default", "err", err)
+			ordering, _ = MakeOrdering("", config.SenderCap)
+		}
+	}
 	return &Miner{
 		config:      &config,
 		chainConfig: eth.BlockChain().Config(),
@@ -84,6 +103,9 @@ func New(eth Backend, config Config, engine consensus.Engine) *Miner {
 		txpool:      eth.TxPool(),
 		chain:       eth.BlockChain(),
 		pending:     &pending{},
+		bundles:     newBundlePool(),
+		ordering:    ordering,
+		reports:     newReportCache(),
 	}
 }
 
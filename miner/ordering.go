@@ -18,6 +18,7 @@ package miner
 
 import (
 	"container/heap"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -26,17 +27,97 @@ import (
 	"github.com/holiman/uint256"
 )
 
-// txWithMinerFee wraps a transaction with its gas price or effective miner gasTipCap
-type txWithMinerFee struct {
-	tx   *txpool.LazyTransaction
-	from common.Address
-	fees *uint256.Int
+// Ordering is a strategy deciding the order in which the pending transactions
+// of different accounts are included into a block. The transactions of a single
+// account are always included in nonce order, and the transactions deprioritized
+// by the pool's admission policies are always included last, irrespective of the
+// strategy.
+type Ordering interface {
+	// Less reports whether transaction a should be included before b.
+	Less(a, b *OrderedTx) bool
+
+	// Admit reports whether a transaction may be included at all. If not, the
+	// remaining transactions of the same account are skipped too.
+	Admit(tx *OrderedTx) bool
+}
+
+// OrderedTx is a transaction considered for inclusion by an ordering strategy.
+type OrderedTx struct {
+	Tx       *txpool.LazyTransaction // Transaction considered for inclusion
+	From     common.Address          // Sender of the transaction
+	Tip      *uint256.Int            // Effective miner tip of the transaction
+	Included int                     // Number of transactions of the sender already included into the block
+}
+
+// PriceOrdering includes the transactions with the highest effective miner tip
+// first, breaking ties by arrival time. This is the default ordering.
+type PriceOrdering struct{}
+
+// Less implements Ordering, preferring the higher tip or the earlier arrival.
+func (PriceOrdering) Less(a, b *OrderedTx) bool {
+	// If the prices are equal, use the time the transaction was first seen for
+	// deterministic sorting
+	cmp := a.Tip.Cmp(b.Tip)
+	if cmp == 0 {
+		return a.Tx.Time.Before(b.Tx.Time)
+	}
+	return cmp > 0
+}
+
+// Admit implements Ordering, admitting all transactions.
+func (PriceOrdering) Admit(tx *OrderedTx) bool { return true }
+
+// ArrivalOrdering includes the transactions first-come-first-served, in order of
+// their arrival time, breaking ties by the effective miner tip.
+type ArrivalOrdering struct{}
+
+// Less implements Ordering, preferring the earlier arrival or the higher tip.
+func (ArrivalOrdering) Less(a, b *OrderedTx) bool {
+	if a.Tx.Time.Equal(b.Tx.Time) {
+		return a.Tip.Gt(b.Tip)
+	}
+	return a.Tx.Time.Before(b.Tx.Time)
+}
+
+// Admit implements Ordering, admitting all transactions.
+func (ArrivalOrdering) Admit(tx *OrderedTx) bool { return true }
+
+// SenderCapOrdering wraps an ordering strategy, limiting the number of transactions
+// included from a single sender into a block to share the block space fairly. The
+// cap spans the local and remote, plain and blob transactions alike, and only the
+// included transactions count towards it.
+type SenderCapOrdering struct {
+	Ordering     // Strategy to order the admitted transactions with
+	Cap      int // Maximum number of transactions included per sender
+}
+
+// Admit implements Ordering, admitting the transactions within the sender cap.
+func (o SenderCapOrdering) Admit(tx *OrderedTx) bool {
+	return tx.Included < o.Cap && o.Ordering.Admit(tx)
 }
 
-// newTxWithMinerFee creates a wrapped transaction, calculating the effective
-// miner gasTipCap if a base fee is provided.
+// MakeOrdering creates the built-in ordering strategy with the given name, and
+// limits it to the given number of transactions per sender, if non-zero.
+func MakeOrdering(name string, senderCap int) (Ordering, error) {
+	var ordering Ordering
+	switch name {
+	case "", "price":
+		ordering = PriceOrdering{}
+	case "fcfs":
+		ordering = ArrivalOrdering{}
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering %q", name)
+	}
+	if senderCap > 0 {
+		ordering = SenderCapOrdering{Ordering: ordering, Cap: senderCap}
+	}
+	return ordering, nil
+}
+
+// newOrderedTx creates a wrapped transaction, calculating the effective miner
+// gasTipCap if a base fee is provided.
 // Returns error in case of a negative effective miner gasTipCap.
-func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee *uint256.Int) (*txWithMinerFee, error) {
+func newOrderedTx(tx *txpool.LazyTransaction, from common.Address, included int, baseFee *uint256.Int) (*OrderedTx, error) {
 	tip := new(uint256.Int).Set(tx.GasTipCap)
 	if baseFee != nil {
 		if tx.GasFeeCap.Cmp(baseFee) < 0 {
@@ -47,120 +128,159 @@ func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee
 			tip = tx.GasTipCap
 		}
 	}
-	return &txWithMinerFee{
-		tx:   tx,
-		from: from,
-		fees: tip,
+	return &OrderedTx{
+		Tx:       tx,
+		From:     from,
+		Tip:      tip,
+		Included: included,
 	}, nil
 }
 
-// txByPriceAndTime implements both the sort and the heap interface, making it useful
-// for all at once sorting as well as individually adding and removing elements.
-type txByPriceAndTime []*txWithMinerFee
-
-func (s txByPriceAndTime) Len() int { return len(s) }
-func (s txByPriceAndTime) Less(i, j int) bool {
-	// If the prices are equal, use the time the transaction was first seen for
-	// deterministic sorting
-	cmp := s[i].fees.Cmp(s[j].fees)
-	if cmp == 0 {
-		return s[i].tx.Time.Before(s[j].tx.Time)
+// includeBefore reports whether transaction a should be included before b. The
+// transactions deprioritized by the pool go last, the rest in strategy order.
+func includeBefore(ordering Ordering, a, b *OrderedTx) bool {
+	if a.Tx.Deprioritized != b.Tx.Deprioritized {
+		return b.Tx.Deprioritized
 	}
-	return cmp > 0
+	return ordering.Less(a, b)
 }
-func (s txByPriceAndTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-func (s *txByPriceAndTime) Push(x interface{}) {
-	*s = append(*s, x.(*txWithMinerFee))
+// txHeap implements the heap interface over the next transactions of accounts,
+// sorting them by the given ordering strategy, after the deprioritized ones.
+type txHeap struct {
+	list     []*OrderedTx
+	ordering Ordering
 }
 
-func (s *txByPriceAndTime) Pop() interface{} {
-	old := *s
+func (h *txHeap) Len() int      { return len(h.list) }
+func (h *txHeap) Swap(i, j int) { h.list[i], h.list[j] = h.list[j], h.list[i] }
+
+func (h *txHeap) Less(i, j int) bool {
+	return includeBefore(h.ordering, h.list[i], h.list[j])
+}
+
+func (h *txHeap) Push(x interface{}) {
+	h.list = append(h.list, x.(*OrderedTx))
+}
+
+func (h *txHeap) Pop() interface{} {
+	old := h.list
 	n := len(old)
 	x := old[n-1]
 	old[n-1] = nil
-	*s = old[0 : n-1]
+	h.list = old[0 : n-1]
 	return x
 }
 
-// transactionsByPriceAndNonce represents a set of transactions that can return
-// transactions in a profit-maximizing sorted order, while supporting removing
+// orderedTransactions represents a set of transactions that can return
+// transactions in the order defined by a strategy, while supporting removing
 // entire batches of transactions for non-executable accounts.
-type transactionsByPriceAndNonce struct {
-	txs     map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
-	heads   txByPriceAndTime                             // Next transaction for each unique account (price heap)
-	signer  types.Signer                                 // Signer for the set of transactions
-	baseFee *uint256.Int                                 // Current base fee
+type orderedTransactions struct {
+	txs      map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
+	heads    *txHeap                                      // Next transaction for each unique account
+	signer   types.Signer                                 // Signer for the set of transactions
+	baseFee  *uint256.Int                                 // Current base fee
+	included map[common.Address]int                       // Number of transactions included per account, shared across sets
 }
 
-// newTransactionsByPriceAndNonce creates a transaction set that can retrieve
-// price sorted transactions in a nonce-honouring way.
+// newOrderedTransactions creates a transaction set that can retrieve transactions
+// sorted by the given ordering strategy in a nonce-honouring way.
+//
+// The included map tracks the number of transactions included per account, to be
+// updated by the caller upon including a transaction, before shifting the set.
 //
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
-func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+func newOrderedTransactions(ordering Ordering, signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, included map[common.Address]int) *orderedTransactions {
 	// Convert the basefee from header format to uint256 format
 	var baseFeeUint *uint256.Int
 	if baseFee != nil {
 		baseFeeUint = uint256.MustFromBig(baseFee)
 	}
-	// Initialize a price and received time based heap with the head transactions
-	heads := make(txByPriceAndTime, 0, len(txs))
+	// Initialize a heap with the head transactions
+	heads := &txHeap{
+		list:     make([]*OrderedTx, 0, len(txs)),
+		ordering: ordering,
+	}
 	for from, accTxs := range txs {
-		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFeeUint)
-		if err != nil {
+		wrapped, err := newOrderedTx(accTxs[0], from, included[from], baseFeeUint)
+		if err != nil || !ordering.Admit(wrapped) {
 			delete(txs, from)
 			continue
 		}
-		heads = append(heads, wrapped)
+		heads.list = append(heads.list, wrapped)
 		txs[from] = accTxs[1:]
 	}
-	heap.Init(&heads)
+	heap.Init(heads)
 
 	// Assemble and return the transaction set
-	return &transactionsByPriceAndNonce{
-		txs:     txs,
-		heads:   heads,
-		signer:  signer,
-		baseFee: baseFeeUint,
+	return &orderedTransactions{
+		txs:      txs,
+		heads:    heads,
+		signer:   signer,
+		baseFee:  baseFeeUint,
+		included: included,
 	}
 }
 
-// Peek returns the next transaction by price.
-func (t *transactionsByPriceAndNonce) Peek() (*txpool.LazyTransaction, *uint256.Int) {
-	if len(t.heads) == 0 {
+// Peek returns the next transaction in order, along with its effective tip.
+func (t *orderedTransactions) Peek() (*txpool.LazyTransaction, *uint256.Int) {
+	if t.heads.Len() == 0 {
 		return nil, nil
 	}
-	return t.heads[0].tx, t.heads[0].fees
+	return t.heads.list[0].Tx, t.heads.list[0].Tip
+}
+
+// head returns the next transaction in order, or nil if the set is empty.
+func (t *orderedTransactions) head() *OrderedTx {
+	if t.heads.Len() == 0 {
+		return nil
+	}
+	return t.heads.list[0]
 }
 
 // Shift replaces the current best head with the next one from the same account.
-func (t *transactionsByPriceAndNonce) Shift() {
-	acc := t.heads[0].from
-	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
-			t.heads[0], t.txs[acc] = wrapped, txs[1:]
-			heap.Fix(&t.heads, 0)
-			return
+// If the next one is not admitted, the remaining transactions of the account are
+// discarded and returned.
+func (t *orderedTransactions) Shift() []*txpool.LazyTransaction {
+	head := t.heads.list[0]
+	if txs, ok := t.txs[head.From]; ok && len(txs) > 0 {
+		wrapped, err := newOrderedTx(txs[0], head.From, t.included[head.From], t.baseFee)
+		if err == nil && t.heads.ordering.Admit(wrapped) {
+			t.heads.list[0], t.txs[head.From] = wrapped, txs[1:]
+			heap.Fix(t.heads, 0)
+			return nil
 		}
 	}
-	heap.Pop(&t.heads)
+	return t.Pop()
 }
 
 // Pop removes the best transaction, *not* replacing it with the next one from
 // the same account. This should be used when a transaction cannot be executed
-// and hence all subsequent ones should be discarded from the same account.
-func (t *transactionsByPriceAndNonce) Pop() {
-	heap.Pop(&t.heads)
+// and hence all subsequent ones should be discarded from the same account. The
+// discarded subsequent transactions are returned.
+func (t *orderedTransactions) Pop() []*txpool.LazyTransaction {
+	head := heap.Pop(t.heads).(*OrderedTx)
+
+	txs := t.txs[head.From]
+	delete(t.txs, head.From)
+	return txs
 }
 
-// Empty returns if the price heap is empty. It can be used to check it simpler
-// than calling peek and checking for nil return.
-func (t *transactionsByPriceAndNonce) Empty() bool {
-	return len(t.heads) == 0
+// Empty returns if the heap is empty. It can be used to check it simpler than
+// calling peek and checking for nil return.
+func (t *orderedTransactions) Empty() bool {
+	return t.heads.Len() == 0
 }
 
-// Clear removes the entire content of the heap.
-func (t *transactionsByPriceAndNonce) Clear() {
-	t.heads, t.txs = nil, nil
+// Clear removes the entire content of the heap, returning the transactions
+// discarded.
+func (t *orderedTransactions) Clear() []*txpool.LazyTransaction {
+	var txs []*txpool.LazyTransaction
+	for _, head := range t.heads.list {
+		txs = append(txs, head.Tx)
+		txs = append(txs, t.txs[head.From]...)
+	}
+	t.heads.list, t.txs = nil, nil
+	return txs
 }
@@ -102,7 +102,7 @@ func testTransactionPriceNonceSort(t *testing.T, baseFee *big.Int) {
 		expectedCount += count
 	}
 	// Sort the transactions and cross check the nonce ordering
-	txset := newTransactionsByPriceAndNonce(signer, groups, baseFee)
+	txset := newOrderedTransactions(PriceOrdering{}, signer, groups, baseFee, nil)
 
 	txs := types.Transactions{}
 	for tx, _ := txset.Peek(); tx != nil; tx, _ = txset.Peek() {
@@ -168,7 +168,7 @@ func TestTransactionTimeSort(t *testing.T) {
 		})
 	}
 	// Sort the transactions and cross check the nonce ordering
-	txset := newTransactionsByPriceAndNonce(signer, groups, nil)
+	txset := newOrderedTransactions(PriceOrdering{}, signer, groups, nil, nil)
 
 	txs := types.Transactions{}
 	for tx, _ := txset.Peek(); tx != nil; tx, _ = txset.Peek() {
@@ -194,3 +194,122 @@ func TestTransactionTimeSort(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the first-come-first-served ordering includes transactions in their
+// arrival order irrespective of their price, honouring the nonces of accounts,
+// and that the sender cap limits the transactions included per account, without
+// counting the skipped ones.
+func TestTransactionArrivalSort(t *testing.T) {
+	t.Parallel()
+	// Generate a batch of accounts to start with
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := types.HomesteadSigner{}
+
+	// Generate a few transactions per account, with prices increasing over the
+	// arrival time and the later accounts arriving first
+	makeGroups := func() map[common.Address][]*txpool.LazyTransaction {
+		groups := map[common.Address][]*txpool.LazyTransaction{}
+		for i, key := range keys {
+			addr := crypto.PubkeyToAddress(key.PublicKey)
+			for nonce := 0; nonce < 3; nonce++ {
+				arrival := (len(keys)-i)*10 + nonce
+
+				tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(100), 100, big.NewInt(int64(arrival)), nil), signer, key)
+				tx.SetTime(time.Unix(0, int64(arrival)))
+
+				groups[addr] = append(groups[addr], &txpool.LazyTransaction{
+					Hash:      tx.Hash(),
+					Tx:        tx,
+					Time:      tx.Time(),
+					GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+					GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+					Gas:       tx.Gas(),
+					BlobGas:   tx.BlobGas(),
+				})
+			}
+		}
+		return groups
+	}
+	for _, tt := range []struct {
+		ordering  Ordering
+		skipFirst bool // Whether the first transaction of each account is skipped
+		perAcc    int
+	}{
+		{ordering: ArrivalOrdering{}, perAcc: 3},
+		{ordering: SenderCapOrdering{Ordering: ArrivalOrdering{}, Cap: 2}, perAcc: 2},
+		{ordering: SenderCapOrdering{Ordering: ArrivalOrdering{}, Cap: 2}, skipFirst: true, perAcc: 3},
+	} {
+		var (
+			included = make(map[common.Address]int)
+			txset    = newOrderedTransactions(tt.ordering, signer, makeGroups(), nil, included)
+			txs      = types.Transactions{}
+		)
+		for head := txset.head(); head != nil; head = txset.head() {
+			txs = append(txs, head.Tx.Tx)
+			if !tt.skipFirst || head.Tx.Tx.Nonce() > 0 {
+				included[head.From]++
+			}
+			txset.Shift()
+		}
+		if len(txs) != len(keys)*tt.perAcc {
+			t.Errorf("expected %d transactions, found %d", len(keys)*tt.perAcc, len(txs))
+		}
+		for i := 1; i < len(txs); i++ {
+			if txs[i-1].Time().After(txs[i].Time()) {
+				t.Errorf("invalid received time ordering: tx #%d (T=%v) > tx #%d (T=%v)", i-1, txs[i-1].Time(), i, txs[i].Time())
+			}
+		}
+	}
+}
+
+// Tests that the deprioritized transactions are ordered last, irrespective of
+// the ordering strategy.
+func TestDeprioritizedSort(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := types.HomesteadSigner{}
+
+	for _, ordering := range []Ordering{PriceOrdering{}, ArrivalOrdering{}} {
+		// Deprioritize the best transaction by both price and arrival
+		groups := map[common.Address][]*txpool.LazyTransaction{}
+		for i, key := range keys {
+			tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 100, big.NewInt(int64(len(keys)-i)), nil), signer, key)
+			tx.SetTime(time.Unix(0, int64(i)))
+
+			groups[crypto.PubkeyToAddress(key.PublicKey)] = []*txpool.LazyTransaction{{
+				Hash:          tx.Hash(),
+				Tx:            tx,
+				Time:          tx.Time(),
+				GasFeeCap:     uint256.MustFromBig(tx.GasFeeCap()),
+				GasTipCap:     uint256.MustFromBig(tx.GasTipCap()),
+				Gas:           tx.Gas(),
+				Deprioritized: i == 0,
+			}}
+		}
+		txset := newOrderedTransactions(ordering, signer, groups, nil, nil)
+
+		var last *types.Transaction
+		for tx, _ := txset.Peek(); tx != nil; tx, _ = txset.Peek() {
+			last = tx.Tx
+			txset.Shift()
+		}
+		if from, _ := types.Sender(signer, last); from != crypto.PubkeyToAddress(keys[0].PublicKey) {
+			t.Errorf("%T: deprioritized transaction not ordered last", ordering)
+		}
+		// Heads of different sets must be compared the same way
+		var (
+			best  = &OrderedTx{Tx: &txpool.LazyTransaction{Time: time.Unix(0, 0), Deprioritized: true}, Tip: uint256.NewInt(2)}
+			worse = &OrderedTx{Tx: &txpool.LazyTransaction{Time: time.Unix(0, 1)}, Tip: uint256.NewInt(1)}
+		)
+		if includeBefore(ordering, best, worse) || !includeBefore(ordering, worse, best) {
+			t.Errorf("%T: deprioritized head not ordered last", ordering)
+		}
+	}
+}
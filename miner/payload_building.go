@@ -72,17 +72,19 @@ type Payload struct {
 	full     *types.Block
 	sidecars []*types.BlobTxSidecar
 	fullFees *big.Int
+	report   *PayloadReport
 	stop     chan struct{}
 	lock     sync.Mutex
 	cond     *sync.Cond
 }
 
 // newPayload initializes the payload object.
-func newPayload(empty *types.Block, id engine.PayloadID) *Payload {
+func newPayload(empty *types.Block, id engine.PayloadID, report *PayloadReport) *Payload {
 	payload := &Payload{
-		id:    id,
-		empty: empty,
-		stop:  make(chan struct{}),
+		id:     id,
+		empty:  empty,
+		report: report,
+		stop:   make(chan struct{}),
 	}
 	log.Info("Starting work on payload", "id", payload.id)
 	payload.cond = sync.NewCond(&payload.lock)
@@ -99,6 +101,9 @@ func (payload *Payload) update(r *newPayloadResult, elapsed time.Duration) {
 		return // reject stale update
 	default:
 	}
+	if r.iteration != nil && payload.report != nil {
+		payload.report.add(r.iteration)
+	}
 	// Ensure the newly provided full block has a higher transaction fee.
 	// In post-merge stage, there is no uncle reward anymore and transaction
 	// fee(apart from the mev revenue) is the only indicator for comparison.
@@ -189,14 +194,22 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 		withdrawals: args.Withdrawals,
 		beaconRoot:  args.BeaconRoot,
 		noTxs:       true,
+		report:      miner.config.Reports,
 	}
 	empty := miner.generateWork(emptyParams)
 	if empty.err != nil {
 		return nil, empty.err
 	}
 
-	// Construct a payload object for return.
-	payload := newPayload(empty.block, args.Id())
+	// Construct a payload object for return, tracking the report of its build
+	// iterations for introspection if enabled, starting with the empty one.
+	var report *PayloadReport
+	if emptyParams.report {
+		report = newPayloadReport(args.Id(), args)
+		report.add(empty.iteration)
+		miner.reports.Add(report.ID, report)
+	}
+	payload := newPayload(empty.block, args.Id(), report)
 
 	// Spin up a routine for updating the payload in background. This strategy
 	// can maximum the revenue for including transactions with highest fee.
@@ -220,6 +233,7 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 			withdrawals: args.Withdrawals,
 			beaconRoot:  args.BeaconRoot,
 			noTxs:       false,
+			report:      emptyParams.report,
 		}
 
 		for {
@@ -231,6 +245,9 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 					payload.update(r, time.Since(start))
 				} else {
 					log.Info("Error while generating work", "id", payload.id, "err", r.err)
+					if report != nil {
+						report.add(&PayloadIteration{Start: start, Error: r.err.Error()})
+					}
 				}
 				timer.Reset(miner.config.Recommit)
 			case <-payload.stop:
@@ -17,8 +17,10 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -37,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 )
 
 var (
@@ -273,3 +277,215 @@ func TestPayloadId(t *testing.T) {
 		ids[id] = i
 	}
 }
+
+// Tests that the build iterations of payloads are reported, along with the
+// transactions left out of them.
+func TestPayloadReport(t *testing.T) {
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		recipient = common.HexToAddress("0xdeadbeef")
+	)
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), db, 0)
+	w.config.Reports = true
+
+	// Submit a bundle conflicting with the pending pool transaction
+	tx := types.MustSignNewTx(testBankKey, types.LatestSigner(params.TestChainConfig), &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(2 * params.GWei),
+		GasFeeCap: big.NewInt(10 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &testUserAddress,
+	})
+	if err := w.AddBundle(&Bundle{Txs: types.Transactions{tx}, MinBlock: 1, MaxBlock: 1}); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	args := &BuildPayloadArgs{
+		Parent:       b.chain.CurrentBlock().Hash(),
+		Timestamp:    uint64(time.Now().Unix()),
+		FeeRecipient: recipient,
+	}
+	if _, err := w.PayloadReport(args.Id()); err != errUnknownPayloadReport {
+		t.Fatalf("unknown payload error mismatch: have %v, want %v", err, errUnknownPayloadReport)
+	}
+	payload, err := w.buildPayload(args)
+	if err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+	payload.ResolveFull()
+
+	report, err := w.PayloadReport(args.Id())
+	if err != nil {
+		t.Fatalf("failed to retrieve payload report: %v", err)
+	}
+	if len(report.Iterations) < 2 {
+		t.Fatalf("build iterations missing: have %d, want at least 2", len(report.Iterations))
+	}
+	if empty := report.Iterations[0]; len(empty.Included) != 0 || len(empty.Skipped) != 0 {
+		t.Errorf("empty iteration mismatch: have %d included, %d skipped", len(empty.Included), len(empty.Skipped))
+	}
+	if report.Best != 1 || !report.Iterations[1].Improved {
+		t.Errorf("best iteration mismatch: have %d, want %d", report.Best, 1)
+	}
+	iter := report.Iterations[1]
+	if report.Value.ToInt().Cmp(iter.Fees.ToInt()) != 0 || iter.Fees.ToInt().Sign() <= 0 {
+		t.Errorf("payload value mismatch: have %v, want %v", report.Value, iter.Fees)
+	}
+	if len(iter.Included) != 1 || iter.Included[0] != tx.Hash() {
+		t.Errorf("included transactions mismatch: have %v, want %v", iter.Included, []common.Hash{tx.Hash()})
+	}
+	if len(iter.Skipped) != 1 || iter.Skipped[0].Hash != pendingTxs[0].Hash() || !strings.Contains(iter.Skipped[0].Reason, "nonce too low") {
+		t.Errorf("skipped transactions mismatch: have %v, want %x with nonce too low", iter.Skipped, pendingTxs[0].Hash())
+	}
+}
+
+// Tests that no build reports are kept unless enabled.
+func TestPayloadReportDisabled(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), db, 0)
+
+	args := &BuildPayloadArgs{
+		Parent:       b.chain.CurrentBlock().Hash(),
+		Timestamp:    uint64(time.Now().Unix()),
+		FeeRecipient: common.HexToAddress("0xdeadbeef"),
+	}
+	payload, err := w.buildPayload(args)
+	if err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+	if full := payload.ResolveFull(); len(full.ExecutionPayload.Transactions) == 0 {
+		t.Fatalf("payload transactions missing")
+	}
+	if _, err := w.PayloadReport(args.Id()); err != errUnknownPayloadReport {
+		t.Fatalf("disabled report error mismatch: have %v, want %v", err, errUnknownPayloadReport)
+	}
+}
+
+// Tests that the skipped transactions recorded in a build iteration are capped,
+// with the ones beyond only counted.
+func TestSkippedReportCap(t *testing.T) {
+	env := &environment{report: true}
+	for i := 0; i < maxSkippedReports+10; i++ {
+		env.skip(common.Hash{byte(i), byte(i >> 8)}, skipGasLimit)
+	}
+	if len(env.skipped) != maxSkippedReports || env.omitted != 10 {
+		t.Errorf("skipped transactions mismatch: have %d recorded, %d omitted, want %d, %d", len(env.skipped), env.omitted, maxSkippedReports, 10)
+	}
+	env = &environment{}
+	env.skip(common.Hash{}, skipGasLimit)
+	if len(env.skipped) != 0 || env.omitted != 0 {
+		t.Errorf("unreported skipped transactions recorded: %d, %d omitted", len(env.skipped), env.omitted)
+	}
+}
+
+// Tests that all the transactions of a sender dropped from a build iteration are
+// reported as skipped, not only the failing one.
+func TestPayloadReportDroppedSender(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), db, 0)
+
+	env, err := w.prepareWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: b.chain.CurrentBlock().Hash(),
+		coinbase:   testBankAddress,
+	})
+	if err != nil {
+		t.Fatalf("failed to prepare block: %v", err)
+	}
+	env.report = true
+
+	// Create a few transactions of a sender, the first not fitting into the block
+	var lazies []*txpool.LazyTransaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		gas := params.TxGas
+		if nonce == 0 {
+			gas = env.header.GasLimit + 1
+		}
+		tx := types.MustSignNewTx(testUserKey, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+			Gas:       gas,
+			To:        &testBankAddress,
+		})
+		lazies = append(lazies, &txpool.LazyTransaction{
+			Hash:      tx.Hash(),
+			Tx:        tx,
+			Time:      tx.Time(),
+			GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+			GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+			Gas:       tx.Gas(),
+		})
+	}
+	plainTxs := newOrderedTransactions(w.ordering, env.signer, map[common.Address][]*txpool.LazyTransaction{testUserAddress: lazies}, env.header.BaseFee, env.included)
+	blobTxs := newOrderedTransactions(w.ordering, env.signer, map[common.Address][]*txpool.LazyTransaction{}, env.header.BaseFee, env.included)
+
+	if err := w.commitTransactions(env, plainTxs, blobTxs, true, nil); err != nil {
+		t.Fatalf("failed to commit transactions: %v", err)
+	}
+	want := []SkippedTx{
+		{Hash: lazies[0].Hash, Reason: skipGasLimit},
+		{Hash: lazies[1].Hash, Reason: skipSender},
+		{Hash: lazies[2].Hash, Reason: skipSender},
+	}
+	if !reflect.DeepEqual(env.skipped, want) {
+		t.Errorf("skipped transactions mismatch: have %v, want %v", env.skipped, want)
+	}
+}
+
+// Tests that the deprioritized local transactions are only included after the
+// remote ones, even if they pay more.
+func TestDeprioritizedHeldBack(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), db, 0)
+
+	env, err := w.prepareWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: b.chain.CurrentBlock().Hash(),
+		coinbase:   testBankAddress,
+	})
+	if err != nil {
+		t.Fatalf("failed to prepare block: %v", err)
+	}
+	env.state.SetBalance(testUserAddress, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	lazy := func(key *ecdsa.PrivateKey, tip int64, deprioritized bool) *txpool.LazyTransaction {
+		tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+			Gas:       params.TxGas,
+			To:        &testBankAddress,
+		})
+		return &txpool.LazyTransaction{
+			Hash:          tx.Hash(),
+			Tx:            tx,
+			Time:          tx.Time(),
+			GasFeeCap:     uint256.MustFromBig(tx.GasFeeCap()),
+			GasTipCap:     uint256.MustFromBig(tx.GasTipCap()),
+			Gas:           tx.Gas(),
+			Deprioritized: deprioritized,
+		}
+	}
+	var (
+		local  = lazy(testBankKey, 2*params.GWei, true)
+		remote = lazy(testUserKey, params.GWei, false)
+	)
+	err = w.commitPending(env,
+		map[common.Address][]*txpool.LazyTransaction{testBankAddress: {local}}, nil,
+		map[common.Address][]*txpool.LazyTransaction{testUserAddress: {remote}}, nil,
+		nil)
+	if err != nil {
+		t.Fatalf("failed to commit transactions: %v", err)
+	}
+	if len(env.txs) != 2 || env.txs[0].Hash() != remote.Hash || env.txs[1].Hash() != local.Hash {
+		t.Fatalf("transaction order mismatch: have %v, want remote %x then local %x", env.txs, remote.Hash, local.Hash)
+	}
+}
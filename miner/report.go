@@ -0,0 +1,164 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/txpool"
+)
+
+const (
+	// maxPayloadReports is the number of the most recent payload reports retained.
+	maxPayloadReports = 16
+
+	// maxSkippedReports is the number of skipped transactions recorded in a single
+	// build iteration, the ones beyond it are only counted.
+	maxSkippedReports = 256
+)
+
+// errUnknownPayloadReport is returned if the build report of a payload which
+// was never built, was built too long ago or was built with the reports being
+// disabled, is requested.
+var errUnknownPayloadReport = errors.New("unknown payload")
+
+// Reasons of transactions being skipped during block building.
+const (
+	skipGasLimit  = "gas limit reached"
+	skipBlobLimit = "blob limit reached"
+	skipEvicted   = "evicted from pool"
+	skipReplay    = "replay protected"
+	skipOrdering  = "not admitted by ordering"
+	skipSender    = "earlier transaction of sender skipped"
+)
+
+// SkippedTx is a transaction considered for inclusion into a payload, but left
+// out, along with the reason why.
+type SkippedTx struct {
+	Hash   common.Hash `json:"hash"`
+	Reason string      `json:"reason"`
+}
+
+// PayloadIteration is the report of a single build iteration of a payload.
+type PayloadIteration struct {
+	Start    time.Time      `json:"start"`
+	Fees     *hexutil.Big   `json:"fees"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	Included []common.Hash  `json:"included"`
+	Skipped  []SkippedTx    `json:"skipped"`
+	Omitted  int            `json:"omitted,omitempty"` // Number of skipped transactions beyond the recorded ones
+	Prepare  time.Duration  `json:"prepareTime"`       // Time spent preparing the block environment
+	Fill     time.Duration  `json:"fillTime"`          // Time spent selecting and executing the transactions
+	Finalize time.Duration  `json:"finalizeTime"`      // Time spent assembling the block
+	Improved bool           `json:"improved"`          // Whether the iteration pays more than the earlier ones
+	Error    string         `json:"error,omitempty"`
+}
+
+// PayloadReport describes how a payload was built, iteration by iteration.
+type PayloadReport struct {
+	ID         engine.PayloadID    `json:"id"`
+	Parent     common.Hash         `json:"parent"`
+	Timestamp  hexutil.Uint64      `json:"timestamp"`
+	Value      *hexutil.Big        `json:"value"` // Fees of the best payload built
+	Best       int                 `json:"best"`  // Iteration of the best payload, -1 if none succeeded
+	Iterations []*PayloadIteration `json:"iterations"`
+
+	lock sync.Mutex
+}
+
+// newPayloadReport creates an empty report for the payload being built.
+func newPayloadReport(id engine.PayloadID, args *BuildPayloadArgs) *PayloadReport {
+	return &PayloadReport{
+		ID:        id,
+		Parent:    args.Parent,
+		Timestamp: hexutil.Uint64(args.Timestamp),
+		Value:     new(hexutil.Big),
+		Best:      -1,
+	}
+}
+
+// add appends a build iteration to the report, updating the best value if the
+// iteration improved upon the earlier ones.
+func (r *PayloadReport) add(iter *PayloadIteration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if iter.Error == "" && (r.Best < 0 || iter.Fees.ToInt().Cmp(r.Value.ToInt()) > 0) {
+		iter.Improved = true
+		r.Value, r.Best = iter.Fees, len(r.Iterations)
+	}
+	r.Iterations = append(r.Iterations, iter)
+}
+
+// copy returns a snapshot of the report which is safe to use concurrently with
+// the payload being built.
+func (r *PayloadReport) copy() *PayloadReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return &PayloadReport{
+		ID:         r.ID,
+		Parent:     r.Parent,
+		Timestamp:  r.Timestamp,
+		Value:      r.Value,
+		Best:       r.Best,
+		Iterations: slices.Clone(r.Iterations),
+	}
+}
+
+// skip records a transaction left out of the block being built, if the build
+// iteration is being reported on. Past maxSkippedReports, the transactions are
+// only counted to keep the memory of the report bounded.
+func (env *environment) skip(hash common.Hash, reason string) {
+	if !env.report {
+		return
+	}
+	if len(env.skipped) >= maxSkippedReports {
+		env.omitted++
+		return
+	}
+	env.skipped = append(env.skipped, SkippedTx{Hash: hash, Reason: reason})
+}
+
+// skipAll records a batch of transactions left out of the block being built, if
+// the build iteration is being reported on.
+func (env *environment) skipAll(txs []*txpool.LazyTransaction, reason string) {
+	for _, ltx := range txs {
+		env.skip(ltx.Hash, reason)
+	}
+}
+
+// newReportCache creates the cache of the build reports of the recent payloads.
+func newReportCache() *lru.Cache[engine.PayloadID, *PayloadReport] {
+	return lru.NewCache[engine.PayloadID, *PayloadReport](maxPayloadReports)
+}
+
+// PayloadReport retrieves the build report of a recently built payload.
+func (miner *Miner) PayloadReport(id engine.PayloadID) (*PayloadReport, error) {
+	report, ok := miner.reports.Get(id)
+	if !ok {
+		return nil, errUnknownPayloadReport
+	}
+	return report.copy(), nil
+}
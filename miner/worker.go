@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
@@ -56,6 +57,13 @@ type environment struct {
 	receipts []*types.Receipt
 	sidecars []*types.BlobTxSidecar
 	blobs    int
+
+	report  bool        // Whether to collect the skipped transactions
+	skipped []SkippedTx // Transactions left out of the block, with the reasons
+	omitted int         // Number of transactions left out beyond the recorded ones
+
+	bundles  []*simulatedBundle     // Bundles awaiting inclusion, by descending payment per gas
+	included map[common.Address]int // Number of pool transactions included per sender
 }
 
 const (
@@ -73,6 +81,8 @@ type newPayloadResult struct {
 	sidecars []*types.BlobTxSidecar // collected blobs of blob transactions
 	stateDB  *state.StateDB         // StateDB after executing the transactions
 	receipts []*types.Receipt       // Receipts collected during construction
+
+	iteration *PayloadIteration // Report of the build iteration, if requested
 }
 
 // generateParams wraps various settings for generating sealing task.
@@ -85,14 +95,19 @@ type generateParams struct {
 	withdrawals types.Withdrawals // List of withdrawals to include in block (shanghai field)
 	beaconRoot  *common.Hash      // The beacon root (cancun field).
 	noTxs       bool              // Flag whether an empty block without any transaction is expected
+	report      bool              // Flag whether a report of the build iteration is requested
 }
 
 // generateWork generates a sealing block based on the given parameters.
 func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
+	start := time.Now()
 	work, err := miner.prepareWork(params)
 	if err != nil {
 		return &newPayloadResult{err: err}
 	}
+	work.report = params.report
+
+	prepared := time.Now()
 	if !params.noTxs {
 		interrupt := new(atomic.Int32)
 		timer := time.AfterFunc(miner.config.Recommit, func() {
@@ -105,18 +120,37 @@ func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
 			log.Warn("Block building is interrupted", "allowance", common.PrettyDuration(miner.config.Recommit))
 		}
 	}
+	filled := time.Now()
 	body := types.Body{Transactions: work.txs, Withdrawals: params.withdrawals}
 	block, err := miner.engine.FinalizeAndAssemble(miner.chain, work.header, work.state, &body, work.receipts)
 	if err != nil {
 		return &newPayloadResult{err: err}
 	}
-	return &newPayloadResult{
+	result := &newPayloadResult{
 		block:    block,
 		fees:     totalFees(block, work.receipts),
 		sidecars: work.sidecars,
 		stateDB:  work.state,
 		receipts: work.receipts,
 	}
+	if params.report {
+		included := make([]common.Hash, len(block.Transactions()))
+		for i, tx := range block.Transactions() {
+			included[i] = tx.Hash()
+		}
+		result.iteration = &PayloadIteration{
+			Start:    start,
+			Fees:     (*hexutil.Big)(result.fees),
+			GasUsed:  hexutil.Uint64(block.GasUsed()),
+			Included: included,
+			Skipped:  work.skipped,
+			Omitted:  work.omitted,
+			Prepare:  prepared.Sub(start),
+			Fill:     filled.Sub(prepared),
+			Finalize: time.Since(filled),
+		}
+	}
+	return result
 }
 
 // prepareWork constructs the sealing task according to the given parameters,
@@ -216,6 +250,7 @@ func (miner *Miner) makeEnv(parent *types.Header, header *types.Header, coinbase
 		state:    state,
 		coinbase: coinbase,
 		header:   header,
+		included: make(map[common.Address]int),
 	}, nil
 }
 
@@ -272,7 +307,10 @@ func (miner *Miner) applyTransaction(env *environment, tx *types.Transaction) (*
 	return receipt, err
 }
 
-func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *transactionsByPriceAndNonce, interrupt *atomic.Int32) error {
+// commitTransactions fills the block with the given plain and blob transactions.
+// Unless deprioritized is set, it stops at the first transaction deprioritized
+// by the pool, leaving it and the ones after it in the sets.
+func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *orderedTransactions, deprioritized bool, interrupt *atomic.Int32) error {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
@@ -293,48 +331,74 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		// skip that list altogether
 		if !blobTxs.Empty() && env.blobs*params.BlobTxBlobGasPerBlob >= params.MaxBlobGasPerBlock {
 			log.Trace("Not enough blob space for further blob transactions")
-			blobTxs.Clear()
+			env.skipAll(blobTxs.Clear(), skipBlobLimit)
 			// Fall though to pick up any plain txs
 		}
 		// Retrieve the next transaction and abort if all done.
 		var (
-			ltx *txpool.LazyTransaction
-			txs *transactionsByPriceAndNonce
+			head *OrderedTx
+			txs  *orderedTransactions
 		)
-		pltx, ptip := plainTxs.Peek()
-		bltx, btip := blobTxs.Peek()
+		phead, bhead := plainTxs.head(), blobTxs.head()
 
 		switch {
-		case pltx == nil:
-			txs, ltx = blobTxs, bltx
-		case bltx == nil:
-			txs, ltx = plainTxs, pltx
+		case phead == nil:
+			txs, head = blobTxs, bhead
+		case bhead == nil:
+			txs, head = plainTxs, phead
 		default:
-			if ptip.Lt(btip) {
-				txs, ltx = blobTxs, bltx
+			if includeBefore(miner.ordering, bhead, phead) {
+				txs, head = blobTxs, bhead
 			} else {
-				txs, ltx = plainTxs, pltx
+				txs, head = plainTxs, phead
 			}
 		}
-		if ltx == nil {
+		if head == nil {
 			break
 		}
+		// Deprioritized transactions are ordered last in both sets, so only such
+		// ones are left. Hold them back if requested.
+		if head.Tx.Deprioritized && !deprioritized {
+			break
+		}
+		// Include the next bundle first if it pays more per gas than the best
+		// pool transaction
+		if len(env.bundles) > 0 && env.bundles[0].price.Gt(head.Tip) {
+			miner.commitBundle(env)
+			continue
+		}
+		ltx := head.Tx
+
+		// Transactions of the sender may have been included from the other sets
+		// since the head was queued, check its admission again.
+		if included := env.included[head.From]; head.Included != included {
+			head.Included = included
+			if !miner.ordering.Admit(head) {
+				log.Trace("Transaction not admitted by ordering", "hash", ltx.Hash, "sender", head.From)
+				env.skip(ltx.Hash, skipOrdering)
+				env.skipAll(txs.Pop(), skipSender)
+				continue
+			}
+		}
 		// If we don't have enough space for the next transaction, skip the account.
 		if env.gasPool.Gas() < ltx.Gas {
 			log.Trace("Not enough gas left for transaction", "hash", ltx.Hash, "left", env.gasPool.Gas(), "needed", ltx.Gas)
-			txs.Pop()
+			env.skip(ltx.Hash, skipGasLimit)
+			env.skipAll(txs.Pop(), skipSender)
 			continue
 		}
 		if left := uint64(params.MaxBlobGasPerBlock - env.blobs*params.BlobTxBlobGasPerBlob); left < ltx.BlobGas {
 			log.Trace("Not enough blob gas left for transaction", "hash", ltx.Hash, "left", left, "needed", ltx.BlobGas)
-			txs.Pop()
+			env.skip(ltx.Hash, skipBlobLimit)
+			env.skipAll(txs.Pop(), skipSender)
 			continue
 		}
 		// Transaction seems to fit, pull it up from the pool
 		tx := ltx.Resolve()
 		if tx == nil {
 			log.Trace("Ignoring evicted transaction", "hash", ltx.Hash)
-			txs.Pop()
+			env.skip(ltx.Hash, skipEvicted)
+			env.skipAll(txs.Pop(), skipSender)
 			continue
 		}
 		// Error may be ignored here. The error has already been checked
@@ -345,9 +409,19 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		// phase, start ignoring the sender until we do.
 		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
 			log.Trace("Ignoring replay protected transaction", "hash", ltx.Hash, "eip155", miner.chainConfig.EIP155Block)
-			txs.Pop()
+			env.skip(ltx.Hash, skipReplay)
+			env.skipAll(txs.Pop(), skipSender)
 			continue
 		}
+		// Check the inclusion conditions of the transaction, if there are any
+		if cond := tx.Conditional(); cond != nil {
+			if err := miner.checkConditional(env, cond); err != nil {
+				log.Trace("Skipping transaction with failed conditional", "hash", ltx.Hash, "err", err)
+				env.skip(ltx.Hash, err.Error())
+				env.skipAll(txs.Pop(), skipSender)
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.SetTxContext(tx.Hash(), env.tcount)
 
@@ -356,25 +430,40 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		case errors.Is(err, core.ErrNonceTooLow):
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "hash", ltx.Hash, "sender", from, "nonce", tx.Nonce())
-			txs.Shift()
+			env.skip(ltx.Hash, err.Error())
+			env.skipAll(txs.Shift(), skipOrdering)
 
 		case errors.Is(err, nil):
 			// Everything ok, collect the logs and shift in the next transaction from the same account
-			txs.Shift()
+			env.included[from]++
+			env.skipAll(txs.Shift(), skipOrdering)
 
 		default:
 			// Transaction is regarded as invalid, drop all consecutive transactions from
 			// the same sender because of `nonce-too-high` clause.
 			log.Debug("Transaction failed, account skipped", "hash", ltx.Hash, "err", err)
-			txs.Pop()
+			env.skip(ltx.Hash, err.Error())
+			env.skipAll(txs.Pop(), skipSender)
 		}
 	}
 	return nil
 }
 
+// checkConditional checks the inclusion conditions of a transaction against the
+// block being built, on top of the transactions already included.
+func (miner *Miner) checkConditional(env *environment, cond *types.TransactionConditional) error {
+	// Storage roots are only updated on demand, hash the pending changes first
+	for _, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			env.state.IntermediateRoot(miner.chainConfig.IsEIP158(env.header.Number))
+			break
+		}
+	}
+	return txpool.ValidateConditional(cond, env.header, env.state)
+}
+
 // fillTransactions retrieves the pending transactions from the txpool and fills them
-// into the given sealing block. The transaction selection and ordering strategy can
-// be customized with the plugin in the future.
+// into the given sealing block, in the order defined by the configured strategy.
 func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment) error {
 	miner.confMu.RLock()
 	tip := miner.config.GasPrice
@@ -410,20 +499,34 @@ func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment)
 			localBlobTxs[account] = txs
 		}
 	}
+	// Simulate the bundles targeting this block, to be merged with the pool
+	// transactions by their payment
+	if err := miner.simulateBundles(env, interrupt); err != nil {
+		return err
+	}
 	// Fill the block with all available pending transactions.
-	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, env.header.BaseFee)
+	if err := miner.commitPending(env, localPlainTxs, localBlobTxs, remotePlainTxs, remoteBlobTxs, interrupt); err != nil {
+		return err
+	}
+	// Include the bundles paying less than any pool transaction
+	return miner.commitBundles(env, interrupt)
+}
 
-		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
+// commitPending fills the block with the local transactions first, then with the
+// remote ones. The deprioritized transactions are held back until both sets are
+// drained.
+func (miner *Miner) commitPending(env *environment, localPlainTxs, localBlobTxs, remotePlainTxs, remoteBlobTxs map[common.Address][]*txpool.LazyTransaction, interrupt *atomic.Int32) error {
+	var (
+		localPlain  = newOrderedTransactions(miner.ordering, env.signer, localPlainTxs, env.header.BaseFee, env.included)
+		localBlob   = newOrderedTransactions(miner.ordering, env.signer, localBlobTxs, env.header.BaseFee, env.included)
+		remotePlain = newOrderedTransactions(miner.ordering, env.signer, remotePlainTxs, env.header.BaseFee, env.included)
+		remoteBlob  = newOrderedTransactions(miner.ordering, env.signer, remoteBlobTxs, env.header.BaseFee, env.included)
+	)
+	for _, deprioritized := range []bool{false, true} {
+		if err := miner.commitTransactions(env, localPlain, localBlob, deprioritized, interrupt); err != nil {
 			return err
 		}
-	}
-	if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, remotePlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, env.header.BaseFee)
-
-		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
+		if err := miner.commitTransactions(env, remotePlain, remoteBlob, deprioritized, interrupt); err != nil {
 			return err
 		}
 	}
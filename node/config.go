@@ -211,6 +211,10 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// DBTrackSizes enables maintaining the approximate size of each data
+	// category of the databases as the data is written.
+	DBTrackSizes bool `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
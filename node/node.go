@@ -724,12 +724,13 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 		db = rawdb.NewMemoryDatabase()
 	} else {
 		db, err = rawdb.Open(rawdb.OpenOptions{
-			Type:      n.config.DBEngine,
-			Directory: n.ResolvePath(name),
-			Namespace: namespace,
-			Cache:     cache,
-			Handles:   handles,
-			ReadOnly:  readonly,
+			Type:       n.config.DBEngine,
+			Directory:  n.ResolvePath(name),
+			Namespace:  namespace,
+			Cache:      cache,
+			Handles:    handles,
+			ReadOnly:   readonly,
+			TrackSizes: n.config.DBTrackSizes,
 		})
 	}
 
@@ -742,9 +743,10 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 // OpenDatabaseWithFreezer opens an existing database with the given name (or
 // creates one if no previous can be found) from within the node's data directory,
 // also attaching a chain freezer to it that moves ancient chain data from the
-// database to immutable append-only files. If the node is an ephemeral one, a
-// memory database is returned.
-func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient string, namespace string, readonly bool) (ethdb.Database, error) {
+// database to immutable append-only files. If the ancient directory holds era1
+// archives, they are verified against the given trusted accumulator roots. If
+// the node is an ephemeral one, a memory database is returned.
+func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient string, eraRoots []common.Hash, namespace string, readonly bool) (ethdb.Database, error) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 	if n.state == closedState {
@@ -759,10 +761,12 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 			Type:              n.config.DBEngine,
 			Directory:         n.ResolvePath(name),
 			AncientsDirectory: n.ResolveAncient(name, ancient),
+			EraRoots:          eraRoots,
 			Namespace:         namespace,
 			Cache:             cache,
 			Handles:           handles,
 			ReadOnly:          readonly,
+			TrackSizes:        n.config.DBTrackSizes,
 		})
 	}
 
@@ -803,6 +807,33 @@ func (db *closeTrackingDB) Close() error {
 	return db.Database.Close()
 }
 
+// Checkpoint forwards the checkpoint request to the wrapped database, if it's
+// supported by it.
+func (db *closeTrackingDB) Checkpoint(dir string) error {
+	if checkpointer, ok := db.Database.(ethdb.Checkpointer); ok {
+		return checkpointer.Checkpoint(dir)
+	}
+	return errors.New("database checkpoint not supported")
+}
+
+// ImmutableAncients forwards the ancient mutability query to the wrapped
+// database, if it's supported by it.
+func (db *closeTrackingDB) ImmutableAncients() bool {
+	if mutability, ok := db.Database.(ethdb.AncientMutability); ok {
+		return mutability.ImmutableAncients()
+	}
+	return false
+}
+
+// NewSnapshot forwards the snapshot request to the wrapped database, if it's
+// supported by it.
+func (db *closeTrackingDB) NewSnapshot() (ethdb.Snapshot, error) {
+	if snapshotter, ok := db.Database.(ethdb.Snapshotter); ok {
+		return snapshotter.NewSnapshot()
+	}
+	return nil, errors.New("database snapshot not supported")
+}
+
 // wrapDatabase ensures the database will be auto-closed when Node is closed.
 func (n *Node) wrapDatabase(db ethdb.Database) ethdb.Database {
 	wrapper := &closeTrackingDB{db, n}
@@ -156,6 +156,10 @@ compile_fuzzer github.com/ethereum/go-ethereum/tests/fuzzers/txfetcher \
   Fuzz fuzzTxfetcher \
   $repo/tests/fuzzers/txfetcher/txfetcher_test.go
 
+compile_fuzzer github.com/ethereum/go-ethereum/tests/fuzzers/statetest \
+  Fuzz fuzzStatetest \
+  $repo/tests/fuzzers/statetest/statetest_test.go
+
 compile_fuzzer github.com/ethereum/go-ethereum/tests/fuzzers/bls12381 \
   FuzzG1Add fuzz_g1_add\
   $repo/tests/fuzzers/bls12381/bls12381_test.go
@@ -352,8 +352,14 @@ type ChainConfig struct {
 	ShanghaiTime *uint64 `json:"shanghaiTime,omitempty"` // Shanghai switch time (nil = no fork, 0 = already on shanghai)
 	CancunTime   *uint64 `json:"cancunTime,omitempty"`   // Cancun switch time (nil = no fork, 0 = already on cancun)
 	PragueTime   *uint64 `json:"pragueTime,omitempty"`   // Prague switch time (nil = no fork, 0 = already on prague)
+	OsakaTime    *uint64 `json:"osakaTime,omitempty"`    // Osaka switch time (nil = no fork, 0 = already on osaka)
 	VerkleTime   *uint64 `json:"verkleTime,omitempty"`   // Verkle switch time (nil = no fork, 0 = already on verkle)
 
+	// P256VerifyTime activates the RIP-7212 secp256r1 signature verification
+	// precompile. It is not part of any Ethereum mainnet fork and is meant for
+	// private and development networks opting into it.
+	P256VerifyTime *uint64 `json:"p256VerifyTime,omitempty"` // P256VERIFY activation time (nil = not active, 0 = already active)
+
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
 	TerminalTotalDifficulty *big.Int `json:"terminalTotalDifficulty,omitempty"`
@@ -368,6 +374,26 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+
+	// PrecompileOverrides allows programs embedding go-ethereum to add, replace
+	// or remove precompiled contracts on their chain. It cannot be expressed in
+	// the JSON chain configuration and must be set programmatically, usually with
+	// a vm.PrecompileRegistry.
+	PrecompileOverrides PrecompileOverrides `json:"-"`
+}
+
+// PrecompileOverrides customizes the set of precompiled contracts of a chain on
+// top of the protocol defined ones. The contracts themselves are defined by the
+// vm package (see vm.PrecompileRegistry), which this package cannot import, so
+// they are surfaced untyped through this interface.
+type PrecompileOverrides interface {
+	// OverriddenPrecompiles returns the addresses whose precompiled contract is
+	// added, replaced or removed under the given rules.
+	OverriddenPrecompiles(rules Rules) []common.Address
+
+	// OverriddenPrecompile returns the vm.PrecompiledContract active at the given
+	// overridden address under the given rules, or nil if it is removed.
+	OverriddenPrecompile(rules Rules, addr common.Address) any
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -475,9 +501,18 @@ func (c *ChainConfig) Description() string {
 	if c.PragueTime != nil {
 		banner += fmt.Sprintf(" - Prague:                      @%-10v\n", *c.PragueTime)
 	}
+	if c.OsakaTime != nil {
+		banner += fmt.Sprintf(" - Osaka:                       @%-10v\n", *c.OsakaTime)
+	}
 	if c.VerkleTime != nil {
 		banner += fmt.Sprintf(" - Verkle:                      @%-10v\n", *c.VerkleTime)
 	}
+	// Add a special section for the optional, non-mainnet features
+	if c.P256VerifyTime != nil {
+		banner += "\n"
+		banner += "Additional features (timestamp based):\n"
+		banner += fmt.Sprintf(" - P256VERIFY (RIP-7212):       @%-10v (https://github.com/ethereum/RIPs/blob/master/RIPS/rip-7212.md)\n", *c.P256VerifyTime)
+	}
 	return banner
 }
 
@@ -576,11 +611,22 @@ func (c *ChainConfig) IsPrague(num *big.Int, time uint64) bool {
 	return c.IsLondon(num) && isTimestampForked(c.PragueTime, time)
 }
 
+// IsOsaka returns whether time is either equal to the Osaka fork time or greater.
+func (c *ChainConfig) IsOsaka(num *big.Int, time uint64) bool {
+	return c.IsLondon(num) && isTimestampForked(c.OsakaTime, time)
+}
+
 // IsVerkle returns whether time is either equal to the Verkle fork time or greater.
 func (c *ChainConfig) IsVerkle(num *big.Int, time uint64) bool {
 	return c.IsLondon(num) && isTimestampForked(c.VerkleTime, time)
 }
 
+// IsP256Verify returns whether time is either equal to the P256VERIFY activation
+// time or greater.
+func (c *ChainConfig) IsP256Verify(num *big.Int, time uint64) bool {
+	return isTimestampForked(c.P256VerifyTime, time)
+}
+
 // IsEIP4762 returns whether eip 4762 has been activated at given block.
 func (c *ChainConfig) IsEIP4762(num *big.Int, time uint64) bool {
 	return c.IsVerkle(num, time)
@@ -640,6 +686,7 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "shanghaiTime", timestamp: c.ShanghaiTime},
 		{name: "cancunTime", timestamp: c.CancunTime, optional: true},
 		{name: "pragueTime", timestamp: c.PragueTime, optional: true},
+		{name: "osakaTime", timestamp: c.OsakaTime, optional: true},
 		{name: "verkleTime", timestamp: c.VerkleTime, optional: true},
 	} {
 		if lastFork.name != "" {
@@ -744,9 +791,15 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.PragueTime, newcfg.PragueTime, headTimestamp) {
 		return newTimestampCompatError("Prague fork timestamp", c.PragueTime, newcfg.PragueTime)
 	}
+	if isForkTimestampIncompatible(c.OsakaTime, newcfg.OsakaTime, headTimestamp) {
+		return newTimestampCompatError("Osaka fork timestamp", c.OsakaTime, newcfg.OsakaTime)
+	}
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if isForkTimestampIncompatible(c.P256VerifyTime, newcfg.P256VerifyTime, headTimestamp) {
+		return newTimestampCompatError("P256VERIFY activation timestamp", c.P256VerifyTime, newcfg.P256VerifyTime)
+	}
 	return nil
 }
 
@@ -766,6 +819,8 @@ func (c *ChainConfig) LatestFork(time uint64) forks.Fork {
 	london := c.LondonBlock
 
 	switch {
+	case c.IsOsaka(london, time):
+		return forks.Osaka
 	case c.IsPrague(london, time):
 		return forks.Prague
 	case c.IsCancun(london, time):
@@ -917,8 +972,9 @@ type Rules struct {
 	IsEIP2929, IsEIP4762                                    bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon                                      bool
-	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
+	IsMerge, IsShanghai, IsCancun, IsPrague, IsOsaka        bool
 	IsVerkle                                                bool
+	IsP256Verify                                            bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -947,7 +1003,9 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsShanghai:       isMerge && c.IsShanghai(num, timestamp),
 		IsCancun:         isMerge && c.IsCancun(num, timestamp),
 		IsPrague:         isMerge && c.IsPrague(num, timestamp),
+		IsOsaka:          isMerge && c.IsOsaka(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+		IsP256Verify:     c.IsP256Verify(num, timestamp),
 	}
 }
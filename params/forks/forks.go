@@ -39,4 +39,6 @@ const (
 	Shanghai
 	Cancun
 	Prague
+	Osaka
+	Verkle
 )
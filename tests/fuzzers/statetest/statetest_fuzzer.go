@@ -0,0 +1,701 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package statetest implements a differential fuzzer executing random transactions
+// on random prestates through the state test plumbing, cross-checking the results
+// of several execution configurations.
+package statetest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// fixtureDirEnv is the environment variable naming the directory into which the
+// state tests reproducing failures are written.
+const fixtureDirEnv = "STATETEST_FUZZ_FIXTURES"
+
+var (
+	senderKey, _ = crypto.ToECDSA(crypto.Keccak256([]byte("statetest-fuzzer")))
+	sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+	coinbase     = common.HexToAddress("0xc014ba5e")
+
+	// contracts are the accounts holding the generated code in the prestate.
+	contracts = []common.Address{
+		common.HexToAddress("0xc0de01"),
+		common.HexToAddress("0xc0de02"),
+		common.HexToAddress("0xc0de03"),
+		common.HexToAddress("0xc0de04"),
+	}
+	// targets are the addresses the generated code interacts with.
+	targets = append([]common.Address{
+		sender,
+		coinbase,
+		common.HexToAddress("0x02"), // sha256
+		common.HexToAddress("0x04"), // identity
+		common.HexToAddress("0xdead"),
+	}, contracts...)
+)
+
+// fuzzFork is a fork the generated transactions are executed on.
+type fuzzFork struct {
+	name   string
+	london bool // Whether dynamic fee transactions and a base fee are available
+	merged bool // Whether the block has a random field instead of a difficulty
+	cancun bool // Whether the block has a blob base fee
+}
+
+var fuzzForks = []fuzzFork{
+	{name: "Byzantium"},
+	{name: "Istanbul"},
+	{name: "Berlin"},
+	{name: "London", london: true},
+	{name: "Merge", london: true, merged: true},
+	{name: "Shanghai", london: true, merged: true},
+	{name: "Cancun", london: true, merged: true, cancun: true},
+}
+
+type fuzzer struct {
+	input     io.Reader
+	exhausted bool
+}
+
+func (f *fuzzer) read(size int) []byte {
+	out := make([]byte, size)
+	if _, err := f.input.Read(out); err != nil {
+		f.exhausted = true
+	}
+	return out
+}
+
+func (f *fuzzer) readByte() byte {
+	return f.read(1)[0]
+}
+
+func (f *fuzzer) readBool() bool {
+	return f.readByte()&0x1 == 0
+}
+
+func (f *fuzzer) readUint64(min, max uint64) uint64 {
+	if min == max {
+		return min
+	}
+	var a uint64
+	if err := binary.Read(f.input, binary.LittleEndian, &a); err != nil {
+		f.exhausted = true
+	}
+	return min + a%(max-min)
+}
+
+func (f *fuzzer) readTarget() common.Address {
+	return targets[int(f.readByte())%len(targets)]
+}
+
+// fuzzCase is a generated prestate along with a transaction to execute on it.
+type fuzzCase struct {
+	fork fuzzFork
+	pre  types.GenesisAlloc
+
+	nonce      uint64
+	to         *common.Address
+	data       []byte
+	value      *big.Int
+	gasLimit   uint64
+	gasPrice   *big.Int // Gas price of legacy transactions, fee cap of dynamic fee ones
+	tip        *big.Int // Tip of dynamic fee transactions
+	accessList types.AccessList
+}
+
+const (
+	blockGasLimit = 30_000_000
+	blockNumber   = 1
+	blockTime     = 1000
+	baseFee       = 0x0a // The default base fee of state tests
+)
+
+func (f *fuzzer) generate() *fuzzCase {
+	c := &fuzzCase{
+		fork: fuzzForks[int(f.readByte())%len(fuzzForks)],
+		pre:  make(types.GenesisAlloc),
+	}
+	c.pre[sender] = types.Account{
+		Balance: new(big.Int).Lsh(big.NewInt(1), uint(f.readUint64(40, 80))),
+		Nonce:   f.readUint64(0, 2),
+	}
+	for _, addr := range contracts {
+		if !f.readBool() {
+			continue
+		}
+		account := types.Account{
+			Balance: new(big.Int).SetUint64(f.readUint64(0, 1<<20)),
+			Nonce:   f.readUint64(0, 2),
+			Code:    f.generateCode(),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+		for i := f.readUint64(0, 4); i > 0; i-- {
+			account.Storage[common.BytesToHash([]byte{f.readByte() % 8})] = common.BytesToHash(f.read(1 + int(f.readByte()%32)))
+		}
+		c.pre[addr] = account
+	}
+	// Most transactions should be valid, only sometimes mess with the nonce
+	c.nonce = c.pre[sender].Nonce
+	if f.readByte() < 0x10 {
+		c.nonce++
+	}
+	if f.readByte() < 0x20 {
+		c.data = f.generateCode() // Contract creation
+	} else {
+		to := contracts[int(f.readByte())%len(contracts)]
+		c.to = &to
+		c.data = f.read(int(f.readByte() % 64))
+	}
+	c.value = new(big.Int).SetUint64(f.readUint64(0, 1<<16))
+	c.gasLimit = f.readUint64(21000, 300_000)
+	if c.fork.london {
+		c.tip = new(big.Int).SetUint64(f.readUint64(0, 4))
+		c.gasPrice = new(big.Int).SetUint64(f.readUint64(baseFee, baseFee+8))
+	} else {
+		c.gasPrice = new(big.Int).SetUint64(f.readUint64(1, 16))
+	}
+	if c.fork.name != "Byzantium" && c.fork.name != "Istanbul" && f.readBool() {
+		addr := f.readTarget()
+		c.accessList = types.AccessList{{
+			Address:     addr,
+			StorageKeys: []common.Hash{common.BytesToHash([]byte{f.readByte() % 8})},
+		}}
+	}
+	return c
+}
+
+// generateCode emits a sequence of snippets interacting with the state, mixed
+// with raw opcodes.
+func (f *fuzzer) generateCode() []byte {
+	var (
+		code  []byte
+		push1 = func(v byte) { code = append(code, byte(vm.PUSH1), v) }
+		push  = func(addr common.Address) { code = append(append(code, byte(vm.PUSH20)), addr.Bytes()...) }
+	)
+	for i := f.readUint64(0, 32); i > 0 && !f.exhausted; i-- {
+		switch f.readByte() % 8 {
+		case 0, 1, 2:
+			// A raw opcode with random immediates
+			op := vm.OpCode(f.readByte())
+			code = append(code, byte(op))
+			if op.IsPush() {
+				code = append(code, f.read(int(op-vm.PUSH0))...)
+			}
+		case 3:
+			// Read a value from the environment
+			push(f.readTarget())
+			code = append(code, []byte{byte(vm.BALANCE), byte(vm.EXTCODESIZE), byte(vm.EXTCODEHASH)}[f.readByte()%3])
+		case 4:
+			// Call into an other account
+			push1(0)
+			push1(0)
+			push1(f.readByte() % 64)
+			push1(0)
+			op := []vm.OpCode{vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL}[f.readByte()%4]
+			if op == vm.CALL || op == vm.CALLCODE {
+				push1(f.readByte() % 4)
+			}
+			push(f.readTarget())
+			code = append(code, byte(vm.GAS), byte(op))
+		case 5:
+			// Access storage, persistent or transient
+			key := f.readByte() % 8
+			switch f.readByte() % 4 {
+			case 0:
+				push1(f.readByte())
+				push1(key)
+				code = append(code, byte(vm.SSTORE))
+			case 1:
+				push1(key)
+				code = append(code, byte(vm.SLOAD))
+			case 2:
+				push1(f.readByte())
+				push1(key)
+				code = append(code, byte(vm.TSTORE))
+			default:
+				push1(key)
+				code = append(code, byte(vm.TLOAD))
+			}
+		case 6:
+			// Emit a log or create a contract out of memory
+			if f.readBool() {
+				push1(f.readByte())
+				push1(f.readByte() % 64)
+				push1(0)
+				code = append(code, byte(vm.LOG1))
+			} else {
+				push1(f.readByte() % 32)
+				push1(0)
+				push1(f.readByte() % 4)
+				code = append(code, byte(vm.CREATE))
+			}
+		default:
+			// Store something into memory, or self-destruct
+			if f.readByte() < 0xf0 {
+				push1(f.readByte())
+				push1(f.readByte() % 64)
+				code = append(code, byte(vm.MSTORE))
+			} else {
+				push(f.readTarget())
+				code = append(code, byte(vm.SELFDESTRUCT))
+			}
+		}
+	}
+	return code
+}
+
+// stateTestFixture is the JSON representation of a general state test.
+type stateTestFixture struct {
+	Env  fixtureEnv               `json:"env"`
+	Pre  types.GenesisAlloc       `json:"pre"`
+	Tx   fixtureTx                `json:"transaction"`
+	Post map[string][]fixturePost `json:"post"`
+}
+
+type fixtureEnv struct {
+	Coinbase      common.UnprefixedAddress `json:"currentCoinbase"`
+	Difficulty    *math.HexOrDecimal256    `json:"currentDifficulty,omitempty"`
+	Random        *math.HexOrDecimal256    `json:"currentRandom,omitempty"`
+	GasLimit      math.HexOrDecimal64      `json:"currentGasLimit"`
+	Number        math.HexOrDecimal64      `json:"currentNumber"`
+	Timestamp     math.HexOrDecimal64      `json:"currentTimestamp"`
+	BaseFee       *math.HexOrDecimal256    `json:"currentBaseFee,omitempty"`
+	ExcessBlobGas *math.HexOrDecimal64     `json:"currentExcessBlobGas,omitempty"`
+}
+
+type fixtureTx struct {
+	GasPrice             *math.HexOrDecimal256 `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *math.HexOrDecimal256 `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *math.HexOrDecimal256 `json:"maxPriorityFeePerGas,omitempty"`
+	Nonce                math.HexOrDecimal64   `json:"nonce"`
+	To                   string                `json:"to"`
+	Data                 []string              `json:"data"`
+	AccessLists          []*types.AccessList   `json:"accessLists,omitempty"`
+	GasLimit             []math.HexOrDecimal64 `json:"gasLimit"`
+	Value                []string              `json:"value"`
+	PrivateKey           hexutil.Bytes         `json:"secretKey"`
+}
+
+type fixturePost struct {
+	Root            common.UnprefixedHash `json:"hash"`
+	Logs            common.UnprefixedHash `json:"logs"`
+	ExpectException string                `json:"expectException,omitempty"`
+	Indexes         struct {
+		Data  int `json:"data"`
+		Gas   int `json:"gas"`
+		Value int `json:"value"`
+	} `json:"indexes"`
+}
+
+// fixture converts the case into a state test, expecting the given results.
+func (c *fuzzCase) fixture(res *result) *stateTestFixture {
+	fx := &stateTestFixture{
+		Env: fixtureEnv{
+			Coinbase:  common.UnprefixedAddress(coinbase),
+			GasLimit:  blockGasLimit,
+			Number:    blockNumber,
+			Timestamp: blockTime,
+		},
+		Pre: c.pre,
+		Tx: fixtureTx{
+			Nonce:      math.HexOrDecimal64(c.nonce),
+			Data:       []string{hexutil.Encode(c.data)},
+			GasLimit:   []math.HexOrDecimal64{math.HexOrDecimal64(c.gasLimit)},
+			Value:      []string{hexutil.EncodeBig(c.value)},
+			PrivateKey: crypto.FromECDSA(senderKey),
+		},
+		Post: make(map[string][]fixturePost),
+	}
+	if c.fork.merged {
+		fx.Env.Random = (*math.HexOrDecimal256)(big.NewInt(0))
+	} else {
+		fx.Env.Difficulty = (*math.HexOrDecimal256)(big.NewInt(0x20000))
+	}
+	if c.fork.london {
+		fx.Env.BaseFee = (*math.HexOrDecimal256)(big.NewInt(baseFee))
+		fx.Tx.MaxFeePerGas = (*math.HexOrDecimal256)(c.gasPrice)
+		fx.Tx.MaxPriorityFeePerGas = (*math.HexOrDecimal256)(c.tip)
+	} else {
+		fx.Tx.GasPrice = (*math.HexOrDecimal256)(c.gasPrice)
+	}
+	if c.fork.cancun {
+		excess := math.HexOrDecimal64(0)
+		fx.Env.ExcessBlobGas = &excess
+	}
+	if c.to != nil {
+		fx.Tx.To = c.to.Hex()
+	}
+	if c.accessList != nil {
+		fx.Tx.AccessLists = []*types.AccessList{&c.accessList}
+	}
+	post := fixturePost{
+		Root: common.UnprefixedHash(res.root),
+		Logs: common.UnprefixedHash(res.logs),
+	}
+	if res.err != "" {
+		post.ExpectException = res.err
+	}
+	fx.Post[c.fork.name] = []fixturePost{post}
+	return fx
+}
+
+// result is the outcome of executing a case in a specific configuration.
+type result struct {
+	root common.Hash
+	logs common.Hash
+	err  string
+}
+
+func (r *result) String() string {
+	return fmt.Sprintf("root %x, logs %x, err %q", r.root, r.logs, r.err)
+}
+
+// execConfig is a configuration to execute a case with.
+type execConfig struct {
+	name        string
+	scheme      string
+	snapshotter bool
+	tracer      bool
+}
+
+var (
+	// referenceConfig is the configuration all others are compared against.
+	referenceConfig = execConfig{name: "reference", scheme: rawdb.HashScheme}
+
+	// execConfigs are executed and compared against the reference configuration.
+	execConfigs = []execConfig{
+		{name: "rerun", scheme: rawdb.HashScheme},
+		{name: "tracer", scheme: rawdb.HashScheme, tracer: true},
+		{name: "snapshot", scheme: rawdb.HashScheme, snapshotter: true},
+		{name: "pathdb-snapshot", scheme: rawdb.PathScheme, snapshotter: true},
+	}
+)
+
+// execute runs the state test in the given configuration. If snapshots are
+// enabled, their content is checked against the tries.
+func execute(test *tests.StateTest, fork string, cfg execConfig) (*result, error) {
+	var vmconfig vm.Config
+	if cfg.tracer {
+		vmconfig.Tracer = logger.NewJSONLogger(&logger.Config{}, io.Discard)
+	}
+	st, root, err := test.RunNoVerify(tests.StateSubtest{Fork: fork}, vmconfig, cfg.snapshotter, cfg.scheme)
+	defer st.Close()
+
+	res := &result{root: root}
+	if err != nil {
+		res.err = err.Error()
+	}
+	if st.StateDB == nil {
+		return nil, fmt.Errorf("state test failed to start: %v", err)
+	}
+	blob, _ := rlp.EncodeToBytes(st.StateDB.Logs())
+	res.logs = crypto.Keccak256Hash(blob)
+
+	if cfg.snapshotter {
+		if err := checkSnapshot(st, root); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// checkSnapshot verifies that the snapshot and trie backed views of the state
+// with the given root are identical.
+func checkSnapshot(st tests.StateTestState, root common.Hash) error {
+	if st.Snapshots.Snapshot(root) == nil {
+		return fmt.Errorf("snapshot missing for root %x", root)
+	}
+	// Iterate the account trie and the account snapshot side by side
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), st.TrieDB)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	accIt, err := st.Snapshots.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	trieIt := trie.NewIterator(nodeIt)
+	for trieIt.Next() {
+		if !accIt.Next() {
+			return fmt.Errorf("account %x missing from snapshot", trieIt.Key)
+		}
+		hash := common.BytesToHash(trieIt.Key)
+		if accIt.Hash() != hash {
+			return fmt.Errorf("account mismatch: trie %x, snapshot %x", hash, accIt.Hash())
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(trieIt.Value, &account); err != nil {
+			return err
+		}
+		if !bytes.Equal(types.SlimAccountRLP(account), accIt.Account()) {
+			return fmt.Errorf("account %x content mismatch: trie %x, snapshot %x", hash, types.SlimAccountRLP(account), accIt.Account())
+		}
+		if err := checkStorageSnapshot(st, root, hash, account.Root); err != nil {
+			return err
+		}
+	}
+	if trieIt.Err != nil {
+		return trieIt.Err
+	}
+	if accIt.Next() {
+		return fmt.Errorf("account %x missing from trie", accIt.Hash())
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	// Read back the accounts touched by the fuzzer through the state reader
+	// with and without the snapshot.
+	sdb := st.StateDB.Database()
+	snapState, err := state.New(root, sdb, st.Snapshots)
+	if err != nil {
+		return err
+	}
+	trieState, err := state.New(root, sdb, nil)
+	if err != nil {
+		return err
+	}
+	for _, addr := range targets {
+		if a, b := snapState.GetBalance(addr), trieState.GetBalance(addr); !a.Eq(b) {
+			return fmt.Errorf("balance mismatch for %x: snapshot %v, trie %v", addr, a, b)
+		}
+		if a, b := snapState.GetNonce(addr), trieState.GetNonce(addr); a != b {
+			return fmt.Errorf("nonce mismatch for %x: snapshot %d, trie %d", addr, a, b)
+		}
+		if a, b := snapState.GetCodeHash(addr), trieState.GetCodeHash(addr); a != b {
+			return fmt.Errorf("code hash mismatch for %x: snapshot %x, trie %x", addr, a, b)
+		}
+		for i := 0; i < 8; i++ {
+			key := common.BytesToHash([]byte{byte(i)})
+			if a, b := snapState.GetState(addr, key), trieState.GetState(addr, key); a != b {
+				return fmt.Errorf("storage mismatch for %x/%x: snapshot %x, trie %x", addr, key, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+// checkStorageSnapshot verifies that the storage snapshot of an account matches
+// its storage trie.
+func checkStorageSnapshot(st tests.StateTestState, root common.Hash, account common.Hash, storageRoot common.Hash) error {
+	tr, err := trie.NewStateTrie(trie.StorageTrieID(root, account, storageRoot), st.TrieDB)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	slotIt, err := st.Snapshots.StorageIterator(root, account, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer slotIt.Release()
+
+	trieIt := trie.NewIterator(nodeIt)
+	for trieIt.Next() {
+		if !slotIt.Next() {
+			return fmt.Errorf("slot %x/%x missing from snapshot", account, trieIt.Key)
+		}
+		if slotIt.Hash() != common.BytesToHash(trieIt.Key) {
+			return fmt.Errorf("slot mismatch in %x: trie %x, snapshot %x", account, trieIt.Key, slotIt.Hash())
+		}
+		if !bytes.Equal(trieIt.Value, slotIt.Slot()) {
+			return fmt.Errorf("slot %x/%x content mismatch: trie %x, snapshot %x", account, trieIt.Key, trieIt.Value, slotIt.Slot())
+		}
+	}
+	if trieIt.Err != nil {
+		return trieIt.Err
+	}
+	if slotIt.Next() {
+		return fmt.Errorf("slot %x/%x missing from trie", account, slotIt.Hash())
+	}
+	return slotIt.Error()
+}
+
+// checkRevert executes the transaction on top of the prestate and reverts it,
+// verifying that the journal restores the state to the prestate.
+func checkRevert(c *fuzzCase) error {
+	config, _, err := tests.GetChainConfig(c.fork.name)
+	if err != nil {
+		return err
+	}
+	st := tests.MakePreState(rawdb.NewMemoryDatabase(), c.pre, false, rawdb.HashScheme)
+	defer st.Close()
+
+	deleteEmpty := config.IsEIP158(big.NewInt(blockNumber))
+	preRoot := st.StateDB.IntermediateRoot(deleteEmpty)
+
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash: func(n uint64) common.Hash {
+			return common.BytesToHash(crypto.Keccak256([]byte(new(big.Int).SetUint64(n).String())))
+		},
+		Coinbase:    coinbase,
+		GasLimit:    blockGasLimit,
+		BlockNumber: big.NewInt(blockNumber),
+		Time:        blockTime,
+		Difficulty:  big.NewInt(0x20000),
+	}
+	msg := &core.Message{
+		From:       sender,
+		To:         c.to,
+		Nonce:      c.nonce,
+		Value:      c.value,
+		GasLimit:   c.gasLimit,
+		GasPrice:   c.gasPrice,
+		Data:       c.data,
+		AccessList: c.accessList,
+	}
+	if c.fork.london {
+		context.BaseFee = big.NewInt(baseFee)
+		msg.GasFeeCap, msg.GasTipCap = c.gasPrice, c.tip
+		msg.GasPrice = math.BigMin(new(big.Int).Add(c.tip, context.BaseFee), c.gasPrice)
+	}
+	if c.fork.merged {
+		context.Random, context.Difficulty = new(common.Hash), new(big.Int)
+	}
+	if c.fork.cancun {
+		context.BlobBaseFee = eip4844.CalcBlobFee(0)
+	}
+	evm := vm.NewEVM(context, core.NewEVMTxContext(msg), st.StateDB, config, vm.Config{})
+
+	snapshot := st.StateDB.Snapshot()
+	gaspool := new(core.GasPool).AddGas(blockGasLimit)
+	_, err = core.ApplyMessage(evm, msg, gaspool)
+	st.StateDB.RevertToSnapshot(snapshot)
+
+	if root := st.StateDB.IntermediateRoot(deleteEmpty); root != preRoot {
+		return fmt.Errorf("state not restored after revert (execution error %v): have %x, want %x", err, root, preRoot)
+	}
+	return nil
+}
+
+// Fuzz function must return
+//
+//   - 1 if the fuzzer should increase priority of the
+//     given input during subsequent fuzzing (for example, the input is lexically
+//     correct and was parsed successfully);
+//   - -1 if the input must not be added to corpus even if gives new coverage; and
+//   - 0 otherwise
+//
+// other values are reserved for future use.
+func fuzz(data []byte) int {
+	f := fuzzer{
+		input:     bytes.NewReader(data),
+		exhausted: false,
+	}
+	c := f.generate()
+	if f.exhausted {
+		return 0
+	}
+	if err := check(c); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// check executes the case in all configurations and cross-checks the results.
+// On failure, the returned error carries the case as a state test.
+func check(c *fuzzCase) error {
+	test, err := c.stateTest(&result{})
+	if err != nil {
+		return err
+	}
+	want, err := execute(test, c.fork.name, referenceConfig)
+	if err != nil {
+		return c.failure(want, err)
+	}
+	for i, cfg := range execConfigs {
+		have, err := execute(test, c.fork.name, cfg)
+		if err != nil {
+			return c.failure(want, fmt.Errorf("case %d (%s): %v", i, cfg.name, err))
+		}
+		if *have != *want {
+			return c.failure(want, fmt.Errorf("case %d (%s): result mismatch\nhave: %v\nwant: %v", i, cfg.name, have, want))
+		}
+	}
+	if err := checkRevert(c); err != nil {
+		return c.failure(want, fmt.Errorf("case %d (revert): %v", len(execConfigs), err))
+	}
+	return nil
+}
+
+// stateTest converts the case into an executable state test.
+func (c *fuzzCase) stateTest(res *result) (*tests.StateTest, error) {
+	blob, err := json.Marshal(c.fixture(res))
+	if err != nil {
+		return nil, err
+	}
+	test := new(tests.StateTest)
+	if err := json.Unmarshal(blob, test); err != nil {
+		return nil, err
+	}
+	return test, nil
+}
+
+// failure wraps the error of a failed check along with the state test
+// reproducing it. The state test is also written into the directory configured
+// by the environment, if any.
+func (c *fuzzCase) failure(res *result, err error) error {
+	if res == nil {
+		res = new(result)
+	}
+	blob, jsonErr := json.MarshalIndent(c.fixture(res), "", "  ")
+	if jsonErr != nil {
+		return errors.Join(err, jsonErr)
+	}
+	name := fmt.Sprintf("fuzz_%x", crypto.Keccak256(blob)[:8])
+	blob, _ = json.MarshalIndent(map[string]json.RawMessage{name: blob}, "", "  ")
+
+	if dir := os.Getenv(fixtureDirEnv); dir != "" {
+		if writeErr := os.WriteFile(filepath.Join(dir, name+".json"), blob, 0644); writeErr != nil {
+			err = errors.Join(err, writeErr)
+		}
+	}
+	return fmt.Errorf("%w\nstate test:\n%s", err, blob)
+}
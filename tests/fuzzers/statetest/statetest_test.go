@@ -0,0 +1,105 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package statetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+func Fuzz(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzz(data)
+	})
+}
+
+// TestRandomCases runs the differential checks on a batch of random inputs.
+func TestRandomCases(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		data := make([]byte, 1024)
+		rng.Read(data)
+
+		f := fuzzer{input: bytes.NewReader(data)}
+		c := f.generate()
+		if err := check(c); err != nil {
+			t.Fatalf("input %d: %v", i, err)
+		}
+	}
+}
+
+// TestFailureFixture checks that the state test emitted on failure reproduces
+// the reference execution.
+func TestFailureFixture(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(fixtureDirEnv, dir)
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 10; i++ {
+		data := make([]byte, 1024)
+		rng.Read(data)
+
+		f := fuzzer{input: bytes.NewReader(data)}
+		c := f.generate()
+		test, err := c.stateTest(&result{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := execute(test, c.fork.name, referenceConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.failure(want, errors.New("failure"))
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 10 {
+		t.Fatalf("fixture count mismatch: have %d, want 10", len(files))
+	}
+	for _, file := range files {
+		blob, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fixtures map[string]tests.StateTest
+		if err := json.Unmarshal(blob, &fixtures); err != nil {
+			t.Fatalf("%s: invalid fixture: %v", file, err)
+		}
+		for name, test := range fixtures {
+			for _, subtest := range test.Subtests() {
+				for _, snapshotter := range []bool{false, true} {
+					for _, scheme := range []string{rawdb.HashScheme, rawdb.PathScheme} {
+						if err := test.Run(subtest, vm.Config{}, snapshotter, scheme, func(err error, st *tests.StateTestState) {}); err != nil {
+							t.Errorf("%s/%s: %v", name, subtest.Fork, err)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -18,6 +18,7 @@ package trie
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/trie/trienode"
@@ -159,6 +160,42 @@ func ForGatherChildren(node []byte, onChild func(common.Hash)) {
 	forGatherChildren(mustDecodeNodeUnsafe(nil, node), onChild)
 }
 
+// ForEachChild decodes the provided node and invokes the callbacks for all the
+// hashnode children and all the values within the node, along with their paths
+// relative to the node in hex-nibble form. Value paths don't carry the trailing
+// terminator. Children embedded into the node are traversed as part of it.
+func ForEachChild(node []byte, onChild func(path []byte, hash common.Hash), onValue func(path []byte, value []byte)) error {
+	n, err := decodeNode(nil, node)
+	if err != nil {
+		return err
+	}
+	forEachChild(n, nil, onChild, onValue)
+	return nil
+}
+
+// forEachChild traverses the node hierarchy and invokes the callbacks for all
+// the hashnode children and values, tracking their paths.
+func forEachChild(n node, path []byte, onChild func([]byte, common.Hash), onValue func([]byte, []byte)) {
+	switch n := n.(type) {
+	case *shortNode:
+		forEachChild(n.Val, append(slices.Clone(path), n.Key...), onChild, onValue)
+	case *fullNode:
+		for i := 0; i < 17; i++ {
+			forEachChild(n.Children[i], append(slices.Clone(path), byte(i)), onChild, onValue)
+		}
+	case hashNode:
+		onChild(path, common.BytesToHash(n))
+	case valueNode:
+		if hasTerm(path) {
+			path = path[:len(path)-1]
+		}
+		onValue(path, n)
+	case nil:
+	default:
+		panic(fmt.Sprintf("unknown node type: %T", n))
+	}
+}
+
 // forGatherChildren traverses the node hierarchy and invokes the callback
 // for all the hashnode children.
 func forGatherChildren(n node, onChild func(hash common.Hash)) {
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -111,6 +112,109 @@ func (t *StateTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
 	return t.trie.Prove(key, proofDb)
 }
 
+// ProveMulti constructs a merkle proof for several keys at once. The result
+// contains all encoded nodes on the paths to the given keys, each of them only
+// once, even if it is shared by multiple paths.
+//
+// Keys not contained in the trie are proven absent the same way as in Prove, by
+// the nodes of their longest existing prefix.
+func (t *Trie) ProveMulti(keys [][]byte, proofDb ethdb.KeyValueWriter) error {
+	// Short circuit if the trie is already committed and not usable.
+	if t.committed {
+		return ErrCommitted
+	}
+	// Collect all nodes on the paths to the keys, visiting every node once.
+	hexKeys := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		hexKeys = append(hexKeys, keybytesToHex(key))
+	}
+	var nodes []node
+	if err := t.collectProofNodes(t.root, nil, hexKeys, &nodes); err != nil {
+		return err
+	}
+	hasher := newHasher(false)
+	defer returnHasherToPool(hasher)
+
+	for i, n := range nodes {
+		var hn node
+		n, hn = hasher.proofHash(n)
+		if hash, ok := hn.(hashNode); ok || i == 0 {
+			// If the node's database encoding is a hash (or is the
+			// root node), it becomes a proof element.
+			enc := nodeToBytes(n)
+			if !ok {
+				hash = hasher.hashData(enc)
+			}
+			proofDb.Put(hash, enc)
+		}
+	}
+	return nil
+}
+
+// collectProofNodes gathers the nodes on the paths from tn to each of the given
+// hex keys in pre-order. The keys are relative to the node at the given prefix.
+func (t *Trie) collectProofNodes(tn node, prefix []byte, keys [][]byte, nodes *[]node) error {
+	if tn == nil || len(keys) == 0 {
+		return nil
+	}
+	switch n := tn.(type) {
+	case *shortNode:
+		*nodes = append(*nodes, n)
+
+		// Descend with the keys running through the node, the rest terminate here
+		var rest [][]byte
+		for _, key := range keys {
+			if len(key) > len(n.Key) && bytes.Equal(n.Key, key[:len(n.Key)]) {
+				rest = append(rest, key[len(n.Key):])
+			}
+		}
+		return t.collectProofNodes(n.Val, append(slices.Clone(prefix), n.Key...), rest, nodes)
+
+	case *fullNode:
+		*nodes = append(*nodes, n)
+
+		// Split the keys by the child they run through and descend into each
+		var children [17][][]byte
+		for _, key := range keys {
+			if len(key) > 1 {
+				children[key[0]] = append(children[key[0]], key[1:])
+			}
+		}
+		for i, rest := range children {
+			if err := t.collectProofNodes(n.Children[i], append(slices.Clone(prefix), byte(i)), rest, nodes); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case hashNode:
+		// Retrieve the specified node from the underlying node reader, without
+		// tracking it, same as in Trie.Prove.
+		blob, err := t.reader.node(prefix, common.BytesToHash(n))
+		if err != nil {
+			log.Error("Unhandled trie error in Trie.ProveMulti", "err", err)
+			return err
+		}
+		return t.collectProofNodes(mustDecodeNodeUnsafe(n, blob), prefix, keys, nodes)
+
+	case valueNode:
+		return nil
+
+	default:
+		panic(fmt.Sprintf("%T: invalid node: %v", tn, tn))
+	}
+}
+
+// ProveMulti constructs a merkle proof for several keys at once. The result
+// contains all encoded nodes on the paths to the given keys, each of them only
+// once, even if it is shared by multiple paths.
+//
+// Keys not contained in the trie are proven absent the same way as in Prove, by
+// the nodes of their longest existing prefix.
+func (t *StateTrie) ProveMulti(keys [][]byte, proofDb ethdb.KeyValueWriter) error {
+	return t.trie.ProveMulti(keys, proofDb)
+}
+
 // VerifyProof checks merkle proofs. The given proof must contain the value for
 // key in a trie with the given root hash. VerifyProof returns an error if the
 // proof contains invalid trie nodes or the wrong value.
@@ -140,6 +244,48 @@ func VerifyProof(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueReader)
 	}
 }
 
+// VerifyMultiProof checks a merkle proof for several keys, as created by
+// ProveMulti. The values of the keys are returned in the same order as the
+// keys, with nil for keys proven absent. An error is returned if the proof
+// is incomplete for any of the keys or contains invalid trie nodes.
+//
+// Nodes shared by multiple paths are decoded only once.
+func VerifyMultiProof(rootHash common.Hash, keys [][]byte, proofDb ethdb.KeyValueReader) ([][]byte, error) {
+	var (
+		decoded = make(map[common.Hash]node)
+		values  = make([][]byte, len(keys))
+	)
+	for i, key := range keys {
+		key = keybytesToHex(key)
+		wantHash := rootHash
+		for depth := 0; ; depth++ {
+			n, ok := decoded[wantHash]
+			if !ok {
+				buf, _ := proofDb.Get(wantHash[:])
+				if buf == nil {
+					return nil, fmt.Errorf("key %d: proof node %d (hash %064x) missing", i, depth, wantHash)
+				}
+				var err error
+				if n, err = decodeNode(wantHash[:], buf); err != nil {
+					return nil, fmt.Errorf("key %d: bad proof node %d: %v", i, depth, err)
+				}
+				decoded[wantHash] = n
+			}
+			keyrest, cld := get(n, key, true)
+			if hash, ok := cld.(hashNode); ok {
+				key = keyrest
+				copy(wantHash[:], hash)
+				continue
+			}
+			if value, ok := cld.(valueNode); ok {
+				values[i] = value
+			}
+			break
+		}
+	}
+	return values, nil
+}
+
 // proofToPath converts a merkle proof to trie node path. The main purpose of
 // this function is recovering a node path from the merkle proof stream. All
 // necessary nodes will be resolved and leave the remaining as hashnode.
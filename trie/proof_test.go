@@ -27,8 +27,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie/trienode"
 )
 
 // Prng is a pseudo random number generator seeded by strong randomness.
@@ -165,6 +167,134 @@ func TestMissingKeyProof(t *testing.T) {
 	}
 }
 
+// Tests that a multiproof for a mix of existing and missing keys verifies,
+// contains exactly the union of the individual proofs and works both on an
+// in-memory and on a database backed trie.
+func TestMultiProof(t *testing.T) {
+	tr, vals := randomTrie(500)
+	var (
+		keys [][]byte
+		want [][]byte
+	)
+	for _, kv := range vals {
+		if len(keys) == 50 {
+			break
+		}
+		keys = append(keys, kv.k)
+		want = append(want, kv.v)
+	}
+	for i := 0; i < 10; i++ {
+		keys = append(keys, randBytes(32))
+		want = append(want, nil)
+	}
+	keys = append(keys, keys[0]) // duplicate key
+	want = append(want, want[0])
+
+	check := func(name string, tr *Trie, root common.Hash) {
+		proof := memorydb.New()
+		if err := tr.ProveMulti(keys, proof); err != nil {
+			t.Fatalf("%s: failed to create multiproof: %v", name, err)
+		}
+		union := memorydb.New()
+		for _, key := range keys {
+			if err := tr.Prove(key, union); err != nil {
+				t.Fatalf("%s: failed to create proof: %v", name, err)
+			}
+		}
+		if proof.Len() != union.Len() {
+			t.Fatalf("%s: proof size mismatch: have %d, want %d", name, proof.Len(), union.Len())
+		}
+		it := union.NewIterator(nil, nil)
+		for it.Next() {
+			if have, _ := proof.Get(it.Key()); !bytes.Equal(have, it.Value()) {
+				t.Fatalf("%s: proof node %x mismatch", name, it.Key())
+			}
+		}
+		it.Release()
+
+		values, err := VerifyMultiProof(root, keys, proof)
+		if err != nil {
+			t.Fatalf("%s: failed to verify multiproof: %v", name, err)
+		}
+		for i := range keys {
+			if !bytes.Equal(values[i], want[i]) {
+				t.Fatalf("%s: value %d mismatch: have %x, want %x", name, i, values[i], want[i])
+			}
+		}
+	}
+	root := tr.Hash()
+	check("memory", tr, root)
+
+	// Commit the trie and reopen it, so that nodes need to be resolved
+	db := newTestDatabase(rawdb.NewMemoryDatabase(), rawdb.HashScheme)
+	root, nodes := tr.Commit(false)
+	db.Update(root, types.EmptyRootHash, trienode.NewWithNodeSet(nodes))
+	reopened, _ := New(TrieID(root), db)
+	check("database", reopened, root)
+}
+
+// Tests that multiproofs with any node missing are rejected.
+func TestBadMultiProof(t *testing.T) {
+	tr, vals := randomTrie(200)
+	root := tr.Hash()
+
+	var keys [][]byte
+	for _, kv := range vals {
+		if len(keys) == 20 {
+			break
+		}
+		keys = append(keys, kv.k)
+	}
+	proof := memorydb.New()
+	if err := tr.ProveMulti(keys, proof); err != nil {
+		t.Fatal(err)
+	}
+	it := proof.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		missing := memorydb.New()
+		inner := proof.NewIterator(nil, nil)
+		for inner.Next() {
+			if !bytes.Equal(inner.Key(), it.Key()) {
+				missing.Put(inner.Key(), inner.Value())
+			}
+		}
+		inner.Release()
+
+		if _, err := VerifyMultiProof(root, keys, missing); err == nil {
+			t.Fatalf("proof with missing node %x verified", it.Key())
+		}
+	}
+}
+
+// Tests that absent keys in tiny and empty tries can be proven.
+func TestMissingKeyMultiProof(t *testing.T) {
+	tr := NewEmpty(newTestDatabase(rawdb.NewMemoryDatabase(), rawdb.HashScheme))
+	keys := [][]byte{[]byte("a"), []byte("z")}
+
+	proof := memorydb.New()
+	if err := tr.ProveMulti(keys, proof); err != nil {
+		t.Fatal(err)
+	}
+	if proof.Len() != 0 {
+		t.Fatalf("empty trie proof should have no elements, have %d", proof.Len())
+	}
+	updateString(tr, "k", "v")
+	if err := tr.ProveMulti(append(keys, []byte("k")), proof); err != nil {
+		t.Fatal(err)
+	}
+	if proof.Len() != 1 {
+		t.Fatalf("proof should have one element, have %d", proof.Len())
+	}
+	values, err := VerifyMultiProof(tr.Hash(), append(keys, []byte("k")), proof)
+	if err != nil {
+		t.Fatalf("failed to verify proof: %v", err)
+	}
+	if values[0] != nil || values[1] != nil || string(values[2]) != "v" {
+		t.Fatalf("verified values mismatch: have %x", values)
+	}
+}
+
 // TestRangeProof tests normal range proof with both edge proofs
 // as the existent proof. The test cases are generated randomly.
 func TestRangeProof(t *testing.T) {
@@ -0,0 +1,266 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package cachejournal persists the most recently accessed entries of a clean
+// cache across restarts, so that a node doesn't start with a cold cache.
+//
+// The journal is bound to the persistent state the cache entries were read from,
+// identified by a root hash and an optional state id. Entries are only loaded
+// back if the persistent state is still the same, otherwise they are dropped.
+package cachejournal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// journalVersion is the version of the cache journal format.
+const journalVersion uint64 = 0
+
+// ErrStale is returned if the cache journal was created for a different
+// persistent state than the current one.
+var ErrStale = errors.New("stale cache journal")
+
+// trackerShards is the number of independently locked shards of a tracker, so
+// that concurrent cache reads don't all contend on the same lock.
+const trackerShards = 16
+
+// Tracker keeps track of the most recently accessed cache entries, bounded by
+// the total size of the entries. It's safe for concurrent use.
+//
+// Entries are spread across shards by key hash, each shard holding its own
+// recency order and an equal part of the size limit.
+type Tracker struct {
+	seed   maphash.Seed
+	shards []trackerShard
+}
+
+// trackerShard is a size bounded LRU of cache entries, indexed by key hash to
+// avoid allocating a string key for already tracked entries.
+type trackerShard struct {
+	limit int                                // Maximum size of the tracked entries
+	size  int                                // Total size of the tracked entries
+	lru   lru.BasicLRU[uint64, trackedEntry] // Tracked key hashes along with their entries
+	lock  sync.Mutex
+}
+
+// trackedEntry is a single tracked cache entry.
+type trackedEntry struct {
+	key  string
+	size int
+}
+
+// NewTracker creates a tracker for cache entries of up to limit bytes in total.
+func NewTracker(limit int) *Tracker {
+	return newTracker(limit, trackerShards)
+}
+
+// newTracker creates a tracker for cache entries of up to limit bytes in total,
+// split across the given number of shards.
+func newTracker(limit int, shards int) *Tracker {
+	t := &Tracker{
+		seed:   maphash.MakeSeed(),
+		shards: make([]trackerShard, shards),
+	}
+	for i := range t.shards {
+		t.shards[i].limit = (limit + shards - 1) / shards
+		t.shards[i].lru = lru.NewBasicLRU[uint64, trackedEntry](1 << 30) // Bounded by size instead
+	}
+	return t
+}
+
+// Touch marks the cache entry with the given key and value size as most
+// recently accessed. It's a noop on a nil tracker.
+func (t *Tracker) Touch(key []byte, size int) {
+	if t == nil {
+		return
+	}
+	var (
+		hash  = maphash.Bytes(t.seed, key)
+		shard = &t.shards[hash%uint64(len(t.shards))]
+	)
+	size += len(key)
+
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	// Reuse the tracked key if there's one to avoid an allocation per access
+	entry := trackedEntry{size: size}
+	if old, ok := shard.lru.Get(hash); ok {
+		if old.key == string(key) {
+			if old.size == size {
+				return
+			}
+			entry.key = old.key
+		}
+		shard.size -= old.size
+	}
+	if entry.key == "" {
+		entry.key = string(key)
+	}
+	shard.lru.Add(hash, entry)
+	shard.size += size
+
+	for shard.size > shard.limit {
+		_, evicted, ok := shard.lru.RemoveOldest()
+		if !ok {
+			break
+		}
+		shard.size -= evicted.size
+	}
+}
+
+// Reset drops all the tracked entries. It's a noop on a nil tracker.
+func (t *Tracker) Reset() {
+	if t == nil {
+		return
+	}
+	for i := range t.shards {
+		shard := &t.shards[i]
+
+		shard.lock.Lock()
+		shard.lru.Purge()
+		shard.size = 0
+		shard.lock.Unlock()
+	}
+}
+
+// Keys returns the keys of the tracked entries, shard by shard, each from the
+// least to the most recently accessed one.
+func (t *Tracker) Keys() []string {
+	var keys []string
+	for i := range t.shards {
+		shard := &t.shards[i]
+
+		shard.lock.Lock()
+		for _, hash := range shard.lru.Keys() {
+			entry, _ := shard.lru.Peek(hash)
+			keys = append(keys, entry.key)
+		}
+		shard.lock.Unlock()
+	}
+	return keys
+}
+
+// journal is the on-disk format of the cache journal, followed by the keccak
+// hash of its RLP encoding as a checksum.
+type journal struct {
+	Version uint64
+	Root    common.Hash // Root of the persistent state the entries belong to
+	ID      uint64      // Id of the persistent state the entries belong to
+	Keys    [][]byte
+	Values  [][]byte
+}
+
+// Save writes the tracked entries still present in the cache into the journal
+// file at path, bound to the persistent state with the given root and id. The
+// number and total size of the journaled entries are returned.
+func Save(path string, root common.Hash, id uint64, cache *fastcache.Cache, tracker *Tracker) (int, int, error) {
+	var (
+		keys = tracker.Keys()
+		size int
+		j    = &journal{
+			Version: journalVersion,
+			Root:    root,
+			ID:      id,
+			Keys:    make([][]byte, 0, len(keys)),
+			Values:  make([][]byte, 0, len(keys)),
+		}
+	)
+	for _, key := range keys {
+		value, found := cache.HasGet(nil, []byte(key))
+		if !found {
+			continue // evicted from the cache meanwhile
+		}
+		j.Keys = append(j.Keys, []byte(key))
+		j.Values = append(j.Values, value)
+		size += len(key) + len(value)
+	}
+	blob, err := rlp.EncodeToBytes(j)
+	if err != nil {
+		return 0, 0, err
+	}
+	blob = append(blob, crypto.Keccak256(blob)...)
+
+	// Write the journal atomically, a partial file would be rejected anyway
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, 0, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, blob, 0600); err != nil {
+		return 0, 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+	return len(j.Keys), size, nil
+}
+
+// Load reads the journal file at path and inserts its entries into the cache,
+// if the journal belongs to the persistent state with the given root and id.
+// The journal file is removed afterwards, it's only ever loaded once.
+//
+// The number and total size of the entries in the journal are returned, which
+// are not inserted if ErrStale is returned. If there's no journal file, an
+// error satisfying errors.Is(err, fs.ErrNotExist) is returned.
+func Load(path string, root common.Hash, id uint64, cache *fastcache.Cache) (int, int, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	os.Remove(path)
+
+	if len(blob) < common.HashLength {
+		return 0, 0, errors.New("cache journal too short")
+	}
+	data, checksum := blob[:len(blob)-common.HashLength], blob[len(blob)-common.HashLength:]
+	if !bytes.Equal(crypto.Keccak256(data), checksum) {
+		return 0, 0, errors.New("cache journal checksum mismatch")
+	}
+	var j journal
+	if err := rlp.DecodeBytes(data, &j); err != nil {
+		return 0, 0, err
+	}
+	if j.Version != journalVersion {
+		return 0, 0, fmt.Errorf("unsupported cache journal version: have %d, want %d", j.Version, journalVersion)
+	}
+	if len(j.Keys) != len(j.Values) {
+		return 0, 0, fmt.Errorf("cache journal key/value count mismatch: %d != %d", len(j.Keys), len(j.Values))
+	}
+	var size int
+	for i := range j.Keys {
+		size += len(j.Keys[i]) + len(j.Values[i])
+	}
+	if j.Root != root || j.ID != id {
+		return len(j.Keys), size, fmt.Errorf("%w: have %#x/%d, want %#x/%d", ErrStale, j.Root, j.ID, root, id)
+	}
+	for i := range j.Keys {
+		cache.Set(j.Keys[i], j.Values[i])
+	}
+	return len(j.Keys), size, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cachejournal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTrackerLimit(t *testing.T) {
+	tracker := newTracker(30, 1)
+	tracker.Touch([]byte("a"), 9) // 10 bytes
+	tracker.Touch([]byte("b"), 9)
+	tracker.Touch([]byte("c"), 9)
+	tracker.Touch([]byte("a"), 9) // refresh a, b is the oldest now
+	tracker.Touch([]byte("d"), 9) // evicts b
+
+	if have, want := tracker.Keys(), []string{"c", "a", "d"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("tracked keys mismatch: have %v, want %v", have, want)
+	}
+	tracker.Touch([]byte("c"), 19) // refreshes and grows c, evicts a
+	if have, want := tracker.Keys(), []string{"d", "c"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("tracked keys mismatch: have %v, want %v", have, want)
+	}
+	tracker.Reset()
+	if keys := tracker.Keys(); len(keys) != 0 {
+		t.Fatalf("tracked keys after reset: %v", keys)
+	}
+	// A nil tracker is valid, but doesn't track anything
+	var nilTracker *Tracker
+	nilTracker.Touch([]byte("a"), 1)
+}
+
+func TestTrackerShards(t *testing.T) {
+	tracker := NewTracker(trackerShards * 1000)
+	for i := 0; i < 10000; i++ {
+		tracker.Touch([]byte(fmt.Sprintf("key-%d", i)), 90)
+	}
+	keys := tracker.Keys()
+	if len(keys) == 0 || len(keys) > trackerShards*10 {
+		t.Fatalf("tracked key count out of bounds: %d", len(keys))
+	}
+	for _, key := range keys {
+		if n, _ := strconv.Atoi(strings.TrimPrefix(key, "key-")); n < 10000-trackerShards*100 {
+			t.Fatalf("stale key %s retained", key)
+		}
+	}
+}
+
+func BenchmarkTrackerTouch(b *testing.B) {
+	tracker := NewTracker(1024 * 1024)
+	b.RunParallel(func(pb *testing.PB) {
+		key := make([]byte, common.HashLength)
+		for i := 0; pb.Next(); i++ {
+			key[0] = byte(i)
+			tracker.Touch(key, 100)
+		}
+	})
+}
+
+func TestSaveLoad(t *testing.T) {
+	var (
+		path    = filepath.Join(t.TempDir(), "journal")
+		root    = common.Hash{0x1}
+		cache   = fastcache.New(1024 * 1024)
+		tracker = NewTracker(1024 * 1024)
+	)
+	cache.Set([]byte("hot"), []byte("value"))
+	cache.Set([]byte("empty"), nil)
+	cache.Set([]byte("cold"), []byte("value"))
+	tracker.Touch([]byte("hot"), 5)
+	tracker.Touch([]byte("empty"), 0)
+	tracker.Touch([]byte("evicted"), 5)
+
+	entries, size, err := Save(path, root, 7, cache, tracker)
+	if err != nil {
+		t.Fatalf("failed to save journal: %v", err)
+	}
+	if entries != 2 || size != 13 {
+		t.Fatalf("saved stats mismatch: have %d/%d, want 2/13", entries, size)
+	}
+	// Loading for a different state should drop the entries
+	blob, _ := os.ReadFile(path)
+	loaded := fastcache.New(1024 * 1024)
+	if _, _, err := Load(path, root, 8, loaded); !errors.Is(err, ErrStale) {
+		t.Fatalf("stale journal error mismatch: have %v, want %v", err, ErrStale)
+	}
+	if loaded.Has([]byte("hot")) {
+		t.Fatal("stale entry loaded")
+	}
+	if _, _, err := Load(path, root, 7, loaded); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("journal not removed after loading: %v", err)
+	}
+	// Loading for the same state should insert the entries
+	os.WriteFile(path, blob, 0600)
+	if entries, size, err = Load(path, root, 7, loaded); err != nil {
+		t.Fatalf("failed to load journal: %v", err)
+	}
+	if entries != 2 || size != 13 {
+		t.Fatalf("loaded stats mismatch: have %d/%d, want 2/13", entries, size)
+	}
+	if value := loaded.Get(nil, []byte("hot")); !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("hot entry mismatch: have %q", value)
+	}
+	if !loaded.Has([]byte("empty")) {
+		t.Fatal("empty entry not loaded")
+	}
+	if loaded.Has([]byte("cold")) {
+		t.Fatal("cold entry loaded")
+	}
+	// Corrupted journals should be rejected
+	for i := 0; i < len(blob); i++ {
+		corrupted := bytes.Clone(blob)
+		corrupted[i] ^= 0x1
+		os.WriteFile(path, corrupted, 0600)
+
+		if _, _, err := Load(path, root, 7, fastcache.New(1024*1024)); err == nil {
+			t.Fatalf("corrupted journal (byte %d) loaded", i)
+		}
+	}
+}
@@ -312,6 +312,17 @@ func (db *Database) Journal(root common.Hash) error {
 	return pdb.Journal(root)
 }
 
+// CheckpointHistory writes a consistent copy of the state histories into the
+// given ancient root directory. It's only supported by path-based database and
+// will return an error for others.
+func (db *Database) CheckpointHistory(ancient string) error {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return errors.New("not supported")
+	}
+	return pdb.CheckpointHistory(ancient)
+}
+
 // SetBufferSize sets the node buffer size to the provided value(in bytes).
 // It's only supported by path-based database and will return an error for
 // others.
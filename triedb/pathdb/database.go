@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
+	"github.com/ethereum/go-ethereum/triedb/cachejournal"
 )
 
 const (
@@ -90,10 +92,12 @@ type layer interface {
 
 // Config contains the settings for database.
 type Config struct {
-	StateHistory   uint64 // Number of recent blocks to maintain state history for
-	CleanCacheSize int    // Maximum memory allowance (in bytes) for caching clean nodes
-	DirtyCacheSize int    // Maximum memory allowance (in bytes) for caching dirty nodes
-	ReadOnly       bool   // Flag whether the database is opened in read only mode.
+	StateHistory          uint64 // Number of recent blocks to maintain state history for
+	CleanCacheSize        int    // Maximum memory allowance (in bytes) for caching clean nodes
+	CleanCacheJournal     string // File to persist the hot clean nodes across restarts (empty = disabled)
+	CleanCacheJournalSize int    // Maximum size (in bytes) of the clean nodes to persist
+	DirtyCacheSize        int    // Maximum memory allowance (in bytes) for caching dirty nodes
+	ReadOnly              bool   // Flag whether the database is opened in read only mode.
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -140,6 +144,7 @@ type Database struct {
 	diskdb     ethdb.Database               // Persistent storage for matured trie nodes
 	tree       *layerTree                   // The group for all known layers
 	freezer    ethdb.ResettableAncientStore // Freezer for storing trie histories, nil possible in tests
+	hot        *cachejournal.Tracker        // Tracker of the hot clean nodes to journal, nil if disabled
 	lock       sync.RWMutex                 // Lock to prevent mutations from happening at the same time
 }
 
@@ -176,6 +181,11 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 	if err := db.repairHistory(); err != nil {
 		log.Crit("Failed to repair pathdb", "err", err)
 	}
+	// Warm up the clean cache with the hot nodes of the last run.
+	if config.CleanCacheJournal != "" && config.CleanCacheSize > 0 && !db.readOnly {
+		db.hot = cachejournal.NewTracker(config.CleanCacheJournalSize)
+		db.loadCleanCache()
+	}
 	// Disable database in case node is still in the initial state sync stage.
 	if rawdb.ReadSnapSyncStatusFlag(diskdb) == rawdb.StateSyncRunning && !db.readOnly {
 		if err := db.Disable(); err != nil {
@@ -449,6 +459,28 @@ func (db *Database) Close() error {
 	return db.freezer.Close()
 }
 
+// CheckpointHistory writes a consistent copy of the state history freezer into
+// the given ancient root directory, at the same location as the freezer itself
+// is placed within its own ancient root.
+//
+// The state histories must be checkpointed after the key-value store, ensuring
+// that the copied histories cover the persistent state in the checkpoint. Any
+// extra histories are truncated when the copy is opened.
+func (db *Database) CheckpointHistory(ancient string) error {
+	if db.freezer == nil {
+		return nil
+	}
+	freezer, ok := db.freezer.(ethdb.Checkpointer)
+	if !ok {
+		return errors.New("state history checkpoint not supported")
+	}
+	name := rawdb.MerkleStateFreezerName
+	if db.isVerkle {
+		name = rawdb.VerkleStateFreezerName
+	}
+	return freezer.Checkpoint(filepath.Join(ancient, name))
+}
+
 // Size returns the current storage size of the memory cache in front of the
 // persistent database layer.
 func (db *Database) Size() (diffs common.StorageSize, nodes common.StorageSize) {
@@ -21,8 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/VictoriaMetrics/fastcache"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -32,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
+	"github.com/ethereum/go-ethereum/triedb/cachejournal"
 	"github.com/holiman/uint256"
 )
 
@@ -614,6 +618,72 @@ func TestCorruptedJournal(t *testing.T) {
 	}
 }
 
+func TestCleanCacheJournal(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0)
+	defer tester.release()
+
+	// Enable the clean cache journal and populate the clean cache
+	path := filepath.Join(t.TempDir(), "triecache")
+	tester.db.config.CleanCacheJournal = path
+	tester.db.hot = cachejournal.NewTracker(1024 * 1024)
+
+	// Flush all the states into the disk, so that the verification reads the
+	// nodes from the disk through the clean cache.
+	root := tester.lastHash()
+	if err := tester.db.Commit(root, false); err != nil {
+		t.Fatalf("Failed to commit states, err: %v", err)
+	}
+	if err := tester.verifyState(root); err != nil {
+		t.Fatalf("Invalid state, err: %v", err)
+	}
+	if err := tester.db.Journal(root); err != nil {
+		t.Errorf("Failed to journal, err: %v", err)
+	}
+	keys := tester.db.hot.Keys()
+	if len(keys) == 0 {
+		t.Fatal("No clean nodes tracked")
+	}
+	tester.db.Close()
+
+	// Reopen the database, the journaled nodes should be loaded
+	config := &Config{
+		CleanCacheSize:        16 * 1024,
+		CleanCacheJournal:     path,
+		CleanCacheJournalSize: 1024 * 1024,
+	}
+	tester.db = New(tester.db.diskdb, config, false)
+	cleans := tester.db.tree.bottom().cleans
+	for _, key := range keys {
+		if !cleans.Has([]byte(key)) {
+			t.Fatalf("Clean node %x not loaded", key)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Clean cache journal not removed after loading, err: %v", err)
+	}
+	if err := tester.verifyState(root); err != nil {
+		t.Fatalf("Invalid state after loading the clean cache, err: %v", err)
+	}
+	// Journal the nodes for another persistent state, they should be dropped
+	if _, _, err := cachejournal.Save(path, common.Hash{0x1}, 0, cleans, tester.db.hot); err != nil {
+		t.Fatalf("Failed to save clean cache journal, err: %v", err)
+	}
+	tester.db.Close()
+
+	tester.db = New(tester.db.diskdb, config, false)
+	var stats fastcache.Stats
+	tester.db.tree.bottom().cleans.UpdateStats(&stats)
+	if stats.EntriesCount != 0 {
+		t.Fatalf("Stale clean nodes loaded: %d", stats.EntriesCount)
+	}
+}
+
 // TestTailTruncateHistory function is designed to test a specific edge case where,
 // when history objects are removed from the end, it should trigger a state flush
 // if the ID of the new tail object is even higher than the persisted state ID.
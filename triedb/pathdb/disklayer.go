@@ -124,6 +124,7 @@ func (dl *diskLayer) node(owner common.Hash, path []byte, depth int) ([]byte, co
 		if blob := dl.cleans.Get(nil, key); len(blob) > 0 {
 			cleanHitMeter.Mark(1)
 			cleanReadMeter.Mark(int64(len(blob)))
+			dl.db.hot.Touch(key, len(blob))
 			return blob, h.hash(blob), &nodeLoc{loc: locCleanCache, depth: depth}, nil
 		}
 		cleanMissMeter.Mark(1)
@@ -138,6 +139,7 @@ func (dl *diskLayer) node(owner common.Hash, path []byte, depth int) ([]byte, co
 	if dl.cleans != nil && len(blob) > 0 {
 		dl.cleans.Set(key, blob)
 		cleanWriteMeter.Mark(int64(len(blob)))
+		dl.db.hot.Touch(key, len(blob))
 	}
 
 	return blob, h.hash(blob), &nodeLoc{loc: locDiskLayer, depth: depth}, nil
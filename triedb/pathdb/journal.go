@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
+	"github.com/ethereum/go-ethereum/triedb/cachejournal"
 )
 
 var (
@@ -378,8 +380,53 @@ func (db *Database) Journal(root common.Hash) error {
 	// Store the journal into the database and return
 	rawdb.WriteTrieJournal(db.diskdb, journal.Bytes())
 
+	// Persist the hot clean nodes too, they can be reused as long as the
+	// persistent state is not changed.
+	if db.hot != nil {
+		db.journalCleanCache(diskRoot)
+	}
+
 	// Set the db in read only mode to reject all following mutations
 	db.readOnly = true
 	log.Info("Persisted dirty state to disk", "size", common.StorageSize(journal.Len()), "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
+
+// loadCleanCache warms up the clean cache of the disk layer with the nodes of
+// the clean cache journal, if it was created for the current persistent state.
+func (db *Database) loadCleanCache() {
+	var (
+		start    = time.Now()
+		diskRoot = types.EmptyRootHash
+		cleans   = db.tree.bottom().cleans
+	)
+	if blob := rawdb.ReadAccountTrieNode(db.diskdb, nil); len(blob) > 0 {
+		diskRoot = crypto.Keccak256Hash(blob)
+	}
+	nodes, size, err := cachejournal.Load(db.config.CleanCacheJournal, diskRoot, rawdb.ReadPersistentStateID(db.diskdb), cleans)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return
+	case errors.Is(err, cachejournal.ErrStale):
+		cleanJournalDroppedGauge.Update(int64(size))
+		log.Info("Dropped stale clean cache journal", "nodes", nodes, "size", common.StorageSize(size), "err", err)
+	case err != nil:
+		log.Warn("Failed to load clean cache journal", "err", err)
+	default:
+		cleanJournalLoadedGauge.Update(int64(size))
+		log.Info("Loaded clean cache journal", "nodes", nodes, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// journalCleanCache persists the hot nodes of the clean cache, bound to the
+// given persistent state root.
+func (db *Database) journalCleanCache(diskRoot common.Hash) {
+	start := time.Now()
+	nodes, size, err := cachejournal.Save(db.config.CleanCacheJournal, diskRoot, rawdb.ReadPersistentStateID(db.diskdb), db.tree.bottom().cleans, db.hot)
+	if err != nil {
+		log.Warn("Failed to journal clean cache", "err", err)
+		return
+	}
+	cleanJournalSavedGauge.Update(int64(size))
+	log.Info("Persisted clean cache journal", "nodes", nodes, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(start)))
+}
@@ -24,6 +24,10 @@ var (
 	cleanReadMeter  = metrics.NewRegisteredMeter("pathdb/clean/read", nil)
 	cleanWriteMeter = metrics.NewRegisteredMeter("pathdb/clean/write", nil)
 
+	cleanJournalSavedGauge   = metrics.NewRegisteredGauge("pathdb/clean/journal/saved", nil)
+	cleanJournalLoadedGauge  = metrics.NewRegisteredGauge("pathdb/clean/journal/loaded", nil)
+	cleanJournalDroppedGauge = metrics.NewRegisteredGauge("pathdb/clean/journal/dropped", nil)
+
 	dirtyHitMeter         = metrics.NewRegisteredMeter("pathdb/dirty/hit", nil)
 	dirtyMissMeter        = metrics.NewRegisteredMeter("pathdb/dirty/miss", nil)
 	dirtyReadMeter        = metrics.NewRegisteredMeter("pathdb/dirty/read", nil)
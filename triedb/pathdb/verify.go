@@ -0,0 +1,486 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// NodeRef identifies a trie node in the path-based database.
+type NodeRef struct {
+	Owner common.Hash // Owner of the trie, zero for the account trie
+	Path  []byte      // Path of the node in hex-nibble form
+	Hash  common.Hash // Hash of the node, as referenced by the parent or as stored
+}
+
+// String implements fmt.Stringer.
+func (ref NodeRef) String() string {
+	if ref.Owner == (common.Hash{}) {
+		return fmt.Sprintf("account path %x hash %x", ref.Path, ref.Hash)
+	}
+	return fmt.Sprintf("storage owner %x path %x hash %x", ref.Owner, ref.Path, ref.Hash)
+}
+
+// VerifyReport contains the result of a consistency check of the persistent
+// state in a path-based database.
+type VerifyReport struct {
+	Root    common.Hash // Root of the persistent state
+	StateID uint64      // ID of the persistent state
+
+	Accounts uint64 // Number of accounts in the persistent state
+	Slots    uint64 // Number of storage slots in the persistent state
+	Nodes    uint64 // Number of verified trie nodes
+
+	Missing   []NodeRef // Referenced nodes missing from the database
+	Corrupted []NodeRef // Nodes mismatching the hash referenced by their parent or undecodable
+	Orphans   []NodeRef // Nodes not reachable from the persistent state root
+	Deleted   int       // Number of orphan nodes deleted in repair mode
+
+	HistoryTail   uint64   // Number of state histories pruned from the tail
+	HistoryHead   uint64   // Number of state histories, including the pruned ones
+	HistoryErrors []string // Inconsistencies found in the state histories
+	TruncatedHead uint64   // Number of state histories truncated from the head in repair mode
+	TruncatedTail uint64   // Number of state histories truncated from the tail in repair mode
+	FixedLookups  int      // Number of state id lookups rewritten in repair mode
+}
+
+// Healthy reports whether no inconsistencies were found.
+func (r *VerifyReport) Healthy() bool {
+	return len(r.Missing) == 0 && len(r.Corrupted) == 0 && len(r.Orphans) == 0 && len(r.HistoryErrors) == 0
+}
+
+// verifier walks the persistent state trie and compares the reachable nodes
+// against the trie nodes stored in the database, both visited in the same
+// lexicographic order.
+type verifier struct {
+	db     ethdb.KeyValueStore
+	repair bool
+	report *VerifyReport
+	batch  ethdb.Batch
+
+	accounts    ethdb.Iterator // Iterator over the stored account trie nodes
+	accountsOk  bool           // Flag whether the account iterator is positioned on an item
+	storages    ethdb.Iterator // Iterator over the stored storage trie nodes
+	storagesOk  bool           // Flag whether the storage iterator is positioned on an item
+	unverified  []NodeRef      // Roots of the subtries which couldn't be verified
+	start       time.Time
+	lastLogTime time.Time
+}
+
+// Verify checks the consistency of the persistent state in the given path-based
+// database. The persisted trie is walked from its root, verifying every node
+// against the hash referenced by its parent and reporting the missing nodes, as
+// well as the stored nodes which are not reachable. The state histories in the
+// optional freezer are checked for continuity, against the persistent state and
+// against the state id lookups.
+//
+// In repair mode, unreachable nodes are deleted and corrupted state histories
+// are truncated, as long as the histories covering the persistent state are
+// retained. Nodes beneath missing or corrupted nodes are never deleted.
+//
+// The database must not be in use while being verified.
+func Verify(db ethdb.KeyValueStore, freezer ethdb.AncientStore, repair bool) (*VerifyReport, error) {
+	v := &verifier{
+		db:          db,
+		repair:      repair,
+		report:      &VerifyReport{StateID: rawdb.ReadPersistentStateID(db)},
+		batch:       db.NewBatch(),
+		accounts:    db.NewIterator(rawdb.TrieNodeAccountPrefix, nil),
+		storages:    db.NewIterator(rawdb.TrieNodeStoragePrefix, nil),
+		start:       time.Now(),
+		lastLogTime: time.Now(),
+	}
+	defer v.accounts.Release()
+	defer v.storages.Release()
+
+	v.accountsOk = v.accounts.Next()
+	v.storagesOk = v.storages.Next()
+
+	v.report.Root = types.EmptyRootHash
+	if blob := rawdb.ReadAccountTrieNode(db, nil); len(blob) > 0 {
+		v.report.Root = crypto.Keccak256Hash(blob)
+		if err := v.walk(common.Hash{}, []byte{}, v.report.Root); err != nil {
+			return nil, err
+		}
+	}
+	// Everything left in the iterators is unreachable
+	if err := v.skipAccounts(nil); err != nil {
+		return nil, err
+	}
+	if err := v.skipStorages(nil); err != nil {
+		return nil, err
+	}
+	if err := v.accounts.Error(); err != nil {
+		return nil, err
+	}
+	if err := v.storages.Error(); err != nil {
+		return nil, err
+	}
+	if repair {
+		if err := v.batch.Write(); err != nil {
+			return nil, err
+		}
+	}
+	log.Info("Verified persistent state", "root", v.report.Root, "id", v.report.StateID, "accounts", v.report.Accounts, "slots", v.report.Slots,
+		"nodes", v.report.Nodes, "missing", len(v.report.Missing), "corrupted", len(v.report.Corrupted), "orphans", len(v.report.Orphans),
+		"elapsed", common.PrettyDuration(time.Since(v.start)))
+
+	if freezer != nil {
+		if err := v.verifyHistory(freezer); err != nil {
+			return nil, err
+		}
+	}
+	return v.report, nil
+}
+
+// walk verifies the trie node with the given owner, path and expected hash, as
+// well as all the nodes and storage tries beneath it.
+func (v *verifier) walk(owner common.Hash, path []byte, hash common.Hash) error {
+	var blob []byte
+	if owner == (common.Hash{}) {
+		if err := v.skipAccounts(path); err != nil {
+			return err
+		}
+		blob = rawdb.ReadAccountTrieNode(v.db, path)
+	} else {
+		if err := v.skipStorages(storageNodeKey(owner, path)); err != nil {
+			return err
+		}
+		blob = rawdb.ReadStorageTrieNode(v.db, owner, path)
+	}
+	ref := NodeRef{Owner: owner, Path: path, Hash: hash}
+	if len(blob) == 0 {
+		log.Error("Missing trie node", "owner", owner, "path", fmt.Sprintf("%x", path), "hash", hash)
+		v.report.Missing = append(v.report.Missing, ref)
+		v.unverified = append(v.unverified, ref)
+		return nil
+	}
+	if have := crypto.Keccak256Hash(blob); have != hash {
+		log.Error("Corrupted trie node", "owner", owner, "path", fmt.Sprintf("%x", path), "hash", have, "want", hash)
+		v.report.Corrupted = append(v.report.Corrupted, ref)
+		v.unverified = append(v.unverified, ref)
+		return nil
+	}
+	v.report.Nodes++
+	if time.Since(v.lastLogTime) > 8*time.Second {
+		log.Info("Verifying persistent state", "accounts", v.report.Accounts, "slots", v.report.Slots, "nodes", v.report.Nodes,
+			"elapsed", common.PrettyDuration(time.Since(v.start)))
+		v.lastLogTime = time.Now()
+	}
+	// Gather the children and values in order, the storage tries need to be
+	// visited in the order of the account hashes.
+	type item struct {
+		path  []byte
+		hash  common.Hash
+		value []byte
+	}
+	var items []item
+	err := trie.ForEachChild(blob, func(child []byte, hash common.Hash) {
+		items = append(items, item{path: append(common.CopyBytes(path), child...), hash: hash})
+	}, func(child []byte, value []byte) {
+		items = append(items, item{path: append(common.CopyBytes(path), child...), value: value})
+	})
+	if err != nil {
+		log.Error("Undecodable trie node", "owner", owner, "path", fmt.Sprintf("%x", path), "err", err)
+		v.report.Corrupted = append(v.report.Corrupted, ref)
+		v.unverified = append(v.unverified, ref)
+		return nil
+	}
+	for _, item := range items {
+		if item.value == nil {
+			if err := v.walk(owner, item.path, item.hash); err != nil {
+				return err
+			}
+			continue
+		}
+		if owner != (common.Hash{}) {
+			v.report.Slots++
+			continue
+		}
+		v.report.Accounts++
+
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(item.value, &account); err != nil {
+			log.Error("Undecodable account", "path", fmt.Sprintf("%x", item.path), "err", err)
+			v.report.Corrupted = append(v.report.Corrupted, NodeRef{Path: item.path})
+			continue
+		}
+		if account.Root != types.EmptyRootHash {
+			if err := v.walk(common.BytesToHash(hexToBytes(item.path)), nil, account.Root); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skipAccounts advances the account node iterator up to and including the node
+// with the given path, handling all skipped nodes as orphans. A nil path skips
+// all the remaining nodes, whereas the root node has an empty non-nil path.
+func (v *verifier) skipAccounts(path []byte) error {
+	for ; v.accountsOk; v.accountsOk = v.accounts.Next() {
+		key := v.accounts.Key()
+		ok, stored := rawdb.ResolveAccountTrieNodeKey(key)
+		if !ok {
+			continue
+		}
+		if path != nil {
+			if cmp := bytes.Compare(stored, path); cmp == 0 {
+				v.accountsOk = v.accounts.Next()
+				return nil
+			} else if cmp > 0 {
+				return nil
+			}
+		}
+		if err := v.orphan(key, NodeRef{Path: common.CopyBytes(stored), Hash: crypto.Keccak256Hash(v.accounts.Value())}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipStorages advances the storage node iterator up to and including the node
+// with the given database key, handling all skipped nodes as orphans. A nil key
+// skips all the remaining nodes.
+func (v *verifier) skipStorages(target []byte) error {
+	for ; v.storagesOk; v.storagesOk = v.storages.Next() {
+		key := v.storages.Key()
+		ok, owner, stored := rawdb.ResolveStorageTrieNode(key)
+		if !ok {
+			continue
+		}
+		if target != nil {
+			if cmp := bytes.Compare(key, target); cmp == 0 {
+				v.storagesOk = v.storages.Next()
+				return nil
+			} else if cmp > 0 {
+				return nil
+			}
+		}
+		if err := v.orphan(key, NodeRef{Owner: owner, Path: common.CopyBytes(stored), Hash: crypto.Keccak256Hash(v.storages.Value())}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orphan handles a stored node which is not reachable from the persistent state
+// root, unless it's beneath a node that couldn't be verified.
+func (v *verifier) orphan(key []byte, ref NodeRef) error {
+	for _, root := range v.unverified {
+		if root.Owner == ref.Owner && bytes.HasPrefix(ref.Path, root.Path) {
+			return nil
+		}
+		// The storage tries of the accounts beneath an unverified account trie
+		// node are unknown
+		if root.Owner == (common.Hash{}) && ref.Owner != (common.Hash{}) && bytes.HasPrefix(bytesToHex(ref.Owner.Bytes()), root.Path) {
+			return nil
+		}
+	}
+	log.Warn("Unreachable trie node", "owner", ref.Owner, "path", fmt.Sprintf("%x", ref.Path), "hash", ref.Hash)
+	v.report.Orphans = append(v.report.Orphans, ref)
+	if !v.repair {
+		return nil
+	}
+	if err := v.batch.Delete(common.CopyBytes(key)); err != nil {
+		return err
+	}
+	v.report.Deleted++
+	if v.batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := v.batch.Write(); err != nil {
+			return err
+		}
+		v.batch.Reset()
+	}
+	return nil
+}
+
+// verifyHistory checks the state histories for continuity and against the
+// persistent state, truncating the corrupted ones in repair mode.
+func (v *verifier) verifyHistory(freezer ethdb.AncientStore) error {
+	tail, err := freezer.Tail()
+	if err != nil {
+		return err
+	}
+	head, err := freezer.Ancients()
+	if err != nil {
+		return err
+	}
+	r := v.report
+	r.HistoryTail, r.HistoryHead = tail, head
+
+	issue := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		log.Error("Inconsistent state history", "err", msg)
+		r.HistoryErrors = append(r.HistoryErrors, msg)
+	}
+	if r.StateID == 0 && head != 0 {
+		issue("state histories (%d, %d] without persistent state", tail, head)
+		if !v.repair {
+			return nil
+		}
+		return v.truncateHistory(freezer, 0, head, tail)
+	}
+	if r.StateID > head || r.StateID < tail {
+		// The histories can't be truncated to fix these, as the persistent
+		// state is not covered by the remaining histories either.
+		issue("persistent state %d is not covered by state histories (%d, %d]", r.StateID, tail, head)
+		return nil
+	}
+	var (
+		prev    *meta
+		roots   = make(map[common.Hash]uint64)
+		newTail = tail
+		newHead = head
+	)
+	// corrupt marks the given history as corrupted and determines the part of
+	// the histories to truncate, while retaining the persistent state.
+	corrupt := func(id uint64, link bool) {
+		switch {
+		case id > r.StateID:
+			newHead = min(newHead, id-1)
+		case link:
+			newTail = max(newTail, id-1)
+		default:
+			newTail = max(newTail, id)
+		}
+	}
+	for id := tail + 1; id <= head; id++ {
+		var m meta
+		if err := m.decode(rawdb.ReadStateHistoryMeta(freezer, id)); err != nil {
+			issue("state history %d is corrupted: %v", id, err)
+			corrupt(id, false)
+			prev = nil
+			continue
+		}
+		if prev != nil && m.parent != prev.root {
+			issue("state history %d is not linked to %d: parent %x, want %x", id, id-1, m.parent, prev.root)
+			corrupt(id, true)
+		}
+		if id == r.StateID && m.root != r.Root {
+			issue("state history %d mismatches persistent state root: %x, want %x", id, m.root, r.Root)
+		}
+		roots[m.root] = id
+		prev = &m
+	}
+	if v.repair && (newHead < head || newTail > tail) {
+		if err := v.truncateHistory(freezer, newHead, head, tail); err != nil {
+			return err
+		}
+		if err := v.truncateTail(freezer, tail, newTail); err != nil {
+			return err
+		}
+		for root, id := range roots {
+			if id > newHead || id <= newTail {
+				delete(roots, root)
+			}
+		}
+	}
+	// Ensure the state id lookups point to the latest histories of the roots
+	for root, id := range roots {
+		if lookup := rawdb.ReadStateID(v.db, root); lookup == nil || *lookup != id {
+			issue("state id lookup of %x is inconsistent with state history %d", root, id)
+			if v.repair {
+				rawdb.WriteStateID(v.db, root, id)
+				r.FixedLookups++
+			}
+		}
+	}
+	log.Info("Verified state histories", "tail", r.HistoryTail, "head", r.HistoryHead, "errors", len(r.HistoryErrors),
+		"truncatedhead", r.TruncatedHead, "truncatedtail", r.TruncatedTail)
+	return nil
+}
+
+// truncateHistory removes the state histories in (nhead, ohead] along with their
+// state id lookups, skipping the lookups of the undecodable histories.
+func (v *verifier) truncateHistory(freezer ethdb.AncientStore, nhead, ohead, tail uint64) error {
+	if nhead >= ohead {
+		return nil
+	}
+	for id := max(nhead, tail) + 1; id <= ohead; id++ {
+		v.deleteLookup(freezer, id)
+	}
+	if _, err := freezer.TruncateHead(nhead); err != nil {
+		return err
+	}
+	v.report.TruncatedHead = ohead - nhead
+	return nil
+}
+
+// truncateTail removes the state histories in (otail, ntail] along with their
+// state id lookups, skipping the lookups of the undecodable histories.
+func (v *verifier) truncateTail(freezer ethdb.AncientStore, otail, ntail uint64) error {
+	if ntail <= otail {
+		return nil
+	}
+	for id := otail + 1; id <= ntail; id++ {
+		v.deleteLookup(freezer, id)
+	}
+	if _, err := freezer.TruncateTail(ntail); err != nil {
+		return err
+	}
+	v.report.TruncatedTail = ntail - otail
+	return nil
+}
+
+// deleteLookup deletes the state id lookup of the given state history, if the
+// history is decodable and the lookup points to it.
+func (v *verifier) deleteLookup(freezer ethdb.AncientReader, id uint64) {
+	var m meta
+	if err := m.decode(rawdb.ReadStateHistoryMeta(freezer, id)); err != nil {
+		return
+	}
+	if lookup := rawdb.ReadStateID(v.db, m.root); lookup != nil && *lookup == id {
+		rawdb.DeleteStateID(v.db, m.root)
+	}
+}
+
+// storageNodeKey returns the database key of the storage trie node with the
+// given owner and path.
+func storageNodeKey(owner common.Hash, path []byte) []byte {
+	key := append(common.CopyBytes(rawdb.TrieNodeStoragePrefix), owner.Bytes()...)
+	return append(key, path...)
+}
+
+// hexToBytes packs the given hex-nibble path of even length into bytes.
+func hexToBytes(hex []byte) []byte {
+	key := make([]byte, len(hex)/2)
+	for i := range key {
+		key[i] = hex[2*i]<<4 | hex[2*i+1]
+	}
+	return key
+}
+
+// bytesToHex expands the given bytes into a hex-nibble path.
+func bytesToHex(key []byte) []byte {
+	hex := make([]byte, len(key)*2)
+	for i, b := range key {
+		hex[2*i], hex[2*i+1] = b/16, b%16
+	}
+	return hex
+}
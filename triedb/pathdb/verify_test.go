@@ -0,0 +1,121 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestVerify(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0)
+	defer tester.release()
+
+	if err := tester.db.Commit(tester.lastHash(), false); err != nil {
+		t.Fatalf("Failed to cap database, err: %v", err)
+	}
+	var (
+		disk    = tester.db.diskdb
+		freezer = tester.db.freezer
+	)
+	report, err := Verify(disk, freezer, false)
+	if err != nil {
+		t.Fatalf("Failed to verify database: %v", err)
+	}
+	if !report.Healthy() {
+		t.Fatalf("Unexpected inconsistencies: %+v", report)
+	}
+	if report.Root != tester.lastHash() {
+		t.Fatalf("Unexpected root, want: %x, got: %x", tester.lastHash(), report.Root)
+	}
+	if report.StateID != uint64(len(tester.roots)) || report.HistoryHead != uint64(len(tester.roots)) {
+		t.Fatalf("Unexpected state id %d and history head %d", report.StateID, report.HistoryHead)
+	}
+	if report.Accounts != uint64(len(tester.accounts)) {
+		t.Fatalf("Unexpected account number, want: %d, got: %d", len(tester.accounts), report.Accounts)
+	}
+	// Remove a node from the account trie, the nodes beneath it can't be
+	// verified anymore but must not be reported as unreachable.
+	var missing []byte
+	it := disk.NewIterator(rawdb.TrieNodeAccountPrefix, nil)
+	for it.Next() {
+		if ok, path := rawdb.ResolveAccountTrieNodeKey(it.Key()); ok && len(path) == 1 && path[0] != 0xf {
+			missing = common.CopyBytes(path)
+			break
+		}
+	}
+	it.Release()
+	if missing == nil {
+		t.Fatal("No account trie node to remove")
+	}
+	rawdb.DeleteAccountTrieNode(disk, missing)
+
+	// Inject unreachable nodes, an extra corrupted state history and remove a
+	// state id lookup.
+	var (
+		orphanPath  = []byte{0xf, 0xf, 0xf, 0xf, 0xf, 0xf}
+		orphanOwner = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	)
+	rawdb.WriteAccountTrieNode(disk, orphanPath, []byte{0x1, 0x2, 0x3})
+	rawdb.WriteStorageTrieNode(disk, orphanOwner, nil, []byte{0x4, 0x5, 0x6})
+	rawdb.WriteStateHistory(freezer, uint64(len(tester.roots))+1, []byte{0xff}, nil, nil, nil, nil)
+	rawdb.DeleteStateID(disk, tester.roots[5])
+
+	report, err = Verify(disk, freezer, false)
+	if err != nil {
+		t.Fatalf("Failed to verify database: %v", err)
+	}
+	if len(report.Missing) != 1 || !bytes.Equal(report.Missing[0].Path, missing) {
+		t.Fatalf("Unexpected missing nodes: %v", report.Missing)
+	}
+	if len(report.Orphans) != 2 {
+		t.Fatalf("Unexpected unreachable nodes: %v", report.Orphans)
+	}
+	if !bytes.Equal(report.Orphans[0].Path, orphanPath) || report.Orphans[1].Owner != orphanOwner {
+		t.Fatalf("Unexpected unreachable nodes: %v", report.Orphans)
+	}
+	if len(report.HistoryErrors) != 2 {
+		t.Fatalf("Unexpected history errors: %v", report.HistoryErrors)
+	}
+	// Repair the database and ensure only the missing node remains
+	report, err = Verify(disk, freezer, true)
+	if err != nil {
+		t.Fatalf("Failed to repair database: %v", err)
+	}
+	if report.Deleted != 2 || report.TruncatedHead != 1 || report.FixedLookups != 1 {
+		t.Fatalf("Unexpected repairs, deleted: %d, truncated: %d, lookups: %d", report.Deleted, report.TruncatedHead, report.FixedLookups)
+	}
+	report, err = Verify(disk, freezer, false)
+	if err != nil {
+		t.Fatalf("Failed to verify database: %v", err)
+	}
+	if len(report.Missing) != 1 || len(report.Orphans) != 0 || len(report.HistoryErrors) != 0 {
+		t.Fatalf("Unexpected inconsistencies after repair: %+v", report)
+	}
+	if report.HistoryHead != uint64(len(tester.roots)) {
+		t.Fatalf("Unexpected history head, want: %d, got: %d", len(tester.roots), report.HistoryHead)
+	}
+}